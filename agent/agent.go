@@ -58,6 +58,15 @@ const (
 	StorageAddr      = "STORAGE_ADDR"
 	AgentServiceName = "AGENT_SERVICE_NAME"
 	MongoOplogSize   = "MONGO_OPLOG_SIZE"
+
+	// The following keys hold the API server's per-entity
+	// limits; see apiserver.RateLimitConfig.
+	APIUserRequestRate     = "API_USER_REQUEST_RATE"
+	APIUserRequestBurst    = "API_USER_REQUEST_BURST"
+	APIUserMaxConnections  = "API_USER_MAX_CONNECTIONS"
+	APIAgentRequestRate    = "API_AGENT_REQUEST_RATE"
+	APIAgentRequestBurst   = "API_AGENT_REQUEST_BURST"
+	APIAgentMaxConnections = "API_AGENT_MAX_CONNECTIONS"
 )
 
 // The Config interface is the sole way that the agent gets access to the
@@ -5,7 +5,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
+	"sync"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -35,7 +37,7 @@ When adding a new machine, you may specify constraints for the machine to be
 provisioned.  Constraints cannot be combined with deploying a container to an
 existing machine.
 
-Currently, the only supported container type is lxc.
+The supported container types are lxc, kvm and nspawn.
 
 Machines are created in a clean state and ready to have units deployed.
 
@@ -43,14 +45,33 @@ This command also supports manual provisioning of existing machines via SSH. The
 target machine must be able to communicate with the API server, and be able to
 access the environment storage.
 
+Many existing machines can be provisioned at once with --inventory, which names
+a YAML file listing the hosts to provision:
+
+   defaults:
+     user: admin              (the user to log in as; defaults to your own)
+     key: ~/.ssh/fleet_rsa    (an additional SSH private key to use)
+   hosts:
+     - host: 10.10.0.3
+     - host: 10.10.0.4
+       user: root             (any of the defaults may be overridden per host)
+       series: trusty         (record this series rather than the detected one)
+
+Hosts are provisioned in parallel, and the outcome for each host is reported.
+Passwordless sudo must be available on each host, as there is no opportunity
+to answer sudo prompts. Hosts that are already provisioned are skipped, so the
+command may be re-run after fixing any failures.
+
 Examples:
    juju add-machine                      (starts a new machine)
    juju add-machine -n 2                 (starts 2 new machines)
    juju add-machine lxc                  (starts a new machine with an lxc container)
    juju add-machine lxc -n 2             (starts 2 new machines with an lxc container)
    juju add-machine lxc:4                (starts a new lxc container on machine 4)
+   juju add-machine nspawn:4             (starts a new nspawn container on machine 4)
    juju add-machine --constraints mem=8G (starts a machine with at least 8GB RAM)
    juju add-machine ssh:user@10.10.0.3   (manually provisions a machine with ssh)
+   juju add-machine --inventory hosts.yaml (manually provisions the listed machines)
 
 See Also:
    juju help constraints
@@ -65,6 +86,8 @@ type AddMachineCommand struct {
 	Constraints constraints.Value
 	// Placement is passed verbatim to the API, to be parsed and evaluated server-side.
 	Placement *instance.Placement
+	// Inventory is the path of a file listing hosts to provision manually.
+	Inventory string
 
 	NumMachines int
 }
@@ -72,7 +95,7 @@ type AddMachineCommand struct {
 func (c *AddMachineCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "add-machine",
-		Args:    "[<container>:machine | <container> | ssh:[user@]host]",
+		Args:    "[<container>:machine | <container> | ssh:[user@]host | --inventory <file>]",
 		Purpose: "start a new, empty machine and optionally a container, or add a container to a machine",
 		Doc:     addMachineDoc,
 	}
@@ -82,6 +105,7 @@ func (c *AddMachineCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Series, "series", "", "the charm series")
 	f.IntVar(&c.NumMachines, "n", 1, "The number of machines to add")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "additional machine constraints")
+	f.StringVar(&c.Inventory, "inventory", "", "path of a file listing existing machines to provision via SSH")
 }
 
 func (c *AddMachineCommand) Init(args []string) error {
@@ -92,6 +116,12 @@ func (c *AddMachineCommand) Init(args []string) error {
 	if err != nil {
 		return err
 	}
+	if c.Inventory != "" {
+		if placement != "" || c.NumMachines != 1 || c.Series != "" || !constraints.IsEmpty(&c.Constraints) {
+			return fmt.Errorf("--inventory cannot be combined with other arguments")
+		}
+		return nil
+	}
 	c.Placement, err = instance.ParsePlacement(placement)
 	if err == instance.ErrPlacementScopeMissing {
 		placement = "env-uuid" + ":" + placement
@@ -120,6 +150,10 @@ var getAddMachineAPI = func(c *AddMachineCommand) (addMachineAPI, error) {
 
 var manualProvisioner = manual.ProvisionMachine
 
+// inventoryParallelism is the maximum number of hosts
+// from an inventory that are provisioned at the same time.
+const inventoryParallelism = 10
+
 func (c *AddMachineCommand) Run(ctx *cmd.Context) error {
 	client, err := getAddMachineAPI(c)
 	if err != nil {
@@ -127,6 +161,10 @@ func (c *AddMachineCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
+	if c.Inventory != "" {
+		return c.provisionInventory(ctx, client)
+	}
+
 	if c.Placement != nil && c.Placement.Scope == "ssh" {
 		args := manual.ProvisionMachineArgs{
 			Host:   c.Placement.Directive,
@@ -195,3 +233,57 @@ func (c *AddMachineCommand) Run(ctx *cmd.Context) error {
 	}
 	return nil
 }
+
+// provisionInventory manually provisions all of the hosts listed in
+// the command's inventory file, reporting the outcome for each host.
+func (c *AddMachineCommand) provisionInventory(ctx *cmd.Context, client addMachineAPI) error {
+	path := c.Inventory
+	if !strings.HasPrefix(path, "~") {
+		path = ctx.AbsPath(path)
+	}
+	hosts, err := manual.ReadInventory(path)
+	if err != nil {
+		return err
+	}
+	type result struct {
+		machineId string
+		err       error
+	}
+	results := make([]result, len(hosts))
+	var wg sync.WaitGroup
+	limiter := make(chan struct{}, inventoryParallelism)
+	for i, host := range hosts {
+		limiter <- struct{}{}
+		wg.Add(1)
+		go func(i int, host manual.InventoryHost) {
+			defer wg.Done()
+			defer func() { <-limiter }()
+			args := host.ProvisionMachineArgs()
+			args.Client = client
+			// Prompts cannot be answered for many hosts at once,
+			// and progress from many hosts is too noisy to show.
+			args.Stdout = ioutil.Discard
+			args.Stderr = ioutil.Discard
+			results[i].machineId, results[i].err = manualProvisioner(args)
+		}(i, host)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, result := range results {
+		host := hosts[i].Host
+		switch result.err {
+		case nil:
+			ctx.Infof("created machine %v for %s", result.machineId, host)
+		case manual.ErrProvisioned:
+			ctx.Infof("skipped %s: already provisioned", host)
+		default:
+			ctx.Infof("failed to provision %s: %v", host, result.err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to provision %d of %d hosts", failed, len(hosts))
+	}
+	return nil
+}
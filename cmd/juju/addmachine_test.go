@@ -5,7 +5,10 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strconv"
+	"sync"
 
 	"github.com/juju/cmd"
 	jc "github.com/juju/testing/checkers"
@@ -62,6 +65,60 @@ func (s *AddMachineSuite) TestSSHPlacementError(c *gc.C) {
 	c.Assert(testing.Stderr(context), gc.Equals, "")
 }
 
+func (s *AddMachineSuite) TestInventory(c *gc.C) {
+	var mu sync.Mutex
+	provisioned := make(map[string]manual.ProvisionMachineArgs)
+	s.PatchValue(&manualProvisioner, func(args manual.ProvisionMachineArgs) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		provisioned[args.Host] = args
+		switch args.Host {
+		case "admin@10.0.0.1":
+			return "1", nil
+		case "root@10.0.0.2":
+			return "", manual.ErrProvisioned
+		}
+		return "", fmt.Errorf("no route to host")
+	})
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "hosts.yaml"), []byte(`
+defaults:
+  user: admin
+hosts:
+  - host: 10.0.0.1
+  - host: 10.0.0.2
+    user: root
+    key: /keys/root_rsa
+    series: trusty
+  - host: 10.0.0.3
+`), 0644)
+	c.Assert(err, gc.IsNil)
+	context, err := testing.RunCommandInDir(c, envcmd.Wrap(&AddMachineCommand{}), []string{"--inventory", "hosts.yaml"}, dir)
+	c.Assert(err, gc.ErrorMatches, "failed to provision 1 of 3 hosts")
+	c.Assert(testing.Stderr(context), gc.Equals, `created machine 1 for 10.0.0.1
+skipped 10.0.0.2: already provisioned
+failed to provision 10.0.0.3: no route to host
+`)
+	c.Assert(provisioned, gc.HasLen, 3)
+	args := provisioned["root@10.0.0.2"]
+	c.Assert(args.IdentityFile, gc.Equals, "/keys/root_rsa")
+	c.Assert(args.Series, gc.Equals, "trusty")
+	c.Assert(args.Client, gc.NotNil)
+}
+
+func (s *AddMachineSuite) TestInventoryWithOtherArgs(c *gc.C) {
+	for _, args := range [][]string{
+		{"ssh:10.0.0.1"},
+		{"-n", "2"},
+		{"--series", "trusty"},
+		{"--constraints", "mem=8G"},
+	} {
+		args = append(args, "--inventory", "hosts.yaml")
+		_, err := runAddMachine(c, args...)
+		c.Check(err, gc.ErrorMatches, "--inventory cannot be combined with other arguments")
+	}
+}
+
 func (s *AddMachineSuite) TestAddMachineWithSeries(c *gc.C) {
 	context, err := runAddMachine(c, "--series", "series")
 	c.Assert(err, gc.IsNil)
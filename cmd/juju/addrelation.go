@@ -7,14 +7,36 @@ import (
 	"fmt"
 
 	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/constraints"
 )
 
+const addRelationDoc = `
+Subordinate units are normally deployed alongside every unit of the
+principal service in a container-scoped relation. The --to-units,
+--to-tags and --to-constraints options restrict the principal units
+that are given a subordinate unit to those that are listed, whose
+machines have all of the given tags, and whose machines satisfy the
+given constraints, respectively. For example:
+
+    juju add-relation nagios-nrpe mysql --to-units mysql/0,mysql/2
+    juju add-relation nagios-nrpe mysql --to-tags monitored
+    juju add-relation nagios-nrpe mysql --to-constraints "mem=8G"
+
+Principal units that are not allowed still take part in the relation,
+but without a subordinate unit. The restrictions are fixed when the
+relation is added, and are shown by juju status.
+`
+
 // AddRelationCommand adds a relation between two service endpoints.
 type AddRelationCommand struct {
 	envcmd.EnvCommandBase
-	Endpoints []string
+	Endpoints   []string
+	Units       []string
+	Tags        []string
+	Constraints constraints.Value
 }
 
 func (c *AddRelationCommand) Info() *cmd.Info {
@@ -22,14 +44,28 @@ func (c *AddRelationCommand) Info() *cmd.Info {
 		Name:    "add-relation",
 		Args:    "<service1>[:<relation name1>] <service2>[:<relation name2>]",
 		Purpose: "add a relation between two services",
+		Doc:     addRelationDoc,
 	}
 }
 
+func (c *AddRelationCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.Var(cmd.NewStringsValue(nil, &c.Units), "to-units", "only give subordinates to these principal units")
+	f.Var(cmd.NewStringsValue(nil, &c.Tags), "to-tags", "only give subordinates to principal units on machines with these tags")
+	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "to-constraints", "only give subordinates to principal units on machines satisfying these constraints")
+}
+
 func (c *AddRelationCommand) Init(args []string) error {
 	if len(args) != 2 {
 		return fmt.Errorf("a relation must involve two services")
 	}
 	c.Endpoints = args
+	if len(c.Tags) > 0 {
+		if c.Constraints.Tags != nil {
+			return fmt.Errorf("cannot specify tags with both --to-tags and --to-constraints")
+		}
+		tags := c.Tags
+		c.Constraints.Tags = &tags
+	}
 	return nil
 }
 
@@ -39,6 +75,10 @@ func (c *AddRelationCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
-	_, err = client.AddRelation(c.Endpoints...)
+	if len(c.Units) == 0 && constraints.IsEmpty(&c.Constraints) {
+		_, err = client.AddRelation(c.Endpoints...)
+	} else {
+		_, err = client.AddRelationWithPlacement(c.Units, c.Constraints, c.Endpoints...)
+	}
 	return err
 }
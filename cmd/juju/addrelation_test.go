@@ -5,10 +5,13 @@ package main
 
 import (
 	charmtesting "github.com/juju/charm/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/constraints"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
 )
 
@@ -151,3 +154,28 @@ func (s *AddRelationSuite) TestAddRelation(c *gc.C) {
 		}
 	}
 }
+
+func (s *AddRelationSuite) TestAddRelationWithPlacement(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "mysql")
+	err := runDeploy(c, "local:mysql", "ms")
+	c.Assert(err, gc.IsNil)
+	charmtesting.Charms.BundlePath(s.SeriesPath, "logging")
+	err = runDeploy(c, "local:logging", "lg")
+	c.Assert(err, gc.IsNil)
+
+	err = runAddRelation(c, "lg", "ms", "--to-units", "ms/0,ms/2", "--to-tags", "monitored", "--to-constraints", "mem=4G")
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.KeyRelation("lg:info ms:juju-info")
+	c.Assert(err, gc.IsNil)
+	placement, ok := rel.SubordinatePlacement()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(placement, gc.DeepEquals, state.SubordinatePlacement{
+		Units:       []string{"ms/0", "ms/2"},
+		Constraints: constraints.MustParse("mem=4G tags=monitored"),
+	})
+}
+
+func (s *AddRelationSuite) TestAddRelationTagsTwice(c *gc.C) {
+	err := runAddRelation(c, "lg", "ms", "--to-tags", "a", "--to-constraints", "tags=b")
+	c.Assert(err, gc.ErrorMatches, "cannot specify tags with both --to-tags and --to-constraints")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/juju/cmd"
+	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
@@ -45,6 +46,7 @@ type AddUnitCommand struct {
 	envcmd.EnvCommandBase
 	UnitCommandBase
 	ServiceName string
+	CloneFrom   string
 }
 
 const addUnitDoc = `
@@ -61,6 +63,16 @@ Examples:
  juju add-unit mysql --to 23       (Add a mysql unit to machine 23)
  juju add-unit mysql --to 24/lxc/3 (Add unit to lxc container 3 on host machine 24)
  juju add-unit mysql --to lxc:25   (Add unit to a new lxc container on host machine 25)
+ juju add-unit mysql --clone-from mysql/0
+                                   (Add unit to a clone of the container running mysql/0)
+
+Units of services running in lxc containers can be added by cloning the
+container of an existing unit using the --clone-from argument.  The container
+is snapshotted and cloned onto the same host, so packages installed by the
+charm do not need to be installed again.  The snapshot is a btrfs snapshot
+if the host keeps its containers on btrfs, and an overlayfs or aufs snapshot
+otherwise.  The existing unit's container is briefly stopped while the
+snapshot is taken.
 `
 
 func (c *AddUnitCommand) Info() *cmd.Info {
@@ -75,6 +87,7 @@ func (c *AddUnitCommand) Info() *cmd.Info {
 func (c *AddUnitCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.UnitCommandBase.SetFlags(f)
 	f.IntVar(&c.NumUnits, "n", 1, "number of service units to add")
+	f.StringVar(&c.CloneFrom, "clone-from", "", "the unit whose container is cloned for each new unit")
 }
 
 func (c *AddUnitCommand) Init(args []string) error {
@@ -87,11 +100,20 @@ func (c *AddUnitCommand) Init(args []string) error {
 	if err := cmd.CheckEmpty(args[1:]); err != nil {
 		return err
 	}
+	if c.CloneFrom != "" {
+		if c.ToMachineSpec != "" {
+			return errors.New("cannot use --to with --clone-from")
+		}
+		if !names.IsValidUnit(c.CloneFrom) {
+			return fmt.Errorf("invalid --clone-from parameter %q", c.CloneFrom)
+		}
+	}
 	return c.UnitCommandBase.Init(args)
 }
 
 // Run connects to the environment specified on the command line
-// and calls AddServiceUnits for the given service.
+// and calls AddServiceUnits, or CloneServiceUnits when cloning an
+// existing unit, for the given service.
 func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 	apiclient, err := c.NewAPIClient()
 	if err != nil {
@@ -99,6 +121,10 @@ func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 	}
 	defer apiclient.Close()
 
+	if c.CloneFrom != "" {
+		_, err = apiclient.CloneServiceUnits(c.ServiceName, c.NumUnits, c.CloneFrom)
+		return err
+	}
 	_, err = apiclient.AddServiceUnits(c.ServiceName, c.NumUnits, c.ToMachineSpec)
 	return err
 }
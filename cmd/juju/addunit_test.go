@@ -34,6 +34,12 @@ var initAddUnitErrorTests = []struct {
 	}, {
 		args: []string{"some-service-name", "-n", "2", "--to", "123"},
 		err:  `cannot use --num-units > 1 with --to`,
+	}, {
+		args: []string{"some-service-name", "--clone-from", "some-service-name/0", "--to", "123"},
+		err:  `cannot use --to with --clone-from`,
+	}, {
+		args: []string{"some-service-name", "--clone-from", "bigglesplop"},
+		err:  `invalid --clone-from parameter "bigglesplop"`,
 	},
 }
 
@@ -131,3 +137,27 @@ func (s *AddUnitSuite) TestForceMachineNewContainer(c *gc.C) {
 	s.assertForceMachine(c, svc, 3, 1, machine.Id()+"/lxc/0")
 	s.assertForceMachine(c, svc, 3, 2, machine.Id())
 }
+
+func (s *AddUnitSuite) TestCloneFrom(c *gc.C) {
+	curl := s.setupService(c)
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	template := state.MachineTemplate{
+		Series: "precise",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, machine.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	err = container.SetProvisioned("inst-id", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	err = runAddUnit(c, "some-service-name", "--to", container.Id())
+	c.Assert(err, gc.IsNil)
+
+	err = runAddUnit(c, "some-service-name", "--clone-from", "some-service-name/1")
+	c.Assert(err, gc.IsNil)
+	svc, _ := s.AssertService(c, "some-service-name", curl, 3, 0)
+	s.assertForceMachine(c, svc, 3, 2, machine.Id()+"/lxc/1")
+	clone, err := s.State.Machine(machine.Id() + "/lxc/1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(clone.CloneFrom(), gc.Equals, container.Id())
+}
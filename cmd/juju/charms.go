@@ -0,0 +1,37 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+type CharmsCommand struct {
+	*cmd.SuperCommand
+}
+
+const charmsCommandDoc = `
+"juju charms" is used to inspect the charms stored in a Juju environment
+and to remove the ones that are no longer used.
+`
+
+const charmsCommandPurpose = "manage the charms stored in an environment"
+
+func NewCharmsCommand() cmd.Command {
+	charmscommand := &CharmsCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "charms",
+			Doc:         charmsCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     charmsCommandPurpose,
+		}),
+	}
+	// Define each subcommand in a separate "charms_FOO.go" source
+	// file (with tests in charms_FOO_test.go) and wire in here.
+	charmscommand.Register(envcmd.Wrap(&CharmsListCommand{}))
+	charmscommand.Register(envcmd.Wrap(&CharmsGCCommand{}))
+	return charmscommand
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const charmsGCCommandDoc = `
+Remove the charms that are not used by any service or unit from the
+environment, together with their archives in the environment storage.
+Charms that are still being uploaded are never removed.
+
+Use --dry-run to show the charms that would be removed without
+removing them.
+
+Examples:
+   juju charms gc --dry-run
+   juju charms gc
+`
+
+// CharmsGCCommand removes unused charms from the environment.
+type CharmsGCCommand struct {
+	envcmd.EnvCommandBase
+	DryRun bool
+}
+
+func (c *CharmsGCCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "gc",
+		Purpose: "remove unused charms from the environment",
+		Doc:     charmsGCCommandDoc,
+	}
+}
+
+func (c *CharmsGCCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.DryRun, "dry-run", false, "show the charms that would be removed")
+}
+
+func (c *CharmsGCCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CharmsGCCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	removed, err := client.CharmsGC(c.DryRun)
+	if err != nil {
+		return err
+	}
+	verb := "removed"
+	if c.DryRun {
+		verb = "would remove"
+	}
+	for _, curl := range removed {
+		fmt.Fprintf(ctx.Stdout, "%s %s\n", verb, curl)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type CharmsGCSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&CharmsGCSuite{})
+
+func (s *CharmsGCSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(envcmd.Wrap(&CharmsGCCommand{}), []string{"extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *CharmsGCSuite) TestGC(c *gc.C) {
+	dummy := s.AddTestingCharm(c, "dummy")
+	s.AddTestingService(c, "dummy", dummy)
+	wordpress := s.AddTestingCharm(c, "wordpress")
+
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&CharmsGCCommand{}), "--dry-run")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "would remove "+wordpress.URL().String()+"\n")
+	_, err = s.State.Charm(wordpress.URL())
+	c.Assert(err, gc.IsNil)
+
+	ctx, err = testing.RunCommand(c, envcmd.Wrap(&CharmsGCCommand{}))
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "removed "+wordpress.URL().String()+"\n")
+	_, err = s.State.Charm(wordpress.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Charm(dummy.URL())
+	c.Assert(err, gc.IsNil)
+}
@@ -0,0 +1,167 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/charm"
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju/osenv"
+)
+
+const charmsListCommandDoc = `
+List the charms stored in the environment, with the services that use
+them. Charms that are still being uploaded are shown as pending.
+
+If a local charm repository is given with --repository (or with the
+JUJU_REPOSITORY environment variable), the charms found in it are listed
+as well, together with whether that exact revision is stored in the
+environment.
+
+Examples:
+   juju charms list
+   juju charms list --repository ~/charms --format json
+`
+
+// CharmsListCommand lists the charms stored in the environment and,
+// optionally, the charms in a local repository.
+type CharmsListCommand struct {
+	envcmd.EnvCommandBase
+	RepoPath string
+	out      cmd.Output
+}
+
+// environmentCharm holds the details of a charm stored in the
+// environment for formatting.
+type environmentCharm struct {
+	Charm    string   `yaml:"charm" json:"charm"`
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+	Pending  bool     `yaml:"pending,omitempty" json:"pending,omitempty"`
+}
+
+// repositoryCharm holds the details of a charm found in a local
+// repository for formatting.
+type repositoryCharm struct {
+	Charm         string `yaml:"charm" json:"charm"`
+	Path          string `yaml:"path" json:"path"`
+	InEnvironment bool   `yaml:"in-environment,omitempty" json:"in-environment,omitempty"`
+}
+
+type charmsList struct {
+	Environment []environmentCharm `yaml:"environment" json:"environment"`
+	Repository  []repositoryCharm  `yaml:"repository,omitempty" json:"repository,omitempty"`
+}
+
+func (c *CharmsListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list the charms stored in the environment",
+		Doc:     charmsListCommandDoc,
+	}
+}
+
+func (c *CharmsListCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *CharmsListCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CharmsListCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	charms, err := client.ListCharms()
+	if err != nil {
+		return err
+	}
+	var result charmsList
+	stored := make(map[string]bool)
+	for _, ch := range charms {
+		stored[ch.URL] = true
+		result.Environment = append(result.Environment, environmentCharm{
+			Charm:    ch.URL,
+			Services: ch.Services,
+			Pending:  !ch.Uploaded,
+		})
+	}
+	if c.RepoPath != "" {
+		local, err := readRepository(ctx.AbsPath(c.RepoPath))
+		if err != nil {
+			return err
+		}
+		for i := range local {
+			local[i].InEnvironment = stored[local[i].Charm]
+		}
+		result.Repository = local
+	}
+	return c.out.Write(ctx, result)
+}
+
+// readRepository returns the charms found in the local charm
+// repository at path, which holds a directory for each series.
+func readRepository(path string) ([]repositoryCharm, error) {
+	seriesDirs, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read charm repository: %v", err)
+	}
+	var result []repositoryCharm
+	for _, seriesDir := range seriesDirs {
+		series := seriesDir.Name()
+		if !seriesDir.IsDir() || strings.HasPrefix(series, ".") {
+			continue
+		}
+		entries, err := ioutil.ReadDir(filepath.Join(path, series))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read charm repository: %v", err)
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			chPath := filepath.Join(path, series, entry.Name())
+			var ch charm.Charm
+			if entry.IsDir() {
+				ch, err = charm.ReadDir(chPath)
+			} else if strings.HasSuffix(entry.Name(), ".charm") {
+				ch, err = charm.ReadBundle(chPath)
+			} else {
+				continue
+			}
+			if err != nil {
+				// Not every entry is expected to be a charm.
+				logger.Warningf("ignoring %s: %v", chPath, err)
+				continue
+			}
+			curl := &charm.URL{
+				Reference: charm.Reference{
+					Schema:   "local",
+					Name:     ch.Meta().Name,
+					Revision: ch.Revision(),
+				},
+				Series: series,
+			}
+			result = append(result, repositoryCharm{
+				Charm: curl.String(),
+				Path:  chPath,
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/charm"
+	charmtesting "github.com/juju/charm/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type CharmsListSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&CharmsListSuite{})
+
+func runCharmsList(c *gc.C, args ...string) charmsList {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&CharmsListCommand{}), args...)
+	c.Assert(err, gc.IsNil)
+	var result charmsList
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &result)
+	c.Assert(err, gc.IsNil)
+	return result
+}
+
+func (s *CharmsListSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(envcmd.Wrap(&CharmsListCommand{}), []string{"extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *CharmsListSuite) TestList(c *gc.C) {
+	dummy := s.AddTestingCharm(c, "dummy")
+	s.AddTestingService(c, "dummy", dummy)
+	wordpress := s.AddTestingCharm(c, "wordpress")
+
+	result := runCharmsList(c, "--repository", "")
+	c.Assert(result, jc.DeepEquals, charmsList{
+		Environment: []environmentCharm{{
+			Charm:    dummy.URL().String(),
+			Services: []string{"dummy"},
+		}, {
+			Charm: wordpress.URL().String(),
+		}},
+	})
+}
+
+func (s *CharmsListSuite) TestListRepository(c *gc.C) {
+	dummy := s.AddTestingCharm(c, "dummy")
+
+	repoPath := c.MkDir()
+	seriesPath := filepath.Join(repoPath, "quantal")
+	err := os.Mkdir(seriesPath, 0755)
+	c.Assert(err, gc.IsNil)
+	dummyPath := charmtesting.Charms.ClonedDirPath(seriesPath, "dummy")
+	bundlePath := charmtesting.Charms.BundlePath(seriesPath, "mysql")
+	bundle, err := charm.ReadBundle(bundlePath)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(seriesPath, "README"), []byte("not a charm"), 0644)
+	c.Assert(err, gc.IsNil)
+
+	result := runCharmsList(c, "--repository", repoPath)
+	c.Assert(result.Environment, gc.HasLen, 1)
+	c.Assert(result.Repository, jc.DeepEquals, []repositoryCharm{{
+		Charm:         dummy.URL().String(),
+		Path:          dummyPath,
+		InEnvironment: true,
+	}, {
+		Charm: fmt.Sprintf("local:quantal/mysql-%d", bundle.Revision()),
+		Path:  bundlePath,
+	}})
+}
+
+func (s *CharmsListSuite) TestListBadRepository(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "missing")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&CharmsListCommand{}), "--repository", path)
+	c.Assert(err, gc.ErrorMatches, "cannot read charm repository: .*")
+}
@@ -13,6 +13,33 @@ import (
 	"github.com/juju/juju/cmd/envcmd"
 )
 
+type EnvironmentCommand struct {
+	*cmd.SuperCommand
+}
+
+const environmentCommandDoc = `
+"juju environment" is used to share access to a Juju environment
+between operators.
+`
+
+const environmentCommandPurpose = "share access to an environment"
+
+func NewEnvironmentCommand() cmd.Command {
+	envcommand := &EnvironmentCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "environment",
+			Doc:         environmentCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     environmentCommandPurpose,
+		}),
+	}
+	// Define each subcommand in a separate "environment_FOO.go" source
+	// file (with tests in environment_FOO_test.go) and wire in here.
+	envcommand.Register(envcmd.Wrap(&EnvironmentExportCommand{}))
+	envcommand.Register(&EnvironmentImportCommand{})
+	return envcommand
+}
+
 // GetEnvironmentCommand is able to output either the entire environment or
 // the requested value in a format of the user's choosing.
 type GetEnvironmentCommand struct {
@@ -0,0 +1,96 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+)
+
+const environmentExportCommandDoc = `
+Export a connection bundle for the current environment.
+
+The bundle holds the API server addresses, the CA certificate, the
+environment UUID and a user name; it never includes the bootstrap
+configuration or the admin secret. A password is only included when
+one is explicitly given for a user created with "juju user add".
+The bundle may be encrypted with a passphrase, which must then be
+supplied to "juju environment import".
+
+Examples:
+  juju environment export -o env.bundle                        (Export the endpoint only)
+  juju environment export --user bob --password pass -o bundle (Export credentials for user "bob")
+  juju environment export --passphrase secret -o env.bundle    (Encrypt the bundle)
+`
+
+type EnvironmentExportCommand struct {
+	envcmd.EnvCommandBase
+	User       string
+	Password   string
+	Passphrase string
+	OutPath    string
+}
+
+func (c *EnvironmentExportCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "export",
+		Purpose: "exports a connection bundle for the environment",
+		Doc:     environmentExportCommandDoc,
+	}
+}
+
+func (c *EnvironmentExportCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.User, "user", "", "User to include in the bundle")
+	f.StringVar(&c.Password, "password", "", "Password for the user given with --user")
+	f.StringVar(&c.Passphrase, "passphrase", "", "Encrypt the bundle with this passphrase")
+	f.StringVar(&c.OutPath, "o", "", "Write the bundle to a file rather than stdout")
+	f.StringVar(&c.OutPath, "output", "", "")
+}
+
+func (c *EnvironmentExportCommand) Init(args []string) error {
+	if c.Password != "" && c.User == "" {
+		return fmt.Errorf("--password requires --user")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *EnvironmentExportCommand) Run(ctx *cmd.Context) error {
+	store, err := configstore.Default()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	info, err := store.ReadInfo(c.ConnectionName())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(info.APIEndpoint().Addresses) == 0 {
+		return fmt.Errorf("environment %q has not been bootstrapped", c.ConnectionName())
+	}
+	bundle := configstore.NewConnectionBundle(info)
+	if c.User != "" {
+		bundle.User = c.User
+		bundle.Password = c.Password
+	}
+	data, err := bundle.Marshal(c.Passphrase)
+	if err != nil {
+		return err
+	}
+	if c.OutPath == "" {
+		_, err = ctx.Stdout.Write(data)
+		return err
+	}
+	outPath := ctx.AbsPath(c.OutPath)
+	if err := ioutil.WriteFile(outPath, data, 0600); err != nil {
+		return errors.Annotate(err, "cannot write connection bundle")
+	}
+	fmt.Fprintf(ctx.Stdout, "connection bundle written to %s\n", outPath)
+	return nil
+}
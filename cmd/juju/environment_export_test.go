@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/testing"
+)
+
+type EnvironmentExportCommandSuite struct {
+	testing.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&EnvironmentExportCommandSuite{})
+
+func newEnvironmentExportCommand() cmd.Command {
+	return envcmd.Wrap(&EnvironmentExportCommand{})
+}
+
+func (s *EnvironmentExportCommandSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		errorString string
+	}{{
+		args: []string{"--user", "bob", "--password", "pass"},
+	}, {
+		args:        []string{"--password", "pass"},
+		errorString: "--password requires --user",
+	}, {
+		args:        []string{"extra"},
+		errorString: `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d", i)
+		err := testing.InitCommand(&EnvironmentExportCommand{}, test.args)
+		if test.errorString == "" {
+			c.Check(err, gc.IsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *EnvironmentExportCommandSuite) TestExportToStdout(c *gc.C) {
+	fakeBootstrapEnvironment(c, "erewhemos")
+	ctx, err := testing.RunCommand(c, newEnvironmentExportCommand())
+	c.Assert(err, gc.IsNil)
+	bundle, err := configstore.ParseConnectionBundle([]byte(testing.Stdout(ctx)), "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(bundle, jc.DeepEquals, &configstore.ConnectionBundle{
+		User:         "admin",
+		StateServers: []string{"localhost:12345"},
+		CACert:       testing.CACert,
+	})
+	c.Assert(testing.Stdout(ctx), gc.Not(jc.Contains), "password")
+	c.Assert(testing.Stdout(ctx), gc.Not(jc.Contains), "extra data")
+}
+
+func (s *EnvironmentExportCommandSuite) TestExportUserToFileEncrypted(c *gc.C) {
+	fakeBootstrapEnvironment(c, "erewhemos")
+	outPath := filepath.Join(c.MkDir(), "env.bundle")
+	ctx, err := testing.RunCommand(c, newEnvironmentExportCommand(),
+		"--user", "bob", "--password", "bobpass", "--passphrase", "secret", "-o", outPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "connection bundle written to "+outPath+"\n")
+
+	data, err := ioutil.ReadFile(outPath)
+	c.Assert(err, gc.IsNil)
+	bundle, err := configstore.ParseConnectionBundle(data, "secret")
+	c.Assert(err, gc.IsNil)
+	c.Assert(bundle.User, gc.Equals, "bob")
+	c.Assert(bundle.Password, gc.Equals, "bobpass")
+}
+
+func (s *EnvironmentExportCommandSuite) TestExportNotBootstrapped(c *gc.C) {
+	_, err := testing.RunCommand(c, newEnvironmentExportCommand())
+	c.Assert(err, gc.ErrorMatches, `environment "erewhemos" not found`)
+}
@@ -0,0 +1,85 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/configstore"
+)
+
+const environmentImportCommandDoc = `
+Import a connection bundle created by "juju environment export".
+
+A new environment with the given name is created in the local
+configuration store, holding the API endpoint and credentials from the
+bundle. The name must not already be in use.
+
+Examples:
+  juju environment import env.bundle shared                     (Import as environment "shared")
+  juju environment import env.bundle shared --passphrase secret (Import an encrypted bundle)
+`
+
+type EnvironmentImportCommand struct {
+	cmd.CommandBase
+	BundlePath string
+	EnvName    string
+	Passphrase string
+}
+
+func (c *EnvironmentImportCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "import",
+		Args:    "<bundle file> <environment name>",
+		Purpose: "imports a connection bundle as a new environment",
+		Doc:     environmentImportCommandDoc,
+	}
+}
+
+func (c *EnvironmentImportCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Passphrase, "passphrase", "", "Passphrase the bundle was encrypted with")
+}
+
+func (c *EnvironmentImportCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("no bundle file supplied")
+	case 1:
+		return fmt.Errorf("no environment name supplied")
+	}
+	c.BundlePath, c.EnvName = args[0], args[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *EnvironmentImportCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.BundlePath))
+	if err != nil {
+		return errors.Annotate(err, "cannot read connection bundle")
+	}
+	bundle, err := configstore.ParseConnectionBundle(data, c.Passphrase)
+	if err != nil {
+		return err
+	}
+	store, err := configstore.Default()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	info, err := configstore.ImportConnectionBundle(store, c.EnvName, bundle)
+	if err == configstore.ErrEnvironInfoAlreadyExists {
+		return fmt.Errorf("environment %q already exists", c.EnvName)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "environment %q imported to %s\n", c.EnvName, info.Location())
+	if bundle.Password == "" {
+		fmt.Fprintf(ctx.Stdout, "the bundle holds no password for user %q; add it before connecting\n", bundle.User)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/testing"
+)
+
+type EnvironmentImportCommandSuite struct {
+	testing.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&EnvironmentImportCommandSuite{})
+
+func (s *EnvironmentImportCommandSuite) writeBundle(c *gc.C, password, passphrase string) string {
+	bundle := &configstore.ConnectionBundle{
+		User:         "bob",
+		Password:     password,
+		EnvironUUID:  "dead-beef",
+		StateServers: []string{"localhost:12345"},
+		CACert:       testing.CACert,
+	}
+	data, err := bundle.Marshal(passphrase)
+	c.Assert(err, gc.IsNil)
+	path := filepath.Join(c.MkDir(), "env.bundle")
+	err = ioutil.WriteFile(path, data, 0600)
+	c.Assert(err, gc.IsNil)
+	return path
+}
+
+func (s *EnvironmentImportCommandSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		errorString string
+	}{{
+		errorString: "no bundle file supplied",
+	}, {
+		args:        []string{"file"},
+		errorString: "no environment name supplied",
+	}, {
+		args:        []string{"file", "name", "extra"},
+		errorString: `unrecognized args: \["extra"\]`,
+	}, {
+		args: []string{"file", "name"},
+	}} {
+		c.Logf("test %d", i)
+		importCmd := &EnvironmentImportCommand{}
+		err := testing.InitCommand(importCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, gc.IsNil)
+			c.Check(importCmd.BundlePath, gc.Equals, "file")
+			c.Check(importCmd.EnvName, gc.Equals, "name")
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *EnvironmentImportCommandSuite) TestImport(c *gc.C) {
+	path := s.writeBundle(c, "bobpass", "secret")
+	ctx, err := testing.RunCommand(c, &EnvironmentImportCommand{}, path, "shared", "--passphrase", "secret")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches, `environment "shared" imported to file ".*shared.jenv"\n`)
+
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.ReadInfo("shared")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.BootstrapConfig(), gc.HasLen, 0)
+	c.Assert(info.APIEndpoint(), jc.DeepEquals, configstore.APIEndpoint{
+		Addresses:   []string{"localhost:12345"},
+		CACert:      testing.CACert,
+		EnvironUUID: "dead-beef",
+	})
+	c.Assert(info.APICredentials(), jc.DeepEquals, configstore.APICredentials{
+		User:     "bob",
+		Password: "bobpass",
+	})
+}
+
+func (s *EnvironmentImportCommandSuite) TestImportWithoutPassword(c *gc.C) {
+	path := s.writeBundle(c, "", "")
+	ctx, err := testing.RunCommand(c, &EnvironmentImportCommand{}, path, "shared")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), jc.Contains, `the bundle holds no password for user "bob"`)
+}
+
+func (s *EnvironmentImportCommandSuite) TestImportNeedsPassphrase(c *gc.C) {
+	path := s.writeBundle(c, "bobpass", "secret")
+	_, err := testing.RunCommand(c, &EnvironmentImportCommand{}, path, "shared")
+	c.Assert(err, gc.Equals, configstore.ErrPassphraseRequired)
+}
+
+func (s *EnvironmentImportCommandSuite) TestImportExisting(c *gc.C) {
+	fakeBootstrapEnvironment(c, "erewhemos")
+	path := s.writeBundle(c, "bobpass", "")
+	_, err := testing.RunCommand(c, &EnvironmentImportCommand{}, path, "erewhemos")
+	c.Assert(err, gc.ErrorMatches, `environment "erewhemos" already exists`)
+}
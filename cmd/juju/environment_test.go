@@ -255,3 +255,32 @@ func (s *UnsetEnvironmentSuite) TestUnsetEnvironment(c *gc.C) {
 		}
 	}
 }
+
+type EnvironmentCommandSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&EnvironmentCommandSuite{})
+
+var expectedEnvironmentCommmandNames = []string{
+	"export",
+	"help",
+	"import",
+}
+
+func (s *EnvironmentCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewEnvironmentCommand(), "--help")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches,
+		"(?s)usage: environment <command> .+"+
+			environmentCommandPurpose+".+"+
+			environmentCommandDoc+".+")
+
+	var namesFound []string
+	commandHelp := strings.SplitAfter(testing.Stdout(ctx), "commands:")[1]
+	commandHelp = strings.TrimSpace(commandHelp)
+	for _, line := range strings.Split(commandHelp, "\n") {
+		namesFound = append(namesFound, strings.TrimSpace(strings.Split(line, " - ")[0]))
+	}
+	c.Assert(namesFound, gc.DeepEquals, expectedEnvironmentCommmandNames)
+}
@@ -312,6 +312,7 @@ container
       none - (default) no container
       lxc - an lxc container
       kvm - a kvm container
+      nspawn - a systemd-nspawn container
 
 cpu-power
    Cpu-power is a whole number that defines the speed of the machine's CPU,
@@ -52,6 +52,10 @@ func (dummyHookContext) OwnerTag() string {
 	return ""
 }
 
+func (dummyHookContext) AddMetric(key string, value float64) error {
+	return nil
+}
+
 type HelpToolCommand struct {
 	cmd.CommandBase
 	tool string
@@ -106,6 +106,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&StatusCommand{}))
 	r.Register(&SwitchCommand{})
 	r.Register(wrapEnvCommand(&EndpointCommand{}))
+	r.Register(wrapEnvCommand(&MetricsCommand{}))
 
 	// Error resolution and debugging commands.
 	r.Register(wrapEnvCommand(&RunCommand{}))
@@ -115,6 +116,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&DebugLogCommand{}))
 	r.Register(wrapEnvCommand(&DebugHooksCommand{}))
 	r.Register(wrapEnvCommand(&RetryProvisioningCommand{}))
+	r.Register(wrapEnvCommand(&MigrateUnitCommand{}))
 
 	// Configuration commands.
 	r.Register(&InitCommand{})
@@ -123,6 +125,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&UnsetCommand{}))
 	r.Register(wrapEnvCommand(&GetConstraintsCommand{}))
 	r.Register(wrapEnvCommand(&SetConstraintsCommand{}))
+	r.Register(wrapEnvCommand(&SetAutoscaleCommand{}))
+	r.Register(wrapEnvCommand(&SetCharmUpgradeCommand{}))
 	r.Register(wrapEnvCommand(&GetEnvironmentCommand{}))
 	r.Register(wrapEnvCommand(&SetEnvironmentCommand{}))
 	r.Register(wrapEnvCommand(&UnsetEnvironmentCommand{}))
@@ -144,6 +148,13 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	// Manage users and access
 	r.Register(NewUserCommand())
 
+	// Share environment access between operators.
+	r.Register(NewEnvironmentCommand())
+
+	// Inspect and clean up the charms stored in an environment.
+	r.Register(NewCharmsCommand())
+	r.Register(NewMaintenanceCommand())
+
 	// Manage state server availability.
 	r.Register(wrapEnvCommand(&EnsureAvailabilityCommand{}))
 }
@@ -194,6 +194,7 @@ var commandNames = []string{
 	"authorised-keys", // alias for authorized-keys
 	"authorized-keys",
 	"bootstrap",
+	"charms",
 	"debug-hooks",
 	"debug-log",
 	"deploy",
@@ -204,6 +205,7 @@ var commandNames = []string{
 	"destroy-unit",
 	"ensure-availability",
 	"env", // alias for switch
+	"environment",
 	"expose",
 	"generate-config", // alias for init
 	"get",
@@ -213,6 +215,9 @@ var commandNames = []string{
 	"help",
 	"help-tool",
 	"init",
+	"maintenance",
+	"metrics",
+	"migrate-unit",
 	"publish",
 	"remove-machine",  // alias for destroy-machine
 	"remove-relation", // alias for destroy-relation
@@ -223,6 +228,8 @@ var commandNames = []string{
 	"run",
 	"scp",
 	"set",
+	"set-autoscale",
+	"set-charm-upgrade",
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
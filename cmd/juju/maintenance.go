@@ -0,0 +1,51 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+type MaintenanceCommand struct {
+	*cmd.SuperCommand
+}
+
+const maintenanceCommandDoc = `
+"juju maintenance" is used to inspect and open the windows within which
+automated maintenance operations, such as charm revision updates, charm
+upgrades, tools upgrades and metric pruning, may run.
+
+Recurring windows are set in the environment configuration, with
+"maintenance-windows" holding a semicolon-separated list of windows.
+Each window is given as five cron fields (minute, hour, day of month,
+month and day of week) followed by how long the window stays open;
+they are interpreted in "maintenance-timezone", which defaults to UTC.
+For example, to allow maintenance for four hours from 2am on Saturdays,
+and for an hour from 4:30am every day:
+
+    juju set-env maintenance-windows="0 2 * * 6 4h; 30 4 * * * 1h"
+
+If no windows are set, maintenance operations may run at any time.
+`
+
+const maintenanceCommandPurpose = "manage the environment's maintenance windows"
+
+func NewMaintenanceCommand() cmd.Command {
+	maintenancecommand := &MaintenanceCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "maintenance",
+			Doc:         maintenanceCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     maintenanceCommandPurpose,
+		}),
+	}
+	// Define each subcommand in a separate "maintenance_FOO.go"
+	// source file (with tests in maintenance_FOO_test.go) and wire
+	// in here.
+	maintenancecommand.Register(envcmd.Wrap(&MaintenanceShowCommand{}))
+	maintenancecommand.Register(envcmd.Wrap(&MaintenanceOpenCommand{}))
+	return maintenancecommand
+}
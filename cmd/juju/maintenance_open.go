@@ -0,0 +1,66 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const maintenanceOpenCommandDoc = `
+Open a maintenance window now, for the given duration, in addition to
+the environment's recurring windows. Workers waiting for a window
+notice it within a few minutes.
+
+Examples:
+   juju maintenance open 2h
+`
+
+// MaintenanceOpenCommand opens an ad-hoc maintenance window.
+type MaintenanceOpenCommand struct {
+	envcmd.EnvCommandBase
+	Duration time.Duration
+}
+
+func (c *MaintenanceOpenCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "open",
+		Args:    "<duration>",
+		Purpose: "open an ad-hoc maintenance window",
+		Doc:     maintenanceOpenCommandDoc,
+	}
+}
+
+func (c *MaintenanceOpenCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no duration specified")
+	}
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid duration %q", args[0])
+	}
+	c.Duration = duration
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *MaintenanceOpenCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	until := maintenanceNow().Add(c.Duration).UTC().Truncate(time.Second)
+	err = client.EnvironmentSet(map[string]interface{}{
+		"maintenance-open-until": until.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "maintenance window open until %s\n", until.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type MaintenanceOpenSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&MaintenanceOpenSuite{})
+
+var maintenanceOpenInitTests = []struct {
+	args []string
+	err  string
+}{{
+	err: `no duration specified`,
+}, {
+	args: []string{"soon"},
+	err:  `invalid duration "soon"`,
+}, {
+	args: []string{"-1h"},
+	err:  `invalid duration "-1h"`,
+}, {
+	args: []string{"2h", "extra"},
+	err:  `unrecognized args: \["extra"\]`,
+}, {
+	args: []string{"2h"},
+}}
+
+func (s *MaintenanceOpenSuite) TestInit(c *gc.C) {
+	for i, t := range maintenanceOpenInitTests {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(envcmd.Wrap(&MaintenanceOpenCommand{}), t.args)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+		} else {
+			c.Check(err, gc.IsNil)
+		}
+	}
+}
+
+func (s *MaintenanceOpenSuite) TestOpen(c *gc.C) {
+	s.PatchValue(&maintenanceNow, func() time.Time {
+		return time.Date(2014, 6, 29, 12, 30, 0, 0, time.UTC)
+	})
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&MaintenanceOpenCommand{}), "2h")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "maintenance window open until 2014-06-29 14:30:00 UTC\n")
+
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	until, ok := cfg.MaintenanceOpenUntil()
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(until, gc.DeepEquals, time.Date(2014, 6, 29, 14, 30, 0, 0, time.UTC))
+}
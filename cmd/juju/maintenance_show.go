@@ -0,0 +1,107 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/worker"
+)
+
+const maintenanceShowCommandDoc = `
+Show the maintenance windows that are open now or will open next, in
+the environment's maintenance timezone. Windows opened with
+"juju maintenance open" are shown as ad-hoc.
+
+Examples:
+   juju maintenance show
+   juju maintenance show -n 10 --format json
+`
+
+// maintenanceNow returns the current time; it is replaced in tests.
+var maintenanceNow = time.Now
+
+// MaintenanceShowCommand shows the upcoming maintenance windows.
+type MaintenanceShowCommand struct {
+	envcmd.EnvCommandBase
+	Count int
+	out   cmd.Output
+}
+
+// maintenanceWindow holds the details of a maintenance window for
+// formatting.
+type maintenanceWindow struct {
+	Start    string `yaml:"start" json:"start"`
+	End      string `yaml:"end" json:"end"`
+	Schedule string `yaml:"schedule" json:"schedule"`
+}
+
+type maintenanceSchedule struct {
+	Timezone string `yaml:"timezone" json:"timezone"`
+	// AlwaysOpen records that no recurring windows are set, so
+	// maintenance operations may run at any time.
+	AlwaysOpen bool                `yaml:"always-open,omitempty" json:"always-open,omitempty"`
+	Upcoming   []maintenanceWindow `yaml:"upcoming,omitempty" json:"upcoming,omitempty"`
+}
+
+func (c *MaintenanceShowCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show",
+		Purpose: "show the upcoming maintenance windows",
+		Doc:     maintenanceShowCommandDoc,
+	}
+}
+
+func (c *MaintenanceShowCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.IntVar(&c.Count, "n", 5, "the number of windows to show")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *MaintenanceShowCommand) Init(args []string) error {
+	if c.Count < 1 {
+		return fmt.Errorf("-n must be at least 1, got %d", c.Count)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *MaintenanceShowCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	attrs, err := client.EnvironmentGet()
+	if err != nil {
+		return err
+	}
+	conf, err := config.New(config.NoDefaults, attrs)
+	if err != nil {
+		return err
+	}
+	result := maintenanceSchedule{
+		Timezone:   conf.MaintenanceTimezone().String(),
+		AlwaysOpen: len(conf.MaintenanceWindows()) == 0,
+	}
+	for _, w := range worker.MaintenanceWindows(conf, maintenanceNow(), c.Count) {
+		schedule := w.Schedule
+		if schedule == "" {
+			schedule = "ad-hoc"
+		}
+		result.Upcoming = append(result.Upcoming, maintenanceWindow{
+			Start:    w.Start.Format("2006-01-02 15:04"),
+			End:      w.End.Format("2006-01-02 15:04"),
+			Schedule: schedule,
+		})
+	}
+	return c.out.Write(ctx, result)
+}
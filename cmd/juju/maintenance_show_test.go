@@ -0,0 +1,75 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type MaintenanceShowSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&MaintenanceShowSuite{})
+
+func (s *MaintenanceShowSuite) SetUpTest(c *gc.C) {
+	s.RepoSuite.SetUpTest(c)
+	s.PatchValue(&maintenanceNow, func() time.Time {
+		return time.Date(2014, 6, 29, 12, 30, 0, 0, time.UTC)
+	})
+}
+
+func (s *MaintenanceShowSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(envcmd.Wrap(&MaintenanceShowCommand{}), []string{"extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+	err = testing.InitCommand(envcmd.Wrap(&MaintenanceShowCommand{}), []string{"-n", "0"})
+	c.Assert(err, gc.ErrorMatches, `-n must be at least 1, got 0`)
+}
+
+func (s *MaintenanceShowSuite) runShow(c *gc.C, args ...string) maintenanceSchedule {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&MaintenanceShowCommand{}), append(args, "--format", "json")...)
+	c.Assert(err, gc.IsNil)
+	var result maintenanceSchedule
+	err = json.Unmarshal([]byte(testing.Stdout(ctx)), &result)
+	c.Assert(err, gc.IsNil)
+	return result
+}
+
+func (s *MaintenanceShowSuite) TestShowAlwaysOpen(c *gc.C) {
+	c.Assert(s.runShow(c), gc.DeepEquals, maintenanceSchedule{
+		Timezone:   "UTC",
+		AlwaysOpen: true,
+	})
+}
+
+func (s *MaintenanceShowSuite) TestShow(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows":    "0 2 * * 6 4h; 30 4 * * * 1h",
+		"maintenance-open-until": "2014-06-29T14:00:00Z",
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.runShow(c, "-n", "3"), gc.DeepEquals, maintenanceSchedule{
+		Timezone: "UTC",
+		Upcoming: []maintenanceWindow{{
+			Start:    "2014-06-29 12:30",
+			End:      "2014-06-29 14:00",
+			Schedule: "ad-hoc",
+		}, {
+			Start:    "2014-06-30 04:30",
+			End:      "2014-06-30 05:30",
+			Schedule: "30 4 * * * 1h",
+		}, {
+			Start:    "2014-07-01 04:30",
+			End:      "2014-07-01 05:30",
+			Schedule: "30 4 * * * 1h",
+		}},
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const metricsDoc = `
+Shows the metrics reported by the units of a service. Charms report
+metrics with the add-metric hook tool, typically from the collect-metrics
+hook, which is run every five minutes for charms that define it. Each
+hook execution that reports metrics records a batch, identified by the
+unit and charm that reported it.
+
+Metrics are kept for a week. Use --since to show only the batches
+recorded within the given duration.
+
+Examples:
+   juju metrics wordpress
+   juju metrics wordpress --since 1h --format json
+`
+
+// MetricsCommand shows the metrics reported by the units of a service.
+type MetricsCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Since       time.Duration
+	out         cmd.Output
+}
+
+// metricBatch holds a batch of metrics for formatting.
+type metricBatch struct {
+	Unit    string             `yaml:"unit" json:"unit"`
+	Charm   string             `yaml:"charm,omitempty" json:"charm,omitempty"`
+	Time    time.Time          `yaml:"time" json:"time"`
+	Metrics map[string]float64 `yaml:"metrics" json:"metrics"`
+}
+
+func (c *MetricsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "metrics",
+		Args:    "<service>",
+		Purpose: "show the metrics reported by the units of a service",
+		Doc:     metricsDoc,
+	}
+}
+
+func (c *MetricsCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.DurationVar(&c.Since, "since", 0, "only show metrics recorded within this duration")
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *MetricsCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no service specified")
+	}
+	c.ServiceName = args[0]
+	if !names.IsValidService(c.ServiceName) {
+		return fmt.Errorf("invalid service name %q", c.ServiceName)
+	}
+	if c.Since < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *MetricsCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var since time.Time
+	if c.Since > 0 {
+		since = time.Now().Add(-c.Since)
+	}
+	batches, err := client.ServiceMetrics(c.ServiceName, since)
+	if err != nil {
+		return err
+	}
+	result := make([]metricBatch, len(batches))
+	for i, batch := range batches {
+		metrics := make(map[string]float64)
+		for _, m := range batch.Metrics {
+			metrics[m.Key] = m.Value
+		}
+		result[i] = metricBatch{
+			Unit:    batch.Unit,
+			Charm:   batch.CharmURL,
+			Time:    batch.Created,
+			Metrics: metrics,
+		}
+	}
+	return c.out.Write(ctx, result)
+}
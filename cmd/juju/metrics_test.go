@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type MetricsSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&MetricsSuite{})
+
+var metricsInitTests = []struct {
+	args []string
+	err  string
+}{
+	{
+		err: `no service specified`,
+	}, {
+		args: []string{"wordpress/0"},
+		err:  `invalid service name "wordpress/0"`,
+	}, {
+		args: []string{"wordpress", "mysql"},
+		err:  `unrecognized args: \["mysql"\]`,
+	}, {
+		args: []string{"wordpress", "--since", "-1h"},
+		err:  `--since must not be negative`,
+	}, {
+		args: []string{"wordpress", "--since", "1h"},
+	},
+}
+
+func (s *MetricsSuite) TestInit(c *gc.C) {
+	for i, t := range metricsInitTests {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(envcmd.Wrap(&MetricsCommand{}), t.args)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+		} else {
+			c.Check(err, gc.IsNil)
+		}
+	}
+}
+
+func (s *MetricsSuite) TestMetrics(c *gc.C) {
+	charm := s.AddTestingCharm(c, "wordpress")
+	svc := s.AddTestingService(c, "wordpress", charm)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.SetCharmURL(charm.URL())
+	c.Assert(err, gc.IsNil)
+	err = unit.AddMetrics([]state.Metric{
+		{Key: "load", Value: 0.5, Time: time.Now()},
+		{Key: "users", Value: 12, Time: time.Now()},
+	})
+	c.Assert(err, gc.IsNil)
+
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&MetricsCommand{}), "wordpress")
+	c.Assert(err, gc.IsNil)
+	var batches []metricBatch
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &batches)
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit, gc.Equals, "wordpress/0")
+	c.Assert(batches[0].Charm, gc.Equals, charm.URL().String())
+	c.Assert(batches[0].Metrics, gc.DeepEquals, map[string]float64{
+		"load":  0.5,
+		"users": 12,
+	})
+}
+
+func (s *MetricsSuite) TestMetricsUnknownService(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&MetricsCommand{}), "unknown")
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const migrateUnitDoc = `
+Migrates a unit to another machine. A new unit of the same service is
+added to the target machine and, once it has started, the charm is given
+the chance to move the unit's data across:
+
+  - the "migrate-out" hook runs on the original unit;
+  - the "migrate-in" hook then runs on the new unit.
+
+Both hooks can find the names of the two units in $JUJU_MIGRATION_SOURCE
+and $JUJU_MIGRATION_TARGET, and the private address of the other unit in
+$JUJU_MIGRATION_REMOTE_ADDRESS.
+
+When the new unit has joined all of the original unit's relations, it
+takes over the original unit's opened ports and the original unit is
+removed. Until then, the migration can be abandoned with --abort, which
+removes the new unit and leaves the original unit in place.
+
+Subordinate units cannot be migrated directly; they move with their
+principal.
+
+Examples:
+   juju migrate-unit mysql/0 --to 3
+   juju migrate-unit mysql/0 --abort
+`
+
+// MigrateUnitCommand moves a unit to another machine.
+type MigrateUnitCommand struct {
+	envcmd.EnvCommandBase
+	UnitName  string
+	ToMachine string
+	Abort     bool
+}
+
+func (c *MigrateUnitCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "migrate-unit",
+		Args:    "<unit> --to <machine> | <unit> --abort",
+		Purpose: "move a service unit to another machine",
+		Doc:     migrateUnitDoc,
+	}
+}
+
+func (c *MigrateUnitCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.ToMachine, "to", "", "the machine to migrate the unit to")
+	f.BoolVar(&c.Abort, "abort", false, "abandon the unit's migration")
+}
+
+func (c *MigrateUnitCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no unit specified")
+	}
+	c.UnitName = args[0]
+	if !names.IsValidUnit(c.UnitName) {
+		return fmt.Errorf("invalid unit name %q", c.UnitName)
+	}
+	switch {
+	case c.Abort && c.ToMachine != "":
+		return fmt.Errorf("cannot specify both --to and --abort")
+	case c.Abort:
+	case c.ToMachine == "":
+		return fmt.Errorf("no machine specified")
+	case !names.IsValidMachine(c.ToMachine):
+		return fmt.Errorf("invalid machine id %q", c.ToMachine)
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *MigrateUnitCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if c.Abort {
+		return client.AbortUnitMigration(c.UnitName)
+	}
+	target, err := client.MigrateUnit(c.UnitName, c.ToMachine)
+	if err != nil {
+		return err
+	}
+	ctx.Infof("migrating unit %s to %s on machine %s", c.UnitName, target, c.ToMachine)
+	return nil
+}
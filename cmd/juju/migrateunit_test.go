@@ -0,0 +1,92 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type MigrateUnitSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&MigrateUnitSuite{})
+
+func runMigrateUnit(c *gc.C, args ...string) error {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&MigrateUnitCommand{}), args...)
+	return err
+}
+
+var migrateUnitInitTests = []struct {
+	args []string
+	err  string
+}{
+	{
+		err: `no unit specified`,
+	}, {
+		args: []string{"jeremy-fisher"},
+		err:  `invalid unit name "jeremy-fisher"`,
+	}, {
+		args: []string{"dummy/0"},
+		err:  `no machine specified`,
+	}, {
+		args: []string{"dummy/0", "--to", "lxc:1"},
+		err:  `invalid machine id "lxc:1"`,
+	}, {
+		args: []string{"dummy/0", "--to", "1", "--abort"},
+		err:  `cannot specify both --to and --abort`,
+	}, {
+		args: []string{"dummy/0", "--to", "1", "dummy/1"},
+		err:  `unrecognized args: \["dummy/1"\]`,
+	}, {
+		args: []string{"dummy/0", "--to", "1"},
+	}, {
+		args: []string{"dummy/0", "--abort"},
+	},
+}
+
+func (s *MigrateUnitSuite) TestInit(c *gc.C) {
+	for i, t := range migrateUnitInitTests {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(envcmd.Wrap(&MigrateUnitCommand{}), t.args)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+		} else {
+			c.Check(err, gc.IsNil)
+		}
+	}
+}
+
+func (s *MigrateUnitSuite) TestMigrateUnit(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(m)
+	c.Assert(err, gc.IsNil)
+	target, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	err = runMigrateUnit(c, "dummy/0", "--to", target.Id())
+	c.Assert(err, gc.IsNil)
+	err = unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	name, ok := unit.MigratingTo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(name, gc.Equals, "dummy/1")
+
+	err = runMigrateUnit(c, "dummy/0", "--abort")
+	c.Assert(err, gc.IsNil)
+	err = unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	_, ok = unit.MigratingTo()
+	c.Assert(ok, jc.IsFalse)
+}
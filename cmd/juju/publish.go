@@ -5,7 +5,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -13,14 +12,15 @@ import (
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
 
-	"github.com/juju/juju/bzr"
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/vcs"
 )
 
 type PublishCommand struct {
 	envcmd.EnvCommandBase
 	URL       string
 	CharmPath string
+	DryRun    bool
 
 	// changePushLocation allows translating the branch location
 	// for testing purposes.
@@ -43,6 +43,15 @@ For cs:~user/precise/mysql
 There is no default series, so one must be provided explicitly when
 informing a charm URL. If the URL isn't provided, an attempt will be
 made to infer it from the current branch push URL.
+
+The charm may be published from a bzr branch or a git working tree.
+Git branches are pushed to the same location as bzr ones, so git must
+be configured to understand "lp:" locations, for example with:
+
+  git config --global url."git+ssh://git.launchpad.net/".insteadOf lp:
+
+With --dry-run, the charm URL, revision and push location are
+reported without pushing anything to the store.
 `
 
 func (c *PublishCommand) Info() *cmd.Info {
@@ -56,6 +65,7 @@ func (c *PublishCommand) Info() *cmd.Info {
 
 func (c *PublishCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.CharmPath, "from", ".", "path for charm to be published")
+	f.BoolVar(&c.DryRun, "dry-run", false, "report what would be published without pushing")
 }
 
 func (c *PublishCommand) Init(args []string) error {
@@ -77,9 +87,10 @@ func (c *PublishCommand) SetPollDelay(delay time.Duration) {
 // Wording guideline to avoid confusion: charms have *URLs*, branches have *locations*.
 
 func (c *PublishCommand) Run(ctx *cmd.Context) (err error) {
-	branch := bzr.New(ctx.AbsPath(c.CharmPath))
-	if _, err := os.Stat(branch.Join(".bzr")); err != nil {
-		return fmt.Errorf("not a charm branch: %s", branch.Location())
+	path := ctx.AbsPath(c.CharmPath)
+	branch, err := vcs.Open(path)
+	if err != nil {
+		return fmt.Errorf("not a charm branch: %s", path)
 	}
 	if err := branch.CheckClean(); err != nil {
 		return err
@@ -147,9 +158,15 @@ func (c *PublishCommand) Run(ctx *cmd.Context) (err error) {
 		return handleEvent(ctx, curl, oldEvent)
 	}
 
+	if c.DryRun {
+		fmt.Fprintf(ctx.Stdout, "%s would be published from %s revision %s via %s\n",
+			curl, branch.Kind(), localDigest, pushLocation)
+		return nil
+	}
+
 	logger.Infof("sending charm to the charm store...")
 
-	err = branch.Push(&bzr.PushAttr{Location: pushLocation, Remember: true})
+	err = branch.Push(pushLocation)
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/juju/charm"
 	"github.com/juju/cmd"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/juju/juju/bzr"
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/git"
 	"github.com/juju/juju/testing"
 )
 
@@ -36,7 +38,15 @@ func touch(c *gc.C, filename string) {
 	f.Close()
 }
 
-func addMeta(c *gc.C, branch *bzr.Branch, meta string) {
+// testBranch holds the methods shared by bzr and git branches
+// that are used to set up charms for publishing.
+type testBranch interface {
+	Join(parts ...string) string
+	Add(parts ...string) error
+	Commit(message string) error
+}
+
+func addMeta(c *gc.C, branch testBranch, meta string) {
 	if meta == "" {
 		meta = "name: wordpress\nsummary: Some summary\ndescription: Some description.\n"
 	}
@@ -286,6 +296,101 @@ func (s *PublishSuite) TestFullPublish(c *gc.C) {
 	}
 }
 
+func (s *PublishSuite) TestDryRun(c *gc.C) {
+	addMeta(c, s.branch, "")
+	digest, err := s.branch.RevisionId()
+	c.Assert(err, gc.IsNil)
+
+	// Neither the local digest nor the charm itself are in the store.
+	body := `{"cs:precise/wordpress": {"errors": ["entry not found"]}}`
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+
+	ctx, err := s.runPublish(c, "--dry-run", "cs:precise/wordpress")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, fmt.Sprintf(
+		"cs:precise/wordpress would be published from bzr revision %s via lp:charms/precise/wordpress\n", digest))
+
+	// Nothing was pushed.
+	_, err = s.branch.PushLocation()
+	c.Assert(err, gc.ErrorMatches, "no push branch location defined")
+}
+
+// newGitBranch returns a new git working tree for publishing from.
+func (s *PublishSuite) newGitBranch(c *gc.C) *git.Branch {
+	for _, prefix := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		s.PatchEnvironment(prefix+"_NAME", "Test")
+		s.PatchEnvironment(prefix+"_EMAIL", "testing@testing.invalid")
+	}
+	branch := git.New(c.MkDir())
+	err := branch.Init()
+	c.Assert(err, gc.IsNil)
+	return branch
+}
+
+func (s *PublishSuite) TestGitNotClean(c *gc.C) {
+	branch := s.newGitBranch(c)
+	addMeta(c, branch, "")
+	touch(c, branch.Join("file"))
+	_, err := testing.RunCommandInDir(c, envcmd.Wrap(&PublishCommand{}), []string{"cs:precise/wordpress"}, branch.Location())
+	c.Assert(err, gc.ErrorMatches, `branch is not clean \(git status\)`)
+}
+
+func (s *PublishSuite) TestGitDryRun(c *gc.C) {
+	branch := s.newGitBranch(c)
+	addMeta(c, branch, "")
+	digest, err := branch.RevisionId()
+	c.Assert(err, gc.IsNil)
+
+	body := `{"cs:~user/precise/wordpress": {"errors": ["entry not found"]}}`
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+
+	ctx, err := testing.RunCommandInDir(c, envcmd.Wrap(&PublishCommand{}),
+		[]string{"--dry-run", "cs:~user/precise/wordpress"}, branch.Location())
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, fmt.Sprintf(
+		"cs:~user/precise/wordpress would be published from git revision %s via lp:~user/charms/precise/wordpress/trunk\n", digest))
+}
+
+func (s *PublishSuite) TestGitFullPublish(c *gc.C) {
+	branch := s.newGitBranch(c)
+	addMeta(c, branch, "")
+	digest, err := branch.RevisionId()
+	c.Assert(err, gc.IsNil)
+
+	pushDir := c.MkDir()
+	err = exec.Command("git", "init", "-q", "--bare", pushDir).Run()
+	c.Assert(err, gc.IsNil)
+
+	cmd := &PublishCommand{}
+	cmd.ChangePushLocation(func(location string) string {
+		c.Assert(location, gc.Equals, "lp:~user/charms/precise/wordpress/trunk")
+		return pushDir
+	})
+	cmd.SetPollDelay(testing.ShortWait)
+
+	var body string
+	body = `{"cs:~user/precise/wordpress": {"kind": "", "errors": ["entry not found"]}}`
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+	body = `{"cs:~user/precise/wordpress": {"kind": "published", "digest": "other-digest"}}`
+	gitjujutesting.Server.Response(200, nil, []byte(body))
+	body = `{"cs:~user/precise/wordpress": {"kind": "published", "digest": %q, "revision": 42}}`
+	gitjujutesting.Server.Response(200, nil, []byte(fmt.Sprintf(body, digest)))
+
+	ctx, err := testing.RunCommandInDir(c, envcmd.Wrap(cmd), []string{"cs:~user/precise/wordpress"}, branch.Location())
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "cs:~user/precise/wordpress-42\n")
+
+	// Ensure the branch was actually pushed, and the location remembered.
+	location, err := branch.PushLocation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(location, gc.Equals, pushDir)
+	pushDigest, err := exec.Command("git", "--git-dir", pushDir, "rev-parse", "HEAD").Output()
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(pushDigest), gc.Equals, digest+"\n")
+}
+
 func (s *PublishSuite) TestFullPublishError(c *gc.C) {
 	addMeta(c, s.branch, "")
 
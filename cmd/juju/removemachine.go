@@ -5,19 +5,25 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/provider"
+	"github.com/juju/juju/state/api"
 )
 
 // RemoveMachineCommand causes an existing machine to be destroyed.
 type RemoveMachineCommand struct {
 	envcmd.EnvCommandBase
-	MachineIds []string
-	Force      bool
+	MachineIds   []string
+	Force        bool
+	Clean        bool
+	IdentityFile string
 }
 
 const destroyMachineDoc = `
@@ -26,12 +32,24 @@ running units or containers can only be removed with the --force flag; doing
 so will also remove all those units and containers without giving them any
 opportunity to shut down cleanly.
 
+Removing a manually provisioned machine with the --clean flag, or removing any
+machine from an environment using the manual provider, also connects to the
+machine's host via SSH to uninstall the agents and remove their upstart jobs,
+data, logs and containers. Anything that could not be removed is reported.
+The --identity-file flag gives the path of an SSH private key to use as well
+as the default keys. Containers on manually provisioned hosts are removed by
+the host's machine agent, as elsewhere, so only the hosts themselves are
+cleaned up via SSH.
+
 Examples:
 	# Remove machine number 5 which has no running units or containers
 	$ juju remove-machine 5
 
 	# Remove machine 6 and any running units or containers
 	$ juju remove-machine 6 --force
+
+	# Remove manually provisioned machine 7 and clean up its host
+	$ juju remove-machine 7 --clean
 `
 
 func (c *RemoveMachineCommand) Info() *cmd.Info {
@@ -46,6 +64,8 @@ func (c *RemoveMachineCommand) Info() *cmd.Info {
 
 func (c *RemoveMachineCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Force, "force", false, "completely remove machine and all dependencies")
+	f.BoolVar(&c.Clean, "clean", false, "uninstall juju from the hosts of manually provisioned machines")
+	f.StringVar(&c.IdentityFile, "identity-file", "", "path of an SSH private key to authenticate with when cleaning up hosts")
 }
 
 func (c *RemoveMachineCommand) Init(args []string) error {
@@ -61,14 +81,103 @@ func (c *RemoveMachineCommand) Init(args []string) error {
 	return nil
 }
 
-func (c *RemoveMachineCommand) Run(_ *cmd.Context) error {
+var manualDecommissioner = manual.DecommissionMachine
+
+func (c *RemoveMachineCommand) Run(ctx *cmd.Context) error {
 	apiclient, err := c.NewAPIClient()
 	if err != nil {
 		return err
 	}
 	defer apiclient.Close()
+	hosts, err := c.manualHosts(apiclient)
+	if err != nil {
+		return err
+	}
 	if c.Force {
-		return apiclient.ForceDestroyMachines(c.MachineIds...)
+		err = apiclient.ForceDestroyMachines(c.MachineIds...)
+	} else {
+		err = apiclient.DestroyMachines(c.MachineIds...)
+	}
+	if err != nil || len(hosts) == 0 {
+		return err
+	}
+	return c.decommission(ctx, apiclient, hosts)
+}
+
+// manualHosts returns the hosts of the machines to be cleaned up,
+// keyed by machine id. When --clean is specified, every machine must
+// have been manually provisioned; otherwise, only the manually
+// provisioned machines of environments using the manual provider are
+// cleaned up.
+func (c *RemoveMachineCommand) manualHosts(apiclient *api.Client) (map[string]string, error) {
+	if !c.Clean {
+		attrs, err := apiclient.EnvironmentGet()
+		if err != nil {
+			return nil, err
+		}
+		if providerType, _ := attrs["type"].(string); !provider.IsManual(providerType) {
+			return nil, nil
+		}
+	}
+	status, err := apiclient.Status(nil)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make(map[string]string)
+	for _, id := range c.MachineIds {
+		// Only top level machines are listed in the status, so
+		// containers are checked by their host machine.
+		hostId := strings.SplitN(id, "/", 2)[0]
+		host, ok := manual.ManualHost(string(status.Machines[hostId].InstanceId))
+		switch {
+		case ok && hostId == id:
+			hosts[id] = host
+		case ok:
+			// The host's machine agent removes the container;
+			// decommissioning would clean up the whole host.
+		case c.Clean && hostId == id:
+			return nil, fmt.Errorf("cannot clean machine %s: machine was not manually provisioned", id)
+		case c.Clean:
+			return nil, fmt.Errorf("cannot clean machine %s: host machine %s was not manually provisioned", id, hostId)
+		}
+	}
+	return hosts, nil
+}
+
+// decommission uninstalls juju from the hosts of the given machines,
+// which have been destroyed, and then removes the machines from the
+// environment, since their agents are no longer there to do so.
+func (c *RemoveMachineCommand) decommission(ctx *cmd.Context, apiclient *api.Client, hosts map[string]string) error {
+	var cleaned []string
+	incomplete := 0
+	for _, id := range c.MachineIds {
+		host, ok := hosts[id]
+		if !ok {
+			continue
+		}
+		failures, err := manualDecommissioner(host, id, c.IdentityFile)
+		if err != nil {
+			ctx.Infof("%v", err)
+			incomplete++
+			continue
+		}
+		cleaned = append(cleaned, id)
+		if len(failures) == 0 {
+			ctx.Infof("cleaned machine %s on %s", id, host)
+			continue
+		}
+		incomplete++
+		for _, failure := range failures {
+			ctx.Infof("machine %s on %s: cannot remove %s", id, host, failure)
+		}
 	}
-	return apiclient.DestroyMachines(c.MachineIds...)
+	if len(cleaned) > 0 {
+		if err := apiclient.ForceDestroyMachines(cleaned...); err != nil {
+			return err
+		}
+	}
+	if incomplete > 0 {
+		return fmt.Errorf("failed to completely clean %d of %d machines", incomplete, len(hosts))
+	}
+	return nil
 }
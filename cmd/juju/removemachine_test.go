@@ -4,12 +4,15 @@
 package main
 
 import (
+	"fmt"
+
 	charmtesting "github.com/juju/charm/testing"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
@@ -117,6 +120,129 @@ func (s *RemoveMachineSuite) TestForce(c *gc.C) {
 	c.Assert(m0.Life(), gc.Equals, state.Alive)
 }
 
+func (s *RemoveMachineSuite) addManualMachine(c *gc.C, host string) *state.Machine {
+	m, err := s.State.AddOneMachine(state.MachineTemplate{
+		Series:     "quantal",
+		Jobs:       []state.MachineJob{state.JobHostUnits},
+		InstanceId: instance.Id("manual:" + host),
+		Nonce:      "manual:" + host + ":nonce",
+	})
+	c.Assert(err, gc.IsNil)
+	return m
+}
+
+func (s *RemoveMachineSuite) TestClean(c *gc.C) {
+	m0 := s.addManualMachine(c, "10.0.0.1")
+	m1 := s.addManualMachine(c, "10.0.0.2")
+	var decommissioned []string
+	s.PatchValue(&manualDecommissioner, func(host, machineId, identityFile string) ([]string, error) {
+		decommissioned = append(decommissioned, machineId+"@"+host)
+		if machineId == "1" {
+			return []string{"/var/log/juju"}, nil
+		}
+		return nil, nil
+	})
+	context, err := testing.RunCommand(c, envcmd.Wrap(&RemoveMachineCommand{}), "0", "1", "--clean")
+	c.Assert(err, gc.ErrorMatches, "failed to completely clean 1 of 2 machines")
+	c.Assert(decommissioned, gc.DeepEquals, []string{"0@10.0.0.1", "1@10.0.0.2"})
+	c.Assert(testing.Stderr(context), gc.Equals, `cleaned machine 0 on 10.0.0.1
+machine 1 on 10.0.0.2: cannot remove /var/log/juju
+`)
+
+	// The machines' agents are gone, so the machines are removed.
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	for _, m := range []*state.Machine{m0, m1} {
+		err = m.Refresh()
+		c.Assert(err, gc.IsNil)
+		c.Assert(m.Life(), gc.Equals, state.Dead)
+	}
+}
+
+func (s *RemoveMachineSuite) TestCleanPassesIdentityFile(c *gc.C) {
+	s.addManualMachine(c, "10.0.0.1")
+	var identityFiles []string
+	s.PatchValue(&manualDecommissioner, func(host, machineId, identityFile string) ([]string, error) {
+		identityFiles = append(identityFiles, identityFile)
+		return nil, nil
+	})
+	err := runRemoveMachine(c, "0", "--clean", "--identity-file", "/home/ubuntu/.ssh/fleet_rsa")
+	c.Assert(err, gc.IsNil)
+	c.Assert(identityFiles, gc.DeepEquals, []string{"/home/ubuntu/.ssh/fleet_rsa"})
+}
+
+func (s *RemoveMachineSuite) TestCleanContainerOnManualHost(c *gc.C) {
+	host := s.addManualMachine(c, "10.0.0.1")
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, host.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(&manualDecommissioner, func(host, machineId, identityFile string) ([]string, error) {
+		c.Fatalf("unexpected decommission of machine %s", machineId)
+		return nil, nil
+	})
+	err = runRemoveMachine(c, container.Id(), "--clean")
+	c.Assert(err, gc.IsNil)
+
+	// The host's machine agent removes the container.
+	err = container.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(container.Life(), gc.Equals, state.Dying)
+	err = host.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(host.Life(), gc.Equals, state.Alive)
+}
+
+func (s *RemoveMachineSuite) TestCleanContainerNotOnManualHost(c *gc.C) {
+	container, err := s.State.AddMachineInsideNewMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, instance.LXC)
+	c.Assert(err, gc.IsNil)
+	err = runRemoveMachine(c, container.Id(), "--clean")
+	c.Assert(err, gc.ErrorMatches, `cannot clean machine 0/lxc/0: host machine 0 was not manually provisioned`)
+}
+
+func (s *RemoveMachineSuite) TestCleanDecommissionError(c *gc.C) {
+	m0 := s.addManualMachine(c, "10.0.0.1")
+	s.PatchValue(&manualDecommissioner, func(host, machineId, identityFile string) ([]string, error) {
+		return nil, fmt.Errorf("cannot decommission machine 0 on 10.0.0.1: connection refused")
+	})
+	context, err := testing.RunCommand(c, envcmd.Wrap(&RemoveMachineCommand{}), "0", "--clean")
+	c.Assert(err, gc.ErrorMatches, "failed to completely clean 1 of 1 machines")
+	c.Assert(testing.Stderr(context), gc.Equals, "cannot decommission machine 0 on 10.0.0.1: connection refused\n")
+
+	// The machine's agent may still be running, so it is left to
+	// remove the machine itself.
+	err = m0.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(m0.Life(), gc.Equals, state.Dying)
+}
+
+func (s *RemoveMachineSuite) TestCleanNotManual(c *gc.C) {
+	m0, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	s.addManualMachine(c, "10.0.0.2")
+	s.PatchValue(&manualDecommissioner, func(host, machineId, identityFile string) ([]string, error) {
+		c.Fatalf("unexpected decommission of machine %s", machineId)
+		return nil, nil
+	})
+	err = runRemoveMachine(c, "1", "0", "--clean")
+	c.Assert(err, gc.ErrorMatches, "cannot clean machine 0: machine was not manually provisioned")
+	err = m0.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(m0.Life(), gc.Equals, state.Alive)
+
+	// Without --clean, manually provisioned machines are only cleaned
+	// up in environments using the manual provider.
+	err = runRemoveMachine(c, "1")
+	c.Assert(err, gc.IsNil)
+}
+
 func (s *RemoveMachineSuite) TestBadArgs(c *gc.C) {
 	// Check invalid args.
 	err := runRemoveMachine(c)
@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+)
+
+const setAutoscaleDoc = `
+Sets the policy by which a service is scaled up and down according to
+a metric reported by its units. Charms report metrics from their hooks
+with the add-metric hook tool, for example:
+
+    add-metric load=0.75
+
+Every minute, the metric is averaged over the service's units that have
+reported it in the last five minutes. If the average is above the
+--scale-up threshold, a unit is added; if it is below the --scale-down
+threshold, the most recently added unit is removed. After a change, no
+further change is made until the --cooldown period has passed. The
+number of units is always kept between --min and --max.
+
+The policy and the most recent scaling decisions are shown by juju
+status. Use --disable to stop autoscaling the service.
+
+Examples:
+   juju set-autoscale wordpress --metric load --min 2 --max 10 \
+       --scale-up 0.8 --scale-down 0.2 --cooldown 10m
+   juju set-autoscale wordpress --disable
+`
+
+// SetAutoscaleCommand sets the autoscale policy of a service.
+type SetAutoscaleCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Policy      params.AutoscalePolicy
+	Disable     bool
+	scaleUp     string
+	scaleDown   string
+}
+
+func (c *SetAutoscaleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-autoscale",
+		Args:    "<service> --metric <key> --max <n> --scale-up <value> --scale-down <value> | <service> --disable",
+		Purpose: "scale a service according to the metrics reported by its units",
+		Doc:     setAutoscaleDoc,
+	}
+}
+
+func (c *SetAutoscaleCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Policy.Metric, "metric", "", "the metric that drives the scaling")
+	f.IntVar(&c.Policy.MinUnits, "min", 1, "the minimum number of units")
+	f.IntVar(&c.Policy.MaxUnits, "max", 0, "the maximum number of units")
+	f.StringVar(&c.scaleUp, "scale-up", "", "add a unit when the average metric is above this value")
+	f.StringVar(&c.scaleDown, "scale-down", "", "remove a unit when the average metric is below this value")
+	f.DurationVar(&c.Policy.Cooldown, "cooldown", 5*time.Minute, "the minimum time between scaling decisions")
+	f.BoolVar(&c.Disable, "disable", false, "stop autoscaling the service")
+}
+
+func (c *SetAutoscaleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no service specified")
+	}
+	c.ServiceName = args[0]
+	if !names.IsValidService(c.ServiceName) {
+		return fmt.Errorf("invalid service name %q", c.ServiceName)
+	}
+	if err := cmd.CheckEmpty(args[1:]); err != nil {
+		return err
+	}
+	if c.Disable {
+		if c.Policy.Metric != "" || c.Policy.MaxUnits != 0 || c.scaleUp != "" || c.scaleDown != "" {
+			return fmt.Errorf("cannot specify a policy with --disable")
+		}
+		return nil
+	}
+	switch {
+	case c.Policy.Metric == "":
+		return fmt.Errorf("no metric specified")
+	case c.Policy.MaxUnits == 0:
+		return fmt.Errorf("no maximum number of units specified")
+	case c.scaleUp == "":
+		return fmt.Errorf("no scale up threshold specified")
+	case c.scaleDown == "":
+		return fmt.Errorf("no scale down threshold specified")
+	}
+	var err error
+	if c.Policy.ScaleUpThreshold, err = strconv.ParseFloat(c.scaleUp, 64); err != nil {
+		return fmt.Errorf("invalid scale up threshold %q", c.scaleUp)
+	}
+	if c.Policy.ScaleDownThreshold, err = strconv.ParseFloat(c.scaleDown, 64); err != nil {
+		return fmt.Errorf("invalid scale down threshold %q", c.scaleDown)
+	}
+	return nil
+}
+
+func (c *SetAutoscaleCommand) Run(_ *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if c.Disable {
+		return client.SetAutoscale(c.ServiceName, nil)
+	}
+	return client.SetAutoscale(c.ServiceName, &c.Policy)
+}
@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type SetAutoscaleSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&SetAutoscaleSuite{})
+
+func runSetAutoscale(c *gc.C, args ...string) error {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&SetAutoscaleCommand{}), args...)
+	return err
+}
+
+var policyArgs = []string{"--metric", "load", "--max", "5", "--scale-up", "0.8", "--scale-down", "0.2"}
+
+var setAutoscaleInitTests = []struct {
+	args []string
+	err  string
+}{
+	{
+		err: `no service specified`,
+	}, {
+		args: []string{"wordpress/0"},
+		err:  `invalid service name "wordpress/0"`,
+	}, {
+		args: []string{"wordpress", "mysql"},
+		err:  `unrecognized args: \["mysql"\]`,
+	}, {
+		args: []string{"wordpress", "--max", "5", "--scale-up", "0.8", "--scale-down", "0.2"},
+		err:  `no metric specified`,
+	}, {
+		args: []string{"wordpress", "--metric", "load", "--scale-up", "0.8", "--scale-down", "0.2"},
+		err:  `no maximum number of units specified`,
+	}, {
+		args: []string{"wordpress", "--metric", "load", "--max", "5", "--scale-down", "0.2"},
+		err:  `no scale up threshold specified`,
+	}, {
+		args: []string{"wordpress", "--metric", "load", "--max", "5", "--scale-up", "0.8"},
+		err:  `no scale down threshold specified`,
+	}, {
+		args: []string{"wordpress", "--metric", "load", "--max", "5", "--scale-up", "high", "--scale-down", "0.2"},
+		err:  `invalid scale up threshold "high"`,
+	}, {
+		args: append([]string{"wordpress", "--disable"}, policyArgs...),
+		err:  `cannot specify a policy with --disable`,
+	}, {
+		args: append([]string{"wordpress"}, policyArgs...),
+	}, {
+		args: []string{"wordpress", "--disable"},
+	},
+}
+
+func (s *SetAutoscaleSuite) TestInit(c *gc.C) {
+	for i, t := range setAutoscaleInitTests {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(envcmd.Wrap(&SetAutoscaleCommand{}), t.args)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+		} else {
+			c.Check(err, gc.IsNil)
+		}
+	}
+}
+
+func (s *SetAutoscaleSuite) TestSetAutoscale(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := runSetAutoscale(c, append([]string{"wordpress", "--min", "2", "--cooldown", "10m"}, policyArgs...)...)
+	c.Assert(err, gc.IsNil)
+	policy, err := svc.AutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, state.AutoscalePolicy{
+		MinUnits:           2,
+		MaxUnits:           5,
+		Metric:             "load",
+		ScaleUpThreshold:   0.8,
+		ScaleDownThreshold: 0.2,
+		Cooldown:           10 * time.Minute,
+	})
+
+	err = runSetAutoscale(c, "wordpress", "--disable")
+	c.Assert(err, gc.IsNil)
+	_, err = svc.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SetAutoscaleSuite) TestSetAutoscaleInvalidPolicy(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := runSetAutoscale(c, "wordpress", "--metric", "load", "--max", "5", "--scale-up", "0.2", "--scale-down", "0.8")
+	c.Assert(err, gc.ErrorMatches, `cannot set autoscale policy for service "wordpress": scale down threshold must be less than scale up threshold`)
+}
+
+func (s *SetAutoscaleSuite) TestSetAutoscaleSubordinate(c *gc.C) {
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	err := runSetAutoscale(c, append([]string{"logging"}, policyArgs...)...)
+	c.Assert(err, gc.ErrorMatches, `cannot autoscale subordinate service "logging"`)
+}
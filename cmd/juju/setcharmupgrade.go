@@ -0,0 +1,101 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+)
+
+const setCharmUpgradeDoc = `
+Sets the policy by which a service deployed from the charm store is
+upgraded when new revisions of its charm appear. The state server
+checks for new revisions periodically; the policy's mode decides what
+happens when one is found:
+
+    never    nothing; this is the default
+    notify   the new revision is recorded, but the service is not upgraded
+    auto     the service is upgraded to the new revision straight away
+    window   the service is upgraded, but only within the environment's
+             maintenance windows (see "juju help maintenance")
+
+Services are only upgraded to revisions for the same series, and are
+not upgraded while any of their units are in an error state.
+
+The policy and the most recent upgrades are shown by juju status. Use
+--reset to discard the policy and its history.
+
+Examples:
+   juju set-charm-upgrade wordpress auto
+   juju set-charm-upgrade wordpress window
+   juju set-charm-upgrade wordpress --reset
+`
+
+// SetCharmUpgradeCommand sets the charm upgrade policy of a service.
+type SetCharmUpgradeCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Policy      params.CharmUpgradePolicy
+	Reset       bool
+}
+
+func (c *SetCharmUpgradeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-charm-upgrade",
+		Args:    "<service> (never|notify|auto|window) | <service> --reset",
+		Purpose: "upgrade a service automatically when new revisions of its charm appear",
+		Doc:     setCharmUpgradeDoc,
+	}
+}
+
+func (c *SetCharmUpgradeCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.Reset, "reset", false, "discard the service's charm upgrade policy")
+}
+
+func (c *SetCharmUpgradeCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no service specified")
+	}
+	c.ServiceName = args[0]
+	if !names.IsValidService(c.ServiceName) {
+		return fmt.Errorf("invalid service name %q", c.ServiceName)
+	}
+	args = args[1:]
+	if c.Reset {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot specify a policy with --reset")
+		}
+		return nil
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no mode specified")
+	}
+	c.Policy.Mode = args[0]
+	if err := cmd.CheckEmpty(args[1:]); err != nil {
+		return err
+	}
+	switch c.Policy.Mode {
+	case "never", "notify", "auto", "window":
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q", c.Policy.Mode)
+}
+
+func (c *SetCharmUpgradeCommand) Run(_ *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if c.Reset {
+		return client.SetCharmUpgradePolicy(c.ServiceName, nil)
+	}
+	return client.SetCharmUpgradePolicy(c.ServiceName, &c.Policy)
+}
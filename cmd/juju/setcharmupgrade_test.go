@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/juju/charm"
+	charmtesting "github.com/juju/charm/testing"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type SetCharmUpgradeSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&SetCharmUpgradeSuite{})
+
+func runSetCharmUpgrade(c *gc.C, args ...string) error {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&SetCharmUpgradeCommand{}), args...)
+	return err
+}
+
+var setCharmUpgradeInitTests = []struct {
+	args []string
+	err  string
+	mode string
+}{
+	{
+		err: `no service specified`,
+	}, {
+		args: []string{"wordpress/0", "auto"},
+		err:  `invalid service name "wordpress/0"`,
+	}, {
+		args: []string{"wordpress"},
+		err:  `no mode specified`,
+	}, {
+		args: []string{"wordpress", "auto", "notify"},
+		err:  `unrecognized args: \["notify"\]`,
+	}, {
+		args: []string{"wordpress", "sometimes"},
+		err:  `unknown mode "sometimes"`,
+	}, {
+		args: []string{"wordpress", "auto", "--reset"},
+		err:  `cannot specify a policy with --reset`,
+	}, {
+		args: []string{"wordpress", "never"},
+		mode: "never",
+	}, {
+		args: []string{"wordpress", "notify"},
+		mode: "notify",
+	}, {
+		args: []string{"wordpress", "auto"},
+		mode: "auto",
+	}, {
+		args: []string{"wordpress", "window"},
+		mode: "window",
+	}, {
+		args: []string{"wordpress", "--reset"},
+	},
+}
+
+func (s *SetCharmUpgradeSuite) TestInit(c *gc.C) {
+	for i, t := range setCharmUpgradeInitTests {
+		c.Logf("test %d: %v", i, t.args)
+		command := &SetCharmUpgradeCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), t.args)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(command.Policy.Mode, gc.Equals, t.mode)
+	}
+}
+
+func (s *SetCharmUpgradeSuite) addStoreService(c *gc.C) *state.Service {
+	curl := charm.MustParseURL("cs:quantal/dummy-1")
+	bundleURL, err := url.Parse("http://bundles.testing.invalid/dummy-1")
+	c.Assert(err, gc.IsNil)
+	ch, err := s.State.AddCharm(charmtesting.Charms.Dir("dummy"), curl, bundleURL, "dummy-1-sha256")
+	c.Assert(err, gc.IsNil)
+	return s.AddTestingService(c, "dummy", ch)
+}
+
+func (s *SetCharmUpgradeSuite) TestSetCharmUpgrade(c *gc.C) {
+	svc := s.addStoreService(c)
+	err := runSetCharmUpgrade(c, "dummy", "window")
+	c.Assert(err, gc.IsNil)
+	policy, err := svc.CharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, state.CharmUpgradePolicy{Mode: state.CharmUpgradeWindow})
+
+	err = runSetCharmUpgrade(c, "dummy", "auto")
+	c.Assert(err, gc.IsNil)
+	policy, err = svc.CharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+
+	err = runSetCharmUpgrade(c, "dummy", "--reset")
+	c.Assert(err, gc.IsNil)
+	_, err = svc.CharmUpgradePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SetCharmUpgradeSuite) TestSetCharmUpgradeLocalCharm(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := runSetCharmUpgrade(c, "wordpress", "auto")
+	c.Assert(err, gc.ErrorMatches, `cannot upgrade local charm of service "wordpress" automatically`)
+}
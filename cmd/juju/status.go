@@ -120,6 +120,7 @@ type machineStatus struct {
 	DNSName        string                   `json:"dns-name,omitempty" yaml:"dns-name,omitempty"`
 	InstanceId     instance.Id              `json:"instance-id,omitempty" yaml:"instance-id,omitempty"`
 	InstanceState  string                   `json:"instance-state,omitempty" yaml:"instance-state,omitempty"`
+	Provisioning   *provisioningStatus      `json:"provisioning-retry,omitempty" yaml:"provisioning-retry,omitempty"`
 	Life           string                   `json:"life,omitempty" yaml:"life,omitempty"`
 	Series         string                   `json:"series,omitempty" yaml:"series,omitempty"`
 	Id             string                   `json:"-" yaml:"-"`
@@ -128,6 +129,14 @@ type machineStatus struct {
 	HAStatus       string                   `json:"state-server-member-status,omitempty" yaml:"state-server-member-status,omitempty"`
 }
 
+// provisioningStatus describes the automatic retries of
+// a machine whose instance could not be started.
+type provisioningStatus struct {
+	Attempt     int    `json:"attempt" yaml:"attempt"`
+	MaxAttempts int    `json:"max-attempts" yaml:"max-attempts"`
+	NextRetry   string `json:"next-retry,omitempty" yaml:"next-retry,omitempty"`
+}
+
 // A goyaml bug means we can't declare these types
 // locally to the GetYAML methods.
 type machineStatusNoMarshal machineStatus
@@ -151,16 +160,49 @@ func (s machineStatus) GetYAML() (tag string, value interface{}) {
 }
 
 type serviceStatus struct {
-	Err           error                 `json:"-" yaml:",omitempty"`
-	Charm         string                `json:"charm" yaml:"charm"`
-	CanUpgradeTo  string                `json:"can-upgrade-to,omitempty" yaml:"can-upgrade-to,omitempty"`
-	Exposed       bool                  `json:"exposed" yaml:"exposed"`
-	Life          string                `json:"life,omitempty" yaml:"life,omitempty"`
-	Relations     map[string][]string   `json:"relations,omitempty" yaml:"relations,omitempty"`
-	Networks      map[string][]string   `json:"networks,omitempty" yaml:"networks,omitempty"`
-	SubordinateTo []string              `json:"subordinate-to,omitempty" yaml:"subordinate-to,omitempty"`
-	Units         map[string]unitStatus `json:"units,omitempty" yaml:"units,omitempty"`
-}
+	Err           error               `json:"-" yaml:",omitempty"`
+	Charm         string              `json:"charm" yaml:"charm"`
+	CanUpgradeTo  string              `json:"can-upgrade-to,omitempty" yaml:"can-upgrade-to,omitempty"`
+	Exposed       bool                `json:"exposed" yaml:"exposed"`
+	Life          string              `json:"life,omitempty" yaml:"life,omitempty"`
+	Relations     map[string][]string `json:"relations,omitempty" yaml:"relations,omitempty"`
+	Networks      map[string][]string `json:"networks,omitempty" yaml:"networks,omitempty"`
+	SubordinateTo []string            `json:"subordinate-to,omitempty" yaml:"subordinate-to,omitempty"`
+	// SubordinatePlacement holds, for each principal service whose
+	// units are given subordinates selectively, the restrictions on
+	// the units that are given subordinates.
+	SubordinatePlacement map[string]string     `json:"subordinate-placement,omitempty" yaml:"subordinate-placement,omitempty"`
+	Autoscale            *autoscaleStatus      `json:"autoscale,omitempty" yaml:"autoscale,omitempty"`
+	CharmUpgrade         *charmUpgradeStatus   `json:"charm-upgrade,omitempty" yaml:"charm-upgrade,omitempty"`
+	Units                map[string]unitStatus `json:"units,omitempty" yaml:"units,omitempty"`
+}
+
+// autoscaleStatus describes a service's autoscale policy,
+// along with its most recent scaling decisions.
+type autoscaleStatus struct {
+	MinUnits           int      `json:"min-units" yaml:"min-units"`
+	MaxUnits           int      `json:"max-units" yaml:"max-units"`
+	Metric             string   `json:"metric" yaml:"metric"`
+	ScaleUpThreshold   float64  `json:"scale-up" yaml:"scale-up"`
+	ScaleDownThreshold float64  `json:"scale-down" yaml:"scale-down"`
+	Cooldown           string   `json:"cooldown" yaml:"cooldown"`
+	Decisions          []string `json:"recent-decisions,omitempty" yaml:"recent-decisions,omitempty"`
+}
+
+// autoscaleDecisionsShown holds the number of the most recent
+// autoscaling decisions shown for a service.
+const autoscaleDecisionsShown = 3
+
+// charmUpgradeStatus describes a service's charm upgrade policy,
+// along with its most recent charm upgrades.
+type charmUpgradeStatus struct {
+	Mode     string   `json:"mode" yaml:"mode"`
+	Upgrades []string `json:"recent-upgrades,omitempty" yaml:"recent-upgrades,omitempty"`
+}
+
+// charmUpgradesShown holds the number of the most recent charm
+// upgrades shown for a service.
+const charmUpgradesShown = 3
 
 type serviceStatusNoMarshal serviceStatus
 
@@ -311,6 +353,7 @@ func (sf *statusFormatter) formatMachine(machine api.MachineStatus) machineStatu
 			Id:             machine.Id,
 			Containers:     make(map[string]machineStatus),
 			Hardware:       machine.Hardware,
+			Provisioning:   getProvisioningStatusFromData(agent.Data),
 		}
 	}
 
@@ -348,9 +391,91 @@ func (sf *statusFormatter) formatService(name string, service api.ServiceStatus)
 	for k, m := range service.Units {
 		out.Units[k] = sf.formatUnit(m, name)
 	}
+	out.SubordinatePlacement = sf.subordinatePlacement(name)
+	if service.Autoscale != nil {
+		out.Autoscale = formatAutoscale(service.Autoscale)
+	}
+	if service.CharmUpgrade != nil {
+		out.CharmUpgrade = formatCharmUpgrade(service.CharmUpgrade)
+	}
+	return out
+}
+
+func formatAutoscale(autoscale *api.AutoscaleStatus) *autoscaleStatus {
+	policy := autoscale.Policy
+	out := &autoscaleStatus{
+		MinUnits:           policy.MinUnits,
+		MaxUnits:           policy.MaxUnits,
+		Metric:             policy.Metric,
+		ScaleUpThreshold:   policy.ScaleUpThreshold,
+		ScaleDownThreshold: policy.ScaleDownThreshold,
+		Cooldown:           policy.Cooldown.String(),
+	}
+	for i, decision := range autoscale.History {
+		if i == autoscaleDecisionsShown {
+			break
+		}
+		out.Decisions = append(out.Decisions, fmt.Sprintf(
+			"%s: %d -> %d units (%s)",
+			decision.Time.UTC().Format("2006-01-02 15:04:05"),
+			decision.From, decision.To, decision.Reason,
+		))
+	}
 	return out
 }
 
+func formatCharmUpgrade(charmUpgrade *api.CharmUpgradeStatus) *charmUpgradeStatus {
+	policy := charmUpgrade.Policy
+	out := &charmUpgradeStatus{Mode: policy.Mode}
+	for i, record := range charmUpgrade.History {
+		if i == charmUpgradesShown {
+			break
+		}
+		var what string
+		switch {
+		case record.Error != "":
+			what = fmt.Sprintf("upgrade to %s failed: %s", record.To, record.Error)
+		case record.Upgraded:
+			what = fmt.Sprintf("upgraded %s -> %s", record.From, record.To)
+		default:
+			what = fmt.Sprintf("%s available", record.To)
+		}
+		out.Upgrades = append(out.Upgrades, fmt.Sprintf(
+			"%s: %s", record.Time.UTC().Format("2006-01-02 15:04:05"), what,
+		))
+	}
+	return out
+}
+
+// subordinatePlacement returns the restrictions on the principal units
+// given units of the named subordinate service, keyed by principal
+// service name.
+func (sf *statusFormatter) subordinatePlacement(serviceName string) map[string]string {
+	var placement map[string]string
+	for _, relation := range sf.relations {
+		if relation.SubordinatePlacement == "" {
+			continue
+		}
+		var principal string
+		isSubordinate := false
+		for _, ep := range relation.Endpoints {
+			if ep.ServiceName == serviceName && ep.Subordinate {
+				isSubordinate = true
+			} else {
+				principal = ep.ServiceName
+			}
+		}
+		if !isSubordinate {
+			continue
+		}
+		if placement == nil {
+			placement = make(map[string]string)
+		}
+		placement[principal] = relation.SubordinatePlacement
+	}
+	return placement
+}
+
 func (sf *statusFormatter) formatUnit(unit api.UnitStatus, serviceName string) unitStatus {
 	out := unitStatus{
 		Err:            unit.Err,
@@ -412,6 +537,22 @@ func makeHAStatus(hasVote, wantsVote bool) string {
 	return s
 }
 
+// getProvisioningStatusFromData returns the provisioning retry
+// state recorded in a machine's status data, or nil if there is none.
+func getProvisioningStatusFromData(data params.StatusData) *provisioningStatus {
+	attempt, ok := data[params.StatusDataProvisioningAttempt].(float64)
+	if !ok {
+		return nil
+	}
+	maxAttempts, _ := data[params.StatusDataProvisioningMaxAttempts].(float64)
+	nextRetry, _ := data[params.StatusDataProvisioningNextRetry].(string)
+	return &provisioningStatus{
+		Attempt:     int(attempt),
+		MaxAttempts: int(maxAttempts),
+		NextRetry:   nextRetry,
+	}
+}
+
 func getRelationIdFromData(unit api.UnitStatus) int {
 	if relationId_, ok := unit.Agent.Data["relation-id"]; ok {
 		if relationId, ok := relationId_.(float64); ok {
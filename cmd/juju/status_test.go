@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/juju/charm"
 	charmtesting "github.com/juju/charm/testing"
@@ -1697,6 +1698,40 @@ var statusTests = []testCase{
 				},
 			},
 		},
+	), test(
+		"machine whose instance failed to start shows provisioning retries",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", []network.Address{network.NewAddress("dummyenv-0.dns", network.ScopeUnknown)}},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setMachineStatusData{"1", params.StatusError, "cannot start instance", params.StatusData{
+			params.StatusDataProvisioningAttempt:     2,
+			params.StatusDataProvisioningMaxAttempts: 5,
+			params.StatusDataProvisioningNextRetry:   "2014-07-01T10:00:00Z",
+		}},
+
+		expect{
+			"machine 1 is waiting for its next provisioning attempt",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": M{
+						"agent-state":      "down",
+						"agent-state-info": "(error: cannot start instance)",
+						"instance-id":      "pending",
+						"series":           "quantal",
+						"provisioning-retry": M{
+							"attempt":      2,
+							"max-attempts": 5,
+							"next-retry":   "2014-07-01T10:00:00Z",
+						},
+					},
+				},
+				"services": M{},
+			},
+		},
 	),
 }
 
@@ -2052,6 +2087,20 @@ func (sms setMachineStatus) step(c *gc.C, ctx *context) {
 	c.Assert(err, gc.IsNil)
 }
 
+type setMachineStatusData struct {
+	machineId  string
+	status     params.Status
+	statusInfo string
+	statusData params.StatusData
+}
+
+func (sms setMachineStatusData) step(c *gc.C, ctx *context) {
+	m, err := ctx.st.Machine(sms.machineId)
+	c.Assert(err, gc.IsNil)
+	err = m.SetStatus(sms.status, sms.statusInfo, sms.statusData)
+	c.Assert(err, gc.IsNil)
+}
+
 type relateServices struct {
 	ep1, ep2 string
 }
@@ -2311,3 +2360,111 @@ func (s *StatusSuite) TestStatusWithPreRelationsServer(c *gc.C) {
 	defer s.resetContext(c, ctx)
 	ctx.run(c, []stepper{expected})
 }
+
+func (s *StatusSuite) TestStatusSubordinatePlacement(c *gc.C) {
+	status := &api.Status{
+		EnvironmentName: "dummyenv",
+		Services: map[string]api.ServiceStatus{
+			"mysql": api.ServiceStatus{
+				Charm: "local:quantal/mysql-1",
+			},
+			"logging": api.ServiceStatus{
+				Charm:         "local:quantal/logging-1",
+				SubordinateTo: []string{"mysql", "wordpress"},
+			},
+		},
+		Relations: []api.RelationStatus{{
+			Id:    0,
+			Scope: charm.ScopeContainer,
+			Endpoints: []api.EndpointStatus{
+				{ServiceName: "logging", Name: "info", Subordinate: true},
+				{ServiceName: "mysql", Name: "juju-info"},
+			},
+			SubordinatePlacement: "units=mysql/0 tags=monitored",
+		}, {
+			Id:    1,
+			Scope: charm.ScopeContainer,
+			Endpoints: []api.EndpointStatus{
+				{ServiceName: "logging", Name: "info", Subordinate: true},
+				{ServiceName: "wordpress", Name: "juju-info"},
+			},
+		}},
+	}
+	out := newStatusFormatter(status).format()
+	c.Assert(out.Services["logging"].SubordinatePlacement, gc.DeepEquals, map[string]string{
+		"mysql": "units=mysql/0 tags=monitored",
+	})
+	c.Assert(out.Services["mysql"].SubordinatePlacement, gc.IsNil)
+}
+
+func (s *StatusSuite) TestStatusAutoscale(c *gc.C) {
+	decisionTime := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	status := &api.Status{
+		EnvironmentName: "dummyenv",
+		Services: map[string]api.ServiceStatus{
+			"wordpress": api.ServiceStatus{
+				Charm: "local:quantal/wordpress-3",
+				Autoscale: &api.AutoscaleStatus{
+					Policy: params.AutoscalePolicy{
+						MinUnits:           1,
+						MaxUnits:           5,
+						Metric:             "load",
+						ScaleUpThreshold:   0.8,
+						ScaleDownThreshold: 0.2,
+						Cooldown:           5 * time.Minute,
+					},
+					History: []params.AutoscaleDecision{
+						{Time: decisionTime.Add(3 * time.Minute), From: 3, To: 4, Reason: "average load of 0.9 is above 0.8"},
+						{Time: decisionTime.Add(2 * time.Minute), From: 2, To: 3, Reason: "average load of 0.9 is above 0.8"},
+						{Time: decisionTime.Add(time.Minute), From: 1, To: 2, Reason: "average load of 0.9 is above 0.8"},
+						{Time: decisionTime, From: 0, To: 1, Reason: "0 units is below the minimum of 1"},
+					},
+				},
+			},
+		},
+	}
+	out := newStatusFormatter(status).format()
+	c.Assert(out.Services["wordpress"].Autoscale, gc.DeepEquals, &autoscaleStatus{
+		MinUnits:           1,
+		MaxUnits:           5,
+		Metric:             "load",
+		ScaleUpThreshold:   0.8,
+		ScaleDownThreshold: 0.2,
+		Cooldown:           "5m0s",
+		Decisions: []string{
+			"2014-07-01 12:03:00: 3 -> 4 units (average load of 0.9 is above 0.8)",
+			"2014-07-01 12:02:00: 2 -> 3 units (average load of 0.9 is above 0.8)",
+			"2014-07-01 12:01:00: 1 -> 2 units (average load of 0.9 is above 0.8)",
+		},
+	})
+}
+
+func (s *StatusSuite) TestStatusCharmUpgrade(c *gc.C) {
+	upgradeTime := time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+	status := &api.Status{
+		EnvironmentName: "dummyenv",
+		Services: map[string]api.ServiceStatus{
+			"wordpress": api.ServiceStatus{
+				Charm: "cs:quantal/wordpress-26",
+				CharmUpgrade: &api.CharmUpgradeStatus{
+					Policy: params.CharmUpgradePolicy{Mode: "window"},
+					History: []params.CharmUpgradeRecord{
+						{Time: upgradeTime.Add(3 * time.Hour), From: "cs:quantal/wordpress-25", To: "cs:quantal/wordpress-26", Upgraded: true},
+						{Time: upgradeTime.Add(2 * time.Hour), To: "cs:quantal/wordpress-26", Error: "unit wordpress/0 is in error"},
+						{Time: upgradeTime.Add(time.Hour), To: "cs:quantal/wordpress-26"},
+						{Time: upgradeTime, From: "cs:quantal/wordpress-24", To: "cs:quantal/wordpress-25", Upgraded: true},
+					},
+				},
+			},
+		},
+	}
+	out := newStatusFormatter(status).format()
+	c.Assert(out.Services["wordpress"].CharmUpgrade, gc.DeepEquals, &charmUpgradeStatus{
+		Mode: "window",
+		Upgrades: []string{
+			"2014-07-01 15:00:00: upgraded cs:quantal/wordpress-25 -> cs:quantal/wordpress-26",
+			"2014-07-01 14:00:00: upgrade to cs:quantal/wordpress-26 failed: unit wordpress/0 is in error",
+			"2014-07-01 13:00:00: cs:quantal/wordpress-26 available",
+		},
+	})
+}
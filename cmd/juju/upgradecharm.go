@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/api"
 )
 
 // UpgradeCharm is responsible for upgrading a service's charm.
@@ -24,6 +25,7 @@ type UpgradeCharmCommand struct {
 	RepoPath    string // defaults to JUJU_REPOSITORY
 	SwitchURL   string
 	Revision    int // defaults to -1 (latest)
+	Diff        bool
 }
 
 const upgradeCharmDoc = `
@@ -59,6 +61,12 @@ The new charm may add new relations and configuration settings.
 number with --switch, give it in the charm URL, for instance "cs:wordpress-5"
 would specify revision number 5 of the wordpress charm.
 
+The --diff flag shows the differences between the service's current charm
+and the charm it would be upgraded to, without upgrading the service: added,
+removed and modified relation endpoints, config options and hooks. Changes
+that would make the upgrade fail, such as removing an endpoint that has live
+relations, are reported as incompatible and the command exits with an error.
+
 Use of the --force flag is not generally recommended; units upgraded while in an
 error state will not have upgrade-charm hooks executed, and may cause unexpected
 behavior.
@@ -78,6 +86,7 @@ func (c *UpgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.BoolVar(&c.Diff, "diff", false, "show the charm changes without upgrading")
 }
 
 func (c *UpgradeCharmCommand) Init(args []string) error {
@@ -159,6 +168,14 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
+	if c.Diff {
+		diff, err := c.diff(client, ctx, oldURL, newURL, repo, conf)
+		if err != nil {
+			return err
+		}
+		return printDiff(ctx, oldURL, newURL, diff)
+	}
+
 	addedURL, err := addCharmViaAPI(client, ctx, newURL, repo)
 	if err != nil {
 		return err
@@ -166,3 +183,47 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 
 	return client.ServiceSetCharm(c.ServiceName, addedURL.String(), c.Force)
 }
+
+// diff compares the charm currently used by the service with the
+// charm at newURL in repo.
+func (c *UpgradeCharmCommand) diff(
+	client *api.Client, ctx *cmd.Context, oldURL, newURL *charm.URL, repo charm.Repository, conf *config.Config,
+) (*charmDiff, error) {
+	newCharm, err := repo.Get(newURL)
+	if err != nil {
+		return nil, err
+	}
+	newContents, err := readCharmContents(newCharm)
+	if err != nil {
+		return nil, err
+	}
+	var oldContents *charmContents
+	if oldURL.Schema == "local" {
+		// The local repository may have changed since the charm was
+		// uploaded, so use the archive stored in the environment.
+		oldContents, err = storedCharmContents(client, oldURL)
+	} else {
+		var oldRepo charm.Repository
+		oldRepo, err = charm.InferRepository(oldURL.Reference, ctx.AbsPath(c.RepoPath))
+		if err != nil {
+			return nil, err
+		}
+		oldRepo = config.SpecializeCharmRepo(oldRepo, conf)
+		var oldCharm charm.Charm
+		if oldCharm, err = oldRepo.Get(oldURL); err == nil {
+			oldContents, err = readCharmContents(oldCharm)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read charm %q: %v", oldURL, err)
+	}
+	status, err := client.Status([]string{c.ServiceName})
+	if err != nil {
+		return nil, err
+	}
+	liveRelations := make(map[string]bool)
+	for name := range status.Services[c.ServiceName].Relations {
+		liveRelations[name] = true
+	}
+	return diffCharms(oldContents, newContents, liveRelations), nil
+}
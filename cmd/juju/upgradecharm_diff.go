@@ -0,0 +1,299 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/juju/charm"
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/state/api"
+)
+
+// charmContents holds the parts of a charm compared by
+// "juju upgrade-charm --diff".
+type charmContents struct {
+	meta   *charm.Meta
+	config *charm.Config
+	// hooks holds the SHA256 hash of each file in the hooks
+	// directory, keyed by file name.
+	hooks map[string]string
+}
+
+func hashBytes(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// readCharmContents returns the contents of a charm read from a
+// repository.
+func readCharmContents(ch charm.Charm) (*charmContents, error) {
+	contents := &charmContents{
+		meta:   ch.Meta(),
+		config: ch.Config(),
+		hooks:  make(map[string]string),
+	}
+	switch ch := ch.(type) {
+	case *charm.Dir:
+		hooksDir := filepath.Join(ch.Path, "hooks")
+		infos, err := ioutil.ReadDir(hooksDir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read hooks: %v", err)
+		}
+		for _, info := range infos {
+			if info.IsDir() {
+				continue
+			}
+			data, err := ioutil.ReadFile(filepath.Join(hooksDir, info.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("cannot read hooks: %v", err)
+			}
+			contents.hooks[info.Name()] = hashBytes(data)
+		}
+	case *charm.Bundle:
+		zipReader, err := zip.OpenReader(ch.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read charm archive: %v", err)
+		}
+		defer zipReader.Close()
+		for _, file := range zipReader.File {
+			name, ok := hookName(file.Name)
+			if !ok || file.FileInfo().IsDir() {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("cannot read hooks: %v", err)
+			}
+			data, err := ioutil.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				return nil, fmt.Errorf("cannot read hooks: %v", err)
+			}
+			contents.hooks[name] = hashBytes(data)
+		}
+	default:
+		return nil, fmt.Errorf("unknown charm type %T", ch)
+	}
+	return contents, nil
+}
+
+// storedCharmContents returns the contents of a local charm stored
+// in the environment.
+func storedCharmContents(client *api.Client, curl *charm.URL) (*charmContents, error) {
+	info, err := client.CharmInfo(curl.String())
+	if err != nil {
+		return nil, err
+	}
+	contents := &charmContents{
+		meta:   info.Meta,
+		config: info.Config,
+		hooks:  make(map[string]string),
+	}
+	files, err := client.CharmFiles(curl)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name, ok := hookName(file)
+		if !ok {
+			continue
+		}
+		data, err := client.CharmFile(curl, file)
+		if err != nil {
+			return nil, err
+		}
+		contents.hooks[name] = hashBytes(data)
+	}
+	return contents, nil
+}
+
+// hookName returns the name of the hook at path within a charm, and
+// whether path is in the hooks directory at all.
+func hookName(path string) (string, bool) {
+	path = filepath.Clean(path)
+	if !strings.HasPrefix(path, "hooks/") {
+		return "", false
+	}
+	name := strings.TrimPrefix(path, "hooks/")
+	if strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// charmDiff holds the differences between two charms.
+type charmDiff struct {
+	// Changes holds a line for each difference, prefixed with "+"
+	// for additions, "-" for removals and "~" for modifications.
+	Changes []string
+	// Incompatibilities describes the changes that prevent the
+	// service from being upgraded.
+	Incompatibilities []string
+}
+
+func (d *charmDiff) change(op, format string, args ...interface{}) {
+	d.Changes = append(d.Changes, op+" "+fmt.Sprintf(format, args...))
+}
+
+func (d *charmDiff) incompatible(format string, args ...interface{}) {
+	d.Incompatibilities = append(d.Incompatibilities, fmt.Sprintf(format, args...))
+}
+
+// diffCharms compares the charm currently used by a service with the
+// charm it would be upgraded to. liveRelations holds the names of the
+// service's endpoints that currently take part in relations.
+func diffCharms(oldCharm, newCharm *charmContents, liveRelations map[string]bool) *charmDiff {
+	diff := &charmDiff{}
+	oldMeta, newMeta := oldCharm.meta, newCharm.meta
+	if oldMeta.Name != newMeta.Name {
+		diff.change("~", "name: %s -> %s", oldMeta.Name, newMeta.Name)
+	}
+	if oldMeta.Subordinate != newMeta.Subordinate {
+		diff.change("~", "subordinate: %v -> %v", oldMeta.Subordinate, newMeta.Subordinate)
+		diff.incompatible("cannot change a service's subordinacy")
+	}
+	diffRelations(diff, oldMeta, newMeta, liveRelations)
+	diffConfig(diff, oldCharm.config, newCharm.config)
+	diffHooks(diff, oldCharm.hooks, newCharm.hooks)
+	return diff
+}
+
+func diffRelations(diff *charmDiff, oldMeta, newMeta *charm.Meta, liveRelations map[string]bool) {
+	roles := []struct {
+		name             string
+		oldRels, newRels map[string]charm.Relation
+	}{
+		{"provides", oldMeta.Provides, newMeta.Provides},
+		{"requires", oldMeta.Requires, newMeta.Requires},
+		{"peers", oldMeta.Peers, newMeta.Peers},
+	}
+	for _, role := range roles {
+		for _, name := range relationNames(role.oldRels, role.newRels) {
+			oldRel, inOld := role.oldRels[name]
+			newRel, inNew := role.newRels[name]
+			switch {
+			case !inOld:
+				diff.change("+", "%s relation %q (interface %s)", role.name, name, newRel.Interface)
+			case !inNew:
+				diff.change("-", "%s relation %q (interface %s)", role.name, name, oldRel.Interface)
+				if liveRelations[name] {
+					diff.incompatible("relation %q is in use but is no longer among the new charm's %s", name, role.name)
+				}
+			case oldRel.Interface != newRel.Interface:
+				diff.change("~", "%s relation %q: interface %s -> %s", role.name, name, oldRel.Interface, newRel.Interface)
+				if liveRelations[name] {
+					diff.incompatible("relation %q is in use but changes interface", name)
+				}
+			case oldRel.Scope != newRel.Scope:
+				diff.change("~", "%s relation %q: scope %s -> %s", role.name, name, oldRel.Scope, newRel.Scope)
+			}
+		}
+	}
+}
+
+// relationNames returns the sorted names of the relations in either
+// of the given maps.
+func relationNames(oldRels, newRels map[string]charm.Relation) []string {
+	var names []string
+	for name := range oldRels {
+		names = append(names, name)
+	}
+	for name := range newRels {
+		if _, ok := oldRels[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffConfig(diff *charmDiff, oldConfig, newConfig *charm.Config) {
+	oldOptions := make(map[string]charm.Option)
+	newOptions := make(map[string]charm.Option)
+	if oldConfig != nil {
+		oldOptions = oldConfig.Options
+	}
+	if newConfig != nil {
+		newOptions = newConfig.Options
+	}
+	var names []string
+	for name := range oldOptions {
+		names = append(names, name)
+	}
+	for name := range newOptions {
+		if _, ok := oldOptions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldOption, inOld := oldOptions[name]
+		newOption, inNew := newOptions[name]
+		switch {
+		case !inOld:
+			diff.change("+", "config option %q (%s)", name, newOption.Type)
+		case !inNew:
+			diff.change("-", "config option %q (%s)", name, oldOption.Type)
+		case oldOption.Type != newOption.Type:
+			diff.change("~", "config option %q: type %s -> %s", name, oldOption.Type, newOption.Type)
+			diff.incompatible("config option %q changes type from %s to %s", name, oldOption.Type, newOption.Type)
+		case !reflect.DeepEqual(oldOption.Default, newOption.Default):
+			diff.change("~", "config option %q: default %v -> %v", name, oldOption.Default, newOption.Default)
+		}
+	}
+}
+
+func diffHooks(diff *charmDiff, oldHooks, newHooks map[string]string) {
+	var names []string
+	for name := range oldHooks {
+		names = append(names, name)
+	}
+	for name := range newHooks {
+		if _, ok := oldHooks[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldHash, inOld := oldHooks[name]
+		newHash, inNew := newHooks[name]
+		switch {
+		case !inOld:
+			diff.change("+", "hook %s", name)
+		case !inNew:
+			diff.change("-", "hook %s", name)
+		case oldHash != newHash:
+			diff.change("~", "hook %s", name)
+		}
+	}
+}
+
+// printDiff writes diff to ctx, and returns an error if the upgrade
+// is incompatible.
+func printDiff(ctx *cmd.Context, oldURL, newURL *charm.URL, diff *charmDiff) error {
+	fmt.Fprintf(ctx.Stdout, "%s -> %s\n", oldURL, newURL)
+	if len(diff.Changes) == 0 {
+		fmt.Fprintf(ctx.Stdout, "no changes\n")
+	}
+	for _, change := range diff.Changes {
+		fmt.Fprintf(ctx.Stdout, "%s\n", change)
+	}
+	for _, problem := range diff.Incompatibilities {
+		fmt.Fprintf(ctx.Stdout, "incompatible: %s\n", problem)
+	}
+	if n := len(diff.Incompatibilities); n > 0 {
+		return fmt.Errorf("%d incompatible change(s) found", n)
+	}
+	return nil
+}
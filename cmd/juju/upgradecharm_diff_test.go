@@ -0,0 +1,125 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/charm"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+)
+
+type CharmDiffSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&CharmDiffSuite{})
+
+func newCharmContents() *charmContents {
+	return &charmContents{
+		meta: &charm.Meta{
+			Name: "mysql",
+			Provides: map[string]charm.Relation{
+				"db":     {Name: "db", Role: charm.RoleProvider, Interface: "mysql", Scope: charm.ScopeGlobal},
+				"shared": {Name: "shared", Role: charm.RoleProvider, Interface: "mysql-shared", Scope: charm.ScopeGlobal},
+			},
+			Peers: map[string]charm.Relation{
+				"cluster": {Name: "cluster", Role: charm.RolePeer, Interface: "mysql-ha", Scope: charm.ScopeGlobal},
+			},
+		},
+		config: &charm.Config{
+			Options: map[string]charm.Option{
+				"port":  {Type: "int", Default: 3306},
+				"mode":  {Type: "string", Default: "fast"},
+				"debug": {Type: "boolean"},
+			},
+		},
+		hooks: map[string]string{
+			"install": "aaa",
+			"start":   "bbb",
+			"stop":    "ccc",
+		},
+	}
+}
+
+func (s *CharmDiffSuite) TestNoChanges(c *gc.C) {
+	diff := diffCharms(newCharmContents(), newCharmContents(), nil)
+	c.Assert(diff.Changes, gc.HasLen, 0)
+	c.Assert(diff.Incompatibilities, gc.HasLen, 0)
+}
+
+func (s *CharmDiffSuite) TestChanges(c *gc.C) {
+	oldCharm := newCharmContents()
+	newCharm := newCharmContents()
+	delete(newCharm.meta.Provides, "shared")
+	newCharm.meta.Provides["db"] = charm.Relation{Name: "db", Role: charm.RoleProvider, Interface: "mysql-root", Scope: charm.ScopeGlobal}
+	newCharm.meta.Requires = map[string]charm.Relation{
+		"logging": {Name: "logging", Role: charm.RoleRequirer, Interface: "syslog", Scope: charm.ScopeContainer},
+	}
+	newCharm.config.Options = map[string]charm.Option{
+		"port":    {Type: "string", Default: "3306"},
+		"mode":    {Type: "string", Default: "safe"},
+		"datadir": {Type: "string"},
+	}
+	newCharm.hooks = map[string]string{
+		"install":       "aaa",
+		"start":         "ddd",
+		"upgrade-charm": "eee",
+	}
+
+	diff := diffCharms(oldCharm, newCharm, nil)
+	c.Assert(diff.Changes, gc.DeepEquals, []string{
+		`~ provides relation "db": interface mysql -> mysql-root`,
+		`- provides relation "shared" (interface mysql-shared)`,
+		`+ requires relation "logging" (interface syslog)`,
+		`+ config option "datadir" (string)`,
+		`- config option "debug" (boolean)`,
+		`~ config option "mode": default fast -> safe`,
+		`~ config option "port": type int -> string`,
+		`~ hook start`,
+		`- hook stop`,
+		`+ hook upgrade-charm`,
+	})
+	c.Assert(diff.Incompatibilities, gc.DeepEquals, []string{
+		`config option "port" changes type from int to string`,
+	})
+}
+
+func (s *CharmDiffSuite) TestLiveRelations(c *gc.C) {
+	oldCharm := newCharmContents()
+	newCharm := newCharmContents()
+	newCharm.meta.Provides = map[string]charm.Relation{
+		"db": {Name: "db", Role: charm.RoleProvider, Interface: "mysql-root", Scope: charm.ScopeGlobal},
+	}
+	newCharm.meta.Peers = nil
+	live := map[string]bool{"db": true, "cluster": true}
+
+	diff := diffCharms(oldCharm, newCharm, live)
+	c.Assert(diff.Incompatibilities, gc.DeepEquals, []string{
+		`relation "db" is in use but changes interface`,
+		`relation "cluster" is in use but is no longer among the new charm's peers`,
+	})
+}
+
+func (s *CharmDiffSuite) TestSubordinate(c *gc.C) {
+	newCharm := newCharmContents()
+	newCharm.meta.Subordinate = true
+	diff := diffCharms(newCharmContents(), newCharm, nil)
+	c.Assert(diff.Changes, gc.DeepEquals, []string{"~ subordinate: false -> true"})
+	c.Assert(diff.Incompatibilities, gc.DeepEquals, []string{"cannot change a service's subordinacy"})
+}
+
+func (s *CharmDiffSuite) TestHookName(c *gc.C) {
+	for path, expect := range map[string]string{
+		"hooks/install":       "install",
+		"./hooks/start":       "start",
+		"hooks/lib/common.sh": "",
+		"hooks":               "",
+		"metadata.yaml":       "",
+	} {
+		name, ok := hookName(path)
+		c.Check(ok, gc.Equals, expect != "", gc.Commentf("path %q", path))
+		c.Check(name, gc.Equals, expect, gc.Commentf("path %q", path))
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/juju/charm"
 	charmtesting "github.com/juju/charm/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/cmd/envcmd"
@@ -217,3 +218,41 @@ func (s *UpgradeCharmSuccessSuite) TestSwitch(c *gc.C) {
 	c.Assert(curl.String(), gc.Equals, "local:precise/myriak-42")
 	s.assertLocalRevision(c, 42, myriakPath)
 }
+
+func (s *UpgradeCharmSuccessSuite) TestDiffNoChanges(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&UpgradeCharmCommand{}), "riak", "--diff")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "local:precise/riak-7 -> local:precise/riak-7\nno changes\n")
+	s.assertUpgraded(c, 7, false)
+}
+
+var riakNoPeersMeta = []byte(`
+name: riak
+summary: "K/V storage engine"
+description: "Scalable K/V Store in Erlang with Clocks :-)"
+provides:
+  endpoint:
+    interface: http
+`)
+
+func (s *UpgradeCharmSuccessSuite) TestDiff(c *gc.C) {
+	err := ioutil.WriteFile(path.Join(s.path, "hooks", "extra"), []byte("#!/bin/sh\n"), 0755)
+	c.Assert(err, gc.IsNil)
+	err = runUpgradeCharm(c, "riak", "--diff")
+	c.Assert(err, gc.IsNil)
+
+	// Dropping the peer relation breaks the live "ring" relation.
+	err = ioutil.WriteFile(path.Join(s.path, "metadata.yaml"), riakNoPeersMeta, 0644)
+	c.Assert(err, gc.IsNil)
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&UpgradeCharmCommand{}), "riak", "--diff")
+	c.Assert(err, gc.ErrorMatches, `1 incompatible change\(s\) found`)
+	output := testing.Stdout(ctx)
+	c.Assert(output, jc.HasPrefix, "local:precise/riak-7 -> local:precise/riak-7\n")
+	c.Assert(output, jc.Contains, "- provides relation \"admin\" (interface http)\n")
+	c.Assert(output, jc.Contains, "- peers relation \"ring\" (interface riak)\n")
+	c.Assert(output, jc.Contains, "+ hook extra\n")
+	c.Assert(output, jc.Contains, "incompatible: relation \"ring\" is in use but is no longer among the new charm's peers\n")
+
+	// The service has not been upgraded.
+	s.assertUpgraded(c, 7, false)
+}
@@ -216,8 +216,11 @@ func openAPIState(agentConfig agent.Config, a Agent) (_ *api.State, _ *apiagent.
 	// keep on retrying. If we block for ages here,
 	// then the worker that's calling this cannot
 	// be interrupted.
+	// Agents use the BSON codec, which is cheaper for the API
+	// server to marshal than JSON.
+	dialOpts := api.DialOpts{PreferBSON: true}
 	info := agentConfig.APIInfo()
-	st, err := apiOpen(info, api.DialOpts{})
+	st, err := apiOpen(info, dialOpts)
 	usedOldPassword := false
 	if params.IsCodeUnauthorized(err) {
 		// We've perhaps used the wrong password, so
@@ -225,7 +228,7 @@ func openAPIState(agentConfig agent.Config, a Agent) (_ *api.State, _ *apiagent.
 		info := *info
 		info.Password = agentConfig.OldPassword()
 		usedOldPassword = true
-		st, err = apiOpen(&info, api.DialOpts{})
+		st, err = apiOpen(&info, dialOpts)
 	}
 	if err != nil {
 		if params.IsCodeNotProvisioned(err) {
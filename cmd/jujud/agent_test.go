@@ -144,6 +144,21 @@ func (s *apiOpenSuite) TestOpenAPIStateReplaceErrors(c *gc.C) {
 	}
 }
 
+func (s *apiOpenSuite) TestOpenAPIStatePrefersBSON(c *gc.C) {
+	var dialOpts []api.DialOpts
+	s.PatchValue(&apiOpen, func(info *api.Info, opts api.DialOpts) (*api.State, error) {
+		dialOpts = append(dialOpts, opts)
+		return nil, &params.Error{Code: params.CodeUnauthorized}
+	})
+	_, _, err := openAPIState(fakeAPIOpenConfig{}, nil)
+	c.Assert(err, gc.Equals, worker.ErrTerminateAgent)
+	// Both the current and the old password are tried.
+	c.Assert(dialOpts, gc.HasLen, 2)
+	for _, opts := range dialOpts {
+		c.Check(opts.PreferBSON, jc.IsTrue)
+	}
+}
+
 type testPinger func() error
 
 func (f testPinger) Ping() error {
@@ -27,23 +27,25 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/container/kvm"
+	"github.com/juju/juju/container/nspawn"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/paths"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	apiagent "github.com/juju/juju/state/api/agent"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver"
 	"github.com/juju/juju/upgrades"
-	"github.com/juju/juju/upstart"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/autoscaler"
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
 	"github.com/juju/juju/worker/deployer"
@@ -269,9 +271,13 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 	rsyslogMode := rsyslog.RsyslogModeForwarding
 	runner := newRunner(connectionIsFatal(st), moreImportant)
 	var singularRunner worker.Runner
+	var upgradeSchedule *worker.MaintenanceScheduler
 	for _, job := range entity.Jobs() {
 		if job == params.JobManageEnviron {
 			rsyslogMode = rsyslog.RsyslogModeAccumulate
+			// Upgrades start with the state servers, so only
+			// they wait for a maintenance window.
+			upgradeSchedule = worker.NewMaintenanceScheduler(st.Environment())
 			conn := singularAPIConn{st, st.Agent()}
 			singularRunner, err = newSingularRunner(runner, conn)
 			if err != nil {
@@ -284,7 +290,7 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 	// Run the upgrader and the upgrade-steps worker without waiting for
 	// the upgrade steps to complete.
 	runner.StartWorker("upgrader", func() (worker.Worker, error) {
-		return upgrader.NewUpgrader(st.Upgrader(), agentConfig), nil
+		return upgrader.NewUpgrader(st.Upgrader(), agentConfig, upgradeSchedule), nil
 	})
 	runner.StartWorker("upgrade-steps", func() (worker.Worker, error) {
 		return a.upgradeWorker(st, entity.Jobs(), agentConfig), nil
@@ -348,7 +354,8 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 				return firewaller.NewFirewaller(st.Firewaller())
 			})
 			a.startWorkerAfterUpgrade(singularRunner, "charm-revision-updater", func() (worker.Worker, error) {
-				return charmrevisionworker.NewRevisionUpdateWorker(st.CharmRevisionUpdater()), nil
+				schedule := worker.NewMaintenanceScheduler(st.Environment())
+				return charmrevisionworker.NewRevisionUpdateWorker(st.CharmRevisionUpdater(), schedule), nil
 			})
 		case params.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
@@ -375,6 +382,13 @@ func (a *MachineAgent) setupContainerSupport(runner worker.Runner, st *api.State
 	if err == nil && supportsKvm {
 		supportedContainers = append(supportedContainers, instance.KVM)
 	}
+	supportsNspawn, err := nspawn.IsNspawnSupported()
+	if err != nil {
+		logger.Infof("determining nspawn support: %v\nno nspawn containers possible", err)
+	}
+	if err == nil && supportsNspawn {
+		supportedContainers = append(supportedContainers, instance.NSPAWN)
+	}
 	return a.updateSupportedContainers(runner, st, entity.Tag(), supportedContainers, agentConfig)
 }
 
@@ -504,11 +518,12 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 					return nil, err
 				}
 				return apiserver.NewServer(st, listener, apiserver.ServerConfig{
-					Cert:      cert,
-					Key:       key,
-					DataDir:   dataDir,
-					LogDir:    logDir,
-					Validator: a.limitLoginsDuringUpgrade,
+					Cert:       cert,
+					Key:        key,
+					DataDir:    dataDir,
+					LogDir:     logDir,
+					Validator:  a.limitLoginsDuringUpgrade,
+					RateLimits: apiRateLimits(agentConfig),
 				})
 			})
 			a.startWorkerAfterUpgrade(singularRunner, "cleaner", func() (worker.Worker, error) {
@@ -523,6 +538,9 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(singularRunner, "minunitsworker", func() (worker.Worker, error) {
 				return minunitsworker.NewMinUnitsWorker(st), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "autoscaler", func() (worker.Worker, error) {
+				return autoscaler.NewAutoscaler(st), nil
+			})
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -558,6 +576,47 @@ func (a *MachineAgent) limitLoginsDuringUpgrade(creds params.Creds) error {
 	}
 }
 
+// apiRateLimits returns the API server's per-entity limits as held
+// in the agent configuration. Invalid values are logged and ignored.
+func apiRateLimits(agentConfig agent.Config) apiserver.RateLimitConfig {
+	parseFloat := func(key string) float64 {
+		s := agentConfig.Value(key)
+		if s == "" {
+			return 0
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			logger.Warningf("ignoring invalid %s value %q", key, s)
+			return 0
+		}
+		return v
+	}
+	parseInt := func(key string) int64 {
+		s := agentConfig.Value(key)
+		if s == "" {
+			return 0
+		}
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			logger.Warningf("ignoring invalid %s value %q", key, s)
+			return 0
+		}
+		return v
+	}
+	return apiserver.RateLimitConfig{
+		Users: apiserver.EntityLimits{
+			RequestRate:    parseFloat(agent.APIUserRequestRate),
+			RequestBurst:   parseInt(agent.APIUserRequestBurst),
+			MaxConnections: int(parseInt(agent.APIUserMaxConnections)),
+		},
+		Agents: apiserver.EntityLimits{
+			RequestRate:    parseFloat(agent.APIAgentRequestRate),
+			RequestBurst:   parseInt(agent.APIAgentRequestBurst),
+			MaxConnections: int(parseInt(agent.APIAgentMaxConnections)),
+		},
+	}
+}
+
 // ensureMongoServer ensures that mongo is installed and running,
 // and ready for opening a state connection.
 func (a *MachineAgent) ensureMongoServer(agentConfig agent.Config) (err error) {
@@ -970,7 +1029,11 @@ func (a *MachineAgent) uninstallAgent(agentConfig agent.Config) error {
 		agentServiceName = os.Getenv("UPSTART_JOB")
 	}
 	if agentServiceName != "" {
-		if err := upstart.NewService(agentServiceName).Remove(); err != nil {
+		svc, err := service.New(service.DetectInitSystem(), agentServiceName, service.Conf{})
+		if err == nil {
+			err = svc.Remove()
+		}
+		if err != nil {
 			errors = append(errors, fmt.Errorf("cannot remove service %q: %v", agentServiceName, err))
 		}
 	}
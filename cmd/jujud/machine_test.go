@@ -33,6 +33,7 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	apideployer "github.com/juju/juju/state/api/deployer"
@@ -90,6 +91,7 @@ func (s *commonMachineSuite) SetUpTest(c *gc.C) {
 	fakeCmd(filepath.Join(testpath, "stop"))
 
 	s.agentSuite.PatchValue(&upstart.InitDir, c.MkDir())
+	s.agentSuite.PatchValue(&systemd.InitDir, c.MkDir())
 
 	s.singularRecord = &singularRunnerRecord{}
 	s.agentSuite.PatchValue(&newSingularRunner, s.singularRecord.newSingularRunner)
@@ -397,6 +399,7 @@ func (s *MachineSuite) TestManageEnviron(c *gc.C) {
 	}
 
 	c.Assert(s.singularRecord.started(), jc.DeepEquals, []string{
+		"autoscaler",
 		"charm-revision-updater",
 		"cleaner",
 		"environ-provisioner",
@@ -0,0 +1,154 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
+)
+
+var buildMirrorDoc = `
+build-mirror creates a self-contained mirror of juju tools and image metadata
+for use by environments without access to the public sources, such as those
+on air-gapped networks.
+
+The tools tarballs matching the given versions, series and architectures are
+downloaded from the tools source and checked against the sizes and hashes in
+the source metadata. Image metadata is copied from the images source for each
+of the clouds given with --clouds, as comma separated region=endpoint pairs;
+if no clouds are given, no image metadata is mirrored.
+
+The simplestreams metadata written for the mirror is signed using the private
+key in the specified keyring file, as for the sign command. The resulting
+directory may be served over plain HTTP and used as the tools-metadata-url and
+image-metadata-url of the environments.
+
+Examples:
+
+   juju metadata build-mirror -d /srv/mirror -k key.asc --versions 1.20 \
+       --series precise,trusty --arches amd64 \
+       --clouds RegionOne=https://keystone.example.com:5000/v2.0
+`
+
+// BuildMirrorCommand is used to build a mirror of tools and image metadata.
+type BuildMirrorCommand struct {
+	cmd.CommandBase
+	dir          string
+	keyFile      string
+	passphrase   string
+	toolsSource  string
+	imagesSource string
+	stream       string
+	versions     string
+	series       string
+	arches       string
+	clouds       string
+}
+
+func (c *BuildMirrorCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "build-mirror",
+		Purpose: "build a mirror of tools and image metadata",
+		Doc:     buildMirrorDoc,
+	}
+}
+
+func (c *BuildMirrorCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.CommandBase.SetFlags(f)
+	f.StringVar(&c.dir, "d", "", "directory in which to build the mirror")
+	f.StringVar(&c.keyFile, "k", "", "file containing the amored private signing key")
+	f.StringVar(&c.passphrase, "p", "", "passphrase used to decrypt the private key")
+	f.StringVar(&c.toolsSource, "tools-source", envtools.DefaultBaseURL, "URL or directory from which to mirror tools")
+	f.StringVar(&c.imagesSource, "images-source", imagemetadata.DefaultBaseURL, "URL or directory from which to mirror image metadata")
+	f.StringVar(&c.stream, "stream", imagemetadata.ReleasedStream, "the image stream to mirror")
+	f.StringVar(&c.versions, "versions", "", "comma separated tools versions to mirror, such as 1.20 or 1.20.1")
+	f.StringVar(&c.series, "series", "", "comma separated series to mirror")
+	f.StringVar(&c.arches, "arches", "", "comma separated architectures to mirror")
+	f.StringVar(&c.clouds, "clouds", "", "comma separated region=endpoint pairs for which to mirror image metadata")
+}
+
+func (c *BuildMirrorCommand) Init(args []string) error {
+	if c.dir == "" {
+		return fmt.Errorf("directory must be specified")
+	}
+	if c.keyFile == "" {
+		return fmt.Errorf("keyfile must be specified")
+	}
+	if _, err := parseClouds(c.clouds); err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// splitList returns the elements of a comma separated list.
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseClouds parses a comma separated list of region=endpoint pairs.
+func parseClouds(clouds string) ([]simplestreams.CloudSpec, error) {
+	var specs []simplestreams.CloudSpec
+	for _, cloud := range splitList(clouds) {
+		parts := strings.SplitN(cloud, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid cloud %q, expected region=endpoint", cloud)
+		}
+		specs = append(specs, simplestreams.CloudSpec{Region: parts[0], Endpoint: parts[1]})
+	}
+	return specs, nil
+}
+
+func (c *BuildMirrorCommand) Run(context *cmd.Context) error {
+	loggo.RegisterWriter("buildmirror", cmd.NewCommandLogWriter("juju.environs.sync", context.Stdout, context.Stderr), loggo.INFO)
+	defer loggo.RemoveWriter("buildmirror")
+	keyData, err := ioutil.ReadFile(context.AbsPath(c.keyFile))
+	if err != nil {
+		return err
+	}
+	toolsURL, err := envtools.ToolsURL(c.toolsSource)
+	if err != nil {
+		return err
+	}
+	imagesURL, err := imagemetadata.ImageMetadataURL(c.imagesSource, c.stream)
+	if err != nil {
+		return err
+	}
+	clouds, _ := parseClouds(c.clouds)
+	params := sync.MirrorParams{
+		Dir: context.AbsPath(c.dir),
+		ToolsSources: []simplestreams.DataSource{
+			simplestreams.NewURLDataSource("tools source", toolsURL, utils.VerifySSLHostnames),
+		},
+		ImageSources: []simplestreams.DataSource{
+			simplestreams.NewURLDataSource("images source", imagesURL, utils.VerifySSLHostnames),
+		},
+		Versions: splitList(c.versions),
+		Series:   splitList(c.series),
+		Arches:   splitList(c.arches),
+		Clouds:   clouds,
+		Stream:   c.stream,
+	}
+	fmt.Fprintf(context.Stdout, "Building mirror in %s\n", params.Dir)
+	if err := sync.BuildMirror(params); err != nil {
+		return err
+	}
+	return process(params.Dir, string(keyData), c.passphrase)
+}
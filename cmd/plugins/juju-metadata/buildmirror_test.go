@@ -0,0 +1,81 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type BuildMirrorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&BuildMirrorSuite{})
+
+func runBuildMirror(c *gc.C, args ...string) error {
+	_, err := coretesting.RunCommand(c, &BuildMirrorCommand{}, args...)
+	return err
+}
+
+func (s *BuildMirrorSuite) TestBuildMirrorErrors(c *gc.C) {
+	err := runBuildMirror(c)
+	c.Assert(err, gc.ErrorMatches, `directory must be specified`)
+	err = runBuildMirror(c, "-d", "foo")
+	c.Assert(err, gc.ErrorMatches, `keyfile must be specified`)
+	err = runBuildMirror(c, "-d", "foo", "-k", "key", "--clouds", "region-1")
+	c.Assert(err, gc.ErrorMatches, `invalid cloud "region-1", expected region=endpoint`)
+	err = runBuildMirror(c, "-d", "foo", "-k", "key", "bar")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["bar"\]`)
+}
+
+func (s *BuildMirrorSuite) TestBuildMirror(c *gc.C) {
+	sourceDir := c.MkDir()
+	toolstesting.MakeToolsWithCheckSum(c, sourceDir, "releases", []string{
+		"1.20.0-precise-amd64",
+		"1.20.0-trusty-amd64",
+	})
+	stor, err := filestorage.NewFileStorageWriter(sourceDir)
+	c.Assert(err, gc.IsNil)
+	cloud := simplestreams.CloudSpec{Region: "region-1", Endpoint: "https://endpoint-1"}
+	err = imagemetadata.MergeAndWriteMetadata("trusty", []*imagemetadata.ImageMetadata{{
+		Id:   "image-1",
+		Arch: "amd64",
+	}}, &cloud, stor)
+	c.Assert(err, gc.IsNil)
+	keyfile := filepath.Join(c.MkDir(), "privatekey.asc")
+	err = ioutil.WriteFile(keyfile, []byte(sstesting.SignedMetadataPrivateKey), 0644)
+	c.Assert(err, gc.IsNil)
+
+	mirrorDir := c.MkDir()
+	err = runBuildMirror(c,
+		"-d", mirrorDir, "-k", keyfile, "-p", sstesting.PrivateKeyPassphrase,
+		"--tools-source", sourceDir, "--images-source", sourceDir,
+		"--series", "trusty", "--clouds", "region-1=https://endpoint-1",
+	)
+	c.Assert(err, gc.IsNil)
+
+	for _, path := range []string{
+		"tools/releases/juju-1.20.0-trusty-amd64.tgz",
+		"tools/streams/v1/index.sjson",
+		"tools/streams/v1/com.ubuntu.juju:released:tools.sjson",
+		"images/streams/v1/index.sjson",
+		"images/streams/v1/com.ubuntu.cloud:released:imagemetadata.sjson",
+	} {
+		_, err := os.Stat(filepath.Join(mirrorDir, path))
+		c.Check(err, gc.IsNil)
+	}
+	_, err = os.Stat(filepath.Join(mirrorDir, "tools/releases/juju-1.20.0-precise-amd64.tgz"))
+	c.Check(os.IsNotExist(err), gc.Equals, true)
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/environs/simplestreams"
+)
+
+var diffDoc = `
+diff compares two simplestreams metadata trees product by product, and prints
+the products, versions and items added to or removed from the new tree, and
+the attributes of items that changed. Each tree is given as a local directory
+or a URL, and is the directory containing streams/v1/index.json.
+
+Added entries are prefixed with "+", removed ones with "-" and changed ones
+with "~". Signatures are not checked; use the lint command for that.
+
+Examples:
+
+   juju metadata diff http://mirror.example.com/tools ~/.juju/.tools
+`
+
+// DiffCommand is used to compare two simplestreams metadata trees.
+type DiffCommand struct {
+	cmd.CommandBase
+	oldLocation string
+	newLocation string
+}
+
+func (c *DiffCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "diff",
+		Args:    "<old> <new>",
+		Purpose: "compare two simplestreams metadata trees",
+		Doc:     diffDoc,
+	}
+}
+
+func (c *DiffCommand) Init(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("two metadata trees must be specified")
+	}
+	c.oldLocation, c.newLocation = args[0], args[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *DiffCommand) Run(context *cmd.Context) error {
+	diffs, err := simplestreams.Diff(
+		metadataSource(context, c.oldLocation), metadataSource(context, c.newLocation))
+	if err != nil {
+		return err
+	}
+	for _, diff := range diffs {
+		fmt.Fprintln(context.Stdout, diff)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type DiffSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&DiffSuite{})
+
+func (s *DiffSuite) TestDiffErrors(c *gc.C) {
+	_, err := coretesting.RunCommand(c, &DiffCommand{}, "foo")
+	c.Assert(err, gc.ErrorMatches, `two metadata trees must be specified`)
+	_, err = coretesting.RunCommand(c, &DiffCommand{}, "foo", "bar", "baz")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["baz"\]`)
+}
+
+func (s *DiffSuite) TestDiff(c *gc.C) {
+	oldDir := c.MkDir()
+	toolstesting.MakeToolsWithCheckSum(c, oldDir, "releases", []string{
+		"1.20.0-precise-amd64",
+		"1.20.0-trusty-amd64",
+	})
+	newDir := c.MkDir()
+	toolstesting.MakeToolsWithCheckSum(c, newDir, "releases", []string{
+		"1.20.0-trusty-amd64",
+		"1.20.1-trusty-amd64",
+	})
+	ctx, err := coretesting.RunCommand(c, &DiffCommand{},
+		filepath.Join(oldDir, "tools"), filepath.Join(newDir, "tools"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Matches, `(?s)`+
+		`- com.ubuntu.juju:12.04:amd64\n`+
+		`\+ com.ubuntu.juju:14.04:amd64 \d+ 1.20.1-trusty-amd64\n`)
+}
@@ -0,0 +1,101 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/utils"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/simplestreams"
+)
+
+var lintDoc = `
+lint checks an entire simplestreams metadata tree, such as one written by
+generate-tools, generate-image or build-mirror, and reports:
+
+ - missing, malformed or wrongly formatted index and products files
+ - index entries that reference missing files or products
+ - files referenced by products whose size or sha256 hash does not match
+ - signed files whose signature cannot be verified with the public key
+   given by --public-key
+ - with --require-signed, metadata files without a signed version
+
+The tree is given with -d as a local directory or a URL, and is the directory
+containing streams/v1/index.json. Checking hashes means reading every
+referenced file; use --skip-hashes to check only the metadata.
+
+Examples:
+
+   juju metadata lint -d ~/.juju/.tools --require-signed --public-key key.pub
+   juju metadata lint -d http://mirror.example.com/tools --skip-hashes
+`
+
+// LintCommand is used to check a simplestreams metadata tree.
+type LintCommand struct {
+	cmd.CommandBase
+	location      string
+	publicKeyFile string
+	requireSigned bool
+	skipHashes    bool
+}
+
+func (c *LintCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "lint",
+		Purpose: "check a simplestreams metadata tree for problems",
+		Doc:     lintDoc,
+	}
+}
+
+func (c *LintCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.CommandBase.SetFlags(f)
+	f.StringVar(&c.location, "d", "", "directory or URL of the metadata tree")
+	f.StringVar(&c.publicKeyFile, "public-key", "", "file containing the armored public key used to check signatures")
+	f.BoolVar(&c.requireSigned, "require-signed", false, "report metadata files without a signed version")
+	f.BoolVar(&c.skipHashes, "skip-hashes", false, "do not check the sizes and hashes of referenced files")
+}
+
+func (c *LintCommand) Init(args []string) error {
+	if c.location == "" {
+		return fmt.Errorf("directory or URL must be specified")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// metadataSource returns a data source for the metadata tree at
+// location, which is either a URL or a local directory.
+func metadataSource(context *cmd.Context, location string) simplestreams.DataSource {
+	url := location
+	if !strings.Contains(location, "://") {
+		url = "file://" + context.AbsPath(location)
+	}
+	return simplestreams.NewURLDataSource(location, url, utils.VerifySSLHostnames)
+}
+
+func (c *LintCommand) Run(context *cmd.Context) error {
+	params := simplestreams.LintParams{
+		RequireSigned: c.requireSigned,
+		SkipHashes:    c.skipHashes,
+	}
+	if c.publicKeyFile != "" {
+		keyData, err := ioutil.ReadFile(context.AbsPath(c.publicKeyFile))
+		if err != nil {
+			return err
+		}
+		params.PublicKey = string(keyData)
+	}
+	problems := simplestreams.Lint(metadataSource(context, c.location), params)
+	for _, problem := range problems {
+		fmt.Fprintln(context.Stdout, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type LintSuite struct {
+	coretesting.BaseSuite
+	metadataDir string
+}
+
+var _ = gc.Suite(&LintSuite{})
+
+func (s *LintSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.metadataDir = c.MkDir()
+	toolstesting.MakeToolsWithCheckSum(c, s.metadataDir, "releases", []string{
+		"1.20.0-precise-amd64",
+		"1.20.0-trusty-amd64",
+	})
+}
+
+func (s *LintSuite) TestLintErrors(c *gc.C) {
+	_, err := coretesting.RunCommand(c, &LintCommand{})
+	c.Assert(err, gc.ErrorMatches, `directory or URL must be specified`)
+	_, err = coretesting.RunCommand(c, &LintCommand{}, "-d", "foo", "bar")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["bar"\]`)
+}
+
+func (s *LintSuite) TestLintClean(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, &LintCommand{}, "-d", filepath.Join(s.metadataDir, "tools"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "")
+}
+
+func (s *LintSuite) TestLintProblems(c *gc.C) {
+	path := filepath.Join(s.metadataDir, "tools", "releases", "juju-1.20.0-trusty-amd64.tgz")
+	err := ioutil.WriteFile(path, []byte("1.20.0-trusty-amd6x"), 0644)
+	c.Assert(err, gc.IsNil)
+	ctx, err := coretesting.RunCommand(c, &LintCommand{}, "-d", filepath.Join(s.metadataDir, "tools"), "--require-signed")
+	c.Assert(err, gc.ErrorMatches, `3 problem\(s\) found`)
+	c.Assert(coretesting.Stdout(ctx), gc.Matches, `(?s)`+
+		`streams/v1/com.ubuntu.juju:released:tools.json: no signed version found\n`+
+		`streams/v1/com.ubuntu.juju:released:tools.json: item .*: sha256 mismatch for "releases/juju-1.20.0-trusty-amd64.tgz", .*\n`+
+		`streams/v1/index.json: no signed version found\n`)
+
+	_, err = coretesting.RunCommand(c, &LintCommand{}, "-d", filepath.Join(s.metadataDir, "tools"), "--skip-hashes")
+	c.Assert(err, gc.IsNil)
+}
@@ -47,6 +47,9 @@ func Main(args []string) {
 	metadatacmd.Register(envcmd.Wrap(&ToolsMetadataCommand{}))
 	metadatacmd.Register(envcmd.Wrap(&ValidateToolsMetadataCommand{}))
 	metadatacmd.Register(&SignMetadataCommand{})
+	metadatacmd.Register(&BuildMirrorCommand{})
+	metadatacmd.Register(&LintCommand{})
+	metadatacmd.Register(&DiffCommand{})
 
 	os.Exit(cmd.Main(metadatacmd, ctx, args[1:]))
 }
@@ -28,9 +28,12 @@ type MetadataSuite struct {
 var _ = gc.Suite(&MetadataSuite{})
 
 var metadataCommandNames = []string{
+	"build-mirror",
+	"diff",
 	"generate-image",
 	"generate-tools",
 	"help",
+	"lint",
 	"sign",
 	"validate-images",
 	"validate-tools",
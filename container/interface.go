@@ -36,6 +36,21 @@ type Manager interface {
 	ListContainers() ([]instance.Instance, error)
 }
 
+// Cloner is implemented by container managers that can create a new
+// container by snapshotting and cloning an existing one.
+type Cloner interface {
+	// CloneContainer creates and starts a new container for the
+	// specified machine from a snapshot of the container identified by
+	// instance id. Any juju agents configured in the existing container
+	// are removed from the clone, so that the new machine agent is
+	// configured only from the given machine config.
+	CloneContainer(
+		source instance.Id,
+		machineConfig *cloudinit.MachineConfig,
+		series string,
+		network *NetworkConfig) (instance.Instance, *instance.HardwareCharacteristics, error)
+}
+
 // Initialiser is responsible for performing the steps required to initialise
 // a host machine so it can run containers.
 type Initialiser interface {
@@ -0,0 +1,313 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/golxc"
+
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/instance"
+)
+
+// clonedAgentFiles are the glob patterns, relative to the root filesystem
+// of a cloned container, of the files that configure and start the juju
+// agents of the original container. They are removed from the clone so
+// that only the new machine agent runs in it.
+var clonedAgentFiles = []string{
+	"etc/init/jujud-*.conf",
+	"etc/systemd/system/jujud-*.service",
+	"etc/systemd/system/*.wants/jujud-*.service",
+	"var/lib/juju/agents/*",
+	"var/lib/juju/tools/machine-*",
+	"var/lib/juju/tools/unit-*",
+	"var/lib/juju/" + cloudinit.NonceFile,
+}
+
+// clonedConfigPrefixes are the prefixes of the lines in the config of a
+// cloned container that are added again when the clone is started.
+var clonedConfigPrefixes = []string{
+	"lxc.start.auto",
+	"lxc.mount.entry=",
+	"lxc.cgroup.",
+}
+
+// containerManager implements container.Cloner.
+var _ container.Cloner = (*containerManager)(nil)
+
+// CloneContainer is specified on the container.Cloner interface. The
+// source container is stopped while it is snapshotted, and restarted
+// afterwards if it was running.
+func (manager *containerManager) CloneContainer(
+	source instance.Id,
+	machineConfig *cloudinit.MachineConfig,
+	series string,
+	network *container.NetworkConfig,
+) (instance.Instance, *instance.HardwareCharacteristics, error) {
+	start := time.Now()
+	sourceContainer := LxcObjectFactory.New(string(source))
+	if !sourceContainer.IsConstructed() {
+		return nil, nil, errors.NotFoundf("container %q", source)
+	}
+	name := names.NewMachineTag(machineConfig.MachineId).String()
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	// Check everything that can be checked before the source
+	// container is stopped.
+	if err := manager.checkCloneable(sourceContainer, name); err != nil {
+		return nil, nil, fmt.Errorf("cannot clone container %q: %v", source, err)
+	}
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The packages needed by the agent are already installed in the
+	// source container.
+	machineConfig.DisablePackageCommands = true
+	userDataFilename, err := container.WriteUserData(machineConfig, directory)
+	if err != nil {
+		logger.Errorf("failed to write user data: %v", err)
+		return nil, nil, err
+	}
+	templateParams := []string{
+		"--debug",                      // Debug errors in the cloud image
+		"--userdata", userDataFilename, // Our groovey cloud-init
+		"--hostid", name, // Use the container name as the hostid
+	}
+	lxcContainer, err := snapshotContainer(sourceContainer, name, manager.snapshotArgs(), templateParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := resetClonedContainer(name); err != nil {
+		logger.Errorf("failed to reset cloned container %q: %v", name, err)
+		if err := lxcContainer.Destroy(); err != nil {
+			logger.Errorf("failed to destroy cloned container %q: %v", name, err)
+		}
+		return nil, nil, err
+	}
+	logger.Tracef("container %q cloned from %q", name, source)
+	return manager.startContainer(lxcContainer, directory, machineConfig, start)
+}
+
+// snapshotArgs returns the arguments passed to lxc-clone to snapshot a
+// container. As when containers are created from a template, btrfs
+// hosts use btrfs snapshots, and other hosts use aufs if use-aufs is
+// set, or overlayfs otherwise.
+func (manager *containerManager) snapshotArgs() []string {
+	args := []string{"--snapshot"}
+	if manager.backingFilesystem != Btrfs && manager.useAUFS {
+		args = append(args, "--backingstore", "aufs")
+	}
+	return args
+}
+
+// checkCloneable returns an error if the source container cannot be
+// snapshotted into a new container with the given name.
+func (manager *containerManager) checkCloneable(source golxc.Container, name string) error {
+	if LxcObjectFactory.New(name).IsConstructed() {
+		return fmt.Errorf("container %q already exists", name)
+	}
+	rootfs, err := containerRootfs(source.Name())
+	if err != nil {
+		return err
+	}
+	switch kind, _, _ := splitSnapshotRootfs(rootfs); kind {
+	case "":
+		if !filepath.IsAbs(rootfs) || strings.HasPrefix(rootfs, "/dev/") {
+			return fmt.Errorf("unsupported root filesystem %q", rootfs)
+		}
+	case overlayfsRootfs, aufsRootfs:
+		if manager.backingFilesystem == Btrfs {
+			return fmt.Errorf("cannot take a %s snapshot of %s root filesystem %q", Btrfs, kind, rootfs)
+		}
+	}
+	return nil
+}
+
+// snapshotContainer clones the source container into a new container with
+// the given name, using a snapshot of the source root filesystem.
+func snapshotContainer(source golxc.Container, name string, cloneArgs, templateParams []string) (golxc.Container, error) {
+	lock, err := AcquireTemplateLock(source.Name(), "snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock on container: %v", err)
+	}
+	defer lock.Unlock()
+
+	wasRunning := source.IsRunning()
+	if wasRunning {
+		logger.Infof("stopping container %q to take a snapshot", source.Name())
+		if err := source.Stop(); err != nil {
+			return nil, fmt.Errorf("cannot stop container %q: %v", source.Name(), err)
+		}
+	}
+	clone, cloneErr := source.Clone(name, cloneArgs, templateParams)
+	if cloneErr != nil {
+		logger.Errorf("lxc container cloning failed: %v", cloneErr)
+	}
+	if wasRunning {
+		consoleFile := filepath.Join(container.ContainerDir, source.Name(), "console.log")
+		if err := source.Start("", consoleFile); err != nil {
+			logger.Errorf("failed to restart container %q: %v", source.Name(), err)
+			if cloneErr == nil {
+				cloneErr = fmt.Errorf("cannot restart container %q: %v", source.Name(), err)
+				if err := clone.Destroy(); err != nil {
+					logger.Errorf("failed to destroy cloned container %q: %v", name, err)
+				}
+			}
+		}
+	}
+	if cloneErr != nil {
+		return nil, cloneErr
+	}
+	return clone, nil
+}
+
+// resetClonedContainer removes the settings and agent files copied from
+// the source container, so the clone can be configured as a new machine.
+func resetClonedContainer(name string) error {
+	configFile := containerConfigFilename(name)
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if !hasAnyPrefix(strings.TrimSpace(line), clonedConfigPrefixes) {
+			lines = append(lines, line)
+		}
+	}
+	if err := ioutil.WriteFile(configFile, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return err
+	}
+	rootfs, err := containerRootfs(name)
+	if err != nil {
+		return err
+	}
+	return withRootfs(name, rootfs, removeAgentFiles)
+}
+
+// removeAgentFiles removes the files matching clonedAgentFiles
+// from the given root filesystem.
+func removeAgentFiles(rootfs string) error {
+	for _, pattern := range clonedAgentFiles {
+		matches, err := filepath.Glob(filepath.Join(rootfs, pattern))
+		if err != nil {
+			return err
+		}
+		for _, path := range matches {
+			logger.Tracef("removing %s from cloned container", path)
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// The kinds of snapshot root filesystem that keep the changes made to
+// a container in a directory separate from the original files.
+const (
+	overlayfsRootfs = "overlayfs"
+	aufsRootfs      = "aufs"
+)
+
+// containerRootfs returns the lxc.rootfs setting of the container with
+// the given name, or the default root filesystem directory if it is
+// not set.
+func containerRootfs(name string) (string, error) {
+	data, err := ioutil.ReadFile(containerConfigFilename(name))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "lxc.rootfs" {
+			return strings.TrimSpace(parts[1]), nil
+		}
+	}
+	return filepath.Join(LxcContainerDir, name, "rootfs"), nil
+}
+
+// splitSnapshotRootfs splits an lxc.rootfs setting of the form
+// "kind:lowerdir:upperdir", as used by overlayfs and aufs snapshots,
+// into its parts. The returned kind is empty for other root
+// filesystems.
+func splitSnapshotRootfs(rootfs string) (kind, lower, upper string) {
+	parts := strings.Split(rootfs, ":")
+	if len(parts) != 3 {
+		return "", "", ""
+	}
+	switch parts[0] {
+	case overlayfsRootfs, aufsRootfs:
+		return parts[0], parts[1], parts[2]
+	}
+	return "", "", ""
+}
+
+// runMountCommand runs a mount or umount command. It is a variable so
+// that tests can replace it.
+var runMountCommand = func(args ...string) error {
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v (%s)", args[0], err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// withRootfs calls f with the path of the root filesystem of the
+// stopped container with the given name. Overlayfs and aufs snapshots
+// are only assembled when the container starts, so they are mounted
+// for the duration of the call; files removed by f are then hidden
+// from the snapshot rather than removed from the original container.
+func withRootfs(name, rootfs string, f func(rootfs string) error) (err error) {
+	kind, lower, upper := splitSnapshotRootfs(rootfs)
+	if kind == "" {
+		return f(rootfs)
+	}
+	mountPoint, err := ioutil.TempDir(filepath.Join(LxcContainerDir, name), "rootfs-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(mountPoint); err != nil {
+			logger.Errorf("cannot remove %s: %v", mountPoint, err)
+		}
+	}()
+	var options string
+	if kind == overlayfsRootfs {
+		options = fmt.Sprintf("lowerdir=%s,upperdir=%s", lower, upper)
+	} else {
+		options = fmt.Sprintf("br=%s=rw:%s=ro", upper, lower)
+	}
+	if err := runMountCommand("mount", "-t", kind, "-o", options, kind, mountPoint); err != nil {
+		return err
+	}
+	defer func() {
+		if umountErr := runMountCommand("umount", mountPoint); umountErr != nil && err == nil {
+			err = umountErr
+		}
+	}()
+	return f(mountPoint)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
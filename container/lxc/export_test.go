@@ -12,6 +12,7 @@ var (
 	NetworkConfigTemplate   = networkConfigTemplate
 	RestartSymlink          = restartSymlink
 	ReleaseVersion          = &releaseVersion
+	RunMountCommand         = &runMountCommand
 	PreferFastLXC           = preferFastLXC
 )
 
@@ -0,0 +1,132 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+)
+
+var (
+	// There are some values where it doesn't make sense to go below.
+	MinMemory   uint64 = 256 // MB
+	MinCpuCores uint64 = 1
+	MinRootDisk uint64 = 1024 // MB
+
+	// cpuPeriod is the CFS scheduler period, in microseconds, against
+	// which the cpu quota of a container is measured.
+	cpuPeriod uint64 = 100000
+)
+
+// ResourceLimits holds the cgroup limits applied to an lxc container.
+// A zero value for any field means that the resource is not limited.
+type ResourceLimits struct {
+	Memory   uint64 // MB
+	CpuCores uint64
+	RootDisk uint64 // MB
+}
+
+// ParseConstraintsToLimits takes a constraints object and returns the
+// resource limits for the container. Constraints that cannot be enforced
+// by lxc cause a message to be logged.
+func ParseConstraintsToLimits(cons constraints.Value) ResourceLimits {
+	var limits ResourceLimits
+	if cons.Mem != nil && *cons.Mem > 0 {
+		limits.Memory = *cons.Mem
+		if limits.Memory < MinMemory {
+			limits.Memory = MinMemory
+		}
+	}
+	if cons.CpuCores != nil && *cons.CpuCores > 0 {
+		limits.CpuCores = *cons.CpuCores
+		if limits.CpuCores < MinCpuCores {
+			limits.CpuCores = MinCpuCores
+		}
+	}
+	if cons.RootDisk != nil && *cons.RootDisk > 0 {
+		limits.RootDisk = *cons.RootDisk
+		if limits.RootDisk < MinRootDisk {
+			limits.RootDisk = MinRootDisk
+		}
+	}
+	if cons.CpuPower != nil {
+		logger.Infof("cpu-power constraint of %v being ignored as not supported", *cons.CpuPower)
+	}
+	if cons.Tags != nil {
+		logger.Infof("tags constraint of %q being ignored as not supported", strings.Join(*cons.Tags, ","))
+	}
+	return limits
+}
+
+// cgroupConfig returns the lxc config lines that apply the memory and
+// cpu limits to a container.
+func (limits ResourceLimits) cgroupConfig() string {
+	var lines []string
+	if limits.Memory != 0 {
+		lines = append(lines,
+			fmt.Sprintf("lxc.cgroup.memory.limit_in_bytes = %dM", limits.Memory))
+	}
+	if limits.CpuCores != 0 {
+		lines = append(lines,
+			fmt.Sprintf("lxc.cgroup.cpu.cfs_period_us = %d", cpuPeriod),
+			fmt.Sprintf("lxc.cgroup.cpu.cfs_quota_us = %d", limits.CpuCores*cpuPeriod))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// applyResourceLimits adds the cgroup limits to the container config and,
+// if the backing filesystem supports it, sets a quota on the root
+// filesystem. The limits that were actually applied are recorded in
+// hardware.
+func applyResourceLimits(name, backingFilesystem string, limits ResourceLimits, hardware *instance.HardwareCharacteristics) error {
+	if config := limits.cgroupConfig(); config != "" {
+		if err := appendToContainerConfig(name, config); err != nil {
+			return err
+		}
+	}
+	if limits.Memory != 0 {
+		mem := limits.Memory
+		hardware.Mem = &mem
+	}
+	if limits.CpuCores != 0 {
+		cores := limits.CpuCores
+		hardware.CpuCores = &cores
+	}
+	if limits.RootDisk == 0 {
+		return nil
+	}
+	if backingFilesystem != Btrfs {
+		logger.Infof("root-disk constraint ignored: %s backing filesystem does not support quotas", backingFilesystem)
+		return nil
+	}
+	if err := setRootfsQuota(name, limits.RootDisk); err != nil {
+		// Quotas need to be enabled on the btrfs filesystem by the
+		// administrator, so failing to set one is not fatal.
+		logger.Warningf("cannot set root-disk quota for %q: %v", name, err)
+		return nil
+	}
+	rootDisk := limits.RootDisk
+	hardware.RootDisk = &rootDisk
+	return nil
+}
+
+// setRootfsQuota limits the size of the btrfs subvolume holding the root
+// filesystem of the container.
+func setRootfsQuota(name string, size uint64) error {
+	rootfs := filepath.Join(LxcContainerDir, name, "rootfs")
+	cmd := exec.Command("btrfs", "qgroup", "limit", fmt.Sprintf("%dM", size), rootfs)
+	out, err := FsCommandOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -225,12 +225,34 @@ func (manager *containerManager) CreateContainer(
 		}
 		logger.Tracef("lxc container created")
 	}
+	return manager.startContainer(lxcContainer, directory, machineConfig, start)
+}
+
+// startContainer configures the newly created container to restart with
+// the host, share the host's log directory and be limited according to the
+// machine's constraints, then starts it.
+func (manager *containerManager) startContainer(
+	lxcContainer golxc.Container,
+	directory string,
+	machineConfig *cloudinit.MachineConfig,
+	start time.Time,
+) (instance.Instance, *instance.HardwareCharacteristics, error) {
+	name := lxcContainer.Name()
 	if err := autostartContainer(name); err != nil {
 		return nil, nil, err
 	}
 	if err := mountHostLogDir(name, manager.logdir); err != nil {
 		return nil, nil, err
 	}
+	arch := version.Current.Arch
+	hardware := &instance.HardwareCharacteristics{
+		Arch: &arch,
+	}
+	limits := ParseConstraintsToLimits(machineConfig.Constraints)
+	if err := applyResourceLimits(name, manager.backingFilesystem, limits, hardware); err != nil {
+		logger.Errorf("failed to apply resource limits: %v", err)
+		return nil, nil, err
+	}
 	// Start the lxc container with the appropriate settings for grabbing the
 	// console output and a log file.
 	consoleFile := filepath.Join(directory, "console.log")
@@ -240,14 +262,10 @@ func (manager *containerManager) CreateContainer(
 	// method as we have passed it through at container creation time.  This
 	// is necessary to get the appropriate rootfs reference without explicitly
 	// setting it ourselves.
-	if err = lxcContainer.Start("", consoleFile); err != nil {
+	if err := lxcContainer.Start("", consoleFile); err != nil {
 		logger.Errorf("container failed to start: %v", err)
 		return nil, nil, err
 	}
-	arch := version.Current.Arch
-	hardware := &instance.HardwareCharacteristics{
-		Arch: &arch,
-	}
 	logger.Tracef("container %q started: %v", name, time.Now().Sub(start))
 	return &lxcInstance{lxcContainer, name}, hardware, nil
 }
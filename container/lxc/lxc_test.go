@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	stdtesting "testing"
@@ -21,6 +22,7 @@ import (
 	"launchpad.net/goyaml"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/container/lxc/mock"
@@ -28,6 +30,7 @@ import (
 	containertesting "github.com/juju/juju/container/testing"
 	instancetest "github.com/juju/juju/instance/testing"
 	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
 )
 
 func Test(t *stdtesting.T) {
@@ -217,6 +220,231 @@ func (s *LxcSuite) TestCreateContainer(c *gc.C) {
 	c.Assert(location, gc.Equals, expectedTarget)
 }
 
+func (s *LxcSuite) makeManagerWithFilesystem(c *gc.C, fstype string) (container.Manager, *[][]string) {
+	var commands [][]string
+	s.PatchValue(&lxc.FsCommandOutput, func(cmd *exec.Cmd) ([]byte, error) {
+		commands = append(commands, cmd.Args)
+		return []byte("Type\n" + fstype + "\n"), nil
+	})
+	return s.makeManager(c, "test"), &commands
+}
+
+func (s *LxcSuite) TestCreateContainerWithConstraints(c *gc.C) {
+	manager, commands := s.makeManagerWithFilesystem(c, "ext4")
+	cons := constraints.MustParse("mem=2G cpu-cores=3 root-disk=10G")
+	instance, hardware := containertesting.CreateContainerWithConstraints(c, manager, "1/lxc/0", cons)
+	name := string(instance.Id())
+
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename(name))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(config), jc.Contains, `
+lxc.cgroup.memory.limit_in_bytes = 2048M
+lxc.cgroup.cpu.cfs_period_us = 100000
+lxc.cgroup.cpu.cfs_quota_us = 300000
+`)
+	c.Assert(hardware.String(), gc.Equals, fmt.Sprintf("arch=%s cpu-cores=3 mem=2048M", version.Current.Arch))
+	// Only the filesystem check is run; ext4 has no quota support.
+	c.Assert(*commands, gc.HasLen, 1)
+}
+
+func (s *LxcSuite) TestCreateContainerWithoutConstraintsIsUnlimited(c *gc.C) {
+	manager := s.makeManager(c, "test")
+	instance, hardware := containertesting.CreateContainerWithConstraints(c, manager, "1/lxc/0", constraints.Value{})
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename(string(instance.Id())))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(config), gc.Not(jc.Contains), "lxc.cgroup")
+	c.Assert(hardware.Mem, gc.IsNil)
+	c.Assert(hardware.CpuCores, gc.IsNil)
+	c.Assert(hardware.RootDisk, gc.IsNil)
+}
+
+func (s *LxcSuite) TestCreateContainerSetsRootDiskQuotaOnBtrfs(c *gc.C) {
+	manager, commands := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	cons := constraints.MustParse("root-disk=10G")
+	instance, hardware := containertesting.CreateContainerWithConstraints(c, manager, "1/lxc/0", cons)
+
+	c.Assert(*commands, gc.HasLen, 2)
+	rootfs := filepath.Join(s.LxcDir, string(instance.Id()), "rootfs")
+	c.Assert((*commands)[1], gc.DeepEquals, []string{"btrfs", "qgroup", "limit", "10240M", rootfs})
+	c.Assert(*hardware.RootDisk, gc.Equals, uint64(10240))
+}
+
+func (s *LxcSuite) TestCloneContainer(c *gc.C) {
+	manager, _ := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	source := containertesting.CreateContainer(c, manager, "1/lxc/0")
+
+	// The mock clone only copies the container config, so fake up the
+	// agent files that would be copied from the source root filesystem.
+	rootfs := filepath.Join(s.LxcDir, "test-machine-1-lxc-1", "rootfs")
+	for _, name := range []string{
+		"etc/init/jujud-machine-1-lxc-0.conf",
+		"etc/init/ssh.conf",
+		"var/lib/juju/agents/unit-wordpress-0/agent.conf",
+		"var/lib/juju/nonce.txt",
+	} {
+		path := filepath.Join(rootfs, name)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), gc.IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte("source"), 0644), gc.IsNil)
+	}
+
+	clone := containertesting.CloneContainer(c, manager.(container.Cloner), source.Id(), "1/lxc/1")
+	c.Assert(string(clone.Id()), gc.Equals, "test-machine-1-lxc-1")
+	c.Assert(s.ContainerFactory.New("test-machine-1-lxc-1").IsRunning(), jc.IsTrue)
+	c.Assert(s.ContainerFactory.New(string(source.Id())).IsRunning(), jc.IsTrue)
+
+	c.Assert(filepath.Join(rootfs, "etc/init/jujud-machine-1-lxc-0.conf"), jc.DoesNotExist)
+	c.Assert(filepath.Join(rootfs, "var/lib/juju/agents/unit-wordpress-0"), jc.DoesNotExist)
+	c.Assert(filepath.Join(rootfs, "var/lib/juju/nonce.txt"), jc.DoesNotExist)
+	c.Assert(filepath.Join(rootfs, "etc/init/ssh.conf"), jc.IsNonEmptyFile)
+
+	// The log mount entry from the source is replaced by the clone's own.
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename("test-machine-1-lxc-1"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(strings.Count(string(config), "lxc.mount.entry"), gc.Equals, 1)
+}
+
+// cloneEvent returns the next Cloned event, skipping the
+// events of creating the source container.
+func (s *LxcSuite) cloneEvent(c *gc.C) mock.Event {
+	for {
+		select {
+		case event := <-s.events:
+			if event.Action == mock.Cloned {
+				return event
+			}
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for clone")
+		}
+	}
+}
+
+func (s *LxcSuite) TestCloneContainerSystemdUnits(c *gc.C) {
+	manager, _ := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	source := containertesting.CreateContainer(c, manager, "1/lxc/0")
+	rootfs := filepath.Join(s.LxcDir, "test-machine-1-lxc-1", "rootfs")
+	for _, name := range []string{
+		"etc/systemd/system/jujud-machine-1-lxc-0.service",
+		"etc/systemd/system/multi-user.target.wants/jujud-machine-1-lxc-0.service",
+		"etc/systemd/system/multi-user.target.wants/ssh.service",
+	} {
+		path := filepath.Join(rootfs, name)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), gc.IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte("source"), 0644), gc.IsNil)
+	}
+	containertesting.CloneContainer(c, manager.(container.Cloner), source.Id(), "1/lxc/1")
+	c.Assert(s.cloneEvent(c).Args, gc.DeepEquals, []string{"--snapshot"})
+
+	c.Assert(filepath.Join(rootfs, "etc/systemd/system/jujud-machine-1-lxc-0.service"), jc.DoesNotExist)
+	c.Assert(filepath.Join(rootfs, "etc/systemd/system/multi-user.target.wants/jujud-machine-1-lxc-0.service"), jc.DoesNotExist)
+	c.Assert(filepath.Join(rootfs, "etc/systemd/system/multi-user.target.wants/ssh.service"), jc.IsNonEmptyFile)
+}
+
+// fakeMount replaces the mount commands run when cloning with
+// ones that make the upper directory of the given snapshot root
+// filesystem appear at the mount point.
+func (s *LxcSuite) fakeMount(c *gc.C, upper string) *[][]string {
+	var commands [][]string
+	s.PatchValue(lxc.RunMountCommand, func(args ...string) error {
+		commands = append(commands, args)
+		mountPoint := args[len(args)-1]
+		if err := os.Remove(mountPoint); err != nil {
+			return err
+		}
+		if args[0] == "mount" {
+			return os.Symlink(upper, mountPoint)
+		}
+		return os.Mkdir(mountPoint, 0755)
+	})
+	return &commands
+}
+
+func (s *LxcSuite) testCloneContainerSnapshot(c *gc.C, kind string, expectArgs []string, expectOptions string) {
+	manager, _ := s.makeManagerWithFilesystem(c, "ext4")
+	source := containertesting.CreateContainer(c, manager, "1/lxc/0")
+
+	// The clone's lxc.rootfs is copied from the source config
+	// by the mock, so set it there.
+	lower := filepath.Join(s.LxcDir, "test-machine-1-lxc-0", "rootfs")
+	upper := filepath.Join(s.LxcDir, "test-machine-1-lxc-1", "delta0")
+	configFile := lxc.ContainerConfigFilename(string(source.Id()))
+	config, err := ioutil.ReadFile(configFile)
+	c.Assert(err, gc.IsNil)
+	config = append(config, fmt.Sprintf("lxc.rootfs = %s:%s:%s\n", kind, lower, upper)...)
+	c.Assert(ioutil.WriteFile(configFile, config, 0644), gc.IsNil)
+	for _, name := range []string{
+		"etc/init/jujud-machine-1-lxc-0.conf",
+		"etc/init/ssh.conf",
+	} {
+		path := filepath.Join(upper, name)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), gc.IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte("source"), 0644), gc.IsNil)
+	}
+	commands := s.fakeMount(c, upper)
+
+	containertesting.CloneContainer(c, manager.(container.Cloner), source.Id(), "1/lxc/1")
+	c.Assert(s.cloneEvent(c).Args, gc.DeepEquals, expectArgs)
+
+	c.Assert(*commands, gc.HasLen, 2)
+	mountPoint := (*commands)[0][len((*commands)[0])-1]
+	c.Assert((*commands)[0], gc.DeepEquals, []string{"mount", "-t", kind, "-o", expectOptions, kind, mountPoint})
+	c.Assert((*commands)[1], gc.DeepEquals, []string{"umount", mountPoint})
+	c.Assert(mountPoint, jc.DoesNotExist)
+	c.Assert(filepath.Join(upper, "etc/init/jujud-machine-1-lxc-0.conf"), jc.DoesNotExist)
+	c.Assert(filepath.Join(upper, "etc/init/ssh.conf"), jc.IsNonEmptyFile)
+}
+
+func (s *LxcSuite) TestCloneContainerOverlayfs(c *gc.C) {
+	lower := filepath.Join(s.LxcDir, "test-machine-1-lxc-0", "rootfs")
+	upper := filepath.Join(s.LxcDir, "test-machine-1-lxc-1", "delta0")
+	s.testCloneContainerSnapshot(c, "overlayfs", []string{"--snapshot"},
+		fmt.Sprintf("lowerdir=%s,upperdir=%s", lower, upper))
+}
+
+func (s *LxcSuite) TestCloneContainerAUFS(c *gc.C) {
+	s.PatchValue(&s.useAUFS, true)
+	lower := filepath.Join(s.LxcDir, "test-machine-1-lxc-0", "rootfs")
+	upper := filepath.Join(s.LxcDir, "test-machine-1-lxc-1", "delta0")
+	s.testCloneContainerSnapshot(c, "aufs", []string{"--snapshot", "--backingstore", "aufs"},
+		fmt.Sprintf("br=%s=rw:%s=ro", upper, lower))
+}
+
+func (s *LxcSuite) TestCloneContainerChecksBeforeStoppingSource(c *gc.C) {
+	manager, _ := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	source := containertesting.CreateContainer(c, manager, "1/lxc/0")
+	containertesting.CreateContainer(c, manager, "1/lxc/1")
+	machineConfig := containertesting.MachineConfig("1/lxc/1")
+	network := container.BridgeNetworkConfig("nic42")
+	_, _, err := manager.(container.Cloner).CloneContainer(source.Id(), machineConfig, "series", network)
+	c.Assert(err, gc.ErrorMatches, `cannot clone container "test-machine-1-lxc-0": container "test-machine-1-lxc-1" already exists`)
+	c.Assert(s.ContainerFactory.New(string(source.Id())).IsRunning(), jc.IsTrue)
+	for len(s.events) > 0 {
+		c.Assert((<-s.events).Action, gc.Not(gc.Equals), mock.Stopped)
+	}
+}
+
+func (s *LxcSuite) TestCloneContainerUnsupportedRootfs(c *gc.C) {
+	manager, _ := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	source := containertesting.CreateContainer(c, manager, "1/lxc/0")
+	configFile := lxc.ContainerConfigFilename(string(source.Id()))
+	config, err := ioutil.ReadFile(configFile)
+	c.Assert(err, gc.IsNil)
+	config = append(config, "lxc.rootfs = overlayfs:/var/lib/lxc/a/rootfs:/var/lib/lxc/b/delta0\n"...)
+	c.Assert(ioutil.WriteFile(configFile, config, 0644), gc.IsNil)
+	machineConfig := containertesting.MachineConfig("1/lxc/1")
+	network := container.BridgeNetworkConfig("nic42")
+	_, _, err = manager.(container.Cloner).CloneContainer(source.Id(), machineConfig, "series", network)
+	c.Assert(err, gc.ErrorMatches, `cannot clone container "test-machine-1-lxc-0": cannot take a btrfs snapshot of overlayfs root filesystem .*`)
+	c.Assert(s.ContainerFactory.New(string(source.Id())).IsRunning(), jc.IsTrue)
+}
+
+func (s *LxcSuite) TestCloneContainerMissingSource(c *gc.C) {
+	manager, _ := s.makeManagerWithFilesystem(c, lxc.Btrfs)
+	machineConfig := containertesting.MachineConfig("1/lxc/1")
+	network := container.BridgeNetworkConfig("nic42")
+	_, _, err := manager.(container.Cloner).CloneContainer("test-machine-1-lxc-0", machineConfig, "series", network)
+	c.Assert(err, gc.ErrorMatches, `container "test-machine-1-lxc-0" not found`)
+}
+
 func (s *LxcSuite) ensureTemplateStopped(name string) <-chan struct{} {
 	ch := make(chan struct{}, 1)
 	go func() {
@@ -487,6 +715,43 @@ func (*NetworkSuite) TestGenerateNetworkConfig(c *gc.C) {
 	}
 }
 
+type LimitsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&LimitsSuite{})
+
+func (*LimitsSuite) TestParseConstraintsToLimits(c *gc.C) {
+	for i, test := range []struct {
+		cons     string
+		expected lxc.ResourceLimits
+	}{{
+		cons: "",
+	}, {
+		cons:     "mem=4G",
+		expected: lxc.ResourceLimits{Memory: 4 * 1024},
+	}, {
+		cons:     "mem=100M",
+		expected: lxc.ResourceLimits{Memory: lxc.MinMemory},
+	}, {
+		cons:     "cpu-cores=4",
+		expected: lxc.ResourceLimits{CpuCores: 4},
+	}, {
+		cons:     "root-disk=512M",
+		expected: lxc.ResourceLimits{RootDisk: lxc.MinRootDisk},
+	}, {
+		cons:     "mem=0 cpu-cores=0 root-disk=0",
+		expected: lxc.ResourceLimits{},
+	}, {
+		cons:     "mem=2G cpu-cores=2 root-disk=20G cpu-power=100",
+		expected: lxc.ResourceLimits{Memory: 2 * 1024, CpuCores: 2, RootDisk: 20 * 1024},
+	}} {
+		c.Logf("test %d: %s", i, test.cons)
+		limits := lxc.ParseConstraintsToLimits(constraints.MustParse(test.cons))
+		c.Check(limits, gc.Equals, test.expected)
+	}
+}
+
 func (*NetworkSuite) TestNetworkConfigTemplate(c *gc.C) {
 	config := lxc.NetworkConfigTemplate("foo", "bar")
 	//In the past, the entire lxc.conf file was just networking. With the addition
@@ -0,0 +1,83 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/container"
+)
+
+type nspawnContainer struct {
+	factory *containerFactory
+	name    string
+	// started is a three state boolean, true, false, or unknown
+	// this allows for checking when we don't know, but using a
+	// value if we already know it (like in the list situation).
+	started *bool
+}
+
+var _ Container = (*nspawnContainer)(nil)
+
+func (c *nspawnContainer) Name() string {
+	return c.name
+}
+
+func (c *nspawnContainer) Start(params StartParams) error {
+	var bridge string
+	if params.Network != nil {
+		if params.Network.NetworkType == container.BridgeNetwork {
+			bridge = params.Network.Device
+		} else {
+			return errors.LoggedErrorf(logger, "Non-bridge network devices not yet supported")
+		}
+	}
+	logger.Debugf("Create the root filesystem for %s", c.name)
+	if err := CreateMachine(CreateMachineParams{
+		Name:          c.name,
+		Series:        params.Series,
+		Arch:          params.Arch,
+		UserDataFile:  params.UserDataFile,
+		NetworkBridge: bridge,
+	}); err != nil {
+		return err
+	}
+	logger.Debugf("Start the machine %s", c.name)
+	if err := StartMachine(c.name); err != nil {
+		return err
+	}
+	logger.Debugf("Set machine %s to autostart", c.name)
+	return AutostartMachine(c.name)
+}
+
+func (c *nspawnContainer) Stop() error {
+	if c.IsRunning() {
+		logger.Debugf("Stop %s", c.name)
+		if err := StopMachine(c.name); err != nil {
+			return err
+		}
+	}
+	// Make started state unknown again.
+	c.started = nil
+	logger.Debugf("Destroy %s", c.name)
+	return DestroyMachine(c.name)
+}
+
+func (c *nspawnContainer) IsRunning() bool {
+	if c.started != nil {
+		return *c.started
+	}
+	machines, err := ListMachines()
+	if err != nil {
+		return false
+	}
+	c.started = isRunning(machines[c.name])
+	return *c.started
+}
+
+func (c *nspawnContainer) String() string {
+	return fmt.Sprintf("<nspawn container %v>", *c)
+}
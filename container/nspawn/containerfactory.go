@@ -0,0 +1,39 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+type containerFactory struct {
+}
+
+var _ ContainerFactory = (*containerFactory)(nil)
+
+func (factory *containerFactory) New(name string) Container {
+	return &nspawnContainer{
+		factory: factory,
+		name:    name,
+	}
+}
+
+func isRunning(value string) *bool {
+	var result *bool = new(bool)
+	if value == "running" {
+		*result = true
+	}
+	return result
+}
+
+func (factory *containerFactory) List() (result []Container, err error) {
+	machines, err := ListMachines()
+	if err != nil {
+		return nil, err
+	}
+	for name, state := range machines {
+		result = append(result, &nspawnContainer{
+			factory: factory,
+			name:    name,
+			started: isRunning(state),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+type nspawnInstance struct {
+	container Container
+	id        string
+}
+
+var _ instance.Instance = (*nspawnInstance)(nil)
+
+// Id implements instance.Instance.Id.
+func (nspawn *nspawnInstance) Id() instance.Id {
+	return instance.Id(nspawn.id)
+}
+
+// Status implements instance.Instance.Status.
+func (nspawn *nspawnInstance) Status() string {
+	if nspawn.container.IsRunning() {
+		return "running"
+	}
+	return "stopped"
+}
+
+func (*nspawnInstance) Refresh() error {
+	return nil
+}
+
+func (nspawn *nspawnInstance) Addresses() ([]network.Address, error) {
+	logger.Errorf("nspawnInstance.Addresses not implemented")
+	return nil, nil
+}
+
+// OpenPorts implements instance.Instance.OpenPorts.
+func (nspawn *nspawnInstance) OpenPorts(machineId string, ports []network.Port) error {
+	return fmt.Errorf("not implemented")
+}
+
+// ClosePorts implements instance.Instance.ClosePorts.
+func (nspawn *nspawnInstance) ClosePorts(machineId string, ports []network.Port) error {
+	return fmt.Errorf("not implemented")
+}
+
+// Ports implements instance.Instance.Ports.
+func (nspawn *nspawnInstance) Ports(machineId string) ([]network.Port, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Add a string representation of the id.
+func (nspawn *nspawnInstance) String() string {
+	return fmt.Sprintf("nspawn:%s", nspawn.id)
+}
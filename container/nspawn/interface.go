@@ -0,0 +1,49 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+import (
+	"github.com/juju/juju/container"
+)
+
+// StartParams is a simple parameter struct for Container.Start.
+type StartParams struct {
+	Series       string
+	Arch         string
+	UserDataFile string
+	Network      *container.NetworkConfig
+}
+
+// Container represents a systemd-nspawn container and provides
+// operations to create, maintain and destroy the container.
+type Container interface {
+
+	// Name returns the name of the container.
+	Name() string
+
+	// Start creates the root filesystem of the container if necessary
+	// and boots it.
+	Start(params StartParams) error
+
+	// Stop terminates the running container and removes its root
+	// filesystem.
+	Stop() error
+
+	// IsRunning returns whether or not the container is running.
+	IsRunning() bool
+
+	// String returns information about the container.
+	String() string
+}
+
+// ContainerFactory represents the methods used to create Containers.  This
+// wraps the low level OS functions for dealing with the containers.
+type ContainerFactory interface {
+	// New returns a container instance which can then be used for operations
+	// like Start() and Stop()
+	New(string) Container
+
+	// List returns all the existing containers on the system.
+	List() ([]Container, error)
+}
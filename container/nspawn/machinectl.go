@@ -0,0 +1,196 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+// This file contains wrappers around the following executables:
+//   debootstrap
+//   machinectl
+// Those executables are found in the following packages:
+//   debootstrap
+//   systemd-container
+//
+// These executables provide Juju's interface to dealing with nspawn
+// containers. Each container is a root filesystem under MachinesDir which
+// is booted by the systemd-nspawn@.service unit, and configured through a
+// .nspawn file in NspawnConfigDir.
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/utils"
+)
+
+var (
+	// MachinesDir is the directory that machinectl looks in for the
+	// root filesystems of the containers it manages.
+	MachinesDir = "/var/lib/machines"
+
+	// NspawnConfigDir holds the per-container settings read by
+	// systemd-nspawn when a container is started by machinectl.
+	NspawnConfigDir = "/etc/systemd/nspawn"
+
+	// requiredGuestPackages are installed into every root filesystem
+	// so the guest can be configured by juju.
+	requiredGuestPackages = []string{
+		"cloud-init",
+		"openssh-server",
+	}
+)
+
+// run the command and return the combined output.
+func run(command string, args ...string) (output string, err error) {
+	logger.Tracef("%s %v", command, args)
+	output, err = utils.RunCommand(command, args...)
+	logger.Tracef("output: %v", output)
+	return output, err
+}
+
+func rootfsForName(name string) string {
+	return filepath.Join(MachinesDir, name)
+}
+
+func configForName(name string) string {
+	return filepath.Join(NspawnConfigDir, name+".nspawn")
+}
+
+type CreateMachineParams struct {
+	Name          string
+	Series        string
+	Arch          string
+	UserDataFile  string
+	NetworkBridge string
+}
+
+// CreateMachine bootstraps a root filesystem for the named container,
+// seeds cloud-init with the user data and writes the nspawn settings
+// used when the container is booted.
+func CreateMachine(params CreateMachineParams) error {
+	if params.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if params.Series == "" {
+		return fmt.Errorf("Series is required")
+	}
+	rootfs := rootfsForName(params.Name)
+	args := []string{
+		"--variant=minbase",
+		"--include=" + strings.Join(requiredGuestPackages, ","),
+	}
+	if params.Arch != "" {
+		args = append(args, "--arch="+params.Arch)
+	}
+	args = append(args, params.Series, rootfs)
+	if _, err := run("debootstrap", args...); err != nil {
+		return err
+	}
+	if params.UserDataFile != "" {
+		if err := writeCloudInitSeed(rootfs, params.Name, params.UserDataFile); err != nil {
+			return err
+		}
+	}
+	return writeNspawnConfig(params.Name, params.NetworkBridge)
+}
+
+// writeCloudInitSeed configures the NoCloud datasource inside the root
+// filesystem so that cloud-init picks up the user data on first boot.
+func writeCloudInitSeed(rootfs, name, userDataFile string) error {
+	userData, err := ioutil.ReadFile(userDataFile)
+	if err != nil {
+		return err
+	}
+	seedDir := filepath.Join(rootfs, "var", "lib", "cloud", "seed", "nocloud-net")
+	if err := os.MkdirAll(seedDir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(seedDir, "user-data"), userData, 0600); err != nil {
+		return err
+	}
+	metaData := fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", name, name)
+	return ioutil.WriteFile(filepath.Join(seedDir, "meta-data"), []byte(metaData), 0644)
+}
+
+func writeNspawnConfig(name, bridge string) error {
+	if err := os.MkdirAll(NspawnConfigDir, 0755); err != nil {
+		return err
+	}
+	config := "[Exec]\nBoot=yes\n"
+	if bridge != "" {
+		config += fmt.Sprintf("\n[Network]\nBridge=%s\n", bridge)
+	}
+	return ioutil.WriteFile(configForName(name), []byte(config), 0644)
+}
+
+// StartMachine boots the named container.
+func StartMachine(name string) error {
+	_, err := run("machinectl", "start", name)
+	return err
+}
+
+// AutostartMachine indicates that the container should automatically
+// restart when the host restarts.
+func AutostartMachine(name string) error {
+	_, err := run("machinectl", "enable", name)
+	return err
+}
+
+// StopMachine terminates the named container.
+func StopMachine(name string) error {
+	_, err := run("machinectl", "terminate", name)
+	return err
+}
+
+// DestroyMachine stops the named container from being started when the
+// host restarts and removes its root filesystem and settings.
+func DestroyMachine(name string) error {
+	if _, err := run("machinectl", "disable", name); err != nil {
+		logger.Warningf("failed to disable %s: %v", name, err)
+	}
+	if err := os.Remove(configForName(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(rootfsForName(name))
+}
+
+// ListMachines returns a map of container name to state, where state is
+// either running or stopped. Containers are stopped if their root
+// filesystem exists but machinectl does not report them.
+func ListMachines() (map[string]string, error) {
+	output, err := run("machinectl", "--no-legend", "list")
+	if err != nil {
+		return nil, err
+	}
+	result := parseMachineList(output)
+	entries, err := ioutil.ReadDir(MachinesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, ok := result[entry.Name()]; !ok {
+			result[entry.Name()] = "stopped"
+		}
+	}
+	return result, nil
+}
+
+// parseMachineList parses the output of 'machinectl --no-legend list',
+// which has one line per running machine holding the machine name,
+// class and service, separated by whitespace.
+func parseMachineList(output string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] != "container" {
+			continue
+		}
+		result[fields[0]] = "running"
+	}
+	return result
+}
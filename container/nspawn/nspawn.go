@@ -0,0 +1,136 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/version"
+)
+
+var (
+	logger = loggo.GetLogger("juju.container.nspawn")
+
+	NspawnObjectFactory ContainerFactory = &containerFactory{}
+	DefaultNspawnBridge                  = "br0"
+)
+
+// IsNspawnSupported reports whether the host has the systemd-nspawn and
+// machinectl executables. It is a variable to allow us to override
+// behaviour in the tests.
+var IsNspawnSupported = func() (bool, error) {
+	for _, command := range []string{"systemd-nspawn", "machinectl"} {
+		if _, err := exec.LookPath(command); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// NewContainerManager returns a manager object that can start and stop
+// systemd-nspawn containers. The containers that are created are namespaced
+// by the name parameter.
+func NewContainerManager(conf container.ManagerConfig) (container.Manager, error) {
+	name := conf.PopValue(container.ConfigName)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	logDir := conf.PopValue(container.ConfigLogDir)
+	if logDir == "" {
+		logDir = agent.DefaultLogDir
+	}
+	conf.WarnAboutUnused()
+	return &containerManager{name: name, logdir: logDir}, nil
+}
+
+// containerManager handles all of the business logic at the juju specific
+// level. It makes sure that the necessary directories are in place, that the
+// user-data is written out in the right place.
+type containerManager struct {
+	name   string
+	logdir string
+}
+
+var _ container.Manager = (*containerManager)(nil)
+
+func (manager *containerManager) CreateContainer(
+	machineConfig *cloudinit.MachineConfig,
+	series string,
+	network *container.NetworkConfig) (instance.Instance, *instance.HardwareCharacteristics, error) {
+
+	name := names.NewMachineTag(machineConfig.MachineId).String()
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	// Note here that the NspawnObjectFactory only returns a valid container
+	// object, and doesn't actually construct the underlying root filesystem.
+	nspawnContainer := NspawnObjectFactory.New(name)
+
+	// Create the cloud-init.
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create container directory: %v", err)
+	}
+	logger.Tracef("write cloud-init")
+	userDataFilename, err := container.WriteUserData(machineConfig, directory)
+	if err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "failed to write user data: %v", err)
+	}
+	arch := version.Current.Arch
+	startParams := StartParams{
+		Series:       series,
+		Arch:         arch,
+		Network:      network,
+		UserDataFile: userDataFilename,
+	}
+	logger.Tracef("create the container")
+	if err := nspawnContainer.Start(startParams); err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "nspawn container creation failed: %v", err)
+	}
+	logger.Tracef("nspawn container created")
+	hardware := &instance.HardwareCharacteristics{
+		Arch: &arch,
+	}
+	return &nspawnInstance{nspawnContainer, name}, hardware, nil
+}
+
+func (manager *containerManager) DestroyContainer(id instance.Id) error {
+	name := string(id)
+	nspawnContainer := NspawnObjectFactory.New(name)
+	if err := nspawnContainer.Stop(); err != nil {
+		logger.Errorf("failed to stop nspawn container: %v", err)
+		return err
+	}
+	return container.RemoveDirectory(name)
+}
+
+func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
+	containers, err := NspawnObjectFactory.List()
+	if err != nil {
+		logger.Errorf("failed getting all instances: %v", err)
+		return
+	}
+	managerPrefix := fmt.Sprintf("%s-", manager.name)
+	for _, container := range containers {
+		// Filter out those not starting with our name.
+		name := container.Name()
+		if !strings.HasPrefix(name, managerPrefix) {
+			continue
+		}
+		if container.IsRunning() {
+			result = append(result, &nspawnInstance{container, name})
+		}
+	}
+	return
+}
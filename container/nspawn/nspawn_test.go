@@ -0,0 +1,135 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package nspawn_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/nspawn"
+	nspawntesting "github.com/juju/juju/container/nspawn/testing"
+	containertesting "github.com/juju/juju/container/testing"
+	"github.com/juju/juju/instance"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type NspawnSuite struct {
+	nspawntesting.TestSuite
+	manager container.Manager
+}
+
+var _ = gc.Suite(&NspawnSuite{})
+
+func (s *NspawnSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	var err error
+	s.manager, err = nspawn.NewContainerManager(container.ManagerConfig{container.ConfigName: "test"})
+	c.Assert(err, gc.IsNil)
+}
+
+func (*NspawnSuite) TestManagerNameNeeded(c *gc.C) {
+	manager, err := nspawn.NewContainerManager(container.ManagerConfig{container.ConfigName: ""})
+	c.Assert(err, gc.ErrorMatches, "name is required")
+	c.Assert(manager, gc.IsNil)
+}
+
+func (s *NspawnSuite) TestListInitiallyEmpty(c *gc.C) {
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 0)
+}
+
+func (s *NspawnSuite) createRunningContainer(c *gc.C, name string) nspawn.Container {
+	nspawnContainer := s.ContainerFactory.New(name)
+	network := container.BridgeNetworkConfig("testbr0")
+	c.Assert(nspawnContainer.Start(nspawn.StartParams{
+		Series:       "trusty",
+		Arch:         version.Current.Arch,
+		UserDataFile: "userdata.txt",
+		Network:      network}), gc.IsNil)
+	return nspawnContainer
+}
+
+func (s *NspawnSuite) TestListMatchesManagerName(c *gc.C) {
+	s.createRunningContainer(c, "test-match1")
+	s.createRunningContainer(c, "test-match2")
+	s.createRunningContainer(c, "testNoMatch")
+	s.createRunningContainer(c, "other")
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 2)
+	expectedIds := []instance.Id{"test-match1", "test-match2"}
+	ids := []instance.Id{containers[0].Id(), containers[1].Id()}
+	c.Assert(ids, jc.SameContents, expectedIds)
+}
+
+func (s *NspawnSuite) TestCreateContainer(c *gc.C) {
+	instance := containertesting.CreateContainer(c, s.manager, "1/nspawn/0")
+	name := string(instance.Id())
+	c.Assert(name, gc.Equals, "test-machine-1-nspawn-0")
+	cloudInitFilename := filepath.Join(s.ContainerDir, name, "cloud-init")
+	containertesting.AssertCloudInit(c, cloudInitFilename)
+}
+
+func (s *NspawnSuite) TestDestroyContainer(c *gc.C) {
+	instance := containertesting.CreateContainer(c, s.manager, "1/nspawn/0")
+
+	err := s.manager.DestroyContainer(instance.Id())
+	c.Assert(err, gc.IsNil)
+
+	name := string(instance.Id())
+	// Check that the container dir is no longer in the container dir
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	// but instead, in the removed container dir
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+}
+
+type MachinectlSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&MachinectlSuite{})
+
+func (*MachinectlSuite) TestParseMachineList(c *gc.C) {
+	output := `
+juju-machine-1-nspawn-0 container nspawn
+juju-machine-1-nspawn-1 container nspawn
+qemu-vm                 vm        libvirt-qemu
+`
+	c.Assert(nspawn.ParseMachineList(output), gc.DeepEquals, map[string]string{
+		"juju-machine-1-nspawn-0": "running",
+		"juju-machine-1-nspawn-1": "running",
+	})
+}
+
+func (s *MachinectlSuite) TestWriteNspawnConfig(c *gc.C) {
+	s.PatchValue(&nspawn.NspawnConfigDir, c.MkDir())
+	err := nspawn.WriteNspawnConfig("juju-machine-1-nspawn-0", "br0")
+	c.Assert(err, gc.IsNil)
+	data, err := ioutil.ReadFile(filepath.Join(nspawn.NspawnConfigDir, "juju-machine-1-nspawn-0.nspawn"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "[Exec]\nBoot=yes\n\n[Network]\nBridge=br0\n")
+}
+
+func (*MachinectlSuite) TestWriteCloudInitSeed(c *gc.C) {
+	rootfs := c.MkDir()
+	userDataFile := filepath.Join(c.MkDir(), "cloud-init")
+	err := ioutil.WriteFile(userDataFile, []byte("#cloud-config\n"), 0644)
+	c.Assert(err, gc.IsNil)
+
+	err = nspawn.WriteCloudInitSeed(rootfs, "juju-machine-1-nspawn-0", userDataFile)
+	c.Assert(err, gc.IsNil)
+	seedDir := filepath.Join(rootfs, "var", "lib", "cloud", "seed", "nocloud-net")
+	data, err := ioutil.ReadFile(filepath.Join(seedDir, "user-data"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "#cloud-config\n")
+	data, err = ioutil.ReadFile(filepath.Join(seedDir, "meta-data"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "instance-id: juju-machine-1-nspawn-0\nlocal-hostname: juju-machine-1-nspawn-0\n")
+}
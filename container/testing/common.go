@@ -9,8 +9,10 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/tools"
@@ -18,13 +20,17 @@ import (
 )
 
 func CreateContainer(c *gc.C, manager container.Manager, machineId string) instance.Instance {
-	stateInfo := jujutesting.FakeStateInfo(machineId)
-	apiInfo := jujutesting.FakeAPIInfo(machineId)
-	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
-	machineConfig.Tools = &tools.Tools{
-		Version: version.MustParseBinary("2.3.4-foo-bar"),
-		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
-	}
+	inst, _ := CreateContainerWithConstraints(c, manager, machineId, constraints.Value{})
+	return inst
+}
+
+// CreateContainerWithConstraints creates a container for the given machine
+// with the given constraints, and returns the instance and its hardware.
+func CreateContainerWithConstraints(
+	c *gc.C, manager container.Manager, machineId string, cons constraints.Value,
+) (instance.Instance, *instance.HardwareCharacteristics) {
+	machineConfig := MachineConfig(machineId)
+	machineConfig.Constraints = cons
 
 	series := "series"
 	network := container.BridgeNetworkConfig("nic42")
@@ -32,9 +38,33 @@ func CreateContainer(c *gc.C, manager container.Manager, machineId string) insta
 	c.Assert(err, gc.IsNil)
 	c.Assert(hardware, gc.NotNil)
 	c.Assert(hardware.String(), gc.Not(gc.Equals), "")
+	return inst, hardware
+}
+
+// CloneContainer clones the source container to create a container for the
+// given machine, and returns the new instance.
+func CloneContainer(c *gc.C, cloner container.Cloner, source instance.Id, machineId string) instance.Instance {
+	series := "series"
+	network := container.BridgeNetworkConfig("nic42")
+	inst, hardware, err := cloner.CloneContainer(source, MachineConfig(machineId), series, network)
+	c.Assert(err, gc.IsNil)
+	c.Assert(hardware, gc.NotNil)
 	return inst
 }
 
+// MachineConfig returns a machine config suitable for starting a container
+// for the given machine.
+func MachineConfig(machineId string) *cloudinit.MachineConfig {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
+	machineConfig.Tools = &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	return machineConfig
+}
+
 func AssertCloudInit(c *gc.C, filename string) []byte {
 	c.Assert(filename, jc.IsNonEmptyFile)
 	data, err := ioutil.ReadFile(filename)
@@ -4,11 +4,14 @@
 package downloader
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
@@ -17,6 +20,17 @@ import (
 
 var logger = loggo.GetLogger("juju.downloader")
 
+// retryDelay holds the time to wait before the first retry of a failed
+// download. The delay doubles after each further failure, up to
+// maxRetryDelay.
+var (
+	retryDelay    = 1 * time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// errStopped is returned by a download that was interrupted by Stop.
+var errStopped = errors.New("download stopped")
+
 // Status represents the status of a completed download.
 type Status struct {
 	// File holds the downloaded data on success.
@@ -25,11 +39,58 @@ type Status struct {
 	Err error
 }
 
+// Progress describes how much of a file has been downloaded.
+type Progress struct {
+	// Received holds the number of bytes downloaded so far,
+	// including any bytes received by earlier attempts.
+	Received int64
+	// Total holds the size of the file in bytes, or -1 if it
+	// is not known.
+	Total int64
+}
+
+// Options holds the parameters of a download.
+type Options struct {
+	// URL holds the location of the file to download.
+	URL string
+
+	// Dir holds the directory into which the file is downloaded.
+	// If it is empty, os.TempDir() is used.
+	Dir string
+
+	// HostnameVerification specifies whether the hostname in
+	// the server's SSL certificate is verified.
+	HostnameVerification utils.SSLHostnameVerification
+
+	// ExpectedSize, if non-zero, holds the expected size of the
+	// file in bytes.
+	ExpectedSize int64
+
+	// ExpectedSHA256, if not empty, holds the expected hex-encoded
+	// SHA256 checksum of the file.
+	ExpectedSHA256 string
+
+	// MaxRetries holds the number of times a failed download is
+	// retried. Data received by a failed attempt is kept, and the
+	// next attempt resumes from where it stopped if the server
+	// supports HTTP range requests.
+	MaxRetries int
+
+	// RateLimit, if non-zero, holds the maximum rate of the
+	// download in bytes per second.
+	RateLimit int64
+
+	// Progress, if not nil, receives updates as the download
+	// proceeds. Updates are dropped rather than holding up the
+	// download when the receiver is not ready.
+	Progress chan<- Progress
+}
+
 // Download can download a file from the network.
 type Download struct {
-	tomb                 tomb.Tomb
-	done                 chan Status
-	hostnameVerification utils.SSLHostnameVerification
+	tomb tomb.Tomb
+	done chan Status
+	opts Options
 }
 
 // New returns a new Download instance downloading from the given URL
@@ -37,11 +98,21 @@ type Download struct {
 // os.TempDir(). If disableSSLHostnameVerification is true then a non-
 // validating http client will be used.
 func New(url, dir string, hostnameVerification utils.SSLHostnameVerification) *Download {
+	return NewWithOptions(Options{
+		URL:                  url,
+		Dir:                  dir,
+		HostnameVerification: hostnameVerification,
+	})
+}
+
+// NewWithOptions returns a new Download instance downloading the
+// file described by opts.
+func NewWithOptions(opts Options) *Download {
 	d := &Download{
-		done:                 make(chan Status),
-		hostnameVerification: hostnameVerification,
+		done: make(chan Status),
+		opts: opts,
 	}
-	go d.run(url, dir)
+	go d.run()
 	return d
 }
 
@@ -58,14 +129,17 @@ func (d *Download) Done() <-chan Status {
 	return d.done
 }
 
-func (d *Download) run(url, dir string) {
+func (d *Download) run() {
 	defer d.tomb.Done()
 	// TODO(dimitern) 2013-10-03 bug #1234715
 	// Add a testing HTTPS storage to verify the
 	// disableSSLHostnameVerification behavior here.
-	file, err := download(url, dir, d.hostnameVerification)
+	file, err := d.download()
+	if err == errStopped {
+		return
+	}
 	if err != nil {
-		err = fmt.Errorf("cannot download %q: %v", url, err)
+		err = fmt.Errorf("cannot download %q: %v", d.opts.URL, err)
 	}
 	status := Status{
 		File: file,
@@ -78,7 +152,14 @@ func (d *Download) run(url, dir string) {
 	}
 }
 
-func download(url, dir string, hostnameVerification utils.SSLHostnameVerification) (file *os.File, err error) {
+// permanentError wraps an error that retrying the download
+// will not fix.
+type permanentError struct {
+	error
+}
+
+func (d *Download) download() (file *os.File, err error) {
+	dir := d.opts.Dir
 	if dir == "" {
 		dir = os.TempDir()
 	}
@@ -91,24 +172,207 @@ func download(url, dir string, hostnameVerification utils.SSLHostnameVerificatio
 			cleanTempFile(tempFile)
 		}
 	}()
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err = d.fetch(tempFile)
+		if err == nil {
+			err = d.verify(tempFile)
+		}
+		if err == nil {
+			break
+		}
+		if err == errStopped || attempt >= d.opts.MaxRetries {
+			return nil, err
+		}
+		if _, ok := err.(*permanentError); ok {
+			return nil, err
+		}
+		logger.Warningf("download of %q failed (attempt %d of %d), retrying in %v: %v",
+			d.opts.URL, attempt+1, d.opts.MaxRetries+1, delay, err)
+		select {
+		case <-d.tomb.Dying():
+			return nil, errStopped
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+	if _, err := tempFile.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	return tempFile, nil
+}
+
+// fetch downloads the file into f, resuming from the end of any data
+// already in f.
+func (d *Download) fetch(f *os.File) error {
+	offset, err := f.Seek(0, 2)
+	if err != nil {
+		return err
+	}
+	if offset > 0 && offset == d.opts.ExpectedSize {
+		return nil
+	}
+	req, err := http.NewRequest("GET", d.opts.URL, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	if offset > 0 {
+		logger.Infof("resuming download of %q at byte %d", d.opts.URL, offset)
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 	// TODO(rog) make the download operation interruptible.
-	client := utils.GetHTTPClient(hostnameVerification)
-	resp, err := client.Get(url)
+	client := utils.GetHTTPClient(d.opts.HostnameVerification)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad http response: %v", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			// The server ignored the range, so start again.
+			if err := truncate(f); err != nil {
+				return err
+			}
+			offset = 0
+		}
+	case http.StatusPartialContent:
+		var start int64
+		contentRange := resp.Header.Get("Content-Range")
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-", &start); err != nil || start != offset {
+			if err := truncate(f); err != nil {
+				return err
+			}
+			return fmt.Errorf("unexpected content range %q", contentRange)
+		}
+	default:
+		err := fmt.Errorf("bad http response: %v", resp.Status)
+		if offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// Whatever we have is no use, so start again.
+			if err := truncate(f); err != nil {
+				return err
+			}
+			return err
+		}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return &permanentError{err}
+		}
+		return err
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	} else if d.opts.ExpectedSize > 0 {
+		total = d.opts.ExpectedSize
+	}
+	var body io.Reader = resp.Body
+	if d.opts.RateLimit > 0 {
+		body = &rateLimitedReader{r: body, rate: d.opts.RateLimit, start: time.Now()}
+	}
+	return d.copy(f, body, offset, total)
+}
+
+// copy copies src to dst, reporting progress as it goes. It stops
+// early if the download is stopped.
+func (d *Download) copy(dst io.Writer, src io.Reader, received, total int64) error {
+	d.sendProgress(received, total)
+	buf := make([]byte, 32*1024)
+	for {
+		select {
+		case <-d.tomb.Dying():
+			return errStopped
+		default:
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+			received += int64(n)
+			d.sendProgress(received, total)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Download) sendProgress(received, total int64) {
+	if d.opts.Progress == nil {
+		return
+	}
+	select {
+	case d.opts.Progress <- Progress{Received: received, Total: total}:
+	default:
 	}
-	_, err = io.Copy(tempFile, resp.Body)
+}
+
+// verify checks the downloaded file against the expected size
+// and checksum, if any. A file that is shorter than expected
+// may still be completed by a later attempt; a longer file or
+// a checksum mismatch cannot be.
+func (d *Download) verify(f *os.File) error {
+	if d.opts.ExpectedSize == 0 && d.opts.ExpectedSHA256 == "" {
+		return nil
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if _, err := tempFile.Seek(0, 0); err != nil {
-		return nil, err
+	if d.opts.ExpectedSize != 0 && size != d.opts.ExpectedSize {
+		err := fmt.Errorf("expected %d bytes, got %d", d.opts.ExpectedSize, size)
+		if size > d.opts.ExpectedSize {
+			return &permanentError{err}
+		}
+		// The transfer ended early, so the next attempt
+		// resumes from the end of the data received so far.
+		return err
 	}
-	return tempFile, nil
+	actualSHA256 := fmt.Sprintf("%x", hash.Sum(nil))
+	if d.opts.ExpectedSHA256 != "" && actualSHA256 != d.opts.ExpectedSHA256 {
+		return &permanentError{fmt.Errorf("expected sha256 %q, got %q", d.opts.ExpectedSHA256, actualSHA256)}
+	}
+	return nil
+}
+
+// truncate discards the contents of f.
+func truncate(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, 0)
+	return err
+}
+
+// rateLimitedReader reads from r at no more than rate bytes
+// per second on average.
+type rateLimitedReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+func (r *rateLimitedReader) Read(buf []byte) (int, error) {
+	if int64(len(buf)) > r.rate {
+		buf = buf[:r.rate]
+	}
+	n, err := r.r.Read(buf)
+	r.n += int64(n)
+	due := r.start.Add(time.Duration(float64(r.n) / float64(r.rate) * float64(time.Second)))
+	if wait := due.Sub(time.Now()); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
 }
 
 func cleanTempFile(f *os.File) {
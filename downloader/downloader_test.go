@@ -4,7 +4,11 @@
 package downloader_test
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	stdtesting "testing"
@@ -36,6 +40,7 @@ func (s *suite) TearDownSuite(c *gc.C) {
 func (s *suite) SetUpTest(c *gc.C) {
 	s.BaseSuite.SetUpTest(c)
 	s.HTTPSuite.SetUpTest(c)
+	s.PatchValue(downloader.RetryDelay, time.Millisecond)
 }
 
 func (s *suite) TearDownTest(c *gc.C) {
@@ -94,6 +99,267 @@ func (s *suite) TestStopDownload(c *gc.C) {
 	c.Assert(infos, gc.HasLen, 0)
 }
 
+func sha256Hex(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+func (s *suite) TestDownloadVerified(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:            s.URL("/archive.tgz"),
+		Dir:            c.MkDir(),
+		ExpectedSize:   7,
+		ExpectedSHA256: sha256Hex("archive"),
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.IsNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	assertFileContents(c, status.File, "archive")
+}
+
+func (s *suite) TestDownloadSHA256Mismatch(c *gc.C) {
+	tmp := c.MkDir()
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:            s.URL("/archive.tgz"),
+		Dir:            tmp,
+		ExpectedSHA256: sha256Hex("something else"),
+	})
+	status := <-d.Done()
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": expected sha256 ".*", got ".*"`)
+	infos, err := ioutil.ReadDir(tmp)
+	c.Assert(err, gc.IsNil)
+	c.Assert(infos, gc.HasLen, 0)
+}
+
+func (s *suite) TestDownloadSizeMismatch(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:          s.URL("/archive.tgz"),
+		Dir:          c.MkDir(),
+		ExpectedSize: 100,
+	})
+	status := <-d.Done()
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": expected 100 bytes, got 7`)
+}
+
+// flakyServer serves content, failing the first failures requests
+// after sending only part of the content. It honours range requests.
+type flakyServer struct {
+	content  string
+	failures int
+	ranges   []string
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	content := f.content
+	rangeHeader := req.Header.Get("Range")
+	f.ranges = append(f.ranges, rangeHeader)
+	status := http.StatusOK
+	if rangeHeader != "" {
+		var start int
+		fmt.Sscanf(rangeHeader, "bytes=%d-", &start)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		content = content[start:]
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	w.WriteHeader(status)
+	if f.failures > 0 {
+		f.failures--
+		// Writing less than the declared length makes the
+		// server drop the connection.
+		content = content[:len(content)/2]
+	}
+	w.Write([]byte(content))
+}
+
+func (s *suite) TestDownloadResumes(c *gc.C) {
+	flaky := &flakyServer{content: "0123456789abcdef", failures: 2}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:            server.URL + "/archive.tgz",
+		Dir:            c.MkDir(),
+		ExpectedSHA256: sha256Hex(flaky.content),
+		MaxRetries:     2,
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.IsNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	assertFileContents(c, status.File, flaky.content)
+	c.Assert(flaky.ranges, gc.DeepEquals, []string{"", "bytes=8-", "bytes=12-"})
+}
+
+func (s *suite) TestDownloadResumesShortFile(c *gc.C) {
+	// The server closes the first response cleanly, but
+	// before the whole file has been sent.
+	content := "0123456789abcdef"
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rangeHeader := req.Header.Get("Range")
+		ranges = append(ranges, rangeHeader)
+		if rangeHeader == "" {
+			w.Write([]byte(content[:4]))
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 4-%d/%d", len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[4:]))
+	}))
+	defer server.Close()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:            server.URL + "/archive.tgz",
+		Dir:            c.MkDir(),
+		ExpectedSize:   int64(len(content)),
+		ExpectedSHA256: sha256Hex(content),
+		MaxRetries:     1,
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.IsNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	assertFileContents(c, status.File, content)
+	c.Assert(ranges, gc.DeepEquals, []string{"", "bytes=4-"})
+}
+
+func (s *suite) TestDownloadDoesNotRetryOverrun(c *gc.C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:          server.URL + "/archive.tgz",
+		Dir:          c.MkDir(),
+		ExpectedSize: 3,
+		MaxRetries:   2,
+	})
+	status := <-d.Done()
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": expected 3 bytes, got 7`)
+	c.Assert(requests, gc.Equals, 1)
+}
+
+func (s *suite) TestStopDownloadInProgress(c *gc.C) {
+	// The server sends part of the file and then stalls
+	// until the test has finished.
+	sent := make(chan struct{})
+	finished := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Length", "16")
+		w.Write([]byte("01234567"))
+		w.(http.Flusher).Flush()
+		close(sent)
+		<-finished
+	}))
+	defer server.Close()
+	defer close(finished)
+	tmp := c.MkDir()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL: server.URL + "/archive.tgz",
+		Dir: tmp,
+	})
+	select {
+	case <-sent:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("download did not start")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+	// The stalled transfer is only abandoned once it is
+	// unblocked, at which point the partial file is removed.
+	finished <- struct{}{}
+	select {
+	case <-stopped:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("download was not abandoned")
+	}
+	select {
+	case status := <-d.Done():
+		c.Fatalf("received status %#v after stop", status)
+	default:
+	}
+	infos, err := ioutil.ReadDir(tmp)
+	c.Assert(err, gc.IsNil)
+	c.Assert(infos, gc.HasLen, 0)
+}
+
+func (s *suite) TestDownloadGivesUpAfterMaxRetries(c *gc.C) {
+	flaky := &flakyServer{content: "0123456789abcdef", failures: 3}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:        server.URL + "/archive.tgz",
+		Dir:        c.MkDir(),
+		MaxRetries: 2,
+	})
+	status := <-d.Done()
+	c.Assert(status.File, gc.IsNil)
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": unexpected EOF`)
+	c.Assert(flaky.ranges, gc.HasLen, 3)
+}
+
+func (s *suite) TestDownloadDoesNotRetryClientErrors(c *gc.C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		http.NotFound(w, req)
+	}))
+	defer server.Close()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:        server.URL + "/archive.tgz",
+		Dir:        c.MkDir(),
+		MaxRetries: 2,
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.ErrorMatches, `cannot download ".*": bad http response: 404 Not Found`)
+	c.Assert(requests, gc.Equals, 1)
+}
+
+func (s *suite) TestDownloadProgress(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("archive"))
+	progress := make(chan downloader.Progress, 10)
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:      s.URL("/archive.tgz"),
+		Dir:      c.MkDir(),
+		Progress: progress,
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.IsNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	var last downloader.Progress
+	for len(progress) > 0 {
+		last = <-progress
+	}
+	c.Assert(last, gc.Equals, downloader.Progress{Received: 7, Total: 7})
+}
+
+func (s *suite) TestDownloadRateLimit(c *gc.C) {
+	gitjujutesting.Server.Response(200, nil, []byte("0123456789abcdefghij"))
+	start := time.Now()
+	d := downloader.NewWithOptions(downloader.Options{
+		URL:       s.URL("/archive.tgz"),
+		Dir:       c.MkDir(),
+		RateLimit: 100,
+	})
+	status := <-d.Done()
+	c.Assert(status.Err, gc.IsNil)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	c.Assert(time.Since(start) >= 200*time.Millisecond, gc.Equals, true)
+	assertFileContents(c, status.File, "0123456789abcdefghij")
+}
+
 func assertFileContents(c *gc.C, f *os.File, expect string) {
 	got, err := ioutil.ReadAll(f)
 	c.Assert(err, gc.IsNil)
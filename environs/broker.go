@@ -37,6 +37,12 @@ type StartInstanceParams struct {
 	// this information to distribute instances for
 	// high availability.
 	DistributionGroup func() ([]instance.Id, error)
+
+	// CloneFrom, if non-empty, holds the id of an existing
+	// container instance that should be snapshotted and cloned
+	// to provide the new instance. It is only ever set for
+	// container brokers.
+	CloneFrom instance.Id
 }
 
 // TODO(wallyworld) - we want this in the environs/instance package but import loops
@@ -48,6 +54,9 @@ type InstanceBroker interface {
 	// unique within an environment, is used by juju to protect against the
 	// consequences of multiple instances being started with the same machine
 	// id.
+	//
+	// The provisioner may call StartInstance concurrently for different
+	// machines, so implementations must be safe for concurrent use.
 	StartInstance(args StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error)
 
 	// StopInstances shuts down the instances with the specified IDs.
@@ -26,10 +26,10 @@ import (
 	"github.com/juju/juju/environmentserver/authentication"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
 	coretools "github.com/juju/juju/tools"
-	"github.com/juju/juju/upstart"
 	"github.com/juju/juju/version"
 )
 
@@ -137,9 +137,14 @@ type MachineConfig struct {
 	// the addition of package management commands.
 	DisablePackageCommands bool
 
-	// MachineAgentServiceName is the Upstart service name for the Juju machine agent.
+	// MachineAgentServiceName is the init system service name for the Juju
+	// machine agent.
 	MachineAgentServiceName string
 
+	// InitSystem is the init system that runs the machine agent. If it
+	// is empty, the default init system for the machine's series is used.
+	InitSystem string
+
 	// ProxySettings define normal http, https and ftp proxies.
 	ProxySettings proxy.Settings
 
@@ -427,10 +432,18 @@ func (cfg *MachineConfig) addMachineAgentToBoot(c *cloudinit.Config, tag, machin
 	c.AddScripts(fmt.Sprintf("ln -s %v %s", cfg.Tools.Version, shquote(toolsDir)))
 
 	name := cfg.MachineAgentServiceName
-	conf := upstart.MachineAgentUpstartService(name, toolsDir, cfg.DataDir, cfg.LogDir, tag, machineId, nil)
-	cmds, err := conf.InstallCommands()
+	initSystem := cfg.InitSystem
+	if initSystem == "" {
+		initSystem = service.InitSystemForSeries(cfg.Tools.Version.Series)
+	}
+	conf := service.MachineAgentConf(toolsDir, cfg.DataDir, cfg.LogDir, tag, machineId, nil)
+	svc, err := service.New(initSystem, name, conf)
+	if err != nil {
+		return errors.Annotatef(err, "cannot make cloud-init script for the %s agent", tag)
+	}
+	cmds, err := svc.InstallCommands()
 	if err != nil {
-		return errors.Annotatef(err, "cannot make cloud-init upstart script for the %s agent", tag)
+		return errors.Annotatef(err, "cannot make cloud-init %s script for the %s agent", initSystem, tag)
 	}
 	c.AddRunCmd(cloudinit.LogProgressCmd("Starting Juju machine agent (%s)", name))
 	c.AddScripts(cmds...)
@@ -281,6 +281,43 @@ printf '%s\\n' '.*' > '/var/lib/juju/agents/machine-2-lxc-1/agent\.conf'
 ln -s 1\.2\.3-linux-amd64 '/var/lib/juju/tools/machine-2-lxc-1'
 cat >> /etc/init/jujud-machine-2-lxc-1\.conf << 'EOF'\\ndescription "juju machine-2-lxc-1 agent"\\nauthor "Juju Team <juju@lists\.ubuntu\.com>"\\nstart on runlevel \[2345\]\\nstop on runlevel \[!2345\]\\nrespawn\\nnormal exit 0\\n\\nlimit nofile 20000 20000\\n\\nscript\\n\\n  # Ensure log files are properly protected\\n  touch /var/log/juju/machine-2-lxc-1\.log\\n  chown syslog:syslog /var/log/juju/machine-2-lxc-1\.log\\n  chmod 0600 /var/log/juju/machine-2-lxc-1\.log\\n\\n  exec /var/lib/juju/tools/machine-2-lxc-1/jujud machine --data-dir '/var/lib/juju' --machine-id 2/lxc/1 --debug >> /var/log/juju/machine-2-lxc-1\.log 2>&1\\nend script\\nEOF\\n
 start jujud-machine-2-lxc-1
+`,
+	}, {
+		// non state server on a series that boots with systemd.
+		cfg: cloudinit.MachineConfig{
+			MachineId:          "99",
+			AuthorizedKeys:     "sshkey1",
+			AgentEnvironment:   map[string]string{agent.ProviderType: "dummy"},
+			DataDir:            environs.DataDir,
+			LogDir:             agent.DefaultLogDir,
+			Jobs:               normalMachineJobs,
+			CloudInitOutputLog: environs.CloudInitOutputLog,
+			Bootstrap:          false,
+			Tools:              newSimpleTools("1.2.3-vivid-amd64"),
+			MachineNonce:       "FAKE_NONCE",
+			MongoInfo: &authentication.MongoInfo{
+				Tag:      names.NewMachineTag("99"),
+				Password: "arble",
+				Info: mongo.Info{
+					Addrs:  []string{"state-addr.testing.invalid:12345"},
+					CACert: "CA CERT\n" + testing.CACert,
+				},
+			},
+			APIInfo: &api.Info{
+				Addrs:    []string{"state-addr.testing.invalid:54321"},
+				Tag:      names.NewMachineTag("99"),
+				Password: "bletch",
+				CACert:   "CA CERT\n" + testing.CACert,
+			},
+			MachineAgentServiceName: "jujud-machine-99",
+		},
+		inexactMatch: true,
+		expectScripts: `
+ln -s 1\.2\.3-vivid-amd64 '/var/lib/juju/tools/machine-99'
+cat >> /etc/systemd/system/jujud-machine-99\.service << 'EOF'\\n\[Unit\]\\nDescription=juju machine-99 agent\\n.*\\nLimitNOFILE=20000\\nExecStart=/bin/bash -c "touch '/var/log/juju/machine-99\.log'; .*; exec /var/lib/juju/tools/machine-99/jujud machine --data-dir '/var/lib/juju' --machine-id 99 --debug >> '/var/log/juju/machine-99\.log' 2>&1"\\n.*EOF\\n
+systemctl daemon-reload
+systemctl enable jujud-machine-99\.service
+systemctl start jujud-machine-99\.service
 `,
 	}, {
 		// hostname verification disabled.
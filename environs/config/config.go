@@ -21,7 +21,9 @@ import (
 	"github.com/juju/utils/proxy"
 
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/schedule"
 	"github.com/juju/juju/version"
 )
 
@@ -59,6 +61,15 @@ const (
 	// refresh addresses from the provider each time.
 	DefaultBootstrapSSHAddressesDelay int = 10
 
+	// DefaultProvisionerParallelism is the number of instances
+	// a provisioner starts at the same time.
+	DefaultProvisionerParallelism int = 8
+
+	// DefaultProvisionerRetryCount is the number of times a
+	// provisioner attempts to start an instance for a machine
+	// before giving up.
+	DefaultProvisionerRetryCount int = 5
+
 	// fallbackLtsSeries is the latest LTS series we'll use, if we fail to
 	// obtain this information from the system.
 	fallbackLtsSeries string = "precise"
@@ -322,6 +333,36 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	if v, ok := cfg.defined["provisioner-parallelism"].(int); ok && v < 1 {
+		return fmt.Errorf("provisioner-parallelism must be at least 1, got %d", v)
+	}
+	if v, ok := cfg.defined["provisioner-retry-count"].(int); ok && v < 1 {
+		return fmt.Errorf("provisioner-retry-count must be at least 1, got %d", v)
+	}
+
+	if v, ok := cfg.defined["maintenance-windows"].(string); ok {
+		if _, err := schedule.ParseList(v); err != nil {
+			return errors.Annotate(err, "invalid maintenance-windows")
+		}
+	}
+	if v, ok := cfg.defined["maintenance-timezone"].(string); ok {
+		if _, err := time.LoadLocation(v); err != nil {
+			return fmt.Errorf("invalid maintenance-timezone %q: %v", v, err)
+		}
+	}
+	if v, ok := cfg.defined["maintenance-open-until"].(string); ok {
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return fmt.Errorf("invalid maintenance-open-until %q: expected an RFC 3339 time", v)
+		}
+	}
+
+	if err := cfg.ToolsMetadataAuth().Validate(); err != nil {
+		return errors.Annotate(err, "invalid tools metadata credentials")
+	}
+	if err := cfg.ImageMetadataAuth().Validate(); err != nil {
+		return errors.Annotate(err, "invalid image metadata credentials")
+	}
+
 	// Check firewall mode.
 	if mode := cfg.FirewallMode(); mode != FwInstance && mode != FwGlobal {
 		return fmt.Errorf("invalid firewall mode in environment configuration: %q", mode)
@@ -662,6 +703,49 @@ func (c *Config) ImageMetadataURL() (string, bool) {
 	return "", false
 }
 
+// ToolsMetadataAuth returns the credentials used to access the
+// tools-metadata-url.
+func (c *Config) ToolsMetadataAuth() simplestreams.URLAuth {
+	return c.metadataAuth("tools-metadata")
+}
+
+// ImageMetadataAuth returns the credentials used to access the
+// image-metadata-url.
+func (c *Config) ImageMetadataAuth() simplestreams.URLAuth {
+	return c.metadataAuth("image-metadata")
+}
+
+// MetadataSecretAttrs holds the names of the attributes that hold
+// credentials for the tools and image metadata sources. Agents that
+// cannot read secrets are given an environment configuration without
+// them. The client certificates are included because they are only
+// valid together with their keys.
+var MetadataSecretAttrs = []string{
+	"tools-metadata-password",
+	"tools-metadata-token",
+	"tools-metadata-client-cert",
+	"tools-metadata-client-key",
+	"image-metadata-password",
+	"image-metadata-token",
+	"image-metadata-client-cert",
+	"image-metadata-client-key",
+}
+
+func (c *Config) metadataAuth(prefix string) simplestreams.URLAuth {
+	username, _ := c.defined[prefix+"-username"].(string)
+	password, _ := c.defined[prefix+"-password"].(string)
+	token, _ := c.defined[prefix+"-token"].(string)
+	clientCert, _ := c.defined[prefix+"-client-cert"].(string)
+	clientKey, _ := c.defined[prefix+"-client-key"].(string)
+	return simplestreams.URLAuth{
+		Username:   username,
+		Password:   password,
+		Token:      token,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+	}
+}
+
 // Development returns whether the environment is in development mode.
 func (c *Config) Development() bool {
 	return c.defined["development"].(bool)
@@ -698,6 +782,66 @@ func (c *Config) ProvisionerSafeMode() bool {
 	return v
 }
 
+// ProvisionerParallelism returns the maximum number of instances
+// the provisioner starts at the same time.
+func (c *Config) ProvisionerParallelism() int {
+	if v, ok := c.defined["provisioner-parallelism"].(int); ok && v > 0 {
+		return v
+	}
+	return DefaultProvisionerParallelism
+}
+
+// ProvisionerRetryCount returns the number of times the provisioner
+// attempts to start an instance for a machine before setting the
+// machine's status to error.
+func (c *Config) ProvisionerRetryCount() int {
+	if v, ok := c.defined["provisioner-retry-count"].(int); ok && v > 0 {
+		return v
+	}
+	return DefaultProvisionerRetryCount
+}
+
+// MaintenanceWindows returns the recurring windows within which
+// automated maintenance operations, such as charm upgrades and
+// cleanups, may run. If there are none, they may run at any time.
+func (c *Config) MaintenanceWindows() []*schedule.Window {
+	windows, _ := schedule.ParseList(c.asString("maintenance-windows"))
+	return windows
+}
+
+// MaintenanceTimezone returns the location in which maintenance
+// windows are interpreted, which is UTC unless specified.
+func (c *Config) MaintenanceTimezone() *time.Location {
+	if v, ok := c.defined["maintenance-timezone"].(string); ok {
+		if loc, err := time.LoadLocation(v); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// MaintenanceOpenUntil returns the time until which an ad-hoc
+// maintenance window is open, and whether one has been opened.
+func (c *Config) MaintenanceOpenUntil() (time.Time, bool) {
+	v, ok := c.defined["maintenance-open-until"].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, err == nil
+}
+
+// MaintenancePlan returns the plan that decides when automated
+// maintenance operations may run.
+func (c *Config) MaintenancePlan() schedule.Plan {
+	openUntil, _ := c.MaintenanceOpenUntil()
+	return schedule.Plan{
+		Windows:   c.MaintenanceWindows(),
+		Location:  c.MaintenanceTimezone(),
+		OpenUntil: openUntil,
+	}
+}
+
 // ImageStream returns the simplestreams stream
 // used to identify which image ids to search
 // when starting an instance.
@@ -770,45 +914,60 @@ func (c *Config) Apply(attrs map[string]interface{}) (*Config, error) {
 }
 
 var fields = schema.Fields{
-	"type":                      schema.String(),
-	"name":                      schema.String(),
-	"default-series":            schema.String(),
-	"tools-metadata-url":        schema.String(),
-	"image-metadata-url":        schema.String(),
-	"image-stream":              schema.String(),
-	"authorized-keys":           schema.String(),
-	"authorized-keys-path":      schema.String(),
-	"firewall-mode":             schema.String(),
-	"agent-version":             schema.String(),
-	"development":               schema.Bool(),
-	"admin-secret":              schema.String(),
-	"ca-cert":                   schema.String(),
-	"ca-cert-path":              schema.String(),
-	"ca-private-key":            schema.String(),
-	"ca-private-key-path":       schema.String(),
-	"ssl-hostname-verification": schema.Bool(),
-	"state-port":                schema.ForceInt(),
-	"api-port":                  schema.ForceInt(),
-	"syslog-port":               schema.ForceInt(),
-	"rsyslog-ca-cert":           schema.String(),
-	"logging-config":            schema.String(),
-	"charm-store-auth":          schema.String(),
-	"provisioner-safe-mode":     schema.Bool(),
-	"http-proxy":                schema.String(),
-	"https-proxy":               schema.String(),
-	"ftp-proxy":                 schema.String(),
-	"no-proxy":                  schema.String(),
-	"apt-http-proxy":            schema.String(),
-	"apt-https-proxy":           schema.String(),
-	"apt-ftp-proxy":             schema.String(),
-	"bootstrap-timeout":         schema.ForceInt(),
-	"bootstrap-retry-delay":     schema.ForceInt(),
-	"bootstrap-addresses-delay": schema.ForceInt(),
-	"test-mode":                 schema.Bool(),
-	"proxy-ssh":                 schema.Bool(),
-	"lxc-clone":                 schema.Bool(),
-	"lxc-clone-aufs":            schema.Bool(),
-	"prefer-ipv6":               schema.Bool(),
+	"type":                       schema.String(),
+	"name":                       schema.String(),
+	"default-series":             schema.String(),
+	"tools-metadata-url":         schema.String(),
+	"image-metadata-url":         schema.String(),
+	"image-stream":               schema.String(),
+	"tools-metadata-username":    schema.String(),
+	"tools-metadata-password":    schema.String(),
+	"tools-metadata-token":       schema.String(),
+	"tools-metadata-client-cert": schema.String(),
+	"tools-metadata-client-key":  schema.String(),
+	"image-metadata-username":    schema.String(),
+	"image-metadata-password":    schema.String(),
+	"image-metadata-token":       schema.String(),
+	"image-metadata-client-cert": schema.String(),
+	"image-metadata-client-key":  schema.String(),
+	"authorized-keys":            schema.String(),
+	"authorized-keys-path":       schema.String(),
+	"firewall-mode":              schema.String(),
+	"agent-version":              schema.String(),
+	"development":                schema.Bool(),
+	"admin-secret":               schema.String(),
+	"ca-cert":                    schema.String(),
+	"ca-cert-path":               schema.String(),
+	"ca-private-key":             schema.String(),
+	"ca-private-key-path":        schema.String(),
+	"ssl-hostname-verification":  schema.Bool(),
+	"state-port":                 schema.ForceInt(),
+	"api-port":                   schema.ForceInt(),
+	"syslog-port":                schema.ForceInt(),
+	"rsyslog-ca-cert":            schema.String(),
+	"logging-config":             schema.String(),
+	"charm-store-auth":           schema.String(),
+	"provisioner-safe-mode":      schema.Bool(),
+	"provisioner-parallelism":    schema.ForceInt(),
+	"provisioner-retry-count":    schema.ForceInt(),
+	"maintenance-windows":        schema.String(),
+	"maintenance-timezone":       schema.String(),
+	"maintenance-open-until":     schema.String(),
+	"http-proxy":                 schema.String(),
+	"https-proxy":                schema.String(),
+	"ftp-proxy":                  schema.String(),
+	"no-proxy":                   schema.String(),
+	"apt-http-proxy":             schema.String(),
+	"apt-https-proxy":            schema.String(),
+	"apt-ftp-proxy":              schema.String(),
+	"bootstrap-timeout":          schema.ForceInt(),
+	"bootstrap-retry-delay":      schema.ForceInt(),
+	"bootstrap-addresses-delay":  schema.ForceInt(),
+	"test-mode":                  schema.Bool(),
+	"proxy-ssh":                  schema.Bool(),
+	"lxc-clone":                  schema.Bool(),
+	"lxc-clone-aufs":             schema.Bool(),
+	"prefer-ipv6":                schema.Bool(),
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     schema.String(),
@@ -824,26 +983,41 @@ var fields = schema.Fields{
 // but some fields listed as optional here are actually mandatory
 // with NoDefaults and are checked at the later Validate stage.
 var alwaysOptional = schema.Defaults{
-	"agent-version":             schema.Omit,
-	"ca-cert":                   schema.Omit,
-	"authorized-keys":           schema.Omit,
-	"authorized-keys-path":      schema.Omit,
-	"ca-cert-path":              schema.Omit,
-	"ca-private-key-path":       schema.Omit,
-	"logging-config":            schema.Omit,
-	"provisioner-safe-mode":     schema.Omit,
-	"bootstrap-timeout":         schema.Omit,
-	"bootstrap-retry-delay":     schema.Omit,
-	"bootstrap-addresses-delay": schema.Omit,
-	"rsyslog-ca-cert":           schema.Omit,
-	"http-proxy":                schema.Omit,
-	"https-proxy":               schema.Omit,
-	"ftp-proxy":                 schema.Omit,
-	"no-proxy":                  schema.Omit,
-	"apt-http-proxy":            schema.Omit,
-	"apt-https-proxy":           schema.Omit,
-	"apt-ftp-proxy":             schema.Omit,
-	"lxc-clone":                 schema.Omit,
+	"agent-version":              schema.Omit,
+	"ca-cert":                    schema.Omit,
+	"authorized-keys":            schema.Omit,
+	"authorized-keys-path":       schema.Omit,
+	"ca-cert-path":               schema.Omit,
+	"ca-private-key-path":        schema.Omit,
+	"logging-config":             schema.Omit,
+	"provisioner-safe-mode":      schema.Omit,
+	"provisioner-parallelism":    schema.Omit,
+	"provisioner-retry-count":    schema.Omit,
+	"maintenance-windows":        schema.Omit,
+	"maintenance-timezone":       schema.Omit,
+	"maintenance-open-until":     schema.Omit,
+	"bootstrap-timeout":          schema.Omit,
+	"bootstrap-retry-delay":      schema.Omit,
+	"bootstrap-addresses-delay":  schema.Omit,
+	"rsyslog-ca-cert":            schema.Omit,
+	"http-proxy":                 schema.Omit,
+	"https-proxy":                schema.Omit,
+	"ftp-proxy":                  schema.Omit,
+	"no-proxy":                   schema.Omit,
+	"apt-http-proxy":             schema.Omit,
+	"apt-https-proxy":            schema.Omit,
+	"apt-ftp-proxy":              schema.Omit,
+	"lxc-clone":                  schema.Omit,
+	"tools-metadata-username":    schema.Omit,
+	"tools-metadata-password":    schema.Omit,
+	"tools-metadata-token":       schema.Omit,
+	"tools-metadata-client-cert": schema.Omit,
+	"tools-metadata-client-key":  schema.Omit,
+	"image-metadata-username":    schema.Omit,
+	"image-metadata-password":    schema.Omit,
+	"image-metadata-token":       schema.Omit,
+	"image-metadata-client-cert": schema.Omit,
+	"image-metadata-client-key":  schema.Omit,
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     "",
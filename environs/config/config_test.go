@@ -6,6 +6,7 @@ package config_test
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	stdtesting "testing"
 	"time"
 
@@ -501,6 +502,101 @@ var configTests = []configTest{
 			"provisioner-safe-mode": "yes please",
 		},
 		err: `provisioner-safe-mode: expected bool, got string\("yes please"\)`,
+	}, {
+		about:       "provisioner parallelism and retry count",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                    "my-type",
+			"name":                    "my-name",
+			"provisioner-parallelism": 2,
+			"provisioner-retry-count": 10,
+		},
+	}, {
+		about:       "provisioner-parallelism invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                    "my-type",
+			"name":                    "my-name",
+			"provisioner-parallelism": 0,
+		},
+		err: `provisioner-parallelism must be at least 1, got 0`,
+	}, {
+		about:       "provisioner-retry-count invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                    "my-type",
+			"name":                    "my-name",
+			"provisioner-retry-count": "lots",
+		},
+		err: `provisioner-retry-count: expected number, got string\("lots"\)`,
+	}, {
+		about:       "maintenance windows",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"maintenance-windows":    "0 2 * * 6 4h; 30 4 * * * 1h",
+			"maintenance-timezone":   "Europe/London",
+			"maintenance-open-until": "2014-07-01T12:00:00Z",
+		},
+	}, {
+		about:       "maintenance-windows invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"maintenance-windows": "0 2 * * 6",
+		},
+		err: `invalid maintenance-windows: invalid window "0 2 \* \* 6": expected 6 fields, got 5`,
+	}, {
+		about:       "maintenance-timezone invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"maintenance-timezone": "Nowhere/Special",
+		},
+		err: `invalid maintenance-timezone "Nowhere/Special": .*`,
+	}, {
+		about:       "maintenance-open-until invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"maintenance-open-until": "tomorrow",
+		},
+		err: `invalid maintenance-open-until "tomorrow": expected an RFC 3339 time`,
+	}, {
+		about:       "Metadata credentials",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                       "my-type",
+			"name":                       "my-name",
+			"tools-metadata-username":    "tools-user",
+			"tools-metadata-password":    "tools-password",
+			"image-metadata-token":       "image-token",
+			"image-metadata-client-cert": testing.ServerCert,
+			"image-metadata-client-key":  testing.ServerKey,
+		},
+	}, {
+		about:       "Metadata credentials with both token and username",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                    "my-type",
+			"name":                    "my-name",
+			"tools-metadata-username": "tools-user",
+			"tools-metadata-token":    "tools-token",
+		},
+		err: `invalid tools metadata credentials: cannot use both token and username authentication`,
+	}, {
+		about:       "Metadata client certificate without key",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                       "my-type",
+			"name":                       "my-name",
+			"image-metadata-client-cert": testing.ServerCert,
+		},
+		err: `invalid image metadata credentials: client certificate and key must be specified together`,
 	}, {
 		about:       "default image stream",
 		useDefaults: config.UseDefaults,
@@ -868,6 +964,13 @@ func (s *ConfigSuite) TestConfigEmptyCertFiles(c *gc.C) {
 	}
 }
 
+// stringAttr returns the string value of the named attribute,
+// or the empty string if it is not set.
+func stringAttr(attrs testing.Attrs, name string) string {
+	v, _ := attrs[name].(string)
+	return v
+}
+
 func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	cfg, err := config.New(test.useDefaults, test.attrs)
 	if test.err != "" {
@@ -981,6 +1084,51 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	} else {
 		c.Assert(cfg.ProvisionerSafeMode(), gc.Equals, false)
 	}
+	if v, ok := test.attrs["provisioner-parallelism"]; ok {
+		c.Assert(cfg.ProvisionerParallelism(), gc.Equals, v)
+	} else {
+		c.Assert(cfg.ProvisionerParallelism(), gc.Equals, config.DefaultProvisionerParallelism)
+	}
+	if v, ok := test.attrs["provisioner-retry-count"]; ok {
+		c.Assert(cfg.ProvisionerRetryCount(), gc.Equals, v)
+	} else {
+		c.Assert(cfg.ProvisionerRetryCount(), gc.Equals, config.DefaultProvisionerRetryCount)
+	}
+	windows := cfg.MaintenanceWindows()
+	if v, ok := test.attrs["maintenance-windows"]; ok {
+		c.Assert(windows, gc.HasLen, len(strings.Split(v.(string), ";")))
+	} else {
+		c.Assert(windows, gc.HasLen, 0)
+	}
+	if v, ok := test.attrs["maintenance-timezone"]; ok {
+		c.Assert(cfg.MaintenanceTimezone().String(), gc.Equals, v)
+	} else {
+		c.Assert(cfg.MaintenanceTimezone(), gc.Equals, time.UTC)
+	}
+	if v, ok := test.attrs["maintenance-open-until"]; ok {
+		openUntil, ok := cfg.MaintenanceOpenUntil()
+		c.Assert(ok, gc.Equals, true)
+		c.Assert(openUntil.Format(time.RFC3339), gc.Equals, v)
+	} else {
+		_, ok := cfg.MaintenanceOpenUntil()
+		c.Assert(ok, gc.Equals, false)
+	}
+	plan := cfg.MaintenancePlan()
+	c.Assert(plan.Windows, gc.DeepEquals, windows)
+	c.Assert(plan.Location.String(), gc.Equals, cfg.MaintenanceTimezone().String())
+	openUntil, _ := cfg.MaintenanceOpenUntil()
+	c.Assert(plan.OpenUntil.Equal(openUntil), gc.Equals, true)
+	for _, prefix := range []string{"tools-metadata", "image-metadata"} {
+		auth := cfg.ToolsMetadataAuth()
+		if prefix == "image-metadata" {
+			auth = cfg.ImageMetadataAuth()
+		}
+		c.Assert(auth.Username, gc.Equals, stringAttr(test.attrs, prefix+"-username"))
+		c.Assert(auth.Password, gc.Equals, stringAttr(test.attrs, prefix+"-password"))
+		c.Assert(auth.Token, gc.Equals, stringAttr(test.attrs, prefix+"-token"))
+		c.Assert(auth.ClientCert, gc.Equals, stringAttr(test.attrs, prefix+"-client-cert"))
+		c.Assert(auth.ClientKey, gc.Equals, stringAttr(test.attrs, prefix+"-client-key"))
+	}
 	sshOpts := cfg.BootstrapSSHOpts()
 	test.assertDuration(
 		c,
@@ -0,0 +1,145 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+// BackendConfigFile is the name of the file in the juju home
+// directory that selects the configstore backend. If it does not
+// exist, environment information is stored on disk.
+const BackendConfigFile = "configstore.yaml"
+
+// BackendConfig holds the contents of the backend configuration file.
+type BackendConfig struct {
+	// Backend names the backend to use: "disk", "http" or "keyring",
+	// or any other backend registered with RegisterBackend.
+	Backend string `yaml:"backend"`
+
+	// URL holds the base URL of the "http" backend.
+	URL string `yaml:"url,omitempty"`
+
+	// AuthToken holds an optional bearer token for the "http" backend.
+	AuthToken string `yaml:"auth-token,omitempty"`
+
+	// Path holds the location of the "keyring" backend file.
+	// It defaults to environments.keyring in the juju home directory.
+	Path string `yaml:"path,omitempty"`
+}
+
+// BackendFunc opens a Storage given the juju home directory
+// and the backend configuration.
+type BackendFunc func(jujuHome string, cfg BackendConfig) (Storage, error)
+
+var (
+	backendsMutex sync.Mutex
+	backends      = map[string]BackendFunc{
+		"disk":    openDisk,
+		"http":    openHTTP,
+		"keyring": openKeyring,
+	}
+)
+
+// RegisterBackend registers a new configstore backend with the
+// given name. It panics if a backend with that name is
+// already registered.
+func RegisterBackend(name string, open BackendFunc) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	if backends[name] != nil {
+		panic(fmt.Errorf("configstore: duplicate backend name %q", name))
+	}
+	backends[name] = open
+}
+
+// Backends returns the names of all registered backends.
+func Backends() []string {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the environment config storage selected by the
+// backend configuration file in JujuHome, or disk-based storage
+// rooted at JujuHome if there is no such file.
+func Default() (Storage, error) {
+	return Open(osenv.JujuHome())
+}
+
+// Open returns the environment config storage selected by the
+// backend configuration file in the given juju home directory.
+func Open(jujuHome string) (Storage, error) {
+	cfg, err := ReadBackendConfig(jujuHome)
+	if err != nil {
+		return nil, err
+	}
+	backendsMutex.Lock()
+	open := backends[cfg.Backend]
+	backendsMutex.Unlock()
+	if open == nil {
+		return nil, fmt.Errorf("unknown configstore backend %q", cfg.Backend)
+	}
+	return open(jujuHome, cfg)
+}
+
+// ReadBackendConfig reads the backend configuration file from the
+// given juju home directory. If the file does not exist, the
+// configuration for the disk backend is returned.
+func ReadBackendConfig(jujuHome string) (BackendConfig, error) {
+	cfg := BackendConfig{Backend: "disk"}
+	path := filepath.Join(jujuHome, BackendConfigFile)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := goyaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, errors.Annotatef(err, "cannot parse %q", path)
+	}
+	if cfg.Backend == "" {
+		return cfg, fmt.Errorf("no backend specified in %q", path)
+	}
+	return cfg, nil
+}
+
+func openDisk(jujuHome string, cfg BackendConfig) (Storage, error) {
+	return NewDisk(jujuHome)
+}
+
+func openHTTP(jujuHome string, cfg BackendConfig) (Storage, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("http configstore backend requires a url")
+	}
+	return NewHTTP(cfg.URL, cfg.AuthToken)
+}
+
+func openKeyring(jujuHome string, cfg BackendConfig) (Storage, error) {
+	path := cfg.Path
+	if path == "" {
+		path = filepath.Join(jujuHome, "environments.keyring")
+	}
+	passphrase := os.Getenv(osenv.JujuKeyringPassphraseEnvKey)
+	if passphrase == "" {
+		return nil, fmt.Errorf("keyring configstore backend requires $%s to be set", osenv.JujuKeyringPassphraseEnvKey)
+	}
+	return NewKeyring(path, passphrase)
+}
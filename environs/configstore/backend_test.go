@@ -0,0 +1,115 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	storetesting "github.com/juju/juju/environs/configstore/testing"
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type backendSuite struct {
+	testing.BaseSuite
+	home string
+}
+
+var _ = gc.Suite(&backendSuite{})
+
+func (s *backendSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.home = c.MkDir()
+}
+
+func (s *backendSuite) writeConfig(c *gc.C, config string) {
+	err := ioutil.WriteFile(filepath.Join(s.home, configstore.BackendConfigFile), []byte(config), 0600)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *backendSuite) TestDefaultsToDisk(c *gc.C) {
+	cfg, err := configstore.ReadBackendConfig(s.home)
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg, gc.Equals, configstore.BackendConfig{Backend: "disk"})
+
+	store, err := configstore.Open(s.home)
+	c.Assert(err, gc.IsNil)
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Location(), gc.Equals, `file "`+filepath.Join(s.home, "environments", "someenv.jenv")+`"`)
+}
+
+func (s *backendSuite) TestBackends(c *gc.C) {
+	// Other tests may register further backends, which sort later.
+	c.Assert(configstore.Backends()[:3], gc.DeepEquals, []string{"disk", "http", "keyring"})
+}
+
+func (s *backendSuite) TestOpenHTTP(c *gc.C) {
+	server := storetesting.NewKVServer()
+	defer server.Close()
+	s.writeConfig(c, "backend: http\nurl: "+server.URL+"\n")
+
+	store, err := configstore.Open(s.home)
+	c.Assert(err, gc.IsNil)
+	_, err = store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	_, ok := server.Entries()["someenv"]
+	c.Assert(ok, gc.Equals, true)
+}
+
+func (s *backendSuite) TestOpenKeyring(c *gc.C) {
+	s.writeConfig(c, "backend: keyring\n")
+	_, err := configstore.Open(s.home)
+	c.Assert(err, gc.ErrorMatches, `keyring configstore backend requires \$JUJU_KEYRING_PASSPHRASE to be set`)
+
+	s.PatchEnvironment(osenv.JujuKeyringPassphraseEnvKey, "passphrase")
+	store, err := configstore.Open(s.home)
+	c.Assert(err, gc.IsNil)
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Location(), gc.Equals, `keyring "`+filepath.Join(s.home, "environments.keyring")+`"`)
+}
+
+func (s *backendSuite) TestOpenErrors(c *gc.C) {
+	for i, test := range []struct {
+		config string
+		err    string
+	}{{
+		config: "backend: unknown\n",
+		err:    `unknown configstore backend "unknown"`,
+	}, {
+		config: "url: http://example.com\n",
+		err:    `no backend specified in ".*configstore.yaml"`,
+	}, {
+		config: "backend: http\n",
+		err:    "http configstore backend requires a url",
+	}, {
+		config: "backend: [",
+		err:    `cannot parse ".*configstore.yaml": .*`,
+	}} {
+		c.Logf("test %d", i)
+		s.writeConfig(c, test.config)
+		_, err := configstore.Open(s.home)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *backendSuite) TestRegisterBackend(c *gc.C) {
+	mem := configstore.NewMem()
+	configstore.RegisterBackend("test-mem", func(string, configstore.BackendConfig) (configstore.Storage, error) {
+		return mem, nil
+	})
+	s.writeConfig(c, "backend: test-mem\n")
+	store, err := configstore.Open(s.home)
+	c.Assert(err, gc.IsNil)
+	c.Assert(store, gc.Equals, mem)
+
+	c.Assert(func() {
+		configstore.RegisterBackend("test-mem", nil)
+	}, gc.PanicMatches, `configstore: duplicate backend name "test-mem"`)
+}
@@ -0,0 +1,195 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"code.google.com/p/go.crypto/nacl/secretbox"
+	"code.google.com/p/go.crypto/scrypt"
+	"github.com/juju/errors"
+	"launchpad.net/goyaml"
+)
+
+// ConnectionBundle holds everything another operator needs to
+// connect to an environment's API server. Unlike a .jenv file, it
+// never holds the bootstrap configuration, so secrets such as the
+// admin-secret and provider credentials are never exposed.
+type ConnectionBundle struct {
+	User         string   `json:"user" yaml:"user"`
+	Password     string   `json:"password,omitempty" yaml:"password,omitempty"`
+	EnvironUUID  string   `json:"environ-uuid,omitempty" yaml:"environ-uuid,omitempty"`
+	StateServers []string `json:"state-servers" yaml:"state-servers"`
+	CACert       string   `json:"ca-cert" yaml:"ca-cert"`
+}
+
+// encryptedBundle is the serialized form of a passphrase
+// protected ConnectionBundle.
+type encryptedBundle struct {
+	Encrypted string `yaml:"encrypted-bundle"`
+}
+
+// ErrPassphraseRequired is returned by ParseConnectionBundle when
+// the bundle is encrypted and no passphrase was supplied.
+var ErrPassphraseRequired = errors.New("connection bundle is encrypted; a passphrase is required")
+
+// NewConnectionBundle returns a bundle holding the API endpoint
+// of the given environment information and the user name from its
+// credentials. The password is deliberately left empty; callers
+// wishing to share credentials must set it explicitly.
+func NewConnectionBundle(info EnvironInfo) *ConnectionBundle {
+	endpoint := info.APIEndpoint()
+	return &ConnectionBundle{
+		User:         info.APICredentials().User,
+		EnvironUUID:  endpoint.EnvironUUID,
+		StateServers: endpoint.Addresses,
+		CACert:       endpoint.CACert,
+	}
+}
+
+// Validate checks that the bundle holds enough information
+// to connect to an environment.
+func (b *ConnectionBundle) Validate() error {
+	if len(b.StateServers) == 0 {
+		return fmt.Errorf("connection bundle has no state server addresses")
+	}
+	if b.CACert == "" {
+		return fmt.Errorf("connection bundle has no CA certificate")
+	}
+	if b.User == "" {
+		return fmt.Errorf("connection bundle has no user")
+	}
+	return nil
+}
+
+// Marshal returns the serialized form of the bundle. If passphrase
+// is non-empty, the bundle is encrypted with a key derived from it.
+func (b *ConnectionBundle) Marshal(passphrase string) ([]byte, error) {
+	data, err := goyaml.Marshal(b)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot marshal connection bundle")
+	}
+	if passphrase == "" {
+		return data, nil
+	}
+	sealed, err := seal(passphrase, data)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot encrypt connection bundle")
+	}
+	return goyaml.Marshal(encryptedBundle{
+		Encrypted: base64.StdEncoding.EncodeToString(sealed),
+	})
+}
+
+// ParseConnectionBundle parses a bundle produced by Marshal,
+// decrypting it with the given passphrase if necessary.
+func ParseConnectionBundle(data []byte, passphrase string) (*ConnectionBundle, error) {
+	var enc encryptedBundle
+	if err := goyaml.Unmarshal(data, &enc); err != nil {
+		return nil, errors.Annotate(err, "cannot parse connection bundle")
+	}
+	if enc.Encrypted != "" {
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		sealed, err := base64.StdEncoding.DecodeString(enc.Encrypted)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot decode connection bundle")
+		}
+		if data, err = unseal(passphrase, sealed); err != nil {
+			return nil, errors.Annotate(err, "cannot decrypt connection bundle")
+		}
+	}
+	var b ConnectionBundle
+	if err := goyaml.Unmarshal(data, &b); err != nil {
+		return nil, errors.Annotate(err, "cannot parse connection bundle")
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ImportConnectionBundle creates new environment information
+// with the given name in the store, holding the endpoint and
+// credentials from the bundle. It returns
+// ErrEnvironInfoAlreadyExists if the name is already in use.
+func ImportConnectionBundle(store Storage, envName string, b *ConnectionBundle) (EnvironInfo, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	info, err := store.CreateInfo(envName)
+	if err != nil {
+		return nil, err
+	}
+	info.SetAPIEndpoint(APIEndpoint{
+		Addresses:   b.StateServers,
+		CACert:      b.CACert,
+		EnvironUUID: b.EnvironUUID,
+	})
+	info.SetAPICredentials(APICredentials{
+		User:     b.User,
+		Password: b.Password,
+	})
+	if err := info.Write(); err != nil {
+		info.Destroy()
+		return nil, errors.Annotatef(err, "cannot write environment info for %q", envName)
+	}
+	return info, nil
+}
+
+const (
+	saltSize  = 16
+	nonceSize = 24
+	keySize   = 32
+)
+
+// deriveKey derives a secretbox key from the passphrase and salt.
+func deriveKey(passphrase string, salt []byte) (*[keySize]byte, error) {
+	k, err := scrypt.Key([]byte(passphrase), salt, 16384, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+	var key [keySize]byte
+	copy(key[:], k)
+	return &key, nil
+}
+
+// seal encrypts data with a key derived from passphrase. The
+// result holds the random salt and nonce followed by the
+// authenticated ciphertext.
+func seal(passphrase string, data []byte) ([]byte, error) {
+	out := make([]byte, saltSize+nonceSize)
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, out[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], out[saltSize:])
+	return secretbox.Seal(out, data, &nonce, key), nil
+}
+
+// unseal reverses seal.
+func unseal(passphrase string, sealed []byte) ([]byte, error) {
+	if len(sealed) < saltSize+nonceSize+secretbox.Overhead {
+		return nil, fmt.Errorf("encrypted data too short")
+	}
+	key, err := deriveKey(passphrase, sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], sealed[saltSize:saltSize+nonceSize])
+	data, ok := secretbox.Open(nil, sealed[saltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("invalid passphrase or corrupt data")
+	}
+	return data, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/testing"
+)
+
+type bundleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&bundleSuite{})
+
+func (s *bundleSuite) newInfo(c *gc.C, store configstore.Storage) configstore.EnvironInfo {
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	info.SetBootstrapConfig(map[string]interface{}{"admin-secret": "very secret"})
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"example.com:17070"},
+		CACert:      "a cert",
+		EnvironUUID: "dead-beef",
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "admin password",
+	})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+	return info
+}
+
+func (s *bundleSuite) TestNewConnectionBundleOmitsSecrets(c *gc.C) {
+	info := s.newInfo(c, configstore.NewMem())
+	b := configstore.NewConnectionBundle(info)
+	c.Assert(b, jc.DeepEquals, &configstore.ConnectionBundle{
+		User:         "admin",
+		EnvironUUID:  "dead-beef",
+		StateServers: []string{"example.com:17070"},
+		CACert:       "a cert",
+	})
+	data, err := b.Marshal("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Not(jc.Contains), "secret")
+	c.Assert(string(data), gc.Not(jc.Contains), "password")
+}
+
+func (s *bundleSuite) TestRoundTrip(c *gc.C) {
+	b := configstore.NewConnectionBundle(s.newInfo(c, configstore.NewMem()))
+	b.User = "bob"
+	b.Password = "bob's password"
+	data, err := b.Marshal("")
+	c.Assert(err, gc.IsNil)
+	b1, err := configstore.ParseConnectionBundle(data, "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(b1, jc.DeepEquals, b)
+}
+
+func (s *bundleSuite) TestEncryptedRoundTrip(c *gc.C) {
+	b := configstore.NewConnectionBundle(s.newInfo(c, configstore.NewMem()))
+	b.Password = "hidden"
+	data, err := b.Marshal("open sesame")
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Not(jc.Contains), "example.com")
+	c.Assert(string(data), gc.Not(jc.Contains), "hidden")
+
+	_, err = configstore.ParseConnectionBundle(data, "")
+	c.Assert(err, gc.Equals, configstore.ErrPassphraseRequired)
+	_, err = configstore.ParseConnectionBundle(data, "wrong")
+	c.Assert(err, gc.ErrorMatches, "cannot decrypt connection bundle: invalid passphrase or corrupt data")
+
+	b1, err := configstore.ParseConnectionBundle(data, "open sesame")
+	c.Assert(err, gc.IsNil)
+	c.Assert(b1, jc.DeepEquals, b)
+}
+
+func (s *bundleSuite) TestParseInvalid(c *gc.C) {
+	for i, test := range []struct {
+		data string
+		err  string
+	}{{
+		data: "user: bob\nca-cert: x\n",
+		err:  "connection bundle has no state server addresses",
+	}, {
+		data: "user: bob\nstate-servers: [a]\n",
+		err:  "connection bundle has no CA certificate",
+	}, {
+		data: "ca-cert: x\nstate-servers: [a]\n",
+		err:  "connection bundle has no user",
+	}, {
+		data: "encrypted-bundle: '!!!'\n",
+		err:  "cannot decode connection bundle: .*",
+	}} {
+		c.Logf("test %d", i)
+		_, err := configstore.ParseConnectionBundle([]byte(test.data), "pass")
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *bundleSuite) TestImportConnectionBundle(c *gc.C) {
+	store := configstore.NewMem()
+	b := configstore.NewConnectionBundle(s.newInfo(c, configstore.NewMem()))
+	b.User = "bob"
+	b.Password = "bob's password"
+	_, err := configstore.ImportConnectionBundle(store, "shared", b)
+	c.Assert(err, gc.IsNil)
+
+	info, err := store.ReadInfo("shared")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Initialized(), jc.IsTrue)
+	c.Assert(info.BootstrapConfig(), gc.HasLen, 0)
+	c.Assert(info.APIEndpoint(), jc.DeepEquals, configstore.APIEndpoint{
+		Addresses:   []string{"example.com:17070"},
+		CACert:      "a cert",
+		EnvironUUID: "dead-beef",
+	})
+	c.Assert(info.APICredentials(), jc.DeepEquals, configstore.APICredentials{
+		User:     "bob",
+		Password: "bob's password",
+	})
+
+	_, err = configstore.ImportConnectionBundle(store, "shared", b)
+	c.Assert(err, gc.Equals, configstore.ErrEnvironInfoAlreadyExists)
+}
@@ -14,18 +14,10 @@ import (
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
 	"launchpad.net/goyaml"
-
-	"github.com/juju/juju/juju/osenv"
 )
 
 var logger = loggo.GetLogger("juju.environs.configstore")
 
-// Default returns disk-based environment config storage
-// rooted at JujuHome.
-func Default() (Storage, error) {
-	return NewDisk(osenv.JujuHome())
-}
-
 type diskStore struct {
 	dir string
 }
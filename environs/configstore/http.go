@@ -0,0 +1,161 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// httpBackend implements kvBackend by talking to a simple HTTP
+// key-value service. Each environment is a resource under the base
+// URL: GET reads it, PUT writes it (with "If-None-Match: *" meaning
+// create only), DELETE removes it, and a GET of the base URL returns
+// a JSON list of environment names.
+type httpBackend struct {
+	baseURL   string
+	authToken string
+	client    *http.Client
+}
+
+// NewHTTP returns a Storage implementation that keeps environment
+// information in the HTTP key-value service at the given URL. If
+// authToken is non-empty, it is sent as a bearer token with every
+// request.
+func NewHTTP(baseURL, authToken string) (Storage, error) {
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, errors.Annotatef(err, "invalid configstore URL %q", baseURL)
+	}
+	return &kvStore{&httpBackend{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		authToken: authToken,
+		client:    utils.GetValidatingHTTPClient(),
+	}}, nil
+}
+
+func (b *httpBackend) url(envName string) string {
+	return b.baseURL + "/" + (&url.URL{Path: envName}).String()
+}
+
+func (b *httpBackend) do(method, url string, body []byte, header http.Header) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if b.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+b.authToken)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot %s %s", method, url)
+	}
+	return resp, nil
+}
+
+func unexpectedResponse(method, url string, resp *http.Response) error {
+	msg, _ := ioutil.ReadAll(resp.Body)
+	if len(msg) > 0 {
+		return fmt.Errorf("cannot %s %s: %s (%s)", method, url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return fmt.Errorf("cannot %s %s: %s", method, url, resp.Status)
+}
+
+func (b *httpBackend) get(envName string) ([]byte, error) {
+	url := b.url(envName)
+	resp, err := b.do("GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errors.NotFoundf("environment %q", envName)
+	}
+	return nil, unexpectedResponse("GET", url, resp)
+}
+
+func (b *httpBackend) create(envName string) error {
+	url := b.url(envName)
+	resp, err := b.do("PUT", url, []byte{}, http.Header{"If-None-Match": {"*"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrEnvironInfoAlreadyExists
+	}
+	return unexpectedResponse("PUT", url, resp)
+}
+
+func (b *httpBackend) put(envName string, data []byte) error {
+	url := b.url(envName)
+	resp, err := b.do("PUT", url, data, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return unexpectedResponse("PUT", url, resp)
+}
+
+func (b *httpBackend) remove(envName string) error {
+	url := b.url(envName)
+	resp, err := b.do("DELETE", url, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errors.NotFoundf("environment %q", envName)
+	}
+	return unexpectedResponse("DELETE", url, resp)
+}
+
+func (b *httpBackend) list() ([]string, error) {
+	url := b.baseURL + "/"
+	resp, err := b.do("GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedResponse("GET", url, resp)
+	}
+	var names []string
+	if err := json.NewDecoder(resp.Body).Decode(&names); err != nil {
+		return nil, errors.Annotate(err, "cannot decode environment list")
+	}
+	return names, nil
+}
+
+func (b *httpBackend) location(envName string) string {
+	return fmt.Sprintf("URL %q", b.url(envName))
+}
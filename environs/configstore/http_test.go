@@ -0,0 +1,72 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	storetesting "github.com/juju/juju/environs/configstore/testing"
+)
+
+var _ = gc.Suite(&httpInterfaceSuite{})
+
+type httpInterfaceSuite struct {
+	interfaceSuite
+	server *storetesting.KVServer
+}
+
+func (s *httpInterfaceSuite) SetUpTest(c *gc.C) {
+	s.interfaceSuite.SetUpTest(c)
+	s.server = storetesting.NewKVServer()
+	s.NewStore = func(c *gc.C) configstore.Storage {
+		store, err := configstore.NewHTTP(s.server.URL, "")
+		c.Assert(err, gc.IsNil)
+		return store
+	}
+}
+
+func (s *httpInterfaceSuite) TearDownTest(c *gc.C) {
+	s.server.Close()
+	s.interfaceSuite.TearDownTest(c)
+}
+
+func (s *httpInterfaceSuite) TestLocation(c *gc.C) {
+	store := s.NewStore(c)
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Location(), gc.Equals, `URL "`+s.server.URL+`/someenv"`)
+}
+
+func (s *httpInterfaceSuite) TestWriteStoresYAML(c *gc.C) {
+	store := s.NewStore(c)
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	info.SetAPICredentials(configstore.APICredentials{User: "foo", Password: "bar"})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(s.server.Entries()["someenv"]), gc.Matches, "(?s).*user: foo.*")
+}
+
+func (s *httpInterfaceSuite) TestReadNotFound(c *gc.C) {
+	store := s.NewStore(c)
+	_, err := store.ReadInfo("someenv")
+	c.Assert(err, gc.ErrorMatches, `environment "someenv" not found`)
+}
+
+func (s *httpInterfaceSuite) TestAuthToken(c *gc.C) {
+	s.server.AuthToken = "sekrit"
+	store, err := configstore.NewHTTP(s.server.URL, "wrong")
+	c.Assert(err, gc.IsNil)
+	_, err = store.List()
+	c.Assert(err, gc.ErrorMatches, `cannot GET .*: 401 Unauthorized \(unauthorized\)`)
+
+	store, err = configstore.NewHTTP(s.server.URL, "sekrit")
+	c.Assert(err, gc.IsNil)
+	_, err = store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	names, err := store.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(names, gc.DeepEquals, []string{"someenv"})
+}
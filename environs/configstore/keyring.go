@@ -0,0 +1,172 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"launchpad.net/goyaml"
+)
+
+// keyringBackend implements kvBackend by keeping the information
+// for all environments in a single file, encrypted with a key
+// derived from a passphrase.
+type keyringBackend struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+}
+
+// NewKeyring returns a Storage implementation that keeps environment
+// information in the encrypted keyring file at the given path. The
+// file is created on demand; if it exists, it must be readable with
+// the given passphrase.
+func NewKeyring(path, passphrase string) (Storage, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("keyring passphrase not specified")
+	}
+	b := &keyringBackend{
+		path:       path,
+		passphrase: passphrase,
+	}
+	// Check that the passphrase is correct up front.
+	if _, err := b.load(); err != nil {
+		return nil, err
+	}
+	return &kvStore{b}, nil
+}
+
+// load reads and decrypts the keyring contents. The caller must
+// hold b.mu unless b is not yet shared.
+func (b *keyringBackend) load() (map[string]string, error) {
+	entries := make(map[string]string)
+	sealed, err := ioutil.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := unseal(b.passphrase, sealed)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot decrypt keyring %q", b.path)
+	}
+	if err := goyaml.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Annotatef(err, "cannot parse keyring %q", b.path)
+	}
+	return entries, nil
+}
+
+// save encrypts and atomically writes the keyring contents.
+// The caller must hold b.mu.
+func (b *keyringBackend) save(entries map[string]string) error {
+	data, err := goyaml.Marshal(entries)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal keyring")
+	}
+	sealed, err := seal(b.passphrase, data)
+	if err != nil {
+		return errors.Annotate(err, "cannot encrypt keyring")
+	}
+	parent := filepath.Dir(b.path)
+	if err := os.MkdirAll(parent, 0700); err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(parent, "")
+	if err != nil {
+		return errors.Annotate(err, "cannot create temporary file")
+	}
+	_, err = tmpFile.Write(sealed)
+	// N.B. We need to close the file before renaming it
+	// otherwise it will fail under Windows with a file-in-use
+	// error.
+	tmpFile.Close()
+	if err != nil {
+		return errors.Annotate(err, "cannot write temporary file")
+	}
+	if err := utils.ReplaceFile(tmpFile.Name(), b.path); err != nil {
+		os.Remove(tmpFile.Name())
+		return errors.Annotate(err, "cannot rename new keyring file")
+	}
+	return nil
+}
+
+func (b *keyringBackend) get(envName string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+	data, ok := entries[envName]
+	if !ok {
+		return nil, errors.NotFoundf("environment %q", envName)
+	}
+	return []byte(data), nil
+}
+
+func (b *keyringBackend) create(envName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, err := b.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[envName]; ok {
+		return ErrEnvironInfoAlreadyExists
+	}
+	entries[envName] = ""
+	return b.save(entries)
+}
+
+func (b *keyringBackend) put(envName string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, err := b.load()
+	if err != nil {
+		return err
+	}
+	entries[envName] = string(data)
+	return b.save(entries)
+}
+
+func (b *keyringBackend) remove(envName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, err := b.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[envName]; !ok {
+		return errors.NotFoundf("environment %q", envName)
+	}
+	delete(entries, envName)
+	return b.save(entries)
+}
+
+func (b *keyringBackend) list() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *keyringBackend) location(envName string) string {
+	return fmt.Sprintf("keyring %q", b.path)
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+)
+
+var _ = gc.Suite(&keyringInterfaceSuite{})
+
+type keyringInterfaceSuite struct {
+	interfaceSuite
+	path string
+}
+
+func (s *keyringInterfaceSuite) SetUpTest(c *gc.C) {
+	s.interfaceSuite.SetUpTest(c)
+	s.path = filepath.Join(c.MkDir(), "environments.keyring")
+	s.NewStore = func(c *gc.C) configstore.Storage {
+		store, err := configstore.NewKeyring(s.path, "passphrase")
+		c.Assert(err, gc.IsNil)
+		return store
+	}
+}
+
+func (s *keyringInterfaceSuite) TestNoPassphrase(c *gc.C) {
+	_, err := configstore.NewKeyring(s.path, "")
+	c.Assert(err, gc.ErrorMatches, "keyring passphrase not specified")
+}
+
+func (s *keyringInterfaceSuite) TestEncrypted(c *gc.C) {
+	store := s.NewStore(c)
+	info, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+	info.SetAPICredentials(configstore.APICredentials{User: "foobie", Password: "bletch"})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Location(), gc.Equals, `keyring "`+s.path+`"`)
+
+	data, err := ioutil.ReadFile(s.path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Not(jc.Contains), "foobie")
+	c.Assert(string(data), gc.Not(jc.Contains), "bletch")
+}
+
+func (s *keyringInterfaceSuite) TestWrongPassphrase(c *gc.C) {
+	store := s.NewStore(c)
+	_, err := store.CreateInfo("someenv")
+	c.Assert(err, gc.IsNil)
+
+	_, err = configstore.NewKeyring(s.path, "wrong")
+	c.Assert(err, gc.ErrorMatches, `cannot decrypt keyring ".*": invalid passphrase or corrupt data`)
+}
@@ -0,0 +1,117 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"launchpad.net/goyaml"
+)
+
+// kvBackend is implemented by simple key-value stores that hold
+// the serialized environment information keyed by environment name.
+type kvBackend interface {
+	// get returns the data stored for the given environment, or
+	// an errors.NotFound error if there is none.
+	get(envName string) ([]byte, error)
+
+	// create stores empty data for the given environment. It returns
+	// ErrEnvironInfoAlreadyExists if the environment already exists.
+	create(envName string) error
+
+	// put stores the data for the given environment.
+	put(envName string, data []byte) error
+
+	// remove removes the data for the given environment, returning
+	// an errors.NotFound error if there is none.
+	remove(envName string) error
+
+	// list returns the names of all the stored environments.
+	list() ([]string, error)
+
+	// location returns a human readable description of
+	// where the environment's data is held.
+	location(envName string) string
+}
+
+// kvStore implements Storage on top of a kvBackend.
+type kvStore struct {
+	backend kvBackend
+}
+
+type kvInfo struct {
+	backend kvBackend
+	name    string
+	environInfo
+}
+
+// CreateInfo implements Storage.CreateInfo.
+func (s *kvStore) CreateInfo(envName string) (EnvironInfo, error) {
+	if err := s.backend.create(envName); err != nil {
+		return nil, err
+	}
+	info := &kvInfo{
+		backend: s.backend,
+		name:    envName,
+	}
+	info.created = true
+	return info, nil
+}
+
+// List implements Storage.List.
+func (s *kvStore) List() ([]string, error) {
+	return s.backend.list()
+}
+
+// ReadInfo implements Storage.ReadInfo.
+func (s *kvStore) ReadInfo(envName string) (EnvironInfo, error) {
+	data, err := s.backend.get(envName)
+	if err != nil {
+		return nil, err
+	}
+	info := &kvInfo{
+		backend: s.backend,
+		name:    envName,
+	}
+	if len(data) == 0 {
+		return info, nil
+	}
+	if err := goyaml.Unmarshal(data, &info.EnvInfo); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %v", s.backend.location(envName), err)
+	}
+	info.initialized = true
+	return info, nil
+}
+
+// Location implements EnvironInfo.Location.
+func (info *kvInfo) Location() string {
+	return info.backend.location(info.name)
+}
+
+// Write implements EnvironInfo.Write.
+func (info *kvInfo) Write() error {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	data, err := goyaml.Marshal(info.EnvInfo)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal environment info")
+	}
+	if err := info.backend.put(info.name, data); err != nil {
+		return errors.Annotate(err, "cannot write environment info")
+	}
+	info.initialized = true
+	return nil
+}
+
+// Destroy implements EnvironInfo.Destroy.
+func (info *kvInfo) Destroy() error {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	err := info.backend.remove(info.name)
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("environment info has already been removed")
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KVServer is a local stand-in for the HTTP key-value service
+// used by the configstore "http" backend. It keeps all data
+// in memory.
+type KVServer struct {
+	*httptest.Server
+
+	// AuthToken, if set, must be supplied as a bearer
+	// token with every request.
+	AuthToken string
+
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+// NewKVServer starts and returns a new KVServer. The
+// caller is responsible for calling Close when done.
+func NewKVServer() *KVServer {
+	s := &KVServer{
+		entries: make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Entries returns a copy of the data currently held by the server.
+func (s *KVServer) Entries() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make(map[string][]byte)
+	for name, data := range s.entries {
+		entries[name] = data
+	}
+	return entries
+}
+
+// ServeHTTP implements http.Handler.
+func (s *KVServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.AuthToken != "" && req.Header.Get("Authorization") != "Bearer "+s.AuthToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := strings.TrimPrefix(req.URL.Path, "/")
+	if name == "" {
+		if req.Method != "GET" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		names := []string{}
+		for name := range s.entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		json.NewEncoder(w).Encode(names)
+		return
+	}
+	data, exists := s.entries[name]
+	switch req.Method {
+	case "GET":
+		if !exists {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case "PUT":
+		if exists && req.Header.Get("If-None-Match") == "*" {
+			http.Error(w, "already exists", http.StatusPreconditionFailed)
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.entries[name] = body
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		if !exists {
+			http.NotFound(w, req)
+			return
+		}
+		delete(s.entries, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
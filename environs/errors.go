@@ -5,6 +5,8 @@ package environs
 
 import (
 	"errors"
+
+	jujuerrors "github.com/juju/errors"
 )
 
 var (
@@ -13,3 +15,24 @@ var (
 	ErrNoInstances         = errors.New("no instances found")
 	ErrPartialInstances    = errors.New("only some instances were found")
 )
+
+// retryableError wraps an error that may not recur if the failed
+// operation is tried again later.
+type retryableError struct {
+	error
+}
+
+// NewRetryableError returns an error with the same message as err
+// that is reported as retryable by IsRetryable. Providers use it to
+// mark StartInstance errors, such as a lack of capacity, that are
+// expected to be transient.
+func NewRetryableError(err error) error {
+	return &retryableError{err}
+}
+
+// IsRetryable reports whether err, or the error it was
+// annotated from, was returned by NewRetryableError.
+func IsRetryable(err error) bool {
+	_, ok := jujuerrors.Cause(err).(*retryableError)
+	return ok
+}
@@ -33,7 +33,11 @@ func GetMetadataSources(env environs.ConfigGetter) ([]simplestreams.DataSource,
 		if !config.SSLHostnameVerification() {
 			verify = utils.NoVerifySSLHostnames
 		}
-		sources = append(sources, simplestreams.NewURLDataSource("image-metadata-url", userURL, verify))
+		source, err := simplestreams.NewDataSource("image-metadata-url", userURL, verify, config.ImageMetadataAuth())
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
 	}
 	if custom, ok := env.(SupportsCustomSources); ok {
 		customSources, err := custom.GetImageSources()
@@ -34,6 +34,9 @@ type BootstrapArgs struct {
 	Context                 environs.BootstrapContext
 	Series                  string
 	HardwareCharacteristics *instance.HardwareCharacteristics
+
+	// InitSystem, if set, is the init system detected on the host.
+	InitSystem string
 }
 
 func errMachineIdInvalid(machineId string) error {
@@ -66,7 +69,7 @@ func Bootstrap(args BootstrapArgs) (err error) {
 		return errors.New("possible tools is empty")
 	}
 
-	provisioned, err := checkProvisioned(args.Host)
+	provisioned, err := checkProvisioned(args.Host, "")
 	if err != nil {
 		return fmt.Errorf("failed to check provisioned status: %v", err)
 	}
@@ -126,6 +129,7 @@ func Bootstrap(args BootstrapArgs) (err error) {
 	mcfg := environs.NewBootstrapMachineConfig(privateKey)
 	mcfg.InstanceId = BootstrapInstanceId
 	mcfg.HardwareCharacteristics = args.HardwareCharacteristics
+	mcfg.InitSystem = args.InitSystem
 	if args.DataDir != "" {
 		mcfg.DataDir = args.DataDir
 	}
@@ -0,0 +1,115 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/utils/ssh"
+)
+
+// decommissionScript is the script run on a manually provisioned host
+// to remove everything juju installed there for a machine. It is run
+// with the machine id as its first argument, and reports each item it
+// could not remove on a line of its own, prefixed with "cannot remove".
+// Every step is attempted, regardless of earlier failures.
+const decommissionScript = `
+machine=$1
+failed() { echo "cannot remove $*"; }
+
+# Stop and remove the agents, including any mongo service.
+for conf in /etc/init/juju*.conf; do
+    [ -e "$conf" ] || continue
+    job=$(basename "$conf" .conf)
+    stop "$job" > /dev/null 2>&1
+    rm -f "$conf" || failed "upstart job $job"
+done
+for unit in /etc/systemd/system/juju*.service; do
+    [ -e "$unit" ] || continue
+    name=$(basename "$unit")
+    systemctl stop "$name" > /dev/null 2>&1
+    systemctl disable "$name" > /dev/null 2>&1
+    rm -f "$unit" || failed "systemd service $name"
+done
+[ -d /run/systemd/system ] && systemctl daemon-reload > /dev/null 2>&1
+
+# Remove the containers created for the machine.
+if which lxc-ls > /dev/null 2>&1; then
+    for c in $(lxc-ls | grep -e "-machine-$machine-"); do
+        lxc-stop -n "$c" > /dev/null 2>&1
+        lxc-destroy -n "$c" > /dev/null 2>&1 || failed "lxc container $c"
+    done
+fi
+if which virsh > /dev/null 2>&1; then
+    for c in $(virsh list --all --name | grep -e "-machine-$machine-"); do
+        virsh destroy "$c" > /dev/null 2>&1
+        virsh undefine "$c" > /dev/null 2>&1 || failed "kvm container $c"
+    done
+fi
+if which machinectl > /dev/null 2>&1; then
+    for c in $(ls /var/lib/machines 2> /dev/null | grep -e "-machine-$machine-"); do
+        machinectl terminate "$c" > /dev/null 2>&1
+        rm -fr "/var/lib/machines/$c" "/etc/systemd/nspawn/$c.nspawn" || failed "nspawn container $c"
+    done
+fi
+
+# Remove the agents' data, logs and configuration.
+for path in %s %s /usr/local/bin/juju-run /etc/rsyslog.d/*juju*; do
+    [ -e "$path" ] || continue
+    rm -fr "$path" 2> /dev/null || failed "$path"
+done
+service rsyslog restart > /dev/null 2>&1
+exit 0
+`
+
+// DecommissionMachine connects to a manually provisioned host via SSH,
+// as the ubuntu user, and removes the agents, upstart jobs or systemd
+// services, data, logs and containers that juju installed on it for the
+// machine with the given id. identityFile, if not empty, is the path of
+// an additional SSH private key to authenticate with.
+//
+// The items that could not be removed are returned; an error is
+// returned only if the host could not be decommissioned at all.
+func DecommissionMachine(host, machineId, identityFile string) (failures []string, err error) {
+	logger.Infof("decommissioning machine %s on %s", machineId, host)
+	script := fmt.Sprintf(
+		decommissionScript,
+		utils.ShQuote(agent.DefaultDataDir),
+		utils.ShQuote(agent.DefaultLogDir),
+	)
+	command := []string{"sudo", "/bin/bash", "-s", "--", utils.ShQuote(machineId)}
+	cmd := ssh.Command("ubuntu@"+host, command, sshOptions(identityFile))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() != 0 {
+			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("cannot decommission machine %s on %s: %v", machineId, host, err)
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "cannot remove ") {
+			failures = append(failures, strings.TrimPrefix(line, "cannot remove "))
+		}
+	}
+	return failures, nil
+}
+
+// ManualHost returns the host that a manually provisioned machine
+// with the given instance id was provisioned on, and whether the
+// instance id is that of a manually provisioned machine.
+func ManualHost(instanceId string) (string, bool) {
+	if !strings.HasPrefix(instanceId, manualInstancePrefix) {
+		return "", false
+	}
+	host := strings.TrimPrefix(instanceId, manualInstancePrefix)
+	return host, host != ""
+}
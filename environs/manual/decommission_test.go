@@ -0,0 +1,52 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/testing"
+)
+
+type decommissionSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&decommissionSuite{})
+
+func (s *decommissionSuite) TestDecommissionMachine(c *gc.C) {
+	defer installFakeSSH(c, nil, "", 0)()
+	failures, err := manual.DecommissionMachine("10.0.0.1", "1", "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(failures, gc.HasLen, 0)
+}
+
+func (s *decommissionSuite) TestDecommissionMachineFailures(c *gc.C) {
+	output := "cannot remove lxc container juju-machine-1-lxc-0\ncannot remove /var/log/juju"
+	defer installFakeSSH(c, nil, output, 0)()
+	failures, err := manual.DecommissionMachine("10.0.0.1", "1", "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(failures, gc.DeepEquals, []string{
+		"lxc container juju-machine-1-lxc-0",
+		"/var/log/juju",
+	})
+}
+
+func (s *decommissionSuite) TestDecommissionMachineError(c *gc.C) {
+	defer installFakeSSH(c, nil, []string{"", "connection refused"}, 255)()
+	_, err := manual.DecommissionMachine("10.0.0.1", "1", "")
+	c.Assert(err, gc.ErrorMatches, `cannot decommission machine 1 on 10.0.0.1: subprocess encountered error code 255 \(connection refused\)`)
+}
+
+func (s *decommissionSuite) TestManualHost(c *gc.C) {
+	host, ok := manual.ManualHost("manual:10.0.0.1")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(host, gc.Equals, "10.0.0.1")
+	_, ok = manual.ManualHost("manual:")
+	c.Assert(ok, jc.IsFalse)
+	_, ok = manual.ManualHost("i-deadbeef")
+	c.Assert(ok, jc.IsFalse)
+}
@@ -95,6 +95,7 @@ func installDetectionFakeSSH(c *gc.C, series, arch string) testing.Restorer {
 		series,
 		arch,
 		"MemTotal: 4096 kB",
+		"upstart",
 		"processor: 0",
 	}, "\n")
 	return installFakeSSH(c, manual.DetectionScript, detectionoutput, 0)
@@ -18,26 +18,40 @@ import (
 )
 
 // detectionScript is the script to run on the remote machine to
-// detect the OS series and hardware characteristics.
+// detect the OS series, hardware characteristics and init system.
+// The init system is systemd if the machine was booted with it.
 const detectionScript = `#!/bin/bash
 set -e
 lsb_release -cs
 uname -m
 grep MemTotal /proc/meminfo
+if [ -d /run/systemd/system ]; then echo systemd; else echo upstart; fi
 cat /proc/cpuinfo`
 
 // checkProvisionedScript is the script to run on the remote machine
-// to check if a machine has already been provisioned.
+// to check if a machine has already been provisioned, under either
+// upstart or systemd.
 //
 // This is a little convoluted to avoid returning an error in the
 // common case of no matching files.
-const checkProvisionedScript = "ls /etc/init/ | grep juju.*\\.conf || exit 0"
+const checkProvisionedScript = "ls /etc/init/ /etc/systemd/system/ 2> /dev/null | grep 'juju.*\\.\\(conf\\|service\\)' || exit 0"
 
-// checkProvisioned checks if any juju upstart jobs already
-// exist on the host machine.
-func checkProvisioned(host string) (bool, error) {
+// sshOptions returns the options with which to connect to a host,
+// authenticating with identityFile as well as the default identities
+// if identityFile is not empty.
+func sshOptions(identityFile string) *ssh.Options {
+	var options ssh.Options
+	if identityFile != "" {
+		options.SetIdentities(identityFile)
+	}
+	return &options
+}
+
+// checkProvisioned checks if any juju upstart jobs or systemd
+// services already exist on the host machine.
+func checkProvisioned(host, identityFile string) (bool, error) {
 	logger.Infof("Checking if %s is already provisioned", host)
-	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, nil)
+	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, sshOptions(identityFile))
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -58,15 +72,20 @@ func checkProvisioned(host string) (bool, error) {
 	return provisioned, nil
 }
 
+// DetectSeriesAndHardwareCharacteristics detects the OS series,
+// hardware characteristics and init system of the remote machine,
+// connecting with the given ssh identity file if it is non-empty.
+//
 // Patch for testing.
 var DetectSeriesAndHardwareCharacteristics = detectSeriesAndHardwareCharacteristics
 
 // detectSeriesAndHardwareCharacteristics detects the OS
-// series and hardware characteristics of the remote machine
-// by connecting to the machine and executing a bash script.
-func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCharacteristics, series string, err error) {
+// series, hardware characteristics and init system of the
+// remote machine by connecting to the machine and executing
+// a bash script.
+func detectSeriesAndHardwareCharacteristics(host, identityFile string) (hc instance.HardwareCharacteristics, series, initSystem string, err error) {
 	logger.Infof("Detecting series and characteristics on %s", host)
-	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, nil)
+	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, sshOptions(identityFile))
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -75,7 +94,7 @@ func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCh
 		if stderr.Len() != 0 {
 			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
 		}
-		return hc, "", err
+		return hc, "", "", err
 	}
 	lines := strings.Split(stdout.String(), "\n")
 	series = strings.TrimSpace(lines[0])
@@ -96,14 +115,16 @@ func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCh
 	recorded := make(map[string]bool)
 	var physicalId string
 	hc.CpuCores = new(uint64)
-	for _, line := range lines[3:] {
+	initSystem = strings.TrimSpace(lines[3])
+
+	for _, line := range lines[4:] {
 		if strings.HasPrefix(line, "physical id") {
 			physicalId = strings.TrimSpace(strings.SplitN(line, ":", 2)[1])
 		} else if strings.HasPrefix(line, "cpu cores") {
 			var cores uint64
 			value := strings.TrimSpace(strings.SplitN(line, ":", 2)[1])
 			if cores, err = strconv.ParseUint(value, 10, 0); err != nil {
-				return hc, "", err
+				return hc, "", "", err
 			}
 			if !recorded[physicalId] {
 				*hc.CpuCores += cores
@@ -118,8 +139,8 @@ func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCh
 	}
 
 	// TODO(axw) calculate CpuPower. What algorithm do we use?
-	logger.Infof("series: %s, characteristics: %s", series, hc)
-	return hc, series, nil
+	logger.Infof("series: %s, characteristics: %s, init system: %s", series, hc, initSystem)
+	return hc, series, initSystem, nil
 }
 
 // InitUbuntuUser adds the ubuntu user if it doesn't
@@ -137,6 +158,12 @@ func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCh
 // stdin and stdout will be used for remote sudo prompts,
 // if the ubuntu user must be created/updated.
 func InitUbuntuUser(host, login, authorizedKeys string, stdin io.Reader, stdout io.Writer) error {
+	return initUbuntuUser(host, login, "", authorizedKeys, stdin, stdout)
+}
+
+// initUbuntuUser is InitUbuntuUser, but also authenticates
+// with identityFile if it is not empty.
+func initUbuntuUser(host, login, identityFile, authorizedKeys string, stdin io.Reader, stdout io.Writer) error {
 	logger.Infof("initialising %q, user %q", host, login)
 
 	// To avoid unnecessary prompting for the specified login,
@@ -146,7 +173,7 @@ func InitUbuntuUser(host, login, authorizedKeys string, stdin io.Reader, stdout
 	//
 	// Note that we explicitly do not allocate a PTY, so we
 	// get a failure if sudo prompts.
-	cmd := ssh.Command("ubuntu@"+host, []string{"sudo", "-n", "true"}, nil)
+	cmd := ssh.Command("ubuntu@"+host, []string{"sudo", "-n", "true"}, sshOptions(identityFile))
 	if cmd.Run() == nil {
 		logger.Infof("ubuntu user is already initialised")
 		return nil
@@ -158,10 +185,10 @@ func InitUbuntuUser(host, login, authorizedKeys string, stdin io.Reader, stdout
 		host = login + "@" + host
 	}
 	script := fmt.Sprintf(initUbuntuScript, utils.ShQuote(authorizedKeys))
-	var options ssh.Options
+	options := sshOptions(identityFile)
 	options.AllowPasswordAuthentication()
 	options.EnablePTY()
-	cmd = ssh.Command(host, []string{"sudo", "/bin/bash -c " + utils.ShQuote(script)}, &options)
+	cmd = ssh.Command(host, []string{"sudo", "/bin/bash -c " + utils.ShQuote(script)}, options)
 	var stderr bytes.Buffer
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout // for sudo prompt
@@ -24,29 +24,45 @@ func (s *initialisationSuite) TestDetectSeries(c *gc.C) {
 		"edgy",
 		"armv4",
 		"MemTotal: 4096 kB",
+		"upstart",
 		"processor: 0",
 	}, "\n")
 	defer installFakeSSH(c, manual.DetectionScript, response, 0)()
-	_, series, err := manual.DetectSeriesAndHardwareCharacteristics("whatever")
+	_, series, _, err := manual.DetectSeriesAndHardwareCharacteristics("whatever", "")
 	c.Assert(err, gc.IsNil)
 	c.Assert(series, gc.Equals, "edgy")
 }
 
+func (s *initialisationSuite) TestDetectInitSystem(c *gc.C) {
+	response := strings.Join([]string{
+		"vivid",
+		"amd64",
+		"MemTotal: 4096 kB",
+		"systemd",
+		"processor: 0",
+	}, "\n")
+	defer installFakeSSH(c, manual.DetectionScript, response, 0)()
+	_, _, initSystem, err := manual.DetectSeriesAndHardwareCharacteristics("whatever", "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(initSystem, gc.Equals, "systemd")
+}
+
 func (s *initialisationSuite) TestDetectionError(c *gc.C) {
 	scriptResponse := strings.Join([]string{
 		"edgy",
 		"armv4",
 		"MemTotal: 4096 kB",
+		"upstart",
 		"processor: 0",
 	}, "\n")
 	// if the script fails for whatever reason, then checkProvisioned
 	// will return an error. stderr will be included in the error message.
 	defer installFakeSSH(c, manual.DetectionScript, []string{scriptResponse, "oh noes"}, 33)()
-	hc, _, err := manual.DetectSeriesAndHardwareCharacteristics("hostname")
+	hc, _, _, err := manual.DetectSeriesAndHardwareCharacteristics("hostname", "")
 	c.Assert(err, gc.ErrorMatches, "subprocess encountered error code 33 \\(oh noes\\)")
 	// if the script doesn't fail, stderr is simply ignored.
 	defer installFakeSSH(c, manual.DetectionScript, []string{scriptResponse, "non-empty-stderr"}, 0)()
-	hc, _, err = manual.DetectSeriesAndHardwareCharacteristics("hostname")
+	hc, _, _, err = manual.DetectSeriesAndHardwareCharacteristics("hostname", "")
 	c.Assert(err, gc.IsNil)
 	c.Assert(hc.String(), gc.Equals, "arch=armhf cpu-cores=1 mem=4M")
 }
@@ -58,12 +74,12 @@ func (s *initialisationSuite) TestDetectHardwareCharacteristics(c *gc.C) {
 		expectedHc     string
 	}{{
 		"Single CPU socket, single core, no hyper-threading",
-		[]string{"edgy", "armv4", "MemTotal: 4096 kB", "processor: 0"},
+		[]string{"edgy", "armv4", "MemTotal: 4096 kB", "upstart", "processor: 0"},
 		"arch=armhf cpu-cores=1 mem=4M",
 	}, {
 		"Single CPU socket, single core, hyper-threading",
 		[]string{
-			"edgy", "armv4", "MemTotal: 4096 kB",
+			"edgy", "armv4", "MemTotal: 4096 kB", "upstart",
 			"processor: 0",
 			"physical id: 0",
 			"cpu cores: 1",
@@ -75,7 +91,7 @@ func (s *initialisationSuite) TestDetectHardwareCharacteristics(c *gc.C) {
 	}, {
 		"Single CPU socket, dual-core, no hyper-threading",
 		[]string{
-			"edgy", "armv4", "MemTotal: 4096 kB",
+			"edgy", "armv4", "MemTotal: 4096 kB", "upstart",
 			"processor: 0",
 			"physical id: 0",
 			"cpu cores: 2",
@@ -87,7 +103,7 @@ func (s *initialisationSuite) TestDetectHardwareCharacteristics(c *gc.C) {
 	}, {
 		"Dual CPU socket, each single-core, hyper-threading",
 		[]string{
-			"edgy", "armv4", "MemTotal: 4096 kB",
+			"edgy", "armv4", "MemTotal: 4096 kB", "upstart",
 			"processor: 0",
 			"physical id: 0",
 			"cpu cores: 1",
@@ -107,7 +123,7 @@ func (s *initialisationSuite) TestDetectHardwareCharacteristics(c *gc.C) {
 		c.Logf("test %d: %s", i, test.summary)
 		scriptResponse := strings.Join(test.scriptResponse, "\n")
 		defer installFakeSSH(c, manual.DetectionScript, scriptResponse, 0)()
-		hc, _, err := manual.DetectSeriesAndHardwareCharacteristics("hostname")
+		hc, _, _, err := manual.DetectSeriesAndHardwareCharacteristics("hostname", "")
 		c.Assert(err, gc.IsNil)
 		c.Assert(hc.String(), gc.Equals, test.expectedHc)
 	}
@@ -115,25 +131,25 @@ func (s *initialisationSuite) TestDetectHardwareCharacteristics(c *gc.C) {
 
 func (s *initialisationSuite) TestCheckProvisioned(c *gc.C) {
 	defer installFakeSSH(c, manual.CheckProvisionedScript, "", 0)()
-	provisioned, err := manual.CheckProvisioned("example.com")
+	provisioned, err := manual.CheckProvisioned("example.com", "")
 	c.Assert(err, gc.IsNil)
 	c.Assert(provisioned, jc.IsFalse)
 
 	defer installFakeSSH(c, manual.CheckProvisionedScript, "non-empty", 0)()
-	provisioned, err = manual.CheckProvisioned("example.com")
+	provisioned, err = manual.CheckProvisioned("example.com", "")
 	c.Assert(err, gc.IsNil)
 	c.Assert(provisioned, jc.IsTrue)
 
 	// stderr should not affect result.
 	defer installFakeSSH(c, manual.CheckProvisionedScript, []string{"", "non-empty-stderr"}, 0)()
-	provisioned, err = manual.CheckProvisioned("example.com")
+	provisioned, err = manual.CheckProvisioned("example.com", "")
 	c.Assert(err, gc.IsNil)
 	c.Assert(provisioned, jc.IsFalse)
 
 	// if the script fails for whatever reason, then checkProvisioned
 	// will return an error. stderr will be included in the error message.
 	defer installFakeSSH(c, manual.CheckProvisionedScript, []string{"non-empty-stdout", "non-empty-stderr"}, 255)()
-	_, err = manual.CheckProvisioned("example.com")
+	_, err = manual.CheckProvisioned("example.com", "")
 	c.Assert(err, gc.ErrorMatches, "subprocess encountered error code 255 \\(non-empty-stderr\\)")
 }
 
@@ -0,0 +1,128 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/utils"
+	"launchpad.net/goyaml"
+)
+
+// InventoryHost describes a host listed in an inventory file.
+type InventoryHost struct {
+	// Host is the host's name or address.
+	Host string `yaml:"host"`
+
+	// User is the user to log in to the host as when
+	// initialising the ubuntu user. If empty, the
+	// current user's name is used.
+	User string `yaml:"user,omitempty"`
+
+	// Key is the path of an SSH private key to
+	// authenticate with.
+	Key string `yaml:"key,omitempty"`
+
+	// Series, if set, is the series recorded for the host's
+	// machine in place of the series detected on the host.
+	Series string `yaml:"series,omitempty"`
+}
+
+// ProvisionMachineArgs returns the arguments with which to
+// provision the host; only the fields that describe the
+// host are filled in.
+func (h InventoryHost) ProvisionMachineArgs() ProvisionMachineArgs {
+	host := h.Host
+	if h.User != "" {
+		host = h.User + "@" + host
+	}
+	return ProvisionMachineArgs{
+		Host:         host,
+		IdentityFile: h.Key,
+		Series:       h.Series,
+	}
+}
+
+// inventory holds the contents of an inventory file. Any of the
+// fields set in Defaults apply to hosts that do not set them.
+type inventory struct {
+	Defaults InventoryHost   `yaml:"defaults"`
+	Hosts    []InventoryHost `yaml:"hosts"`
+}
+
+// ReadInventory reads the inventory file at the given path.
+// See ParseInventory for the format of the file.
+func ReadInventory(path string) ([]InventoryHost, error) {
+	path, err := utils.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := ParseInventory(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory %q: %v", path, err)
+	}
+	return hosts, nil
+}
+
+// ParseInventory parses the YAML contents of an inventory file,
+// which lists hosts to be provisioned along with any per-host
+// overrides of the defaults, for example:
+//
+//	defaults:
+//	  user: admin
+//	  key: ~/.ssh/fleet_rsa
+//	hosts:
+//	  - host: 10.0.0.1
+//	  - host: 10.0.0.2
+//	    user: root
+//	    series: trusty
+func ParseInventory(data []byte) ([]InventoryHost, error) {
+	var inv inventory
+	if err := goyaml.Unmarshal(data, &inv); err != nil {
+		return nil, err
+	}
+	if inv.Defaults.Host != "" {
+		return nil, fmt.Errorf("defaults cannot specify a host")
+	}
+	if len(inv.Hosts) == 0 {
+		return nil, fmt.Errorf("no hosts specified")
+	}
+	seen := make(map[string]bool)
+	hosts := make([]InventoryHost, len(inv.Hosts))
+	for i, h := range inv.Hosts {
+		switch {
+		case h.Host == "":
+			return nil, fmt.Errorf("host %d has no address", i+1)
+		case strings.Contains(h.Host, "@"):
+			return nil, fmt.Errorf("host %q: specify the login with user, not in the address", h.Host)
+		case seen[h.Host]:
+			return nil, fmt.Errorf("host %q is listed more than once", h.Host)
+		}
+		seen[h.Host] = true
+		if h.User == "" {
+			h.User = inv.Defaults.User
+		}
+		if h.Key == "" {
+			h.Key = inv.Defaults.Key
+		}
+		if h.Series == "" {
+			h.Series = inv.Defaults.Series
+		}
+		if h.Key != "" {
+			key, err := utils.NormalizePath(h.Key)
+			if err != nil {
+				return nil, fmt.Errorf("host %q: %v", h.Host, err)
+			}
+			h.Key = key
+		}
+		hosts[i] = h
+	}
+	return hosts, nil
+}
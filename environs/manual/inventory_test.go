@@ -0,0 +1,123 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/testing"
+)
+
+type inventorySuite struct {
+	testing.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&inventorySuite{})
+
+func (s *inventorySuite) TestParseInventory(c *gc.C) {
+	hosts, err := manual.ParseInventory([]byte(`
+defaults:
+  user: admin
+  key: /keys/fleet_rsa
+hosts:
+  - host: 10.0.0.1
+  - host: 10.0.0.2
+    user: root
+    key: /keys/other_rsa
+    series: trusty
+`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosts, gc.DeepEquals, []manual.InventoryHost{{
+		Host: "10.0.0.1",
+		User: "admin",
+		Key:  "/keys/fleet_rsa",
+	}, {
+		Host:   "10.0.0.2",
+		User:   "root",
+		Key:    "/keys/other_rsa",
+		Series: "trusty",
+	}})
+}
+
+func (s *inventorySuite) TestParseInventoryExpandsKeyPath(c *gc.C) {
+	hosts, err := manual.ParseInventory([]byte(`
+hosts:
+  - host: 10.0.0.1
+    key: ~/.ssh/fleet_rsa
+`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosts[0].Key, gc.Equals, filepath.Join(utils.Home(), ".ssh", "fleet_rsa"))
+}
+
+var parseInventoryErrorTests = []struct {
+	about     string
+	inventory string
+	err       string
+}{{
+	about:     "no hosts",
+	inventory: "defaults: {user: admin}",
+	err:       "no hosts specified",
+}, {
+	about:     "host in defaults",
+	inventory: "defaults: {host: 10.0.0.1}\nhosts: [{host: 10.0.0.2}]",
+	err:       "defaults cannot specify a host",
+}, {
+	about:     "missing address",
+	inventory: "hosts: [{host: 10.0.0.1}, {user: root}]",
+	err:       "host 2 has no address",
+}, {
+	about:     "login in address",
+	inventory: "hosts: [{host: root@10.0.0.1}]",
+	err:       `host "root@10.0.0.1": specify the login with user, not in the address`,
+}, {
+	about:     "duplicate host",
+	inventory: "hosts: [{host: 10.0.0.1}, {host: 10.0.0.1, user: root}]",
+	err:       `host "10.0.0.1" is listed more than once`,
+}, {
+	about:     "invalid yaml",
+	inventory: "hosts: {",
+	err:       "YAML error: .*",
+}}
+
+func (s *inventorySuite) TestParseInventoryErrors(c *gc.C) {
+	for i, t := range parseInventoryErrorTests {
+		c.Logf("test %d: %s", i, t.about)
+		_, err := manual.ParseInventory([]byte(t.inventory))
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *inventorySuite) TestReadInventory(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hosts.yaml")
+	err := ioutil.WriteFile(path, []byte("hosts: [{host: 10.0.0.1}]"), 0644)
+	c.Assert(err, gc.IsNil)
+	hosts, err := manual.ReadInventory(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosts, gc.DeepEquals, []manual.InventoryHost{{Host: "10.0.0.1"}})
+
+	err = ioutil.WriteFile(path, []byte("hosts: []"), 0644)
+	c.Assert(err, gc.IsNil)
+	_, err = manual.ReadInventory(path)
+	c.Assert(err, gc.ErrorMatches, `invalid inventory ".*hosts.yaml": no hosts specified`)
+}
+
+func (s *inventorySuite) TestProvisionMachineArgs(c *gc.C) {
+	args := manual.InventoryHost{
+		Host:   "10.0.0.1",
+		User:   "admin",
+		Key:    "/keys/fleet_rsa",
+		Series: "trusty",
+	}.ProvisionMachineArgs()
+	c.Assert(args.Host, gc.Equals, "admin@10.0.0.1")
+	c.Assert(args.IdentityFile, gc.Equals, "/keys/fleet_rsa")
+	c.Assert(args.Series, gc.Equals, "trusty")
+
+	args = manual.InventoryHost{Host: "10.0.0.1"}.ProvisionMachineArgs()
+	c.Assert(args.Host, gc.Equals, "10.0.0.1")
+}
@@ -42,6 +42,14 @@ type ProvisionMachineArgs struct {
 	// Host is the SSH host: [user@]host
 	Host string
 
+	// IdentityFile, if set, is the path of an SSH private key to
+	// authenticate with, in addition to the default identities.
+	IdentityFile string
+
+	// Series, if set, is recorded as the machine's series in
+	// place of the series detected on the host.
+	Series string
+
 	// DataDir is the root directory for juju data.
 	// If left blank, the default location "/var/lib/juju" will be used.
 	DataDir string
@@ -91,14 +99,18 @@ func ProvisionMachine(args ProvisionMachineArgs) (machineId string, err error) {
 	// ubuntu user's authorized_keys.
 	user, hostname := splitUserHost(args.Host)
 	authorizedKeys, err := config.ReadAuthorizedKeys("")
-	if err := InitUbuntuUser(hostname, user, authorizedKeys, args.Stdin, args.Stdout); err != nil {
+	if err := initUbuntuUser(hostname, user, args.IdentityFile, authorizedKeys, args.Stdin, args.Stdout); err != nil {
 		return "", err
 	}
 
-	machineParams, err := gatherMachineParams(hostname)
+	machineParams, initSystem, err := gatherMachineParams(hostname, args.IdentityFile)
 	if err != nil {
 		return "", err
 	}
+	if args.Series != "" && args.Series != machineParams.Series {
+		logger.Infof("using series %q for %s in place of detected series %q", args.Series, hostname, machineParams.Series)
+		machineParams.Series = args.Series
+	}
 
 	// Inform Juju that the machine exists.
 	machineId, err = recordMachineInState(args.Client, *machineParams)
@@ -107,15 +119,16 @@ func ProvisionMachine(args ProvisionMachineArgs) (machineId string, err error) {
 	}
 
 	provisioningScript, err := args.Client.ProvisioningScript(params.ProvisioningScriptParams{
-		MachineId: machineId,
-		Nonce:     machineParams.Nonce,
+		MachineId:  machineId,
+		Nonce:      machineParams.Nonce,
+		InitSystem: initSystem,
 	})
 	if err != nil {
 		return "", err
 	}
 
 	// Finally, provision the machine agent.
-	err = runProvisionScript(provisioningScript, hostname, args.Stderr)
+	err = runProvisionScript(provisioningScript, hostname, args.IdentityFile, args.Stderr)
 	if err != nil {
 		return machineId, err
 	}
@@ -161,12 +174,12 @@ func convertToStateJobs(jobs []params.MachineJob) ([]state.MachineJob, error) {
 // The hostname supplied should not include a username.
 // If we can, we will reverse lookup the hostname by its IP address, and use
 // the DNS resolved name, rather than the name that was supplied
-func gatherMachineParams(hostname string) (*params.AddMachineParams, error) {
+func gatherMachineParams(hostname, identityFile string) (*params.AddMachineParams, string, error) {
 
 	// Generate a unique nonce for the machine.
 	uuid, err := utils.NewUUID()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var addrs []network.Address
@@ -176,19 +189,19 @@ func gatherMachineParams(hostname string) (*params.AddMachineParams, error) {
 		addrs = append(addrs, addr)
 	}
 
-	provisioned, err := checkProvisioned(hostname)
+	provisioned, err := checkProvisioned(hostname, identityFile)
 	if err != nil {
 		err = fmt.Errorf("error checking if provisioned: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 	if provisioned {
-		return nil, ErrProvisioned
+		return nil, "", ErrProvisioned
 	}
 
-	hc, series, err := DetectSeriesAndHardwareCharacteristics(hostname)
+	hc, series, initSystem, err := DetectSeriesAndHardwareCharacteristics(hostname, identityFile)
 	if err != nil {
 		err = fmt.Errorf("error detecting hardware characteristics: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 
 	// There will never be a corresponding "instance" that any provider
@@ -207,7 +220,7 @@ func gatherMachineParams(hostname string) (*params.AddMachineParams, error) {
 		Addrs:                   addrs,
 		Jobs:                    []params.MachineJob{params.JobHostUnits},
 	}
-	return machineParams, nil
+	return machineParams, initSystem, nil
 }
 
 var provisionMachineAgent = func(host string, mcfg *cloudinit.MachineConfig, progressWriter io.Writer) error {
@@ -215,7 +228,7 @@ var provisionMachineAgent = func(host string, mcfg *cloudinit.MachineConfig, pro
 	if err != nil {
 		return err
 	}
-	return runProvisionScript(script, host, progressWriter)
+	return runProvisionScript(script, host, "", progressWriter)
 }
 
 // ProvisioningScript generates a bash script that can be
@@ -243,9 +256,10 @@ func ProvisioningScript(mcfg *cloudinit.MachineConfig) (string, error) {
 	return buf.String(), nil
 }
 
-func runProvisionScript(script, host string, progressWriter io.Writer) error {
+func runProvisionScript(script, host, identityFile string, progressWriter io.Writer) error {
 	params := sshinit.ConfigureParams{
 		Host:           "ubuntu@" + host,
+		Options:        sshOptions(identityFile),
 		ProgressWriter: progressWriter,
 	}
 	return sshinit.RunConfigureScript(script, params)
@@ -4,9 +4,12 @@
 package simplestreams
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/errors"
@@ -35,6 +38,45 @@ type urlDataSource struct {
 	description          string
 	baseURL              string
 	hostnameVerification utils.SSLHostnameVerification
+	auth                 URLAuth
+	client               *http.Client
+}
+
+// URLAuth holds the credentials used to access a private
+// simplestreams source over HTTP.
+type URLAuth struct {
+	// Username and Password, if set, are sent using HTTP basic
+	// authentication.
+	Username string
+	Password string
+
+	// Token, if set, is sent as a bearer token in the
+	// Authorization header. It may not be combined with Username.
+	Token string
+
+	// ClientCert and ClientKey, if set, hold the PEM-encoded
+	// certificate and private key presented to the server.
+	ClientCert string
+	ClientKey  string
+}
+
+// Validate checks that the credentials are consistent.
+func (a URLAuth) Validate() error {
+	if a.Token != "" && a.Username != "" {
+		return fmt.Errorf("cannot use both token and username authentication")
+	}
+	if a.Password != "" && a.Username == "" {
+		return fmt.Errorf("password specified without username")
+	}
+	if (a.ClientCert == "") != (a.ClientKey == "") {
+		return fmt.Errorf("client certificate and key must be specified together")
+	}
+	if a.ClientCert != "" {
+		if _, err := tls.X509KeyPair([]byte(a.ClientCert), []byte(a.ClientKey)); err != nil {
+			return fmt.Errorf("invalid client certificate: %v", err)
+		}
+	}
+	return nil
 }
 
 // NewURLDataSource returns a new datasource reading from the specified baseURL.
@@ -46,6 +88,50 @@ func NewURLDataSource(description, baseURL string, hostnameVerification utils.SS
 	}
 }
 
+// NewAuthenticatedURLDataSource returns a new datasource reading from the
+// specified baseURL, using the given credentials.
+func NewAuthenticatedURLDataSource(
+	description, baseURL string, hostnameVerification utils.SSLHostnameVerification, auth URLAuth,
+) (DataSource, error) {
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+	source := &urlDataSource{
+		description:          description,
+		baseURL:              baseURL,
+		hostnameVerification: hostnameVerification,
+		auth:                 auth,
+	}
+	if auth.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(auth.ClientCert), []byte(auth.ClientKey))
+		if err != nil {
+			return nil, err
+		}
+		source.client = &http.Client{
+			Transport: utils.NewHttpTLSTransport(&tls.Config{
+				Certificates:       []tls.Certificate{cert},
+				InsecureSkipVerify: hostnameVerification == utils.NoVerifySSLHostnames,
+			}),
+		}
+	}
+	return source, nil
+}
+
+// NewDataSource returns a datasource reading from location, which may be
+// a local directory, a file:// URL or an HTTP URL. Local trees are read
+// directly from the filesystem; auth is only used for HTTP URLs.
+func NewDataSource(
+	description, location string, hostnameVerification utils.SSLHostnameVerification, auth URLAuth,
+) (DataSource, error) {
+	if strings.HasPrefix(location, "file://") {
+		return NewFileDataSource(description, strings.TrimPrefix(location, "file://")), nil
+	}
+	if filepath.IsAbs(location) {
+		return NewFileDataSource(description, location), nil
+	}
+	return NewAuthenticatedURLDataSource(description, location, hostnameVerification, auth)
+}
+
 // Description is defined in simplestreams.DataSource.
 func (u *urlDataSource) Description() string {
 	return u.description
@@ -69,13 +155,28 @@ func urlJoin(baseURL, relpath string) string {
 // Fetch is defined in simplestreams.DataSource.
 func (h *urlDataSource) Fetch(path string) (io.ReadCloser, string, error) {
 	dataURL := urlJoin(h.baseURL, path)
-	client := utils.GetHTTPClient(h.hostnameVerification)
+	client := h.client
+	if client == nil {
+		client = utils.GetHTTPClient(h.hostnameVerification)
+	}
+	req, err := http.NewRequest("GET", dataURL, nil)
+	if err != nil {
+		return nil, dataURL, errors.NotFoundf("invalid URL %q", dataURL)
+	}
+	if h.auth.Username != "" {
+		req.SetBasicAuth(h.auth.Username, h.auth.Password)
+	} else if h.auth.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.auth.Token)
+	}
 	// dataURL can be http:// or file://
-	resp, err := client.Get(dataURL)
+	resp, err := client.Do(req)
 	if err != nil {
 		logger.Debugf("Got error requesting %q: %v", dataURL, err)
 		return nil, dataURL, errors.NotFoundf("invalid URL %q", dataURL)
 	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+	}
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, dataURL, errors.NotFoundf("cannot find URL %q", dataURL)
 	}
@@ -97,3 +198,51 @@ func (h *urlDataSource) URL(path string) (string, error) {
 func (h *urlDataSource) SetAllowRetry(allow bool) {
 	// This is a NOOP for url datasources.
 }
+
+// A fileDataSource retrieves data from a directory on the local
+// filesystem.
+type fileDataSource struct {
+	description string
+	dir         string
+}
+
+// NewFileDataSource returns a new datasource reading from the
+// specified directory.
+func NewFileDataSource(description, dir string) DataSource {
+	return &fileDataSource{
+		description: description,
+		dir:         dir,
+	}
+}
+
+// Description is defined in simplestreams.DataSource.
+func (f *fileDataSource) Description() string {
+	return f.description
+}
+
+func (f *fileDataSource) GoString() string {
+	return fmt.Sprintf("%v: fileDataSource(%q)", f.description, f.dir)
+}
+
+// Fetch is defined in simplestreams.DataSource.
+func (f *fileDataSource) Fetch(path string) (io.ReadCloser, string, error) {
+	dataURL, _ := f.URL(path)
+	file, err := os.Open(filepath.Join(f.dir, filepath.FromSlash(path)))
+	if os.IsNotExist(err) {
+		return nil, dataURL, errors.NotFoundf("cannot find URL %q", dataURL)
+	}
+	if err != nil {
+		return nil, dataURL, fmt.Errorf("cannot access URL %q, %v", dataURL, err)
+	}
+	return file, dataURL, nil
+}
+
+// URL is defined in simplestreams.DataSource.
+func (f *fileDataSource) URL(path string) (string, error) {
+	return urlJoin("file://"+filepath.ToSlash(f.dir), path), nil
+}
+
+// SetAllowRetry is defined in simplestreams.DataSource.
+func (f *fileDataSource) SetAllowRetry(allow bool) {
+	// This is a NOOP for file datasources.
+}
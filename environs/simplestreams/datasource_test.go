@@ -4,10 +4,15 @@
 package simplestreams_test
 
 import (
+	"crypto/tls"
+	"encoding/base64"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
@@ -15,10 +20,13 @@ import (
 	"github.com/juju/juju/environs/imagemetadata"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/environs/simplestreams/testing"
+	coretesting "github.com/juju/juju/testing"
 )
 
 var _ = gc.Suite(&datasourceSuite{})
 var _ = gc.Suite(&datasourceHTTPSSuite{})
+var _ = gc.Suite(&fileDatasourceSuite{})
+var _ = gc.Suite(&authDatasourceSuite{})
 
 type datasourceSuite struct {
 	testing.TestDataSuite
@@ -90,3 +98,144 @@ func (s *datasourceHTTPSSuite) TestNonVerifyingClientSucceeds(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Check(string(byteContent), gc.Equals, "Greetings!\n")
 }
+
+type fileDatasourceSuite struct {
+	dir string
+}
+
+func (s *fileDatasourceSuite) SetUpTest(c *gc.C) {
+	s.dir = c.MkDir()
+	err := os.MkdirAll(filepath.Join(s.dir, "streams", "v1"), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(s.dir, "streams", "v1", "index.json"), []byte("{}"), 0644)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *fileDatasourceSuite) TestFetch(c *gc.C) {
+	ds := simplestreams.NewFileDataSource("test", s.dir)
+	rc, url, err := ds.Fetch("streams/v1/index.json")
+	c.Assert(err, gc.IsNil)
+	defer rc.Close()
+	c.Assert(url, gc.Equals, "file://"+s.dir+"/streams/v1/index.json")
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "{}")
+}
+
+func (s *fileDatasourceSuite) TestFetchNotFound(c *gc.C) {
+	ds := simplestreams.NewFileDataSource("test", s.dir)
+	_, _, err := ds.Fetch("streams/v1/missing.json")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `cannot find URL "file://.*/streams/v1/missing.json" not found`)
+}
+
+func (s *fileDatasourceSuite) TestNewDataSourceLocal(c *gc.C) {
+	for _, location := range []string{s.dir, "file://" + s.dir} {
+		ds, err := simplestreams.NewDataSource("test", location, utils.VerifySSLHostnames, simplestreams.URLAuth{})
+		c.Assert(err, gc.IsNil)
+		c.Assert(ds.Description(), gc.Equals, "test")
+		rc, _, err := ds.Fetch("streams/v1/index.json")
+		c.Assert(err, gc.IsNil)
+		rc.Close()
+	}
+}
+
+type authDatasourceSuite struct {
+	server  *httptest.Server
+	request *http.Request
+}
+
+func (s *authDatasourceSuite) SetUpTest(c *gc.C) {
+	s.request = nil
+	s.server = httptest.NewUnstartedServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		s.request = req
+		if req.Header.Get("Authorization") == "" && req.TLS == nil {
+			resp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp.Write([]byte("Greetings!\n"))
+	}))
+}
+
+func (s *authDatasourceSuite) TearDownTest(c *gc.C) {
+	s.server.Close()
+}
+
+func (s *authDatasourceSuite) fetch(c *gc.C, auth simplestreams.URLAuth) error {
+	ds, err := simplestreams.NewDataSource("test", s.server.URL, utils.NoVerifySSLHostnames, auth)
+	c.Assert(err, gc.IsNil)
+	rc, _, err := ds.Fetch("bar")
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "Greetings!\n")
+	return nil
+}
+
+func (s *authDatasourceSuite) TestUnauthorised(c *gc.C) {
+	s.server.Start()
+	err := s.fetch(c, simplestreams.URLAuth{})
+	c.Assert(err, jc.Satisfies, errors.IsUnauthorized)
+}
+
+func (s *authDatasourceSuite) TestBasicAuth(c *gc.C) {
+	s.server.Start()
+	err := s.fetch(c, simplestreams.URLAuth{Username: "user", Password: "secret"})
+	c.Assert(err, gc.IsNil)
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	c.Assert(s.request.Header.Get("Authorization"), gc.Equals, "Basic "+auth)
+}
+
+func (s *authDatasourceSuite) TestTokenAuth(c *gc.C) {
+	s.server.Start()
+	err := s.fetch(c, simplestreams.URLAuth{Token: "sekrit"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.request.Header.Get("Authorization"), gc.Equals, "Bearer sekrit")
+}
+
+func (s *authDatasourceSuite) TestClientCertificate(c *gc.C) {
+	s.server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	s.server.StartTLS()
+	auth := simplestreams.URLAuth{
+		ClientCert: coretesting.ServerCert,
+		ClientKey:  coretesting.ServerKey,
+	}
+	err := s.fetch(c, auth)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.request.TLS.PeerCertificates, gc.HasLen, 1)
+
+	err = s.fetch(c, simplestreams.URLAuth{})
+	c.Assert(err, gc.ErrorMatches, `invalid URL ".*/bar" not found`)
+}
+
+func (s *authDatasourceSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		auth simplestreams.URLAuth
+		err  string
+	}{{
+		auth: simplestreams.URLAuth{Username: "user", Password: "secret"},
+	}, {
+		auth: simplestreams.URLAuth{Username: "user", Token: "sekrit"},
+		err:  "cannot use both token and username authentication",
+	}, {
+		auth: simplestreams.URLAuth{Password: "secret"},
+		err:  "password specified without username",
+	}, {
+		auth: simplestreams.URLAuth{ClientCert: coretesting.ServerCert},
+		err:  "client certificate and key must be specified together",
+	}, {
+		auth: simplestreams.URLAuth{ClientCert: coretesting.ServerCert, ClientKey: "bad"},
+		err:  "invalid client certificate: .*",
+	}} {
+		c.Logf("test %d", i)
+		err := test.auth.Validate()
+		if test.err == "" {
+			c.Check(err, gc.IsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"code.google.com/p/go.crypto/openpgp/clearsign"
+	"github.com/juju/errors"
+)
+
+// DiffKind describes how a product, version or item differs
+// between two metadata trees.
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// Difference describes a difference between two metadata trees.
+type Difference struct {
+	Kind DiffKind
+
+	// ProductId, Version and Item identify what differs. Item is
+	// empty when a whole version differs, and Version is empty when
+	// a whole product differs.
+	ProductId string
+	Version   string
+	Item      string
+
+	// Changes describes each attribute of a changed item that
+	// differs, such as "size: 10 -> 12".
+	Changes []string
+}
+
+func (d Difference) String() string {
+	var prefix string
+	switch d.Kind {
+	case DiffAdded:
+		prefix = "+"
+	case DiffRemoved:
+		prefix = "-"
+	default:
+		prefix = "~"
+	}
+	parts := []string{prefix, d.ProductId}
+	if d.Version != "" {
+		parts = append(parts, d.Version)
+	}
+	if d.Item != "" {
+		parts = append(parts, d.Item)
+	}
+	s := strings.Join(parts, " ")
+	if len(d.Changes) > 0 {
+		s += ": " + strings.Join(d.Changes, ", ")
+	}
+	return s
+}
+
+// Diff compares the simplestreams metadata trees rooted at oldSource
+// and newSource product by product, and returns the differences in
+// order of product id, version and item. Signatures are not checked;
+// use Lint for that.
+func Diff(oldSource, newSource DataSource) ([]Difference, error) {
+	oldProducts, err := readProducts(oldSource)
+	if err != nil {
+		return nil, err
+	}
+	newProducts, err := readProducts(newSource)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []Difference
+	for _, productId := range sortedKeys(unionKeys(oldProducts, newProducts)) {
+		oldProduct, inOld := oldProducts[productId]
+		newProduct, inNew := newProducts[productId]
+		switch {
+		case !inOld:
+			diffs = append(diffs, Difference{Kind: DiffAdded, ProductId: productId})
+		case !inNew:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, ProductId: productId})
+		default:
+			diffs = append(diffs, diffVersions(productId, oldProduct.Versions, newProduct.Versions)...)
+		}
+	}
+	return diffs, nil
+}
+
+func diffVersions(productId string, oldVersions, newVersions map[string]lintItems) []Difference {
+	var diffs []Difference
+	for _, version := range sortedKeys(unionKeys(oldVersions, newVersions)) {
+		oldItems, inOld := oldVersions[version]
+		newItems, inNew := newVersions[version]
+		switch {
+		case !inOld:
+			diffs = append(diffs, Difference{Kind: DiffAdded, ProductId: productId, Version: version})
+		case !inNew:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, ProductId: productId, Version: version})
+		default:
+			diffs = append(diffs, diffItems(productId, version, oldItems.Items, newItems.Items)...)
+		}
+	}
+	return diffs
+}
+
+func diffItems(productId, version string, oldItems, newItems map[string]map[string]interface{}) []Difference {
+	var diffs []Difference
+	for _, item := range sortedKeys(unionKeys(oldItems, newItems)) {
+		oldItem, inOld := oldItems[item]
+		newItem, inNew := newItems[item]
+		diff := Difference{ProductId: productId, Version: version, Item: item}
+		switch {
+		case !inOld:
+			diff.Kind = DiffAdded
+		case !inNew:
+			diff.Kind = DiffRemoved
+		default:
+			diff.Kind = DiffChanged
+			for _, attr := range sortedKeys(unionKeys(oldItem, newItem)) {
+				oldValue, newValue := formatValue(oldItem[attr]), formatValue(newItem[attr])
+				if oldValue != newValue {
+					diff.Changes = append(diff.Changes, fmt.Sprintf("%s: %s -> %s", attr, oldValue, newValue))
+				}
+			}
+			if len(diff.Changes) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// formatValue formats an item attribute for display.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case float64:
+		// JSON numbers are decoded as float64, but the
+		// numbers in metadata are sizes.
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// unionKeys returns a map holding the keys of both of the given maps,
+// which must have the same type.
+func unionKeys(m1, m2 interface{}) map[string]bool {
+	keys := make(map[string]bool)
+	for _, m := range []interface{}{m1, m2} {
+		for _, key := range sortedKeys(m) {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// readProducts returns all the products in the metadata tree rooted
+// at source, keyed by product id.
+func readProducts(source DataSource) (map[string]lintProduct, error) {
+	data, url, err := readMetadataFile(source, DefaultIndexPath+UnsignedSuffix)
+	if errors.IsNotFound(err) {
+		data, url, err = readMetadataFile(source, DefaultIndexPath+signedSuffix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var indices Indices
+	if err := json.Unmarshal(data, &indices); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal JSON index metadata at URL %q: %v", url, err)
+	}
+	products := make(map[string]lintProduct)
+	for _, contentId := range sortedKeys(indices.Indexes) {
+		entry := indices.Indexes[contentId]
+		if entry == nil || entry.ProductsFilePath == "" {
+			continue
+		}
+		data, url, err := readMetadataFile(source, entry.ProductsFilePath)
+		if err != nil {
+			return nil, err
+		}
+		var metadata lintMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal JSON metadata at URL %q: %v", url, err)
+		}
+		for productId, product := range metadata.Products {
+			products[productId] = product
+		}
+	}
+	return products, nil
+}
+
+// readMetadataFile returns the contents of the metadata file at the
+// given path, with any signature removed but not checked.
+func readMetadataFile(source DataSource, path string) ([]byte, string, error) {
+	rc, url, err := source.Fetch(path)
+	if err != nil {
+		return nil, url, err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, url, fmt.Errorf("cannot read URL data, %v", err)
+	}
+	if strings.HasSuffix(path, signedSuffix) {
+		b, _ := clearsign.Decode(data)
+		if b == nil {
+			return nil, url, fmt.Errorf("cannot read URL %q: %v", url, &NotPGPSignedError{})
+		}
+		data = b.Plaintext
+	}
+	return data, url, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/simplestreams"
+)
+
+type diffSuite struct{}
+
+const diffProductsTemplate = `{
+ "format": "products:1.0",
+ "content_id": "com.ubuntu.juju:released:tools",
+ "products": {
+  %s
+ }
+}`
+
+const diffPrecise = `"com.ubuntu.juju:12.04:amd64": {
+   "versions": {
+    "20140101": {
+     "items": {
+      "1.18.0-precise-amd64": {"version": "1.18.0", "size": 10, "sha256": "aaa"}
+     }
+    }
+   }
+  }`
+
+const diffTrusty = `"com.ubuntu.juju:14.04:amd64": {
+   "versions": {
+    "20140101": {
+     "items": {
+      "1.18.0-trusty-amd64": {"version": "1.18.0", "size": 10, "sha256": "bbb"}
+     }
+    }
+   }
+  }`
+
+func (s *diffSuite) makeTree(c *gc.C, products ...string) simplestreams.DataSource {
+	dir := c.MkDir()
+	err := os.MkdirAll(filepath.Join(dir, "streams", "v1"), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "streams", "v1", "index.json"), []byte(lintIndex), 0644)
+	c.Assert(err, gc.IsNil)
+	data := strings.Replace(diffProductsTemplate, "%s", strings.Join(products, ",\n"), 1)
+	err = ioutil.WriteFile(filepath.Join(dir, "streams", "v1", "com.ubuntu.juju:released:tools.json"), []byte(data), 0644)
+	c.Assert(err, gc.IsNil)
+	return simplestreams.NewURLDataSource("test", "file://"+dir, utils.VerifySSLHostnames)
+}
+
+func (s *diffSuite) diff(c *gc.C, oldSource, newSource simplestreams.DataSource) []string {
+	diffs, err := simplestreams.Diff(oldSource, newSource)
+	c.Assert(err, gc.IsNil)
+	var result []string
+	for _, diff := range diffs {
+		result = append(result, diff.String())
+	}
+	return result
+}
+
+func (s *diffSuite) TestDiffSame(c *gc.C) {
+	c.Assert(s.diff(c, s.makeTree(c, diffPrecise), s.makeTree(c, diffPrecise)), gc.HasLen, 0)
+}
+
+func (s *diffSuite) TestDiffProducts(c *gc.C) {
+	oldSource := s.makeTree(c, diffPrecise)
+	newSource := s.makeTree(c, diffTrusty)
+	c.Assert(s.diff(c, oldSource, newSource), gc.DeepEquals, []string{
+		"- com.ubuntu.juju:12.04:amd64",
+		"+ com.ubuntu.juju:14.04:amd64",
+	})
+}
+
+func (s *diffSuite) TestDiffVersions(c *gc.C) {
+	oldSource := s.makeTree(c, diffPrecise)
+	newSource := s.makeTree(c, strings.Replace(diffPrecise, "20140101", "20140202", 1))
+	c.Assert(s.diff(c, oldSource, newSource), gc.DeepEquals, []string{
+		"- com.ubuntu.juju:12.04:amd64 20140101",
+		"+ com.ubuntu.juju:12.04:amd64 20140202",
+	})
+}
+
+func (s *diffSuite) TestDiffItems(c *gc.C) {
+	oldSource := s.makeTree(c, diffPrecise)
+	newPrecise := strings.Replace(diffPrecise, `"size": 10, "sha256": "aaa"`, `"size": 12, "sha256": "ccc"`, 1)
+	newPrecise = strings.Replace(newPrecise, "}\n     }", `},
+      "1.18.1-precise-amd64": {"version": "1.18.1"}
+     }`, 1)
+	newSource := s.makeTree(c, newPrecise)
+	c.Assert(s.diff(c, oldSource, newSource), gc.DeepEquals, []string{
+		`~ com.ubuntu.juju:12.04:amd64 20140101 1.18.0-precise-amd64: sha256: "aaa" -> "ccc", size: 10 -> 12`,
+		"+ com.ubuntu.juju:12.04:amd64 20140101 1.18.1-precise-amd64",
+	})
+	c.Assert(s.diff(c, newSource, oldSource), gc.DeepEquals, []string{
+		`~ com.ubuntu.juju:12.04:amd64 20140101 1.18.0-precise-amd64: sha256: "ccc" -> "aaa", size: 12 -> 10`,
+		"- com.ubuntu.juju:12.04:amd64 20140101 1.18.1-precise-amd64",
+	})
+}
+
+func (s *diffSuite) TestDiffNoIndex(c *gc.C) {
+	source := simplestreams.NewURLDataSource("test", "file://"+c.MkDir(), utils.VerifySSLHostnames)
+	_, err := simplestreams.Diff(source, s.makeTree(c, diffPrecise))
+	c.Assert(err, gc.ErrorMatches, ".*not found")
+}
@@ -0,0 +1,310 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	indexFormat    = "index:1.0"
+	productsFormat = "products:1.0"
+)
+
+// LintParams holds the parameters for Lint.
+type LintParams struct {
+	// PublicKey, if not empty, holds the armored public key with which
+	// the signatures of signed metadata files are checked.
+	PublicKey string
+
+	// RequireSigned specifies whether metadata files without a
+	// signed version are reported.
+	RequireSigned bool
+
+	// SkipHashes specifies whether the files referenced by metadata
+	// items are left unchecked. Checking them means downloading them.
+	SkipHashes bool
+}
+
+// LintProblem describes a problem found by Lint.
+type LintProblem struct {
+	// Path holds the path, relative to the root of the metadata tree,
+	// of the file with the problem.
+	Path string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (p LintProblem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// linter accumulates the problems found in a metadata tree.
+type linter struct {
+	source   DataSource
+	params   LintParams
+	problems []LintProblem
+	products map[string]*lintMetadata
+}
+
+func (l *linter) addProblem(path, message string, args ...interface{}) {
+	l.problems = append(l.problems, LintProblem{path, fmt.Sprintf(message, args...)})
+}
+
+// Lint checks the simplestreams metadata tree rooted at source, and
+// returns the problems found. It reports missing or malformed index and
+// products files, index entries that reference missing products, files
+// with bad or missing signatures, and items whose files are missing or
+// do not match their recorded size and sha256 hash.
+func Lint(source DataSource, params LintParams) []LintProblem {
+	l := &linter{
+		source:   source,
+		params:   params,
+		products: make(map[string]*lintMetadata),
+	}
+	l.lintIndex(DefaultIndexPath)
+	sort.Stable(byPath(l.problems))
+	return l.problems
+}
+
+type byPath []LintProblem
+
+func (b byPath) Len() int           { return len(b) }
+func (b byPath) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPath) Less(i, j int) bool { return b[i].Path < b[j].Path }
+
+// readFile returns the contents of the given metadata file, checking
+// its signature if it is signed and a public key was supplied. If the
+// file cannot be read, a problem is recorded and ok is false.
+func (l *linter) readFile(path string) (data []byte, ok bool) {
+	if !strings.HasSuffix(path, signedSuffix) || l.params.PublicKey == "" {
+		data, _, err := readMetadataFile(l.source, path)
+		if err != nil {
+			l.addProblem(path, "cannot read file: %v", err)
+			return nil, false
+		}
+		return data, true
+	}
+	rc, _, err := l.source.Fetch(path)
+	if err != nil {
+		l.addProblem(path, "cannot read file: %v", err)
+		return nil, false
+	}
+	defer rc.Close()
+	data, err = DecodeCheckSignature(rc, l.params.PublicKey)
+	if err != nil {
+		l.addProblem(path, "bad signature: %v", err)
+		return nil, false
+	}
+	return data, true
+}
+
+// exists returns whether the given file exists in the tree.
+func (l *linter) exists(path string) bool {
+	rc, _, err := l.source.Fetch(path)
+	if err != nil {
+		return false
+	}
+	rc.Close()
+	return true
+}
+
+// checkSigned reports an unsigned metadata file at path if signed
+// metadata is required.
+func (l *linter) checkSigned(path string) {
+	if !l.params.RequireSigned || strings.HasSuffix(path, signedSuffix) {
+		return
+	}
+	signedPath := strings.TrimSuffix(path, UnsignedSuffix) + signedSuffix
+	if !l.exists(signedPath) {
+		l.addProblem(path, "no signed version found")
+	}
+}
+
+// lintIndex checks the signed and unsigned versions of the index
+// at the given path, without suffix.
+func (l *linter) lintIndex(basePath string) {
+	found := false
+	for _, path := range []string{basePath + signedSuffix, basePath + UnsignedSuffix} {
+		if !l.exists(path) {
+			continue
+		}
+		found = true
+		l.checkSigned(path)
+		data, ok := l.readFile(path)
+		if !ok {
+			continue
+		}
+		var indices Indices
+		if err := json.Unmarshal(data, &indices); err != nil {
+			l.addProblem(path, "invalid JSON: %v", err)
+			continue
+		}
+		if indices.Format != indexFormat {
+			l.addProblem(path, "unexpected format %q, expected %q", indices.Format, indexFormat)
+			continue
+		}
+		if len(indices.Indexes) == 0 {
+			l.addProblem(path, "no index entries")
+		}
+		for _, contentId := range sortedKeys(indices.Indexes) {
+			l.lintIndexEntry(path, contentId, indices.Indexes[contentId])
+		}
+	}
+	if !found {
+		l.addProblem(basePath+UnsignedSuffix, "no index found")
+	}
+}
+
+// lintIndexEntry checks the index entry for contentId in the index
+// at indexPath, and the products file it references.
+func (l *linter) lintIndexEntry(indexPath, contentId string, entry *IndexMetadata) {
+	if entry == nil {
+		l.addProblem(indexPath, "empty index entry %q", contentId)
+		return
+	}
+	if entry.DataType == "" {
+		l.addProblem(indexPath, "index entry %q has no datatype", contentId)
+	}
+	if entry.Format != productsFormat {
+		l.addProblem(indexPath, "index entry %q has unexpected format %q, expected %q", contentId, entry.Format, productsFormat)
+	}
+	if len(entry.ProductIds) == 0 {
+		l.addProblem(indexPath, "index entry %q lists no products", contentId)
+	}
+	if entry.ProductsFilePath == "" {
+		l.addProblem(indexPath, "index entry %q has no path", contentId)
+		return
+	}
+	if !l.exists(entry.ProductsFilePath) {
+		l.addProblem(indexPath, "index entry %q references missing file %q", contentId, entry.ProductsFilePath)
+		return
+	}
+	metadata, ok := l.lintProducts(entry.ProductsFilePath)
+	if !ok {
+		return
+	}
+	if metadata.ContentId != contentId {
+		l.addProblem(entry.ProductsFilePath, "content id %q does not match index entry %q", metadata.ContentId, contentId)
+	}
+	for _, productId := range entry.ProductIds {
+		if _, ok := metadata.Products[productId]; !ok {
+			l.addProblem(indexPath, "index entry %q references product %q missing from %q", contentId, productId, entry.ProductsFilePath)
+		}
+	}
+	listed := make(map[string]bool)
+	for _, productId := range entry.ProductIds {
+		listed[productId] = true
+	}
+	for _, productId := range sortedKeys(metadata.Products) {
+		if !listed[productId] {
+			l.addProblem(entry.ProductsFilePath, "product %q is not listed in index entry %q", productId, contentId)
+		}
+	}
+}
+
+// lintProducts checks the products file at the given path, and the
+// files referenced by its items. Each file is checked only once.
+func (l *linter) lintProducts(path string) (*lintMetadata, bool) {
+	if metadata, ok := l.products[path]; ok {
+		return metadata, metadata != nil
+	}
+	l.products[path] = nil
+	l.checkSigned(path)
+	data, ok := l.readFile(path)
+	if !ok {
+		return nil, false
+	}
+	var metadata lintMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		l.addProblem(path, "invalid JSON: %v", err)
+		return nil, false
+	}
+	if metadata.Format != productsFormat {
+		l.addProblem(path, "unexpected format %q, expected %q", metadata.Format, productsFormat)
+		return nil, false
+	}
+	l.products[path] = &metadata
+	for _, productId := range sortedKeys(metadata.Products) {
+		product := metadata.Products[productId]
+		if len(product.Versions) == 0 {
+			l.addProblem(path, "product %q has no versions", productId)
+		}
+		for _, version := range sortedKeys(product.Versions) {
+			items := product.Versions[version].Items
+			if len(items) == 0 {
+				l.addProblem(path, "product %q version %q has no items", productId, version)
+			}
+			for _, itemId := range sortedKeys(items) {
+				l.lintItem(path, fmt.Sprintf("%s/%s/%s", productId, version, itemId), items[itemId])
+			}
+		}
+	}
+	return &metadata, true
+}
+
+// lintItem checks that the file referenced by an item, if any, exists
+// and matches the item's size and sha256 hash.
+func (l *linter) lintItem(productsPath, itemPath string, item map[string]interface{}) {
+	filePath, _ := item["path"].(string)
+	if filePath == "" || l.params.SkipHashes {
+		return
+	}
+	rc, _, err := l.source.Fetch(filePath)
+	if err != nil {
+		l.addProblem(productsPath, "item %s references missing file %q", itemPath, filePath)
+		return
+	}
+	defer rc.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, rc)
+	if err != nil {
+		l.addProblem(productsPath, "item %s: cannot read %q: %v", itemPath, filePath, err)
+		return
+	}
+	if expected, ok := item["size"].(float64); ok && int64(expected) != size {
+		l.addProblem(productsPath, "item %s: size mismatch for %q, expected %d, got %d", itemPath, filePath, int64(expected), size)
+	}
+	actual := fmt.Sprintf("%x", hash.Sum(nil))
+	if expected, ok := item["sha256"].(string); ok && expected != actual {
+		l.addProblem(productsPath, "item %s: sha256 mismatch for %q, expected %s, got %s", itemPath, filePath, expected, actual)
+	} else if !ok {
+		l.addProblem(productsPath, "item %s has no sha256", itemPath)
+	}
+}
+
+// lintMetadata holds the parts of a products file that are checked
+// by Lint and compared by Diff. Unlike CloudMetadata, it preserves
+// all the attributes of each item.
+type lintMetadata struct {
+	Format    string                 `json:"format"`
+	ContentId string                 `json:"content_id"`
+	Products  map[string]lintProduct `json:"products"`
+}
+
+type lintProduct struct {
+	Versions map[string]lintItems `json:"versions"`
+}
+
+type lintItems struct {
+	Items map[string]map[string]interface{} `json:"items"`
+}
+
+// sortedKeys returns the keys of the given map, which must have
+// string keys, in ascending order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
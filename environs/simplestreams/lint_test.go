@@ -0,0 +1,186 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+)
+
+type lintSuite struct {
+	dir string
+}
+
+const lintIndex = `{
+ "format": "index:1.0",
+ "index": {
+  "com.ubuntu.juju:released:tools": {
+   "datatype": "content-download",
+   "format": "products:1.0",
+   "path": "streams/v1/com.ubuntu.juju:released:tools.json",
+   "products": ["com.ubuntu.juju:12.04:amd64"]
+  }
+ }
+}`
+
+const lintProductsTemplate = `{
+ "format": "products:1.0",
+ "content_id": "com.ubuntu.juju:released:tools",
+ "products": {
+  "com.ubuntu.juju:12.04:amd64": {
+   "versions": {
+    "20140101": {
+     "items": {
+      "1.18.0-precise-amd64": {
+       "version": "1.18.0",
+       "path": "releases/juju-1.18.0-precise-amd64.tgz",
+       "size": %d,
+       "sha256": %q
+      }
+     }
+    }
+   }
+  }
+ }
+}`
+
+const lintTarball = "juju tools tarball"
+
+func lintProducts(content string) string {
+	return fmt.Sprintf(lintProductsTemplate, len(content), fmt.Sprintf("%x", sha256.Sum256([]byte(content))))
+}
+
+func (s *lintSuite) SetUpTest(c *gc.C) {
+	s.dir = c.MkDir()
+	s.writeFile(c, "streams/v1/index.json", lintIndex)
+	s.writeFile(c, "streams/v1/com.ubuntu.juju:released:tools.json", lintProducts(lintTarball))
+	s.writeFile(c, "releases/juju-1.18.0-precise-amd64.tgz", lintTarball)
+}
+
+func (s *lintSuite) writeFile(c *gc.C, path, content string) {
+	path = filepath.Join(s.dir, path)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *lintSuite) writeSignedFile(c *gc.C, path, content string) {
+	data, err := simplestreams.Encode(
+		strings.NewReader(content), sstesting.SignedMetadataPrivateKey, sstesting.PrivateKeyPassphrase)
+	c.Assert(err, gc.IsNil)
+	s.writeFile(c, path, string(data))
+}
+
+func (s *lintSuite) lint(params simplestreams.LintParams) []string {
+	source := simplestreams.NewURLDataSource("test", "file://"+s.dir, utils.VerifySSLHostnames)
+	var problems []string
+	for _, problem := range simplestreams.Lint(source, params) {
+		problems = append(problems, problem.String())
+	}
+	return problems
+}
+
+func (s *lintSuite) TestLintClean(c *gc.C) {
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.HasLen, 0)
+}
+
+func (s *lintSuite) TestLintNoIndex(c *gc.C) {
+	err := os.Remove(filepath.Join(s.dir, "streams/v1/index.json"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.DeepEquals, []string{
+		"streams/v1/index.json: no index found",
+	})
+}
+
+func (s *lintSuite) TestLintInvalidJSON(c *gc.C) {
+	s.writeFile(c, "streams/v1/com.ubuntu.juju:released:tools.json", "{")
+	problems := s.lint(simplestreams.LintParams{})
+	c.Assert(problems, gc.HasLen, 1)
+	c.Assert(problems[0], gc.Matches, "streams/v1/com.ubuntu.juju:released:tools.json: invalid JSON: .*")
+}
+
+func (s *lintSuite) TestLintWrongFormat(c *gc.C) {
+	s.writeFile(c, "streams/v1/index.json", strings.Replace(lintIndex, `"index:1.0"`, `"index:2.0"`, 1))
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.DeepEquals, []string{
+		`streams/v1/index.json: unexpected format "index:2.0", expected "index:1.0"`,
+	})
+}
+
+func (s *lintSuite) TestLintDanglingProductsPath(c *gc.C) {
+	err := os.Remove(filepath.Join(s.dir, "streams/v1/com.ubuntu.juju:released:tools.json"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.DeepEquals, []string{
+		`streams/v1/index.json: index entry "com.ubuntu.juju:released:tools" references missing file "streams/v1/com.ubuntu.juju:released:tools.json"`,
+	})
+}
+
+func (s *lintSuite) TestLintMismatchedProducts(c *gc.C) {
+	s.writeFile(c, "streams/v1/index.json", strings.Replace(lintIndex, "12.04", "14.04", 1))
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.DeepEquals, []string{
+		`streams/v1/com.ubuntu.juju:released:tools.json: product "com.ubuntu.juju:12.04:amd64" is not listed in index entry "com.ubuntu.juju:released:tools"`,
+		`streams/v1/index.json: index entry "com.ubuntu.juju:released:tools" references product "com.ubuntu.juju:14.04:amd64" missing from "streams/v1/com.ubuntu.juju:released:tools.json"`,
+	})
+}
+
+func (s *lintSuite) TestLintMissingItemFile(c *gc.C) {
+	err := os.Remove(filepath.Join(s.dir, "releases/juju-1.18.0-precise-amd64.tgz"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.lint(simplestreams.LintParams{}), gc.DeepEquals, []string{
+		`streams/v1/com.ubuntu.juju:released:tools.json: item com.ubuntu.juju:12.04:amd64/20140101/1.18.0-precise-amd64 references missing file "releases/juju-1.18.0-precise-amd64.tgz"`,
+	})
+	c.Assert(s.lint(simplestreams.LintParams{SkipHashes: true}), gc.HasLen, 0)
+}
+
+func (s *lintSuite) TestLintBadHash(c *gc.C) {
+	s.writeFile(c, "releases/juju-1.18.0-precise-amd64.tgz", "juju tools tarbalx")
+	problems := s.lint(simplestreams.LintParams{})
+	c.Assert(problems, gc.HasLen, 1)
+	c.Assert(problems[0], gc.Matches, `streams/v1/com.ubuntu.juju:released:tools.json: item .*: sha256 mismatch for "releases/juju-1.18.0-precise-amd64.tgz", expected [0-9a-f]+, got [0-9a-f]+`)
+	c.Assert(s.lint(simplestreams.LintParams{SkipHashes: true}), gc.HasLen, 0)
+}
+
+func (s *lintSuite) TestLintBadSize(c *gc.C) {
+	s.writeFile(c, "releases/juju-1.18.0-precise-amd64.tgz", lintTarball+"x")
+	problems := s.lint(simplestreams.LintParams{})
+	c.Assert(problems, gc.HasLen, 2)
+	c.Assert(problems[0], gc.Matches, `.*: size mismatch for "releases/juju-1.18.0-precise-amd64.tgz", expected 18, got 19`)
+	c.Assert(problems[1], gc.Matches, `.*: sha256 mismatch for .*`)
+}
+
+func (s *lintSuite) TestLintRequireSigned(c *gc.C) {
+	c.Assert(s.lint(simplestreams.LintParams{RequireSigned: true}), gc.DeepEquals, []string{
+		"streams/v1/com.ubuntu.juju:released:tools.json: no signed version found",
+		"streams/v1/index.json: no signed version found",
+	})
+}
+
+func (s *lintSuite) TestLintSigned(c *gc.C) {
+	s.writeSignedFile(c, "streams/v1/index.sjson", lintIndex)
+	s.writeSignedFile(c, "streams/v1/com.ubuntu.juju:released:tools.sjson", lintProducts(lintTarball))
+	c.Assert(s.lint(simplestreams.LintParams{
+		PublicKey:     sstesting.SignedMetadataPublicKey,
+		RequireSigned: true,
+	}), gc.HasLen, 0)
+}
+
+func (s *lintSuite) TestLintBadSignature(c *gc.C) {
+	s.writeSignedFile(c, "streams/v1/index.sjson", lintIndex)
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, "streams/v1/index.sjson"))
+	c.Assert(err, gc.IsNil)
+	s.writeFile(c, "streams/v1/index.sjson", strings.Replace(string(data), "content-download", "content-uploaded", 1))
+	problems := s.lint(simplestreams.LintParams{PublicKey: sstesting.SignedMetadataPublicKey})
+	c.Assert(problems, gc.HasLen, 1)
+	c.Assert(problems[0], gc.Matches, "streams/v1/index.sjson: bad signature: .*")
+}
@@ -20,6 +20,8 @@ func Test(t *testing.T) {
 	registerSimpleStreamsTests()
 	gc.Suite(&signingSuite{})
 	gc.Suite(&jsonSuite{})
+	gc.Suite(&lintSuite{})
+	gc.Suite(&diffSuite{})
 	gc.TestingT(t)
 }
 
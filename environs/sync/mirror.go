@@ -0,0 +1,220 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/downloader"
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/storage"
+	envtools "github.com/juju/juju/environs/tools"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+// mirrorDownloadRetries holds the number of times an interrupted
+// tools download is resumed while building a mirror.
+var mirrorDownloadRetries = 3
+
+// MirrorParams describes the contents of a mirror built by BuildMirror.
+type MirrorParams struct {
+	// Dir holds the directory in which the mirror is built.
+	Dir string
+
+	// ToolsSources holds the data sources from which tools are
+	// mirrored. If it is empty, no tools are mirrored.
+	ToolsSources []simplestreams.DataSource
+
+	// ImageSources holds the data sources from which image metadata
+	// is mirrored. If it is empty, no image metadata is mirrored.
+	ImageSources []simplestreams.DataSource
+
+	// Versions, if not empty, restricts the mirrored tools to those
+	// matching one of the given versions, such as "1.20" or "1.20.1".
+	Versions []string
+
+	// Series, if not empty, restricts the mirror to the given series.
+	Series []string
+
+	// Arches, if not empty, restricts the mirror to the given
+	// architectures.
+	Arches []string
+
+	// Clouds holds the clouds for which image metadata is mirrored.
+	Clouds []simplestreams.CloudSpec
+
+	// Stream holds the image stream to mirror. It defaults to the
+	// released stream.
+	Stream string
+}
+
+// BuildMirror copies the tools and image metadata described by params
+// into a self-contained directory, together with the simplestreams
+// metadata needed to use it. The directory may be served over HTTP and
+// used as the tools-metadata-url and image-metadata-url of environments
+// without access to the public sources. Every tools tarball is checked
+// against the size and hash recorded in the source metadata.
+func BuildMirror(params MirrorParams) error {
+	if err := os.MkdirAll(params.Dir, 0755); err != nil {
+		return err
+	}
+	stor, err := filestorage.NewFileStorageWriter(params.Dir)
+	if err != nil {
+		return err
+	}
+	if len(params.ToolsSources) > 0 {
+		if err := mirrorTools(stor, params); err != nil {
+			return errors.Annotate(err, "cannot mirror tools")
+		}
+	}
+	if len(params.ImageSources) > 0 && len(params.Clouds) > 0 {
+		if err := mirrorImages(stor, params); err != nil {
+			return errors.Annotate(err, "cannot mirror image metadata")
+		}
+	}
+	return nil
+}
+
+// mirrorTools copies the selected tools and writes their metadata.
+func mirrorTools(stor storage.Storage, params MirrorParams) error {
+	logger.Infof("listing available tools")
+	sourceTools, err := envtools.FindToolsForCloud(
+		params.ToolsSources, simplestreams.CloudSpec{}, -1, -1, coretools.Filter{})
+	if err != nil {
+		return err
+	}
+	var toolsList coretools.List
+	for _, tools := range sourceTools {
+		if matchesMirror(tools.Version, params) {
+			toolsList = append(toolsList, tools)
+		}
+	}
+	if len(toolsList) == 0 {
+		return coretools.ErrNoMatches
+	}
+	logger.Infof("found %d tools to mirror", len(toolsList))
+	for _, tools := range toolsList {
+		if err := mirrorToolsTarball(stor, params.Dir, tools); err != nil {
+			return err
+		}
+	}
+	metadata := envtools.MetadataFromTools(toolsList)
+	return envtools.WriteMetadata(stor, metadata, envtools.DoNotWriteMirrors)
+}
+
+// matchesMirror returns whether tools with the given version
+// should be included in the mirror.
+func matchesMirror(vers version.Binary, params MirrorParams) bool {
+	if len(params.Series) > 0 && !containsString(params.Series, vers.Series) {
+		return false
+	}
+	if len(params.Arches) > 0 && !containsString(params.Arches, vers.Arch) {
+		return false
+	}
+	if len(params.Versions) == 0 {
+		return true
+	}
+	number := vers.Number.String()
+	for _, v := range params.Versions {
+		if number == v || strings.HasPrefix(number, v+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorToolsTarball downloads the given tools into stor, verifying
+// their size and hash. Tools that are already in the mirror are kept.
+func mirrorToolsTarball(stor storage.Storage, dir string, tools *coretools.Tools) error {
+	if tools.Size == 0 || tools.SHA256 == "" {
+		return fmt.Errorf("no size or sha256 recorded for tools %s", tools.Version)
+	}
+	toolsName := envtools.StorageName(tools.Version)
+	if r, err := stor.Get(toolsName); err == nil {
+		sha256, size, err := utils.ReadSHA256(r)
+		r.Close()
+		if err == nil && size == tools.Size && sha256 == tools.SHA256 {
+			logger.Infof("%s already mirrored", tools.Version)
+			return nil
+		}
+	}
+	logger.Infof("mirroring %s from %s", tools.Version, tools.URL)
+	dl := downloader.NewWithOptions(downloader.Options{
+		URL:                  tools.URL,
+		Dir:                  dir,
+		HostnameVerification: utils.VerifySSLHostnames,
+		ExpectedSize:         tools.Size,
+		ExpectedSHA256:       tools.SHA256,
+		MaxRetries:           mirrorDownloadRetries,
+	})
+	defer dl.Stop()
+	status := <-dl.Done()
+	if status.Err != nil {
+		return status.Err
+	}
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	return stor.Put(toolsName, status.File, tools.Size)
+}
+
+// mirrorImages copies the selected image metadata for each of the
+// mirror's clouds.
+func mirrorImages(stor storage.Storage, params MirrorParams) error {
+	series := params.Series
+	if len(series) == 0 {
+		series = version.SupportedSeries()
+	}
+	count := 0
+	for _, cloud := range params.Clouds {
+		for _, s := range series {
+			cons := imagemetadata.NewImageConstraint(simplestreams.LookupParams{
+				CloudSpec: cloud,
+				Series:    []string{s},
+				Arches:    params.Arches,
+				Stream:    params.Stream,
+			})
+			metadata, _, err := imagemetadata.Fetch(
+				params.ImageSources, simplestreams.DefaultIndexPath, cons, false)
+			if errors.IsNotFound(err) {
+				logger.Debugf("no image metadata for %s in %s", s, cloud.Region)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if len(metadata) == 0 {
+				continue
+			}
+			for _, md := range metadata {
+				md.Stream = params.Stream
+			}
+			logger.Infof("mirroring %d images for %s in %s", len(metadata), s, cloud.Region)
+			if err := imagemetadata.MergeAndWriteMetadata(s, metadata, &cloud, stor); err != nil {
+				return err
+			}
+			count += len(metadata)
+		}
+	}
+	if count == 0 {
+		return errors.NotFoundf("image metadata")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/imagemetadata"
+	imagetesting "github.com/juju/juju/environs/imagemetadata/testing"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type mirrorSuite struct {
+	coretesting.BaseSuite
+	sourceDir string
+	mirrorDir string
+}
+
+var _ = gc.Suite(&mirrorSuite{})
+
+var mirrorCloud = simplestreams.CloudSpec{
+	Region:   "region-1",
+	Endpoint: "https://endpoint-1",
+}
+
+func (s *mirrorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.sourceDir = c.MkDir()
+	s.mirrorDir = filepath.Join(c.MkDir(), "mirror")
+	toolstesting.MakeToolsWithCheckSum(c, s.sourceDir, "releases", []string{
+		"1.8.0-precise-amd64",
+		"1.8.0-quantal-amd64",
+		"1.8.1-quantal-i386",
+		"1.9.0-quantal-amd64",
+	})
+	stor, err := filestorage.NewFileStorageWriter(s.sourceDir)
+	c.Assert(err, gc.IsNil)
+	err = imagemetadata.MergeAndWriteMetadata("precise", []*imagemetadata.ImageMetadata{{
+		Id:   "image-1",
+		Arch: "amd64",
+	}, {
+		Id:   "image-2",
+		Arch: "i386",
+	}}, &mirrorCloud, stor)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *mirrorSuite) toolsSources() []simplestreams.DataSource {
+	return []simplestreams.DataSource{simplestreams.NewURLDataSource(
+		"test tools", "file://"+filepath.Join(s.sourceDir, "tools"), utils.VerifySSLHostnames),
+	}
+}
+
+func (s *mirrorSuite) imageSources() []simplestreams.DataSource {
+	return []simplestreams.DataSource{simplestreams.NewURLDataSource(
+		"test images", "file://"+filepath.Join(s.sourceDir, "images"), utils.VerifySSLHostnames),
+	}
+}
+
+func (s *mirrorSuite) TestBuildMirrorTools(c *gc.C) {
+	err := sync.BuildMirror(sync.MirrorParams{
+		Dir:          s.mirrorDir,
+		ToolsSources: s.toolsSources(),
+		Versions:     []string{"1.8"},
+		Series:       []string{"quantal"},
+	})
+	c.Assert(err, gc.IsNil)
+
+	metadata := toolstesting.ParseMetadataFromDir(c, s.mirrorDir, false)
+	var mirrored []string
+	for _, md := range metadata {
+		mirrored = append(mirrored, md.Version+"-"+md.Release+"-"+md.Arch)
+		vers := version.MustParseBinary(md.Version + "-" + md.Release + "-" + md.Arch)
+		size, sha256 := toolstesting.SHA256sum(c, filepath.Join(s.mirrorDir, envtools.StorageName(vers)))
+		c.Check(size, gc.Equals, md.Size)
+		c.Check(sha256, gc.Equals, md.SHA256)
+	}
+	c.Assert(mirrored, jc.SameContents, []string{
+		"1.8.0-quantal-amd64",
+		"1.8.1-quantal-i386",
+	})
+	_, err = os.Stat(filepath.Join(s.mirrorDir, "tools", "releases", "juju-1.8.0-precise-amd64.tgz"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *mirrorSuite) TestBuildMirrorVerifiesTools(c *gc.C) {
+	path := filepath.Join(s.sourceDir, "tools", "releases", "juju-1.9.0-quantal-amd64.tgz")
+	err := ioutil.WriteFile(path, []byte("1.9.0-quantal-amd6x"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = sync.BuildMirror(sync.MirrorParams{
+		Dir:          s.mirrorDir,
+		ToolsSources: s.toolsSources(),
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot mirror tools: cannot download ".*": expected sha256 ".*", got ".*"`)
+}
+
+func (s *mirrorSuite) TestBuildMirrorNoMatchingTools(c *gc.C) {
+	err := sync.BuildMirror(sync.MirrorParams{
+		Dir:          s.mirrorDir,
+		ToolsSources: s.toolsSources(),
+		Versions:     []string{"2.0"},
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot mirror tools: no matching tools available")
+}
+
+func (s *mirrorSuite) TestBuildMirrorImages(c *gc.C) {
+	err := sync.BuildMirror(sync.MirrorParams{
+		Dir:          s.mirrorDir,
+		ImageSources: s.imageSources(),
+		Series:       []string{"precise"},
+		Arches:       []string{"amd64"},
+		Clouds:       []simplestreams.CloudSpec{mirrorCloud},
+	})
+	c.Assert(err, gc.IsNil)
+	metadata := imagetesting.ParseMetadataFromDir(c, s.mirrorDir)
+	c.Assert(metadata, gc.HasLen, 1)
+	c.Assert(metadata[0].Id, gc.Equals, "image-1")
+	c.Assert(metadata[0].RegionName, gc.Equals, "region-1")
+	c.Assert(metadata[0].Endpoint, gc.Equals, "https://endpoint-1")
+}
+
+func (s *mirrorSuite) TestBuildMirrorUnknownCloud(c *gc.C) {
+	err := sync.BuildMirror(sync.MirrorParams{
+		Dir:          s.mirrorDir,
+		ImageSources: s.imageSources(),
+		Clouds:       []simplestreams.CloudSpec{{Region: "region-2", Endpoint: "https://endpoint-2"}},
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot mirror image metadata: image metadata not found")
+}
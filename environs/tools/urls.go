@@ -41,7 +41,11 @@ func GetMetadataSourcesWithRetries(env environs.ConfigGetter, allowRetry bool) (
 		if !config.SSLHostnameVerification() {
 			verify = utils.NoVerifySSLHostnames
 		}
-		sources = append(sources, simplestreams.NewURLDataSource("tools-metadata-url", userURL, verify))
+		source, err := simplestreams.NewDataSource("tools-metadata-url", userURL, verify, config.ToolsMetadataAuth())
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
 	}
 	if custom, ok := env.(SupportsCustomSources); ok {
 		customSources, err := custom.GetToolsSources()
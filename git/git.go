@@ -0,0 +1,188 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package git offers an interface to manage branches of the Git VCS.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Branch represents the checked out branch of a Git working tree.
+type Branch struct {
+	location string
+	env      []string
+}
+
+// New returns a new Branch for the Git working tree at location.
+func New(location string) *Branch {
+	b := &Branch{location, cenv()}
+	if _, err := os.Stat(location); err == nil {
+		stdout, err := b.git("rev-parse", "--show-toplevel")
+		if err == nil {
+			b.location = strings.TrimRight(string(stdout), "\n")
+		}
+	}
+	return b
+}
+
+// cenv returns a copy of the current process environment with LC_ALL=C.
+func cenv() []string {
+	env := os.Environ()
+	for i, pair := range env {
+		if strings.HasPrefix(pair, "LC_ALL=") {
+			env[i] = "LC_ALL=C"
+			return env
+		}
+	}
+	return append(env, "LC_ALL=C")
+}
+
+// Location returns the location of the working tree of branch b.
+func (b *Branch) Location() string {
+	return b.location
+}
+
+// Join returns b's location with parts appended as path components.
+func (b *Branch) Join(parts ...string) string {
+	return path.Join(append([]string{b.location}, parts...)...)
+}
+
+func (b *Branch) git(subcommand string, args ...string) (stdout []byte, err error) {
+	cmd := exec.Command("git", append([]string{subcommand}, args...)...)
+	if _, err := os.Stat(b.location); err == nil {
+		cmd.Dir = b.location
+	}
+	errbuf := &bytes.Buffer{}
+	cmd.Stderr = errbuf
+	cmd.Env = b.env
+	stdout, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf(`error running "git %s": %s%s%s`, subcommand, stdout, errbuf.Bytes(), err)
+	}
+	return stdout, nil
+}
+
+// Init intializes a new repository at b's location.
+func (b *Branch) Init() error {
+	_, err := b.git("init", "-q", b.location)
+	return err
+}
+
+// Add adds to b the path resultant from calling b.Join(parts...).
+func (b *Branch) Add(parts ...string) error {
+	_, err := b.git("add", b.Join(parts...))
+	return err
+}
+
+// Commit commits pending changes into b.
+func (b *Branch) Commit(message string) error {
+	_, err := b.git("commit", "-q", "-m", message)
+	return err
+}
+
+// RevisionId returns the commit id for the tip of b.
+func (b *Branch) RevisionId() (string, error) {
+	stdout, err := b.git("rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		// HEAD cannot be resolved until the first commit.
+		return "", fmt.Errorf("branch has no content")
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// name returns the name of the checked out branch.
+func (b *Branch) name() (string, error) {
+	stdout, err := b.git("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// config returns the value of the given git configuration key,
+// or the empty string if it is not set.
+func (b *Branch) config(key string) string {
+	stdout, err := b.git("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(stdout))
+}
+
+// PushLocation returns the default push location for b, which is the
+// location of the remote the checked out branch tracks.
+func (b *Branch) PushLocation() (string, error) {
+	name, err := b.name()
+	if err != nil {
+		return "", err
+	}
+	remote := b.config("branch." + name + ".pushRemote")
+	if remote == "" {
+		remote = b.config("branch." + name + ".remote")
+	}
+	if remote == "" {
+		return "", fmt.Errorf("no push branch location defined")
+	}
+	// The remote may be the name of a configured remote, or a location.
+	if url := b.config("remote." + remote + ".pushurl"); url != "" {
+		return url, nil
+	}
+	if url := b.config("remote." + remote + ".url"); url != "" {
+		return url, nil
+	}
+	return remote, nil
+}
+
+// PushAttr holds options for the Branch.Push method.
+type PushAttr struct {
+	Location string // Location to push to. Use the default push location if empty.
+	Remember bool   // Whether to remember the location being pushed to as the default.
+}
+
+// Push pushes the checked out branch of b to the branch of the same
+// name at attr.Location if that's provided, or at the default push
+// location otherwise. As with bzr, the location is remembered if there
+// is no default push location yet. See PushAttr for other options.
+func (b *Branch) Push(attr *PushAttr) error {
+	name, err := b.name()
+	if err != nil {
+		return err
+	}
+	var location string
+	var remember bool
+	if attr != nil {
+		location, remember = attr.Location, attr.Remember
+	}
+	if location == "" {
+		if location, err = b.PushLocation(); err != nil {
+			return err
+		}
+	} else if _, err := b.PushLocation(); err != nil {
+		remember = true
+	}
+	args := []string{"-q"}
+	if remember {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, location, "HEAD:refs/heads/"+name)
+	_, err = b.git("push", args...)
+	return err
+}
+
+// CheckClean returns an error if 'git status' is not clean.
+func (b *Branch) CheckClean() error {
+	stdout, err := b.git("status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if len(stdout) > 0 {
+		return fmt.Errorf("branch is not clean (git status)")
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	stdtesting "testing"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/git"
+	"github.com/juju/juju/testing"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+var _ = gc.Suite(&GitSuite{})
+
+type GitSuite struct {
+	testing.BaseSuite
+	b *git.Branch
+}
+
+func (s *GitSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchEnvironment("HOME", c.MkDir())
+	for _, prefix := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		s.PatchEnvironment(prefix+"_NAME", "testing")
+		s.PatchEnvironment(prefix+"_EMAIL", "test@example.com")
+	}
+	s.b = git.New(c.MkDir())
+	c.Assert(s.b.Init(), gc.IsNil)
+}
+
+func (s *GitSuite) addFile(c *gc.C, b *git.Branch, name string) {
+	f, err := os.Create(b.Join(name))
+	c.Assert(err, gc.IsNil)
+	f.Close()
+	err = b.Add(name)
+	c.Assert(err, gc.IsNil)
+	err = b.Commit("added " + name)
+	c.Assert(err, gc.IsNil)
+}
+
+// newBare returns the location of a new bare repository.
+func newBare(c *gc.C) string {
+	dir := c.MkDir()
+	err := exec.Command("git", "init", "-q", "--bare", dir).Run()
+	c.Assert(err, gc.IsNil)
+	return dir
+}
+
+func (s *GitSuite) TestNewFindsRoot(c *gc.C) {
+	err := os.Mkdir(s.b.Join("dir"), 0755)
+	c.Assert(err, gc.IsNil)
+	b := git.New(s.b.Join("dir"))
+	path, err := filepath.EvalSymlinks(s.b.Location())
+	c.Assert(err, gc.IsNil)
+	c.Assert(b.Location(), gc.Equals, path)
+}
+
+func (s *GitSuite) TestJoin(c *gc.C) {
+	path := git.New("/foo").Join("baz", "bar")
+	c.Assert(path, gc.Equals, "/foo/baz/bar")
+}
+
+func (s *GitSuite) TestErrorHandling(c *gc.C) {
+	err := git.New(c.MkDir()).Commit("nothing")
+	c.Assert(err, gc.ErrorMatches, `(?s)error running "git commit":.*`)
+}
+
+func (s *GitSuite) TestInit(c *gc.C) {
+	_, err := os.Stat(s.b.Join(".git"))
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *GitSuite) TestRevisionIdOnEmpty(c *gc.C) {
+	revid, err := s.b.RevisionId()
+	c.Assert(err, gc.ErrorMatches, "branch has no content")
+	c.Assert(revid, gc.Equals, "")
+}
+
+func (s *GitSuite) TestCommit(c *gc.C) {
+	s.addFile(c, s.b, "myfile")
+	revid, err := s.b.RevisionId()
+	c.Assert(err, gc.IsNil)
+	c.Assert(revid, gc.Matches, "[0-9a-f]{40}")
+
+	cmd := exec.Command("git", "log", "--stat", revid)
+	cmd.Dir = s.b.Location()
+	output, err := cmd.CombinedOutput()
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(output), gc.Matches, "(?s)commit "+revid+"\n.*added myfile\n.*myfile .*")
+}
+
+func (s *GitSuite) TestPush(c *gc.C) {
+	s.addFile(c, s.b, "file")
+	loc1 := newBare(c)
+	loc2 := newBare(c)
+
+	_, err := s.b.PushLocation()
+	c.Assert(err, gc.ErrorMatches, "no push branch location defined")
+
+	// The first push location is remembered.
+	err = s.b.Push(&git.PushAttr{Location: loc1})
+	c.Assert(err, gc.IsNil)
+	location, err := s.b.PushLocation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(location, gc.Equals, loc1)
+
+	// Push it to loc2; the push location is unchanged.
+	err = s.b.Push(&git.PushAttr{Location: loc2})
+	c.Assert(err, gc.IsNil)
+	location, err = s.b.PushLocation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(location, gc.Equals, loc1)
+
+	// Push it again, this time with the remember flag set.
+	err = s.b.Push(&git.PushAttr{Location: loc2, Remember: true})
+	c.Assert(err, gc.IsNil)
+	location, err = s.b.PushLocation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(location, gc.Equals, loc2)
+
+	// Both repositories have the commit.
+	revid, err := s.b.RevisionId()
+	c.Assert(err, gc.IsNil)
+	for _, loc := range []string{loc1, loc2} {
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = loc
+		output, err := cmd.Output()
+		c.Assert(err, gc.IsNil)
+		c.Assert(string(output), gc.Equals, revid+"\n")
+	}
+}
+
+func (s *GitSuite) TestPushLocationNamedRemote(c *gc.C) {
+	s.addFile(c, s.b, "file")
+	loc := newBare(c)
+	cmd := exec.Command("git", "remote", "add", "origin", loc)
+	cmd.Dir = s.b.Location()
+	c.Assert(cmd.Run(), gc.IsNil)
+	cmd = exec.Command("git", "push", "-q", "--set-upstream", "origin", "HEAD")
+	cmd.Dir = s.b.Location()
+	c.Assert(cmd.Run(), gc.IsNil)
+
+	location, err := s.b.PushLocation()
+	c.Assert(err, gc.IsNil)
+	c.Assert(location, gc.Equals, loc)
+}
+
+func (s *GitSuite) TestCheckClean(c *gc.C) {
+	err := s.b.CheckClean()
+	c.Assert(err, gc.IsNil)
+
+	f, err := os.Create(s.b.Join("file"))
+	c.Assert(err, gc.IsNil)
+	f.Close()
+
+	err = s.b.CheckClean()
+	c.Assert(err, gc.ErrorMatches, `branch is not clean \(git status\)`)
+}
@@ -175,3 +175,62 @@ func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string)
 	}
 	return units, nil
 }
+
+// CloneUnits starts n units of the given service, each in a new container
+// cloned from the container running the unit named sourceUnitName. The new
+// containers are created on the same host as the source container.
+func CloneUnits(st *state.State, svc *state.Service, n int, sourceUnitName string) ([]*state.Unit, error) {
+	source, err := st.Unit(sourceUnitName)
+	if err != nil {
+		return nil, err
+	}
+	if source.ServiceName() != svc.Name() {
+		return nil, fmt.Errorf("cannot clone unit %q: not a unit of service %q", sourceUnitName, svc.Name())
+	}
+	if !source.IsPrincipal() {
+		return nil, fmt.Errorf("cannot clone unit %q: unit is a subordinate", sourceUnitName)
+	}
+	sourceMachineId, err := source.AssignedMachineId()
+	if err != nil {
+		return nil, fmt.Errorf("cannot clone unit %q: %v", sourceUnitName, err)
+	}
+	containerType := state.ContainerTypeFromId(sourceMachineId)
+	parentId := state.ParentId(sourceMachineId)
+	if containerType == "" || parentId == "" {
+		return nil, fmt.Errorf("cannot clone unit %q: machine %s is not a container", sourceUnitName, sourceMachineId)
+	}
+	networks, err := svc.Networks()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get service %q networks: %v", svc.Name(), err)
+	}
+	units := make([]*state.Unit, n)
+	for i := 0; i < n; i++ {
+		unit, err := svc.AddUnit()
+		if err != nil {
+			return nil, fmt.Errorf("cannot add unit %d/%d to service %q: %v", i+1, n, svc.Name(), err)
+		}
+		unitCons, err := unit.Constraints()
+		if err != nil {
+			return nil, err
+		}
+		// Create the new machine marked as dirty so that
+		// nothing else will grab it before we assign the unit to it.
+		template := state.MachineTemplate{
+			Series:            unit.Series(),
+			Jobs:              []state.MachineJob{state.JobHostUnits},
+			Dirty:             true,
+			Constraints:       *unitCons,
+			RequestedNetworks: networks,
+			CloneFrom:         sourceMachineId,
+		}
+		m, err := st.AddMachineInsideMachine(template, parentId, containerType)
+		if err != nil {
+			return nil, fmt.Errorf("cannot assign unit %q to machine: %v", unit.Name(), err)
+		}
+		if err := unit.AssignToMachine(m); err != nil {
+			return nil, err
+		}
+		units[i] = unit
+	}
+	return units, nil
+}
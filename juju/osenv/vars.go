@@ -8,6 +8,9 @@ const (
 	JujuHomeEnvKey          = "JUJU_HOME"
 	JujuRepositoryEnvKey    = "JUJU_REPOSITORY"
 	JujuLoggingConfigEnvKey = "JUJU_LOGGING_CONFIG"
+	// JujuKeyringPassphraseEnvKey holds the passphrase used to
+	// unlock the encrypted keyring configstore backend.
+	JujuKeyringPassphraseEnvKey = "JUJU_KEYRING_PASSPHRASE"
 	// TODO(thumper): 2013-09-02 bug 1219630
 	// As much as I'd like to remove JujuContainerType now, it is still
 	// needed as MAAS still needs it at this stage, and we can't fix
@@ -13,7 +13,7 @@ import (
 
 	"labix.org/v2/mgo"
 
-	"github.com/juju/juju/upstart"
+	"github.com/juju/juju/service"
 )
 
 var (
@@ -75,8 +75,11 @@ func EnsureAdminUser(p EnsureAdminUserParams) (added bool, err error) {
 	// Login failed, so we need to add the user.
 	// Stop mongo, so we can start it in --noauth mode.
 	mongoServiceName := ServiceName(p.Namespace)
-	mongoService := upstart.NewService(mongoServiceName)
-	if err := upstartServiceStop(mongoService); err != nil {
+	mongoService, err := service.New(initSystem(), mongoServiceName, service.Conf{})
+	if err != nil {
+		return false, err
+	}
+	if err := serviceStop(mongoService); err != nil {
 		return false, fmt.Errorf("failed to stop %v: %v", mongoServiceName, err)
 	}
 
@@ -103,7 +106,7 @@ func EnsureAdminUser(p EnsureAdminUserParams) (added bool, err error) {
 	}
 	logger.Infof("added %q to admin database", p.User)
 
-	// Restart mongo using upstart.
+	// Restart mongo using the init system.
 	if err := processSignal(cmd.Process, syscall.SIGTERM); err != nil {
 		return false, fmt.Errorf("cannot kill mongod: %v", err)
 	}
@@ -112,7 +115,7 @@ func EnsureAdminUser(p EnsureAdminUserParams) (added bool, err error) {
 			return false, fmt.Errorf("mongod did not cleanly terminate: %v", err)
 		}
 	}
-	if err := upstartServiceStart(mongoService); err != nil {
+	if err := serviceStart(mongoService); err != nil {
 		return false, err
 	}
 	return true, nil
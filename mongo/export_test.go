@@ -12,11 +12,12 @@ var (
 	SharedSecretPath = sharedSecretPath
 	SSLKeyPath       = sslKeyPath
 
-	UpstartConfInstall          = &upstartConfInstall
-	UpstartService              = upstartService
-	UpstartServiceStopAndRemove = &upstartServiceStopAndRemove
-	UpstartServiceStop          = &upstartServiceStop
-	UpstartServiceStart         = &upstartServiceStart
+	InitSystem           = &initSystem
+	MongoServiceConf     = mongoServiceConf
+	ServiceInstall       = &serviceInstall
+	ServiceStopAndRemove = &serviceStopAndRemove
+	ServiceStop          = &serviceStop
+	ServiceStart         = &serviceStart
 
 	HostWordSize   = &hostWordSize
 	RuntimeGOOS    = &runtimeGOOS
@@ -22,8 +22,8 @@ import (
 
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/replicaset"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/api/params"
-	"github.com/juju/juju/upstart"
 	"github.com/juju/juju/version"
 )
 
@@ -49,10 +49,14 @@ var (
 	// JujuMongodPath holds the default path to the juju-specific mongod.
 	JujuMongodPath = "/usr/lib/juju/bin/mongod"
 
-	upstartConfInstall          = (*upstart.Conf).Install
-	upstartServiceStopAndRemove = (*upstart.Service).StopAndRemove
-	upstartServiceStop          = (*upstart.Service).Stop
-	upstartServiceStart         = (*upstart.Service).Start
+	// initSystem returns the init system that manages
+	// the mongo service on this machine.
+	initSystem = service.DetectInitSystem
+
+	serviceInstall       = service.Service.Install
+	serviceStopAndRemove = service.Service.StopAndRemove
+	serviceStop          = service.Service.Stop
+	serviceStart         = service.Service.Start
 )
 
 // WithAddresses represents an entity that has a set of
@@ -129,10 +133,20 @@ func Path() (string, error) {
 	return path, nil
 }
 
-// RemoveService removes the mongoDB upstart service from this machine.
+// RemoveService removes the mongoDB service from this machine. The
+// service is removed from every supported init system, so that one
+// installed before the machine changed init system is removed too.
 func RemoveService(namespace string) error {
-	svc := upstart.NewService(ServiceName(namespace))
-	return upstartServiceStopAndRemove(svc)
+	for _, initSystem := range []string{service.InitSystemUpstart, service.InitSystemSystemd} {
+		svc, err := service.New(initSystem, ServiceName(namespace), service.Conf{})
+		if err != nil {
+			return err
+		}
+		if err := serviceStopAndRemove(svc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // EnsureServerParams is a parameter struct for EnsureServer.
@@ -153,10 +167,10 @@ type EnsureServerParams struct {
 	OplogSize int
 }
 
-// EnsureServer ensures that the correct mongo upstart script is installed
-// and running.
+// EnsureServer ensures that the correct mongo service is installed
+// and running, using the machine's init system.
 //
-// This method will remove old versions of the mongo upstart script as necessary
+// This method will remove old versions of the mongo service as necessary
 // before installing the new version.
 //
 // The namespace is a unique identifier to prevent multiple instances of mongo
@@ -209,13 +223,17 @@ func EnsureServer(args EnsureServerParams) error {
 		}
 	}
 
-	upstartConf, mongoPath, err := upstartService(args.Namespace, args.DataDir, dbDir, args.StatePort, oplogSizeMB)
+	conf, mongoPath, err := mongoServiceConf(args.DataDir, dbDir, args.StatePort, oplogSizeMB)
 	if err != nil {
 		return err
 	}
 	logVersion(mongoPath)
 
-	if err := upstartServiceStop(&upstartConf.Service); err != nil {
+	svc, err := service.New(initSystem(), ServiceName(args.Namespace), conf)
+	if err != nil {
+		return err
+	}
+	if err := serviceStop(svc); err != nil {
 		return fmt.Errorf("failed to stop mongo: %v", err)
 	}
 	if err := makeJournalDirs(dbDir); err != nil {
@@ -224,10 +242,10 @@ func EnsureServer(args EnsureServerParams) error {
 	if err := preallocOplog(dbDir, oplogSizeMB); err != nil {
 		return fmt.Errorf("error creating oplog files: %v", err)
 	}
-	return upstartConfInstall(upstartConf)
+	return serviceInstall(svc)
 }
 
-// ServiceName returns the name of the upstart service config for mongo using
+// ServiceName returns the name of the service for mongo using
 // the given namespace.
 func ServiceName(namespace string) string {
 	if namespace != "" {
@@ -268,15 +286,13 @@ func sharedSecretPath(dataDir string) string {
 	return filepath.Join(dataDir, SharedSecretFile)
 }
 
-// upstartService returns the upstart config for the mongo state service.
-// It also returns the path to the mongod executable that the upstart config
+// mongoServiceConf returns the service config for the mongo state service.
+// It also returns the path to the mongod executable that the service
 // will be using.
-func upstartService(namespace, dataDir, dbDir string, port, oplogSizeMB int) (*upstart.Conf, string, error) {
-	svc := upstart.NewService(ServiceName(namespace))
-
+func mongoServiceConf(dataDir, dbDir string, port, oplogSizeMB int) (service.Conf, string, error) {
 	mongoPath, err := Path()
 	if err != nil {
-		return nil, "", err
+		return service.Conf{}, "", err
 	}
 
 	mongoCmd := mongoPath + " --auth" +
@@ -293,9 +309,8 @@ func upstartService(namespace, dataDir, dbDir string, port, oplogSizeMB int) (*u
 		" --replSet " + ReplicaSetName +
 		" --ipv6 " +
 		" --oplogSize " + strconv.Itoa(oplogSizeMB)
-	conf := &upstart.Conf{
-		Service: *svc,
-		Desc:    "juju state database",
+	conf := service.Conf{
+		Desc: "juju state database",
 		Limit: map[string]string{
 			"nofile": fmt.Sprintf("%d %d", maxFiles, maxFiles),
 			"nproc":  fmt.Sprintf("%d %d", maxProcs, maxProcs),
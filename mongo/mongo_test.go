@@ -21,6 +21,8 @@ import (
 
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/upstart"
@@ -35,10 +37,10 @@ type MongoSuite struct {
 	mongodPath       string
 
 	installError error
-	installed    []upstart.Conf
+	installed    []service.Service
 
 	removeError error
-	removed     []upstart.Service
+	removed     []service.Service
 }
 
 var _ = gc.Suite(&MongoSuite{})
@@ -76,12 +78,13 @@ func (s *MongoSuite) SetUpTest(c *gc.C) {
 	s.mongodConfigPath = filepath.Join(testPath, "mongodConfig")
 	s.PatchValue(mongo.MongoConfigPath, s.mongodConfigPath)
 
-	s.PatchValue(mongo.UpstartConfInstall, func(conf *upstart.Conf) error {
-		s.installed = append(s.installed, *conf)
+	s.PatchValue(mongo.InitSystem, func() string { return service.InitSystemUpstart })
+	s.PatchValue(mongo.ServiceInstall, func(svc service.Service) error {
+		s.installed = append(s.installed, svc)
 		return s.installError
 	})
-	s.PatchValue(mongo.UpstartServiceStopAndRemove, func(svc *upstart.Service) error {
-		s.removed = append(s.removed, *svc)
+	s.PatchValue(mongo.ServiceStopAndRemove, func(svc service.Service) error {
+		s.removed = append(s.removed, svc)
 		return s.removeError
 	})
 	// Clear out the values that are set by the above patched functions.
@@ -146,7 +149,7 @@ func (s *MongoSuite) TestEnsureServer(c *gc.C) {
 
 	assertInstalled := func() {
 		c.Assert(s.installed, gc.HasLen, 1)
-		conf := s.installed[0]
+		conf := s.installed[0].(*upstart.Conf)
 		c.Assert(conf.Name, gc.Equals, "juju-db-namespace")
 		c.Assert(conf.InitDir, gc.Equals, "/etc/init")
 		c.Assert(conf.Desc, gc.Equals, "juju state database")
@@ -224,26 +227,26 @@ func (s *MongoSuite) TestInstallMongod(c *gc.C) {
 	}
 }
 
-func (s *MongoSuite) TestUpstartServiceWithReplSet(c *gc.C) {
+func (s *MongoSuite) TestServiceConfWithReplSet(c *gc.C) {
 	dataDir := c.MkDir()
 
-	svc, _, err := mongo.UpstartService("", dataDir, dataDir, 1234, 1024)
+	svc, _, err := mongo.MongoServiceConf(dataDir, dataDir, 1234, 1024)
 	c.Assert(err, gc.IsNil)
 	c.Assert(strings.Contains(svc.Cmd, "--replSet"), jc.IsTrue)
 }
 
-func (s *MongoSuite) TestUpstartServiceIPv6(c *gc.C) {
+func (s *MongoSuite) TestServiceConfIPv6(c *gc.C) {
 	dataDir := c.MkDir()
 
-	svc, _, err := mongo.UpstartService("", dataDir, dataDir, 1234, 1024)
+	svc, _, err := mongo.MongoServiceConf(dataDir, dataDir, 1234, 1024)
 	c.Assert(err, gc.IsNil)
 	c.Assert(strings.Contains(svc.Cmd, "--ipv6"), jc.IsTrue)
 }
 
-func (s *MongoSuite) TestUpstartServiceWithJournal(c *gc.C) {
+func (s *MongoSuite) TestServiceConfWithJournal(c *gc.C) {
 	dataDir := c.MkDir()
 
-	svc, _, err := mongo.UpstartService("", dataDir, dataDir, 1234, 1024)
+	svc, _, err := mongo.MongoServiceConf(dataDir, dataDir, 1234, 1024)
 	c.Assert(err, gc.IsNil)
 	journalPresent := strings.Contains(svc.Cmd, " --journal ") || strings.HasSuffix(svc.Cmd, " --journal")
 	c.Assert(journalPresent, jc.IsTrue)
@@ -266,10 +269,30 @@ func (s *MongoSuite) TestNoAuthCommandWithJournal(c *gc.C) {
 func (s *MongoSuite) TestRemoveService(c *gc.C) {
 	err := mongo.RemoveService("namespace")
 	c.Assert(err, gc.IsNil)
-	c.Assert(s.removed, jc.DeepEquals, []upstart.Service{{
+	c.Assert(s.removed, gc.HasLen, 2)
+	c.Assert(s.removed[0].(*upstart.Conf).Service, jc.DeepEquals, upstart.Service{
 		Name:    "juju-db-namespace",
 		InitDir: upstart.InitDir,
-	}})
+	})
+	c.Assert(s.removed[1].(*systemd.Conf).Service, jc.DeepEquals, systemd.Service{
+		Name:    "juju-db-namespace",
+		InitDir: systemd.InitDir,
+	})
+}
+
+func (s *MongoSuite) TestEnsureServerSystemd(c *gc.C) {
+	s.PatchValue(mongo.InitSystem, func() string { return service.InitSystemSystemd })
+	dataDir := c.MkDir()
+	mockShellCommand(c, &s.CleanupSuite, "apt-get")
+
+	err := mongo.EnsureServer(makeEnsureServerParams(dataDir, "namespace"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.installed, gc.HasLen, 1)
+	conf, ok := s.installed[0].(*systemd.Conf)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(conf.Name, gc.Equals, "juju-db-namespace")
+	c.Assert(conf.Desc, gc.Equals, "juju state database")
+	c.Assert(conf.Cmd, gc.Matches, regexp.QuoteMeta(s.mongodPath)+".*")
 }
 
 func (s *MongoSuite) TestQuantalAptAddRepo(c *gc.C) {
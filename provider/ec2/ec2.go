@@ -629,7 +629,11 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 		}
 	}
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("cannot run instances: %v", err)
+		runErr := fmt.Errorf("cannot run instances: %v", err)
+		if isCapacityError(err) {
+			runErr = environs.NewRetryableError(runErr)
+		}
+		return nil, nil, nil, runErr
 	}
 	if len(instResp.Instances) != 1 {
 		return nil, nil, nil, fmt.Errorf("expected 1 started instance, got %d", len(instResp.Instances))
@@ -1266,6 +1270,19 @@ func isZoneConstrainedError(err error) bool {
 	return false
 }
 
+// isCapacityError reports whether the error indicates that
+// RunInstances failed because EC2 was temporarily unable to
+// satisfy the request.
+func isCapacityError(err error) bool {
+	switch ec2ErrCode(err) {
+	case "InsufficientInstanceCapacity", "RequestLimitExceeded", "InternalError", "Unavailable":
+		return true
+	}
+	// The constrained zone errors are only returned once
+	// every availability zone has been tried.
+	return isZoneConstrainedError(err)
+}
+
 // If the err is of type *ec2.Error, ec2ErrCode returns
 // its code, otherwise it returns the empty string.
 func ec2ErrCode(err error) string {
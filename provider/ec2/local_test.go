@@ -521,9 +521,24 @@ func (t *localServerSuite) TestStartInstanceAvailZoneAllConstrained(c *gc.C) {
 	})
 	_, _, _, err = testing.StartInstance(env, "1")
 	c.Assert(err, gc.ErrorMatches, `cannot run instances: The requested Availability Zone is currently constrained etc\. \(Unsupported\)`)
+	c.Assert(environs.IsRetryable(err), jc.IsTrue)
 	c.Assert(azArgs, gc.DeepEquals, []string{"az1", "az2"})
 }
 
+func (t *localServerSuite) TestStartInstanceOtherErrorNotRetryable(c *gc.C) {
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		return nil, &amzec2.Error{Code: "InvalidAMIID.NotFound", Message: "no such image"}
+	})
+	_, _, _, err = testing.StartInstance(env, "1")
+	c.Assert(err, gc.ErrorMatches, `cannot run instances: no such image \(InvalidAMIID.NotFound\)`)
+	c.Assert(environs.IsRetryable(err), jc.IsFalse)
+}
+
 func (t *localServerSuite) TestStartInstanceAvailZoneOneConstrained(c *gc.C) {
 	env := t.Prepare(c)
 	envtesting.UploadFakeTools(c, env.Storage())
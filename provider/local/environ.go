@@ -40,8 +40,8 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/api/params"
-	"github.com/juju/juju/upstart"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker/terminationworker"
 )
@@ -50,6 +50,10 @@ import (
 // Using "localhost" because it is, and it makes sense.
 const bootstrapInstanceId instance.Id = "localhost"
 
+// detectInitSystem returns the init system that manages the
+// machine agent's service on the local machine.
+var detectInitSystem = service.DetectInitSystem
+
 // localEnviron implements Environ.
 var _ environs.Environ = (*localEnviron)(nil)
 
@@ -498,7 +502,11 @@ func (env *localEnviron) Destroy() error {
 	// Stop the mongo database and machine agent. It's possible that the
 	// service doesn't exist or is not running, so don't check the error.
 	mongo.RemoveService(env.config.namespace())
-	upstart.NewService(env.machineAgentServiceName()).StopAndRemove()
+	agentService, err := service.New(detectInitSystem(), env.machineAgentServiceName(), service.Conf{})
+	if err != nil {
+		return err
+	}
+	agentService.StopAndRemove()
 
 	// Finally, remove the data-dir.
 	if err := os.RemoveAll(env.config.rootDir()); err != nil && !os.IsNotExist(err) {
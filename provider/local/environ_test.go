@@ -29,6 +29,8 @@ import (
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/provider/local"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/systemd"
 	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/upstart"
@@ -262,6 +264,7 @@ func (s *localJujuTestSuite) TestDestroyRemovesUpstartServices(c *gc.C) {
 	s.makeAgentsDir(c, env)
 	mongo, machineAgent := s.makeFakeUpstartScripts(c, env)
 	s.PatchValue(local.CheckIfRoot, func() bool { return true })
+	s.PatchValue(local.DetectInitSystem, func() string { return service.InitSystemUpstart })
 
 	err := env.Destroy()
 	c.Assert(err, gc.IsNil)
@@ -270,6 +273,26 @@ func (s *localJujuTestSuite) TestDestroyRemovesUpstartServices(c *gc.C) {
 	c.Assert(machineAgent.Installed(), jc.IsFalse)
 }
 
+func (s *localJujuTestSuite) TestDestroyRemovesSystemdServices(c *gc.C) {
+	env := s.testBootstrap(c, minimalConfig(c))
+	s.makeAgentsDir(c, env)
+	systemdDir := c.MkDir()
+	s.PatchValue(&systemd.InitDir, systemdDir)
+	s.MakeTool(c, "systemctl", `[ "$1" = is-active ] && { echo inactive; exit 3; }; exit 0`)
+	namespace := env.Config().AllAttrs()["namespace"].(string)
+	agentUnit := filepath.Join(systemdDir, fmt.Sprintf("juju-agent-%s.service", namespace))
+	err := ioutil.WriteFile(agentUnit, []byte("[Unit]\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(local.CheckIfRoot, func() bool { return true })
+	s.PatchValue(local.DetectInitSystem, func() string { return service.InitSystemSystemd })
+
+	err = env.Destroy()
+	c.Assert(err, gc.IsNil)
+
+	_, err = os.Stat(agentUnit)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
 func (s *localJujuTestSuite) TestDestroyRemovesContainers(c *gc.C) {
 	env := s.testBootstrap(c, minimalConfig(c))
 	s.makeAgentsDir(c, env)
@@ -13,6 +13,7 @@ var (
 	CheckIfRoot      = &checkIfRoot
 	CheckLocalPort   = &checkLocalPort
 	DetectAptProxies = &detectAptProxies
+	DetectInitSystem = &detectInitSystem
 	FinishBootstrap  = &finishBootstrap
 	Provider         = providerInstance
 	UserCurrent      = &userCurrent
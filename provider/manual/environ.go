@@ -112,7 +112,7 @@ func (e *manualEnviron) Bootstrap(ctx environs.BootstrapContext, args environs.B
 	envConfig := e.envConfig()
 	// TODO(axw) consider how we can use placement to override bootstrap-host.
 	host := envConfig.bootstrapHost()
-	hc, series, err := manual.DetectSeriesAndHardwareCharacteristics(host)
+	hc, series, initSystem, err := manual.DetectSeriesAndHardwareCharacteristics(host, "")
 	if err != nil {
 		return err
 	}
@@ -128,6 +128,7 @@ func (e *manualEnviron) Bootstrap(ctx environs.BootstrapContext, args environs.B
 		PossibleTools:           selectedTools,
 		Series:                  series,
 		HardwareCharacteristics: &hc,
+		InitSystem:              initSystem,
 	})
 }
 
@@ -250,8 +251,8 @@ func (e *manualEnviron) Destroy() error {
 	script := `
 set -x
 pkill -%d jujud && exit
-stop %s
-rm -f /etc/init/juju*
+if [ -d /run/systemd/system ]; then systemctl stop %s; else stop %s; fi
+rm -f /etc/init/juju* /etc/systemd/system/juju*
 rm -f /etc/rsyslog.d/*juju*
 rm -fr %s %s
 exit 0
@@ -260,6 +261,7 @@ exit 0
 		script,
 		terminationworker.TerminationSignal,
 		mongo.ServiceName(""),
+		mongo.ServiceName(""),
 		utils.ShQuote(agent.DefaultDataDir),
 		utils.ShQuote(agent.DefaultLogDir),
 	)
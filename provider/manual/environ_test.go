@@ -93,8 +93,8 @@ func (s *environSuite) TestDestroy(c *gc.C) {
 		c.Assert(stdin, gc.DeepEquals, `
 set -x
 pkill -6 jujud && exit
-stop juju-db
-rm -f /etc/init/juju*
+if [ -d /run/systemd/system ]; then systemctl stop juju-db; else stop juju-db; fi
+rm -f /etc/init/juju* /etc/systemd/system/juju*
 rm -f /etc/rsyslog.d/*juju*
 rm -fr '/var/lib/juju' '/var/log/juju'
 exit 0
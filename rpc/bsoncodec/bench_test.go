@@ -0,0 +1,173 @@
+package bsoncodec_test
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	stdtesting "testing"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/bsoncodec"
+	"github.com/juju/juju/rpc/jsoncodec"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// The benchmarks below compare the BSON and JSON codecs
+// sending the kind of large messages that are common in
+// big environments: watcher deltas and full status results.
+
+func BenchmarkJSONAllWatcherDeltas(b *stdtesting.B) {
+	benchmarkCodec(b, newJSONCodec, allWatcherDeltas(1000), new(params.AllWatcherNextResults))
+}
+
+func BenchmarkBSONAllWatcherDeltas(b *stdtesting.B) {
+	benchmarkCodec(b, newBSONCodec, allWatcherDeltas(1000), new(params.AllWatcherNextResults))
+}
+
+func BenchmarkJSONFullStatus(b *stdtesting.B) {
+	benchmarkCodec(b, newJSONCodec, fullStatus(200), new(api.Status))
+}
+
+func BenchmarkBSONFullStatus(b *stdtesting.B) {
+	benchmarkCodec(b, newBSONCodec, fullStatus(200), new(api.Status))
+}
+
+func newJSONCodec(conn net.Conn) rpc.Codec {
+	return jsoncodec.NewNet(conn)
+}
+
+func newBSONCodec(conn net.Conn) rpc.Codec {
+	return bsoncodec.NewNet(conn)
+}
+
+// countingConn counts the bytes written to a net.Conn.
+type countingConn struct {
+	net.Conn
+	written int64
+}
+
+func (c *countingConn) Write(data []byte) (int, error) {
+	n, err := c.Conn.Write(data)
+	atomic.AddInt64(&c.written, int64(n))
+	return n, err
+}
+
+// benchmarkCodec measures the time taken to send the given
+// response body from one codec to another and decode it into
+// result. The number of bytes per message is reported so that
+// message sizes can be compared too.
+func benchmarkCodec(b *stdtesting.B, newCodec func(net.Conn) rpc.Codec, body, result interface{}) {
+	c0, c1 := net.Pipe()
+	counter := &countingConn{Conn: c0}
+	sender := newCodec(counter)
+	receiver := newCodec(c1)
+	defer sender.Close()
+	defer receiver.Close()
+
+	done := make(chan error, 1)
+	b.ResetTimer()
+	go func() {
+		for i := 0; i < b.N; i++ {
+			hdr := &rpc.Header{RequestId: uint64(i + 1)}
+			if err := sender.WriteMessage(hdr, body); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	for i := 0; i < b.N; i++ {
+		var hdr rpc.Header
+		if err := receiver.ReadHeader(&hdr); err != nil {
+			b.Fatalf("cannot read header: %v", err)
+		}
+		if err := receiver.ReadBody(result, false); err != nil {
+			b.Fatalf("cannot read body: %v", err)
+		}
+	}
+	if err := <-done; err != nil {
+		b.Fatalf("cannot write message: %v", err)
+	}
+	b.StopTimer()
+	b.SetBytes(atomic.LoadInt64(&counter.written) / int64(b.N))
+}
+
+func allWatcherDeltas(n int) *params.AllWatcherNextResults {
+	var deltas []params.Delta
+	for i := 0; i < n; i++ {
+		deltas = append(deltas, params.Delta{
+			Entity: &params.MachineInfo{
+				Id:         fmt.Sprint(i),
+				InstanceId: fmt.Sprintf("i-%08d", i),
+				Status:     params.StatusStarted,
+				Life:       params.Alive,
+				Series:     "trusty",
+				Jobs:       []params.MachineJob{params.JobHostUnits},
+				Addresses: []network.Address{
+					network.NewAddress(fmt.Sprintf("10.0.%d.%d", i/256, i%256), network.ScopeCloudLocal),
+				},
+			},
+		}, params.Delta{
+			Entity: &params.UnitInfo{
+				Name:           fmt.Sprintf("wordpress/%d", i),
+				Service:        "wordpress",
+				Series:         "trusty",
+				CharmURL:       "cs:trusty/wordpress-42",
+				PrivateAddress: fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+				MachineId:      fmt.Sprint(i),
+				Ports:          []network.Port{{Protocol: "tcp", Number: 80}},
+				Status:         params.StatusStarted,
+			},
+		})
+	}
+	return &params.AllWatcherNextResults{Deltas: deltas}
+}
+
+func fullStatus(n int) *api.Status {
+	status := &api.Status{
+		EnvironmentName: "bench",
+		Machines:        make(map[string]api.MachineStatus),
+		Services:        make(map[string]api.ServiceStatus),
+	}
+	units := make(map[string]api.UnitStatus)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprint(i)
+		status.Machines[id] = api.MachineStatus{
+			Agent: api.AgentStatus{
+				Status:  params.StatusStarted,
+				Version: "1.20.0",
+				Life:    "alive",
+			},
+			AgentState:   params.StatusStarted,
+			AgentVersion: "1.20.0",
+			DNSName:      fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+			InstanceId:   "i-" + id,
+			Series:       "trusty",
+			Id:           id,
+			Hardware:     "arch=amd64 cpu-cores=1 mem=1740M",
+			Jobs:         []params.MachineJob{params.JobHostUnits},
+		}
+		units[fmt.Sprintf("wordpress/%d", i)] = api.UnitStatus{
+			Agent: api.AgentStatus{
+				Status:  params.StatusStarted,
+				Version: "1.20.0",
+				Life:    "alive",
+			},
+			AgentState:    params.StatusStarted,
+			AgentVersion:  "1.20.0",
+			Machine:       id,
+			OpenedPorts:   []string{"80/tcp"},
+			PublicAddress: fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+			Charm:         "cs:trusty/wordpress-42",
+		}
+	}
+	status.Services["wordpress"] = api.ServiceStatus{
+		Charm:     "cs:trusty/wordpress-42",
+		Exposed:   true,
+		Relations: map[string][]string{"db": {"mysql"}},
+		Units:     units,
+	}
+	return status
+}
@@ -0,0 +1,270 @@
+// The bsoncodec package provides a BSON codec for the rpc package.
+// It produces smaller messages than jsoncodec and is cheaper to
+// marshal, which matters for large watcher deltas and status results.
+package bsoncodec
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/loggo"
+	"labix.org/v2/mgo/bson"
+
+	"github.com/juju/juju/rpc"
+)
+
+var logger = loggo.GetLogger("juju.rpc.bsoncodec")
+
+// BSONConn sends and receives messages to an underlying connection
+// in BSON format.
+type BSONConn interface {
+	// Send sends a message.
+	Send(msg interface{}) error
+	// Receive receives a message into msg.
+	Receive(msg interface{}) error
+	Close() error
+}
+
+// Codec implements rpc.Codec for a connection.
+type Codec struct {
+	// msg holds the message that's just been read by ReadHeader, so
+	// that the body can be read by ReadBody.
+	msg         inMsg
+	conn        BSONConn
+	logMessages int32
+	mu          sync.Mutex
+	closing     bool
+}
+
+// New returns an rpc codec that uses conn to send and receive
+// messages.
+func New(conn BSONConn) *Codec {
+	return &Codec{
+		conn: conn,
+	}
+}
+
+// SetLogging sets whether messages will be logged
+// by the codec.
+func (c *Codec) SetLogging(on bool) {
+	val := int32(0)
+	if on {
+		val = 1
+	}
+	atomic.StoreInt32(&c.logMessages, val)
+}
+
+func (c *Codec) isLogging() bool {
+	return atomic.LoadInt32(&c.logMessages) != 0
+}
+
+// inMsg holds an incoming message.  We don't know the type of the
+// parameters or response yet, so we delay parsing by storing them
+// in a bson.Raw.
+type inMsg struct {
+	RequestId uint64   `bson:"i"`
+	Type      string   `bson:"t"`
+	Version   int      `bson:"v"`
+	Id        string   `bson:"id"`
+	Request   string   `bson:"r"`
+	Params    bson.Raw `bson:"p"`
+	Error     string   `bson:"e"`
+	ErrorCode string   `bson:"c"`
+	Response  bson.Raw `bson:"re"`
+}
+
+// outMsg holds an outgoing message. The field names are
+// abbreviated to keep messages small.
+type outMsg struct {
+	RequestId uint64      `bson:"i"`
+	Type      string      `bson:"t,omitempty"`
+	Version   int         `bson:"v,omitempty"`
+	Id        string      `bson:"id,omitempty"`
+	Request   string      `bson:"r,omitempty"`
+	Params    interface{} `bson:"p,omitempty"`
+	Error     string      `bson:"e,omitempty"`
+	ErrorCode string      `bson:"c,omitempty"`
+	Response  interface{} `bson:"re,omitempty"`
+}
+
+func (c *Codec) Close() error {
+	c.mu.Lock()
+	c.closing = true
+	c.mu.Unlock()
+	return c.conn.Close()
+}
+
+func (c *Codec) isClosing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closing
+}
+
+func (c *Codec) ReadHeader(hdr *rpc.Header) error {
+	c.msg = inMsg{} // avoid any potential cross-message contamination.
+	var err error
+	if c.isLogging() {
+		var m bson.Raw
+		err = c.conn.Receive(&m)
+		if err == nil {
+			logger.Tracef("<- %s", dump(m))
+			err = m.Unmarshal(&c.msg)
+		} else {
+			logger.Tracef("<- error: %v (closing %v)", err, c.isClosing())
+		}
+	} else {
+		err = c.conn.Receive(&c.msg)
+	}
+	if err != nil {
+		// If we've closed the connection, we may get a spurious error,
+		// so ignore it.
+		if c.isClosing() || err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("error receiving message: %v", err)
+	}
+	hdr.RequestId = c.msg.RequestId
+	hdr.Request = rpc.Request{
+		Type:    c.msg.Type,
+		Version: c.msg.Version,
+		Id:      c.msg.Id,
+		Action:  c.msg.Request,
+	}
+	hdr.Error = c.msg.Error
+	hdr.ErrorCode = c.msg.ErrorCode
+	return nil
+}
+
+func (c *Codec) ReadBody(body interface{}, isRequest bool) error {
+	if body == nil {
+		return nil
+	}
+	var rawBody bson.Raw
+	if isRequest {
+		rawBody = c.msg.Params
+	} else {
+		rawBody = c.msg.Response
+	}
+	if rawBody.Kind == 0 {
+		// If the response or params are omitted, it's
+		// equivalent to an empty object.
+		return nil
+	}
+	if err := rawBody.Unmarshal(body); err != nil {
+		return err
+	}
+	fixDocs(reflect.ValueOf(body))
+	return nil
+}
+
+var (
+	typeMap   = reflect.TypeOf(map[string]interface{}{})
+	typeSlice = reflect.TypeOf([]interface{}{})
+)
+
+// fixDocs walks the value v, which has been unmarshalled from BSON,
+// and converts every document held in an interface{} value to a
+// map[string]interface{}. The bson package produces bson.M for such
+// documents (or the type of the enclosing map), and callers written
+// for jsoncodec type-assert them as map[string]interface{}.
+func fixDocs(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			fixDocs(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		if generic := genericValue(v.Elem()); generic.IsValid() {
+			v.Set(generic)
+			return
+		}
+		fixDocs(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				fixDocs(field)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fixDocs(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			// Map elements are not addressable, so fix a
+			// copy and store it back.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			fixDocs(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// genericValue returns v converted to a map[string]interface{} or an
+// []interface{} with its contents fixed, if v holds a generic document
+// or array. Otherwise it returns the zero Value.
+func genericValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Map && t.Key() == typeMap.Key() && t.Elem() == typeMap.Elem():
+		if t != typeMap {
+			v = v.Convert(typeMap)
+		}
+	case t == typeSlice:
+	default:
+		return reflect.Value{}
+	}
+	fixDocs(v)
+	return v
+}
+
+func (c *Codec) WriteMessage(hdr *rpc.Header, body interface{}) error {
+	var m outMsg
+	m.init(hdr, body)
+	if c.isLogging() {
+		data, err := bson.Marshal(&m)
+		if err != nil {
+			logger.Tracef("-> marshal error: %v", err)
+			return err
+		}
+		logger.Tracef("-> %s", dump(bson.Raw{Kind: 3, Data: data}))
+	}
+	return c.conn.Send(&m)
+}
+
+// init fills out the receiving outMsg with information from the given
+// header and body.
+func (m *outMsg) init(hdr *rpc.Header, body interface{}) {
+	m.RequestId = hdr.RequestId
+	m.Type = hdr.Request.Type
+	m.Version = hdr.Request.Version
+	m.Id = hdr.Request.Id
+	m.Request = hdr.Request.Action
+	m.Error = hdr.Error
+	m.ErrorCode = hdr.ErrorCode
+	if hdr.IsRequest() {
+		m.Params = body
+	} else {
+		m.Response = body
+	}
+}
+
+// dump returns a human readable representation of
+// the given BSON document for logging.
+func dump(raw bson.Raw) string {
+	var doc bson.D
+	if err := raw.Unmarshal(&doc); err != nil {
+		return fmt.Sprintf("<invalid BSON: %v>", err)
+	}
+	return fmt.Sprintf("%v", doc)
+}
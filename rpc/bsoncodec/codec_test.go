@@ -0,0 +1,365 @@
+package bsoncodec_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	stdtesting "testing"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	"labix.org/v2/mgo/bson"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/bsoncodec"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type suite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&suite{})
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type value struct {
+	X string
+}
+
+var readTests = []struct {
+	msg        bson.M
+	expectHdr  rpc.Header
+	expectBody interface{}
+}{{
+	msg: bson.M{"i": 1, "t": "foo", "id": "id", "r": "frob", "p": bson.M{"x": "param"}},
+	expectHdr: rpc.Header{
+		RequestId: 1,
+		Request: rpc.Request{
+			Type:   "foo",
+			Id:     "id",
+			Action: "frob",
+		},
+	},
+	expectBody: &value{X: "param"},
+}, {
+	msg: bson.M{"i": 2, "e": "an error", "c": "a code"},
+	expectHdr: rpc.Header{
+		RequestId: 2,
+		Error:     "an error",
+		ErrorCode: "a code",
+	},
+	expectBody: new(map[string]interface{}),
+}, {
+	msg: bson.M{"i": 3, "re": bson.M{"x": "result"}},
+	expectHdr: rpc.Header{
+		RequestId: 3,
+	},
+	expectBody: &value{X: "result"},
+}, {
+	msg: bson.M{"i": 4, "t": "foo", "v": 2, "id": "id", "r": "frob", "p": bson.M{"x": "param"}},
+	expectHdr: rpc.Header{
+		RequestId: 4,
+		Request: rpc.Request{
+			Type:    "foo",
+			Version: 2,
+			Id:      "id",
+			Action:  "frob",
+		},
+	},
+	expectBody: &value{X: "param"},
+}}
+
+func (*suite) TestRead(c *gc.C) {
+	for i, test := range readTests {
+		c.Logf("test %d", i)
+		codec := bsoncodec.New(&testConn{
+			readMsgs: []bson.M{test.msg},
+		})
+		var hdr rpc.Header
+		err := codec.ReadHeader(&hdr)
+		c.Assert(err, gc.IsNil)
+		c.Assert(hdr, gc.DeepEquals, test.expectHdr)
+
+		c.Assert(hdr.IsRequest(), gc.Equals, test.expectHdr.IsRequest())
+
+		body := reflect.New(reflect.ValueOf(test.expectBody).Type().Elem()).Interface()
+		err = codec.ReadBody(body, test.expectHdr.IsRequest())
+		c.Assert(err, gc.IsNil)
+		c.Assert(body, gc.DeepEquals, test.expectBody)
+
+		err = codec.ReadHeader(&hdr)
+		c.Assert(err, gc.Equals, io.EOF)
+	}
+}
+
+var writeTests = []struct {
+	hdr    *rpc.Header
+	body   interface{}
+	expect wireMsg
+}{{
+	hdr: &rpc.Header{
+		RequestId: 1,
+		Request: rpc.Request{
+			Type:   "foo",
+			Id:     "id",
+			Action: "frob",
+		},
+	},
+	body:   &value{X: "param"},
+	expect: wireMsg{RequestId: 1, Type: "foo", Id: "id", Request: "frob", Params: bson.M{"x": "param"}},
+}, {
+	hdr: &rpc.Header{
+		RequestId: 2,
+		Error:     "an error",
+		ErrorCode: "a code",
+	},
+	expect: wireMsg{RequestId: 2, Error: "an error", ErrorCode: "a code"},
+}, {
+	hdr: &rpc.Header{
+		RequestId: 3,
+	},
+	body:   &value{X: "result"},
+	expect: wireMsg{RequestId: 3, Response: bson.M{"x": "result"}},
+}, {
+	hdr: &rpc.Header{
+		RequestId: 4,
+		Request: rpc.Request{
+			Type:    "foo",
+			Version: 2,
+			Action:  "frob",
+		},
+	},
+	body:   &value{X: "param"},
+	expect: wireMsg{RequestId: 4, Type: "foo", Version: 2, Request: "frob", Params: bson.M{"x": "param"}},
+}}
+
+func (*suite) TestWrite(c *gc.C) {
+	for i, test := range writeTests {
+		c.Logf("test %d", i)
+		var conn testConn
+		codec := bsoncodec.New(&conn)
+		err := codec.WriteMessage(test.hdr, test.body)
+		c.Assert(err, gc.IsNil)
+		c.Assert(conn.writeMsgs, gc.HasLen, 1)
+		c.Assert(conn.writeMsgs[0], jc.DeepEquals, test.expect)
+	}
+}
+
+func (*suite) TestLogging(c *gc.C) {
+	codecLogger := loggo.GetLogger("juju.rpc.bsoncodec")
+	defer codecLogger.SetLogLevel(codecLogger.LogLevel())
+	codecLogger.SetLogLevel(loggo.TRACE)
+	msg := bson.M{"i": 1, "t": "foo", "id": "id", "r": "frob", "p": bson.M{"x": "param"}}
+	conn := &testConn{
+		readMsgs: []bson.M{msg, msg},
+	}
+	codec := bsoncodec.New(conn)
+
+	// Check that logging is off by default.
+	var h rpc.Header
+	err := codec.ReadHeader(&h)
+	c.Assert(err, gc.IsNil)
+	c.Assert(c.GetTestLog(), gc.Matches, "")
+
+	codec.SetLogging(true)
+	err = codec.ReadHeader(&h)
+	c.Assert(err, gc.IsNil)
+	c.Assert(h.Request.Action, gc.Equals, "frob")
+	c.Assert(c.GetTestLog(), gc.Matches, `(?s).*TRACE juju.rpc.bsoncodec <- .*frob.*`)
+
+	err = codec.WriteMessage(&rpc.Header{RequestId: 1}, &value{X: "result"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(c.GetTestLog(), gc.Matches, `(?s).*TRACE juju.rpc.bsoncodec -> .*result.*`)
+}
+
+func (*suite) TestErrorAfterClose(c *gc.C) {
+	conn := &testConn{
+		err: errors.New("some error"),
+	}
+	codec := bsoncodec.New(conn)
+	var hdr rpc.Header
+	err := codec.ReadHeader(&hdr)
+	c.Assert(err, gc.ErrorMatches, "error receiving message: some error")
+
+	err = codec.Close()
+	c.Assert(err, gc.IsNil)
+	c.Assert(conn.closed, gc.Equals, true)
+
+	err = codec.ReadHeader(&hdr)
+	c.Assert(err, gc.Equals, io.EOF)
+}
+
+func (*suite) TestNetRoundTrip(c *gc.C) {
+	c0, c1 := net.Pipe()
+	client := bsoncodec.NewNet(c0)
+	server := bsoncodec.NewNet(c1)
+	defer client.Close()
+	defer server.Close()
+
+	hdr := &rpc.Header{
+		RequestId: 99,
+		Request: rpc.Request{
+			Type:    "foo",
+			Version: 1,
+			Id:      "id",
+			Action:  "frob",
+		},
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- client.WriteMessage(hdr, &value{X: "param"})
+	}()
+	var got rpc.Header
+	err := server.ReadHeader(&got)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.DeepEquals, *hdr)
+	var body value
+	err = server.ReadBody(&body, true)
+	c.Assert(err, gc.IsNil)
+	c.Assert(body, gc.Equals, value{X: "param"})
+	c.Assert(<-done, gc.IsNil)
+}
+
+var paramsRoundTripTests = []struct {
+	about string
+	value interface{}
+}{{
+	about: "simple struct",
+	value: &params.ServiceSet{
+		ServiceName: "wordpress",
+		Options:     map[string]string{"blog-title": "hello"},
+	},
+}, {
+	about: "map with nested documents and arrays",
+	value: &params.EnvironmentGetResults{
+		Config: map[string]interface{}{
+			"name": "dummyenv",
+			"tools-metadata": map[string]interface{}{
+				"mirrors": []interface{}{
+					map[string]interface{}{"url": "http://example.com"},
+				},
+			},
+		},
+	},
+}, {
+	about: "named map type",
+	value: &params.ConfigSettingsResult{
+		Settings: params.ConfigSettings{
+			"nested": map[string]interface{}{"key": "value"},
+		},
+	},
+}, {
+	about: "interface field",
+	value: &params.EntityId{
+		Kind: "annotation",
+		Id:   map[string]interface{}{"globalKey": "m#0"},
+	},
+}, {
+	about: "slice of structs with named map fields",
+	value: &params.ConfigSettingsResults{
+		Results: []params.ConfigSettingsResult{{
+			Settings: params.ConfigSettings{"outfile": map[string]interface{}{"path": "/tmp"}},
+		}, {
+			Error: &params.Error{Message: "not found", Code: params.CodeNotFound},
+		}},
+	},
+}}
+
+func (*suite) TestParamsRoundTrip(c *gc.C) {
+	c0, c1 := net.Pipe()
+	client := bsoncodec.NewNet(c0)
+	server := bsoncodec.NewNet(c1)
+	defer client.Close()
+	defer server.Close()
+	for i, test := range paramsRoundTripTests {
+		c.Logf("test %d. %s", i, test.about)
+		done := make(chan error, 1)
+		go func() {
+			done <- client.WriteMessage(&rpc.Header{RequestId: uint64(i)}, test.value)
+		}()
+		var hdr rpc.Header
+		err := server.ReadHeader(&hdr)
+		c.Assert(err, gc.IsNil)
+		body := reflect.New(reflect.TypeOf(test.value).Elem()).Interface()
+		err = server.ReadBody(body, false)
+		c.Assert(err, gc.IsNil)
+		c.Assert(<-done, gc.IsNil)
+		// Documents held in interface{} values must be
+		// map[string]interface{}, as they are with jsoncodec,
+		// so gc.DeepEquals is used to check their types.
+		c.Assert(body, gc.DeepEquals, test.value)
+	}
+}
+
+func (*suite) TestNetInvalidSize(c *gc.C) {
+	c0, c1 := net.Pipe()
+	server := bsoncodec.NewNet(c1)
+	defer server.Close()
+	go func() {
+		c0.Write([]byte{1, 0, 0, 0})
+		c0.Close()
+	}()
+	var hdr rpc.Header
+	err := server.ReadHeader(&hdr)
+	c.Assert(err, gc.ErrorMatches, "error receiving message: invalid BSON message size 1")
+}
+
+// wireMsg describes the format of a message on the wire.
+type wireMsg struct {
+	RequestId uint64 `bson:"i"`
+	Type      string `bson:"t"`
+	Version   int    `bson:"v"`
+	Id        string `bson:"id"`
+	Request   string `bson:"r"`
+	Params    bson.M `bson:"p"`
+	Error     string `bson:"e"`
+	ErrorCode string `bson:"c"`
+	Response  bson.M `bson:"re"`
+}
+
+type testConn struct {
+	readMsgs  []bson.M
+	err       error
+	writeMsgs []wireMsg
+	closed    bool
+}
+
+func (c *testConn) Receive(msg interface{}) error {
+	if len(c.readMsgs) > 0 {
+		m := c.readMsgs[0]
+		c.readMsgs = c.readMsgs[1:]
+		data, err := bson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return bson.Unmarshal(data, msg)
+	}
+	if c.err != nil {
+		return c.err
+	}
+	return io.EOF
+}
+
+func (c *testConn) Send(msg interface{}) error {
+	data, err := bson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var m wireMsg
+	if err := bson.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	c.writeMsgs = append(c.writeMsgs, m)
+	return nil
+}
+
+func (c *testConn) Close() error {
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,100 @@
+package bsoncodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+
+	"code.google.com/p/go.net/websocket"
+	"labix.org/v2/mgo/bson"
+)
+
+// WebsocketProtocol holds the websocket subprotocol name used to
+// negotiate the BSON codec when a websocket connection is opened.
+const WebsocketProtocol = "juju-rpc-bson"
+
+// maxMessageSize holds the largest message that will be read
+// from a net connection.
+const maxMessageSize = 64 * 1024 * 1024
+
+// websocketCodec marshals messages as BSON documents sent
+// in binary websocket frames.
+var websocketCodec = websocket.Codec{
+	Marshal: func(v interface{}) ([]byte, byte, error) {
+		data, err := bson.Marshal(v)
+		return data, websocket.BinaryFrame, err
+	},
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		return bson.Unmarshal(data, v)
+	},
+}
+
+// NewWebsocket returns an rpc codec that uses the given websocket
+// connection to send and receive messages.
+func NewWebsocket(conn *websocket.Conn) *Codec {
+	return New(wsBSONConn{conn})
+}
+
+type wsBSONConn struct {
+	conn *websocket.Conn
+}
+
+func (conn wsBSONConn) Send(msg interface{}) error {
+	return websocketCodec.Send(conn.conn, msg)
+}
+
+func (conn wsBSONConn) Receive(msg interface{}) error {
+	return websocketCodec.Receive(conn.conn, msg)
+}
+
+func (conn wsBSONConn) Close() error {
+	return conn.conn.Close()
+}
+
+// NewNet returns an rpc codec that uses the given net
+// connection to send and receive messages.
+func NewNet(conn net.Conn) *Codec {
+	return New(&netConn{
+		conn: conn,
+	})
+}
+
+type netConn struct {
+	conn net.Conn
+}
+
+func (conn *netConn) Send(msg interface{}) error {
+	data, err := bson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.conn.Write(data)
+	return err
+}
+
+// Receive reads a single BSON document. Each document
+// starts with its total length as a little-endian int32.
+func (conn *netConn) Receive(msg interface{}) error {
+	var size [4]byte
+	if _, err := io.ReadFull(conn.conn, size[:]); err != nil {
+		return err
+	}
+	n := binary.LittleEndian.Uint32(size[:])
+	if n < 5 || n > maxMessageSize {
+		return fmt.Errorf("invalid BSON message size %d", n)
+	}
+	data := make([]byte, n)
+	copy(data, size[:])
+	if _, err := io.ReadFull(conn.conn, data[4:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return bson.Unmarshal(data, msg)
+}
+
+func (conn *netConn) Close() error {
+	return conn.conn.Close()
+}
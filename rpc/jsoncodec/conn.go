@@ -7,6 +7,11 @@ import (
 	"code.google.com/p/go.net/websocket"
 )
 
+// WebsocketProtocol holds the websocket subprotocol name used to
+// negotiate the JSON codec when a websocket connection is opened.
+// A connection that negotiates no subprotocol also uses JSON.
+const WebsocketProtocol = "juju-rpc-json"
+
 // NewWebsocket returns an rpc codec that uses the given websocket
 // connection to send and receive messages.
 func NewWebsocket(conn *websocket.Conn) *Codec {
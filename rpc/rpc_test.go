@@ -17,6 +17,7 @@ import (
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/bsoncodec"
 	"github.com/juju/juju/rpc/jsoncodec"
 	"github.com/juju/juju/rpc/rpcreflect"
 	"github.com/juju/juju/testing"
@@ -375,8 +376,16 @@ func SimpleRoot() *Root {
 }
 
 func (*rpcSuite) TestRPC(c *gc.C) {
+	testRPC(c, NewJSONCodec)
+}
+
+func (*rpcSuite) TestRPCWithBSONCodec(c *gc.C) {
+	testRPC(c, NewBSONCodec)
+}
+
+func testRPC(c *gc.C, newCodec codecFunc) {
 	root := SimpleRoot()
-	client, srvDone, clientNotifier, serverNotifier := newRPCClientServer(c, root, nil, false)
+	client, srvDone, clientNotifier, serverNotifier := newRPCClientServerWithCodec(c, root, nil, false, newCodec)
 	defer closeClient(c, client, srvDone)
 	for narg := 0; narg < 2; narg++ {
 		for nret := 0; nret < 2; nret++ {
@@ -1176,6 +1185,13 @@ func chanReadError(c *gc.C, ch <-chan error, what string) error {
 // it sends a value on the returned channel.
 // If bidir is true, requests can flow in both directions.
 func newRPCClientServer(c *gc.C, root interface{}, tfErr func(error) error, bidir bool) (client *rpc.Conn, srvDone chan error, clientNotifier, serverNotifier *notifier) {
+	return newRPCClientServerWithCodec(c, root, tfErr, bidir, NewJSONCodec)
+}
+
+// codecFunc returns a new rpc codec for one end of a connection.
+type codecFunc func(c net.Conn, role connRole) rpc.Codec
+
+func newRPCClientServerWithCodec(c *gc.C, root interface{}, tfErr func(error) error, bidir bool, newCodec codecFunc) (client *rpc.Conn, srvDone chan error, clientNotifier, serverNotifier *notifier) {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	c.Assert(err, gc.IsNil)
 
@@ -1193,7 +1209,7 @@ func newRPCClientServer(c *gc.C, root interface{}, tfErr func(error) error, bidi
 		if bidir {
 			role = roleBoth
 		}
-		rpcConn := rpc.NewConn(NewJSONCodec(conn, role), serverNotifier)
+		rpcConn := rpc.NewConn(newCodec(conn, role), serverNotifier)
 		if custroot, ok := root.(*CustomMethodFinder); ok {
 			rpcConn.ServeFinder(custroot, tfErr)
 			custroot.root.conn = rpcConn
@@ -1213,7 +1229,7 @@ func newRPCClientServer(c *gc.C, root interface{}, tfErr func(error) error, bidi
 	if bidir {
 		role = roleBoth
 	}
-	client = rpc.NewConn(NewJSONCodec(conn, role), clientNotifier)
+	client = rpc.NewConn(newCodec(conn, role), clientNotifier)
 	client.Start()
 	return client, srvDone, clientNotifier, serverNotifier
 }
@@ -1269,7 +1285,11 @@ func (c *testCodec) ReadBody(r interface{}, isRequest bool) error {
 	if c.role != roleBoth && isRequest == (c.role == roleClient) {
 		panic(fmt.Errorf("codec role %v; read wrong body type %#v", c.role, r))
 	}
-	// Note: this will need to change if we want to test a non-JSON codec.
+	if _, ok := c.Codec.(*jsoncodec.Codec); !ok {
+		err := c.Codec.ReadBody(r, isRequest)
+		logger.Infof("unmarshalled into %#v", r)
+		return err
+	}
 	var m json.RawMessage
 	err := c.Codec.ReadBody(&m, isRequest)
 	if err != nil {
@@ -1296,6 +1316,13 @@ func NewJSONCodec(c net.Conn, role connRole) rpc.Codec {
 	}
 }
 
+func NewBSONCodec(c net.Conn, role connRole) rpc.Codec {
+	return &testCodec{
+		role:  role,
+		Codec: bsoncodec.NewNet(c),
+	}
+}
+
 type requestEvent struct {
 	hdr  rpc.Header
 	body interface{}
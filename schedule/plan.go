@@ -0,0 +1,90 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package schedule
+
+import (
+	"sort"
+	"time"
+)
+
+// Plan describes when operations may run: within any of a set of
+// recurring windows, and within an ad-hoc window.
+type Plan struct {
+	// Windows holds the recurring windows. If there are none,
+	// operations may run at any time.
+	Windows []*Window
+
+	// Location holds the location in which the windows are
+	// interpreted. If it is nil, UTC is used.
+	Location *time.Location
+
+	// OpenUntil, if not zero, holds the time until which an
+	// ad-hoc window is open.
+	OpenUntil time.Time
+}
+
+// Period is a period of time within which a plan allows operations
+// to run.
+type Period struct {
+	Start time.Time
+	End   time.Time
+
+	// Window holds the specification of the recurring window
+	// that opens at Start. It is empty for ad-hoc windows.
+	Window string
+}
+
+type periodsByStart []Period
+
+func (p periodsByStart) Len() int           { return len(p) }
+func (p periodsByStart) Less(i, j int) bool { return p[i].Start.Before(p[j].Start) }
+func (p periodsByStart) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// Upcoming returns, in order, the first n periods of the plan that
+// are open at or after t. Times are given in the plan's location.
+// An ad-hoc window is open from t until the plan's OpenUntil time.
+func (p Plan) Upcoming(t time.Time, n int) []Period {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	var periods []Period
+	if p.OpenUntil.After(t) {
+		periods = append(periods, Period{
+			Start: t,
+			End:   p.OpenUntil.In(loc),
+		})
+	}
+	for _, w := range p.Windows {
+		start, ok := w.Open(t)
+		if !ok {
+			start, ok = w.Next(t)
+		}
+		for i := 0; ok && i < n; i++ {
+			periods = append(periods, Period{
+				Start:  start,
+				End:    start.Add(w.Duration),
+				Window: w.String(),
+			})
+			start, ok = w.Next(start)
+		}
+	}
+	sort.Stable(periodsByStart(periods))
+	if len(periods) > n {
+		periods = periods[:n]
+	}
+	return periods
+}
+
+// Delay returns how long after t operations must wait for the plan
+// to allow them to run. It returns zero if they may run at t, which
+// is always the case if the plan has no windows at all.
+func (p Plan) Delay(t time.Time) time.Duration {
+	periods := p.Upcoming(t, 1)
+	if len(periods) == 0 || !periods[0].Start.After(t) {
+		return 0
+	}
+	return periods[0].Start.Sub(t)
+}
@@ -0,0 +1,80 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package schedule_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/schedule"
+)
+
+func mustParseList(c *gc.C, specs string) []*schedule.Window {
+	windows, err := schedule.ParseList(specs)
+	c.Assert(err, gc.IsNil)
+	return windows
+}
+
+func (*ScheduleSuite) TestPlanUpcoming(c *gc.C) {
+	plan := schedule.Plan{Windows: mustParseList(c, "0 12 * * * 1h; 0 2 * * 6 4h")}
+	periods := plan.Upcoming(sunday, 3)
+	c.Assert(periods, gc.DeepEquals, []schedule.Period{{
+		Start:  time.Date(2014, 6, 29, 12, 0, 0, 0, time.UTC),
+		End:    time.Date(2014, 6, 29, 13, 0, 0, 0, time.UTC),
+		Window: "0 12 * * * 1h",
+	}, {
+		Start:  time.Date(2014, 6, 30, 12, 0, 0, 0, time.UTC),
+		End:    time.Date(2014, 6, 30, 13, 0, 0, 0, time.UTC),
+		Window: "0 12 * * * 1h",
+	}, {
+		Start:  time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC),
+		End:    time.Date(2014, 7, 1, 13, 0, 0, 0, time.UTC),
+		Window: "0 12 * * * 1h",
+	}})
+}
+
+func (*ScheduleSuite) TestPlanUpcomingAdHoc(c *gc.C) {
+	plan := schedule.Plan{
+		Windows:   mustParseList(c, "0 2 * * 6 4h"),
+		OpenUntil: time.Date(2014, 6, 29, 14, 0, 0, 0, time.UTC),
+	}
+	periods := plan.Upcoming(sunday, 2)
+	c.Assert(periods, gc.DeepEquals, []schedule.Period{{
+		Start: sunday,
+		End:   time.Date(2014, 6, 29, 14, 0, 0, 0, time.UTC),
+	}, {
+		Start:  time.Date(2014, 7, 5, 2, 0, 0, 0, time.UTC),
+		End:    time.Date(2014, 7, 5, 6, 0, 0, 0, time.UTC),
+		Window: "0 2 * * 6 4h",
+	}})
+
+	// The ad-hoc window is ignored once it has closed.
+	periods = plan.Upcoming(sunday.Add(2*time.Hour), 1)
+	c.Assert(periods, gc.HasLen, 1)
+	c.Assert(periods[0].Window, gc.Equals, "0 2 * * 6 4h")
+}
+
+func (*ScheduleSuite) TestPlanUpcomingInLocation(c *gc.C) {
+	plan := schedule.Plan{
+		Windows:  mustParseList(c, "0 2 * * * 1h"),
+		Location: time.FixedZone("UTC+10", 10*60*60),
+	}
+	periods := plan.Upcoming(sunday, 1)
+	c.Assert(periods, gc.HasLen, 1)
+	c.Assert(periods[0].Start.UTC(), gc.DeepEquals, time.Date(2014, 6, 29, 16, 0, 0, 0, time.UTC))
+}
+
+func (*ScheduleSuite) TestPlanDelay(c *gc.C) {
+	var plan schedule.Plan
+	c.Assert(plan.Delay(sunday), gc.Equals, time.Duration(0))
+
+	plan.Windows = mustParseList(c, "0 12 * * * 1h; 0 14 * * * 1h")
+	c.Assert(plan.Delay(sunday), gc.Equals, time.Duration(0))
+	c.Assert(plan.Delay(sunday.Add(time.Hour)), gc.Equals, 30*time.Minute)
+	c.Assert(plan.Delay(sunday.Add(3*time.Hour)), gc.Equals, 20*time.Hour+30*time.Minute)
+
+	plan.OpenUntil = time.Date(2014, 6, 29, 16, 0, 0, 0, time.UTC)
+	c.Assert(plan.Delay(sunday.Add(3*time.Hour)), gc.Equals, time.Duration(0))
+}
@@ -0,0 +1,229 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package schedule implements recurring windows of time, opening at
+// times given by cron-like specifications.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a window of time that opens at the times matched by a
+// cron-like specification, and stays open for a fixed duration.
+type Window struct {
+	// Duration holds how long the window stays open.
+	Duration time.Duration
+
+	spec     string
+	minutes  fieldSet
+	hours    fieldSet
+	days     fieldSet
+	months   fieldSet
+	weekdays fieldSet
+
+	// anyDay and anyWeekday record whether the day of month and
+	// day of week fields were "*", which decides how the two are
+	// combined, as in cron.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// searchDays holds how many days ahead Next looks for the window to
+// open. Four years are enough to find any day of the year.
+const searchDays = 4*366 + 1
+
+// field describes one of the fields of a window specification.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// fieldSet holds the values matched by a field, one bit per value.
+type fieldSet uint64
+
+func (s fieldSet) has(v int) bool {
+	return s&(1<<uint(v)) != 0
+}
+
+// Parse parses a window of the form
+//
+//	MINUTE HOUR DAY-OF-MONTH MONTH DAY-OF-WEEK DURATION
+//
+// where the first five fields are as for cron, and DURATION is
+// parsed by time.ParseDuration. Each cron field is "*", a value, a
+// range "a-b", or a comma-separated list of those, and values and
+// ranges may be followed by a step "/n". Days of the week are
+// numbered from 0 (Sunday) to 7 (Sunday again). For example, a
+// four hour window opening at 2am every Saturday is
+//
+//	0 2 * * 6 4h
+func Parse(spec string) (*Window, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields)+1 {
+		return nil, fmt.Errorf("invalid window %q: expected %d fields, got %d", spec, len(fields)+1, len(parts))
+	}
+	w := &Window{spec: strings.Join(parts, " ")}
+	sets := []*fieldSet{&w.minutes, &w.hours, &w.days, &w.months, &w.weekdays}
+	for i, f := range fields {
+		set, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %v", spec, err)
+		}
+		*sets[i] = set
+	}
+	// Sunday may be given as either 0 or 7.
+	if w.weekdays.has(7) {
+		w.weekdays |= 1
+	}
+	w.anyDay = parts[2] == "*"
+	w.anyWeekday = parts[4] == "*"
+	duration, err := time.ParseDuration(parts[len(fields)])
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %v", spec, err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid window %q: duration must be positive", spec)
+	}
+	w.Duration = duration
+	if _, ok := w.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)); !ok {
+		return nil, fmt.Errorf("invalid window %q: window never opens", spec)
+	}
+	return w, nil
+}
+
+// ParseList parses a list of windows separated by semicolons.
+// Empty entries are ignored.
+func ParseList(specs string) ([]*Window, error) {
+	var windows []*Window
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		w, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseField(s string, f field) (fieldSet, error) {
+	var set fieldSet
+	for _, item := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rangeSpec := item
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, s)
+			}
+			step = n
+			rangeSpec = item[:i]
+		}
+		if rangeSpec != "*" {
+			bounds := strings.SplitN(rangeSpec, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], f); err != nil {
+				return 0, fmt.Errorf("invalid %s field %q", f.name, s)
+			}
+			switch {
+			case len(bounds) == 2:
+				if hi, err = parseValue(bounds[1], f); err != nil || hi < lo {
+					return 0, fmt.Errorf("invalid %s field %q", f.name, s)
+				}
+			case step == 1:
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d out of range", v)
+	}
+	return v, nil
+}
+
+// String returns the window's specification.
+func (w *Window) String() string {
+	return w.spec
+}
+
+// Next returns the first time after t at which the window opens,
+// interpreting the window's specification in t's location. It
+// returns false if the window does not open in the next four years.
+func (w *Window) Next(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	year, month, day := t.Date()
+	for i := 0; i < searchDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if !w.matchDate(date) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !w.hours.has(hour) {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !w.minutes.has(minute) {
+					continue
+				}
+				start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+				if start.After(t) {
+					return start, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// matchDate reports whether the window opens on the given date.
+func (w *Window) matchDate(date time.Time) bool {
+	if !w.months.has(int(date.Month())) {
+		return false
+	}
+	day := w.days.has(date.Day())
+	weekday := w.weekdays.has(int(date.Weekday()))
+	switch {
+	case w.anyDay:
+		return weekday
+	case w.anyWeekday:
+		return day
+	}
+	// As in cron, when both are restricted, either may match.
+	return day || weekday
+}
+
+// Open returns the time at which the window opened, if it is open
+// at t.
+func (w *Window) Open(t time.Time) (time.Time, bool) {
+	start, ok := w.Next(t.Add(-w.Duration))
+	if !ok || start.After(t) {
+		return time.Time{}, false
+	}
+	return start, true
+}
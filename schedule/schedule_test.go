@@ -0,0 +1,176 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package schedule_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/schedule"
+	"github.com/juju/juju/testing"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type ScheduleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ScheduleSuite{})
+
+var parseErrorTests = []struct {
+	spec string
+	err  string
+}{{
+	spec: "0 2 * * *",
+	err:  `invalid window "0 2 \* \* \*": expected 6 fields, got 5`,
+}, {
+	spec: "60 2 * * * 1h",
+	err:  `invalid window "60 2 \* \* \* 1h": invalid minute field "60"`,
+}, {
+	spec: "0 2-1 * * * 1h",
+	err:  `invalid window "0 2-1 \* \* \* 1h": invalid hour field "2-1"`,
+}, {
+	spec: "0 2 0 * * 1h",
+	err:  `invalid window "0 2 0 \* \* 1h": invalid day of month field "0"`,
+}, {
+	spec: "0 2 * jan * 1h",
+	err:  `invalid window "0 2 \* jan \* 1h": invalid month field "jan"`,
+}, {
+	spec: "0 2 * * 8 1h",
+	err:  `invalid window "0 2 \* \* 8 1h": invalid day of week field "8"`,
+}, {
+	spec: "*/0 2 * * * 1h",
+	err:  `invalid window "\*/0 2 \* \* \* 1h": invalid step in minute field "\*/0"`,
+}, {
+	spec: "0 2 * * * forever",
+	err:  `invalid window "0 2 \* \* \* forever": time: invalid duration .*forever.*`,
+}, {
+	spec: "0 2 * * * -1h",
+	err:  `invalid window "0 2 \* \* \* -1h": duration must be positive`,
+}, {
+	spec: "0 2 31 2 * 1h",
+	err:  `invalid window "0 2 31 2 \* 1h": window never opens`,
+}}
+
+func (*ScheduleSuite) TestParseErrors(c *gc.C) {
+	for i, t := range parseErrorTests {
+		c.Logf("test %d: %q", i, t.spec)
+		_, err := schedule.Parse(t.spec)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+// Sunday 29th June 2014.
+var sunday = time.Date(2014, 6, 29, 12, 30, 0, 0, time.UTC)
+
+var nextTests = []struct {
+	spec string
+	from time.Time
+	next time.Time
+}{{
+	spec: "0 2 * * * 1h",
+	from: sunday,
+	next: time.Date(2014, 6, 30, 2, 0, 0, 0, time.UTC),
+}, {
+	spec: "*/15 * * * * 5m",
+	from: sunday,
+	next: time.Date(2014, 6, 29, 12, 45, 0, 0, time.UTC),
+}, {
+	spec: "30 12 * * * 1h",
+	from: sunday,
+	next: time.Date(2014, 6, 30, 12, 30, 0, 0, time.UTC),
+}, {
+	spec: "0 2 * * 6 4h",
+	from: sunday,
+	next: time.Date(2014, 7, 5, 2, 0, 0, 0, time.UTC),
+}, {
+	spec: "0 2 * * 7 4h",
+	from: sunday,
+	next: time.Date(2014, 7, 6, 2, 0, 0, 0, time.UTC),
+}, {
+	spec: "0 22 * * 1-5 1h",
+	from: time.Date(2014, 7, 4, 23, 0, 0, 0, time.UTC),
+	next: time.Date(2014, 7, 7, 22, 0, 0, 0, time.UTC),
+}, {
+	spec: "0 0 1,15 * * 1h",
+	from: sunday,
+	next: time.Date(2014, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	// When both days are restricted, either may match.
+	spec: "0 0 15 * 2 1h",
+	from: sunday,
+	next: time.Date(2014, 7, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	spec: "0 0 29 2 * 1h",
+	from: sunday,
+	next: time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC),
+}, {
+	spec: "0 3 * 1-12/6 * 1h",
+	from: sunday,
+	next: time.Date(2014, 7, 1, 3, 0, 0, 0, time.UTC),
+}}
+
+func (*ScheduleSuite) TestNext(c *gc.C) {
+	for i, t := range nextTests {
+		c.Logf("test %d: %q from %v", i, t.spec, t.from)
+		w, err := schedule.Parse(t.spec)
+		c.Assert(err, gc.IsNil)
+		next, ok := w.Next(t.from)
+		c.Check(ok, gc.Equals, true)
+		c.Check(next, gc.DeepEquals, t.next)
+	}
+}
+
+func (*ScheduleSuite) TestNextInLocation(c *gc.C) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	w, err := schedule.Parse("0 2 * * * 1h")
+	c.Assert(err, gc.IsNil)
+	next, ok := w.Next(sunday.In(loc))
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(next.UTC(), gc.DeepEquals, time.Date(2014, 6, 29, 16, 0, 0, 0, time.UTC))
+}
+
+func (*ScheduleSuite) TestOpen(c *gc.C) {
+	w, err := schedule.Parse("0 23 * * * 2h")
+	c.Assert(err, gc.IsNil)
+	opened := time.Date(2014, 6, 29, 23, 0, 0, 0, time.UTC)
+	for i, t := range []struct {
+		at   time.Time
+		open bool
+	}{
+		{opened.Add(-time.Minute), false},
+		{opened, true},
+		{opened.Add(90 * time.Minute), true},
+		{opened.Add(2*time.Hour - time.Second), true},
+		{opened.Add(2 * time.Hour), false},
+	} {
+		c.Logf("test %d: %v", i, t.at)
+		start, ok := w.Open(t.at)
+		c.Check(ok, gc.Equals, t.open)
+		if t.open {
+			c.Check(start, gc.DeepEquals, opened)
+		}
+	}
+}
+
+func (*ScheduleSuite) TestParseList(c *gc.C) {
+	windows, err := schedule.ParseList("0 2 * * 6 4h; 30  4 * * *   1h;")
+	c.Assert(err, gc.IsNil)
+	c.Assert(windows, gc.HasLen, 2)
+	c.Assert(windows[0].String(), gc.Equals, "0 2 * * 6 4h")
+	c.Assert(windows[0].Duration, gc.Equals, 4*time.Hour)
+	c.Assert(windows[1].String(), gc.Equals, "30 4 * * * 1h")
+
+	windows, err = schedule.ParseList("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(windows, gc.HasLen, 0)
+
+	_, err = schedule.ParseList("0 2 * * 6 4h; 0 2")
+	c.Assert(err, gc.ErrorMatches, `invalid window "0 2": expected 6 fields, got 2`)
+}
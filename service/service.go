@@ -0,0 +1,177 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package service provides an interface to the services, such as juju
+// agents, managed by a machine's init system, independently of whether
+// the machine uses upstart or systemd.
+package service
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/service/systemd"
+	"github.com/juju/juju/upstart"
+)
+
+// The init systems supported by juju.
+const (
+	InitSystemUpstart = "upstart"
+	InitSystemSystemd = "systemd"
+)
+
+const maxAgentFiles = 20000
+
+// Service is implemented by the services of each supported
+// init system.
+type Service interface {
+	// Installed returns whether the service's configuration exists
+	// in the init directory.
+	Installed() bool
+
+	// Running returns whether the service appears to be running.
+	Running() bool
+
+	// Install installs, enables and starts the service.
+	Install() error
+
+	// InstallCommands returns shell commands to install, enable
+	// and start the service.
+	InstallCommands() ([]string, error)
+
+	// Start starts the service.
+	Start() error
+
+	// Stop stops the service.
+	Stop() error
+
+	// StopAndRemove stops the service and then removes its
+	// configuration from the init directory.
+	StopAndRemove() error
+
+	// Remove removes the service's configuration from the
+	// init directory.
+	Remove() error
+}
+
+// Conf defines a service independently of the init system
+// that manages it.
+type Conf struct {
+	// InitDir, if set, overrides the init system's default
+	// directory for service configurations.
+	InitDir string
+	// Desc is the service's description.
+	Desc string
+	// Env holds the environment variables that will be set when the command runs.
+	Env map[string]string
+	// Limit holds the ulimit values that will be set when the command runs,
+	// such as "nofile": "20000 20000".
+	Limit map[string]string
+	// Cmd is the command (with arguments) that will be run.
+	// The command will be restarted if it exits with a non-zero exit code.
+	Cmd string
+	// Out, if set, will redirect output to that path.
+	Out string
+}
+
+// New returns the named service, as defined by conf, managed
+// by the given init system.
+func New(initSystem, name string, conf Conf) (Service, error) {
+	switch initSystem {
+	case InitSystemUpstart:
+		svc := upstart.NewService(name)
+		if conf.InitDir != "" {
+			svc.InitDir = conf.InitDir
+		}
+		return &upstart.Conf{
+			Service: *svc,
+			Desc:    conf.Desc,
+			Env:     conf.Env,
+			Limit:   conf.Limit,
+			Cmd:     conf.Cmd,
+			Out:     conf.Out,
+		}, nil
+	case InitSystemSystemd:
+		svc := systemd.NewService(name)
+		if conf.InitDir != "" {
+			svc.InitDir = conf.InitDir
+		}
+		return &systemd.Conf{
+			Service: *svc,
+			Desc:    conf.Desc,
+			Env:     conf.Env,
+			Limit:   conf.Limit,
+			Cmd:     conf.Cmd,
+			Out:     conf.Out,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown init system %q", initSystem)
+}
+
+// InitDir returns the default directory in which the given init
+// system keeps its service configurations.
+func InitDir(initSystem string) string {
+	if initSystem == InitSystemSystemd {
+		return systemd.InitDir
+	}
+	return upstart.InitDir
+}
+
+// ConfSuffix returns the suffix of the names of the given init
+// system's service configuration files.
+func ConfSuffix(initSystem string) string {
+	if initSystem == InitSystemSystemd {
+		return ".service"
+	}
+	return ".conf"
+}
+
+// systemdRunDir exists only on machines booted with systemd.
+var systemdRunDir = "/run/systemd/system"
+
+// DetectInitSystem returns the init system used by the local machine.
+func DetectInitSystem() string {
+	if fi, err := os.Stat(systemdRunDir); err == nil && fi.IsDir() {
+		return InitSystemSystemd
+	}
+	return InitSystemUpstart
+}
+
+// systemdSeries holds the series that boot with systemd by default.
+var systemdSeries = map[string]bool{
+	"vivid": true,
+	"wily":  true,
+}
+
+// InitSystemForSeries returns the init system used by default by
+// machines running the given series.
+func InitSystemForSeries(series string) string {
+	if systemdSeries[series] {
+		return InitSystemSystemd
+	}
+	return InitSystemUpstart
+}
+
+// MachineAgentConf returns the service configuration for a machine agent
+// based on the tag and machineId passed in.
+func MachineAgentConf(toolsDir, dataDir, logDir, tag, machineId string, env map[string]string) Conf {
+	logFile := path.Join(logDir, tag+".log")
+	// The machine agent always starts with debug turned on.  The logger worker
+	// will update this to the system logging environment as soon as it starts.
+	return Conf{
+		Desc: fmt.Sprintf("juju %s agent", tag),
+		Limit: map[string]string{
+			"nofile": fmt.Sprintf("%d %d", maxAgentFiles, maxAgentFiles),
+		},
+		Cmd: path.Join(toolsDir, "jujud") +
+			" machine" +
+			" --data-dir " + utils.ShQuote(dataDir) +
+			" --machine-id " + machineId +
+			" --debug",
+		Out: logFile,
+		Env: env,
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/systemd"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/upstart"
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type ServiceSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&ServiceSuite{})
+
+func (s *ServiceSuite) TestDetectInitSystem(c *gc.C) {
+	dir := filepath.Join(c.MkDir(), "system")
+	s.PatchValue(service.SystemdRunDir, dir)
+	c.Assert(service.DetectInitSystem(), gc.Equals, service.InitSystemUpstart)
+	err := os.Mkdir(dir, 0755)
+	c.Assert(err, gc.IsNil)
+	c.Assert(service.DetectInitSystem(), gc.Equals, service.InitSystemSystemd)
+}
+
+func (s *ServiceSuite) TestInitSystemForSeries(c *gc.C) {
+	c.Assert(service.InitSystemForSeries("precise"), gc.Equals, service.InitSystemUpstart)
+	c.Assert(service.InitSystemForSeries("trusty"), gc.Equals, service.InitSystemUpstart)
+	c.Assert(service.InitSystemForSeries("vivid"), gc.Equals, service.InitSystemSystemd)
+}
+
+func (s *ServiceSuite) TestInitDirAndConfSuffix(c *gc.C) {
+	c.Assert(service.InitDir(service.InitSystemUpstart), gc.Equals, "/etc/init")
+	c.Assert(service.ConfSuffix(service.InitSystemUpstart), gc.Equals, ".conf")
+	c.Assert(service.InitDir(service.InitSystemSystemd), gc.Equals, "/etc/systemd/system")
+	c.Assert(service.ConfSuffix(service.InitSystemSystemd), gc.Equals, ".service")
+}
+
+var conf = service.Conf{
+	Desc:  "a service",
+	Env:   map[string]string{"FOO": "bar"},
+	Limit: map[string]string{"nofile": "10 10"},
+	Cmd:   "do something",
+	Out:   "/some/output/path",
+}
+
+func (s *ServiceSuite) TestNewUpstart(c *gc.C) {
+	svc, err := service.New(service.InitSystemUpstart, "some-service", conf)
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc, gc.DeepEquals, &upstart.Conf{
+		Service: upstart.Service{Name: "some-service", InitDir: "/etc/init"},
+		Desc:    "a service",
+		Env:     map[string]string{"FOO": "bar"},
+		Limit:   map[string]string{"nofile": "10 10"},
+		Cmd:     "do something",
+		Out:     "/some/output/path",
+	})
+}
+
+func (s *ServiceSuite) TestNewSystemd(c *gc.C) {
+	withDir := conf
+	withDir.InitDir = "/some/dir"
+	svc, err := service.New(service.InitSystemSystemd, "some-service", withDir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc, gc.DeepEquals, &systemd.Conf{
+		Service: systemd.Service{Name: "some-service", InitDir: "/some/dir"},
+		Desc:    "a service",
+		Env:     map[string]string{"FOO": "bar"},
+		Limit:   map[string]string{"nofile": "10 10"},
+		Cmd:     "do something",
+		Out:     "/some/output/path",
+	})
+}
+
+func (s *ServiceSuite) TestNewUnknownInitSystem(c *gc.C) {
+	_, err := service.New("sysvinit", "some-service", conf)
+	c.Assert(err, gc.ErrorMatches, `unknown init system "sysvinit"`)
+}
+
+func (s *ServiceSuite) TestMachineAgentConf(c *gc.C) {
+	conf := service.MachineAgentConf("/tools/machine-1", "/data", "/log", "machine-1", "1", nil)
+	c.Assert(conf, gc.DeepEquals, service.Conf{
+		Desc:  "juju machine-1 agent",
+		Limit: map[string]string{"nofile": "20000 20000"},
+		Cmd:   "/tools/machine-1/jujud machine --data-dir '/data' --machine-id 1 --debug",
+		Out:   "/log/machine-1.log",
+	})
+}
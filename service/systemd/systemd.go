@@ -0,0 +1,317 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package systemd provides control over services managed by systemd.
+package systemd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/juju/utils"
+)
+
+// InitDir holds the default directory for systemd unit files.
+var InitDir = "/etc/systemd/system"
+
+var InstallStartRetryAttempts = utils.AttemptStrategy{
+	Total: 1 * time.Second,
+	Delay: 250 * time.Millisecond,
+}
+
+// Service provides visibility into and control over a systemd service.
+type Service struct {
+	Name    string
+	InitDir string // defaults to "/etc/systemd/system"
+}
+
+func NewService(name string) *Service {
+	return &Service{Name: name, InitDir: InitDir}
+}
+
+// unitName returns the name of the service's unit.
+func (s *Service) unitName() string {
+	return s.Name + ".service"
+}
+
+// confPath returns the path to the service's unit file.
+func (s *Service) confPath() string {
+	return path.Join(s.InitDir, s.unitName())
+}
+
+// Installed returns whether the service's unit file exists in the
+// init directory.
+func (s *Service) Installed() bool {
+	_, err := os.Stat(s.confPath())
+	return err == nil
+}
+
+// Running returns true if the Service appears to be running.
+func (s *Service) Running() bool {
+	out, err := exec.Command("systemctl", "is-active", s.unitName()).CombinedOutput()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "active"
+}
+
+// Start starts the service.
+func (s *Service) Start() error {
+	if s.Running() {
+		return nil
+	}
+	err := runCommand("systemctl", "start", s.unitName())
+	if err != nil {
+		// Double check to see if we were started before our command ran.
+		if s.Running() {
+			return nil
+		}
+	}
+	return err
+}
+
+func runCommand(args ...string) error {
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) > 0 {
+		return fmt.Errorf("exec %q: %v (%s)", args, err, out)
+	}
+	return fmt.Errorf("exec %q: %v", args, err)
+}
+
+// Stop stops the service.
+func (s *Service) Stop() error {
+	if !s.Running() {
+		return nil
+	}
+	return runCommand("systemctl", "stop", s.unitName())
+}
+
+// StopAndRemove stops the service and then disables it and deletes
+// its unit file from the init directory.
+func (s *Service) StopAndRemove() error {
+	if !s.Installed() {
+		return nil
+	}
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	return s.Remove()
+}
+
+// Remove disables the service and deletes its unit file from the
+// init directory.
+func (s *Service) Remove() error {
+	if !s.Installed() {
+		return nil
+	}
+	if err := runCommand("systemctl", "disable", s.unitName()); err != nil {
+		return err
+	}
+	if err := os.Remove(s.confPath()); err != nil {
+		return err
+	}
+	return runCommand("systemctl", "daemon-reload")
+}
+
+var confT = template.Must(template.New("").Parse(`
+[Unit]
+Description={{.Desc}}
+After=syslog.target network.target
+
+[Service]
+{{range .Env}}Environment={{.}}
+{{end}}{{range .Limit}}{{.}}
+{{end}}ExecStart={{.ExecStart}}
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`[1:]))
+
+// Conf is responsible for defining and installing systemd services. Its
+// fields represent elements of a systemd unit file.
+type Conf struct {
+	Service
+	// Desc is the service's description.
+	Desc string
+	// Env holds the environment variables that will be set when the command runs.
+	Env map[string]string
+	// Limit holds the ulimit values that will be set when the command runs,
+	// keyed by their upstart names, such as "nofile".
+	Limit map[string]string
+	// Cmd is the command (with arguments) that will be run.
+	// The command will be restarted if it exits with a non-zero exit code.
+	Cmd string
+	// Out, if set, will redirect output to that path.
+	Out string
+}
+
+// validate returns an error if the service is not adequately defined.
+func (c *Conf) validate() error {
+	if c.Name == "" {
+		return errors.New("missing Name")
+	}
+	if c.InitDir == "" {
+		return errors.New("missing InitDir")
+	}
+	if c.Desc == "" {
+		return errors.New("missing Desc")
+	}
+	if c.Cmd == "" {
+		return errors.New("missing Cmd")
+	}
+	return nil
+}
+
+// escape escapes s for use within a double-quoted systemd unit file
+// value. Specifiers and variables are escaped so they are passed on
+// to the shell unchanged.
+func escape(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "%", "%%", -1)
+	return strings.Replace(s, "$", "$$", -1)
+}
+
+// execStart returns the command line that starts the service. The
+// command is run by a shell, so it may redirect its output.
+func (c *Conf) execStart() string {
+	script := "exec " + c.Cmd
+	if c.Out != "" {
+		// Ensure log files are properly protected.
+		out := utils.ShQuote(c.Out)
+		script = fmt.Sprintf(
+			"touch %s; chown syslog:syslog %s; chmod 0600 %s; %s >> %s 2>&1",
+			out, out, out, script, out,
+		)
+	}
+	return `/bin/bash -c "` + escape(script) + `"`
+}
+
+// limits returns the unit file directives for the service's limits.
+func (c *Conf) limits() ([]string, error) {
+	var limits []string
+	for name, value := range c.Limit {
+		fields := strings.Fields(value)
+		switch len(fields) {
+		case 1:
+		case 2:
+			if fields[0] != fields[1] {
+				value = fields[0] + ":" + fields[1]
+			} else {
+				value = fields[0]
+			}
+		default:
+			return nil, fmt.Errorf("invalid limit %s %q", name, value)
+		}
+		limits = append(limits, fmt.Sprintf("Limit%s=%s", strings.ToUpper(name), value))
+	}
+	sort.Strings(limits)
+	return limits, nil
+}
+
+// render returns the systemd unit file for the service as a slice of bytes.
+func (c *Conf) render() ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	limits, err := c.limits()
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for name, value := range c.Env {
+		env = append(env, `"`+escape(name+"="+value)+`"`)
+	}
+	sort.Strings(env)
+	var buf bytes.Buffer
+	err = confT.Execute(&buf, struct {
+		Desc      string
+		Env       []string
+		Limit     []string
+		ExecStart string
+	}{c.Desc, env, limits, c.execStart()})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Install installs, enables and starts the service.
+func (c *Conf) Install() error {
+	conf, err := c.render()
+	if err != nil {
+		return err
+	}
+
+	exists, err := c.removeOld(conf)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := ioutil.WriteFile(c.confPath(), conf, 0644); err != nil {
+			return err
+		}
+		if err := runCommand("systemctl", "daemon-reload"); err != nil {
+			return err
+		}
+		if err := runCommand("systemctl", "enable", c.unitName()); err != nil {
+			return err
+		}
+	}
+
+	for attempt := InstallStartRetryAttempts.Start(); attempt.Next(); {
+		if err = c.Start(); err == nil {
+			break
+		}
+	}
+	return err
+}
+
+func (c *Conf) removeOld(expected []byte) (exists bool, err error) {
+	current, err := ioutil.ReadFile(c.confPath())
+	if os.IsNotExist(err) {
+		// no existing unit file
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("systemd: could not read existing unit file: %v", err)
+	}
+
+	// if we have a current unit file on disk, check to see if it's different
+	if bytes.Equal(current, expected) {
+		return true, nil
+	}
+	if err := c.StopAndRemove(); err != nil {
+		return false, fmt.Errorf("systemd: could not remove installed service: %s", err)
+	}
+	return false, nil
+}
+
+// InstallCommands returns shell commands to install, enable and start
+// the service.
+func (c *Conf) InstallCommands() ([]string, error) {
+	conf, err := c.render()
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf("cat >> %s << 'EOF'\n%sEOF\n", c.confPath(), conf),
+		"systemctl daemon-reload",
+		"systemctl enable " + c.unitName(),
+		"systemctl start " + c.unitName(),
+	}, nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package systemd_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/service/systemd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type SystemdSuite struct {
+	coretesting.BaseSuite
+	testPath string
+	service  *systemd.Service
+}
+
+var _ = gc.Suite(&SystemdSuite{})
+
+// systemctl runs the fake tool for its subcommand, if there is one,
+// and records the subcommands it was run with.
+var systemctl = `
+#!/bin/bash --norc
+dir=$(dirname "$0")
+echo "$@" >> "$dir/systemctl.log"
+if [ -x "$dir/systemctl-$1" ]; then
+  exec "$dir/systemctl-$1"
+fi
+`[1:]
+
+func (s *SystemdSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.testPath = c.MkDir()
+	s.PatchEnvPathPrepend(s.testPath)
+	s.PatchValue(&systemd.InstallStartRetryAttempts, utils.AttemptStrategy{})
+	err := ioutil.WriteFile(filepath.Join(s.testPath, "systemctl"), []byte(systemctl), 0755)
+	c.Assert(err, gc.IsNil)
+	s.service = &systemd.Service{Name: "some-service", InitDir: c.MkDir()}
+	_, err = os.Create(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, gc.IsNil)
+}
+
+// MakeTool makes the fake systemctl run the given script for
+// the given subcommand.
+func (s *SystemdSuite) MakeTool(c *gc.C, subcommand, script string) {
+	path := filepath.Join(s.testPath, "systemctl-"+subcommand)
+	err := ioutil.WriteFile(path, []byte("#!/bin/bash --norc\n"+script), 0755)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *SystemdSuite) StoppedStatus(c *gc.C) {
+	s.MakeTool(c, "is-active", "echo inactive; exit 3")
+}
+
+func (s *SystemdSuite) RunningStatus(c *gc.C) {
+	s.MakeTool(c, "is-active", "echo active")
+}
+
+func (s *SystemdSuite) commands(c *gc.C) []string {
+	data, err := ioutil.ReadFile(filepath.Join(s.testPath, "systemctl.log"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	c.Assert(err, gc.IsNil)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func (s *SystemdSuite) TestInitDir(c *gc.C) {
+	svc := systemd.NewService("blah")
+	c.Assert(svc.InitDir, gc.Equals, "/etc/systemd/system")
+}
+
+func (s *SystemdSuite) TestInstalled(c *gc.C) {
+	c.Assert(s.service.Installed(), gc.Equals, true)
+	err := os.Remove(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.Installed(), gc.Equals, false)
+}
+
+func (s *SystemdSuite) TestRunning(c *gc.C) {
+	s.MakeTool(c, "is-active", "exit 1")
+	c.Assert(s.service.Running(), gc.Equals, false)
+	s.MakeTool(c, "is-active", "echo activating")
+	c.Assert(s.service.Running(), gc.Equals, false)
+	s.RunningStatus(c)
+	c.Assert(s.service.Running(), gc.Equals, true)
+}
+
+func (s *SystemdSuite) TestStart(c *gc.C) {
+	s.RunningStatus(c)
+	s.MakeTool(c, "start", "exit 99")
+	c.Assert(s.service.Start(), gc.IsNil)
+	s.StoppedStatus(c)
+	c.Assert(s.service.Start(), gc.ErrorMatches, ".*exit status 99.*")
+	s.MakeTool(c, "start", "exit 0")
+	c.Assert(s.service.Start(), gc.IsNil)
+}
+
+func (s *SystemdSuite) TestStop(c *gc.C) {
+	s.StoppedStatus(c)
+	s.MakeTool(c, "stop", "exit 99")
+	c.Assert(s.service.Stop(), gc.IsNil)
+	s.RunningStatus(c)
+	c.Assert(s.service.Stop(), gc.ErrorMatches, ".*exit status 99.*")
+	s.MakeTool(c, "stop", "exit 0")
+	c.Assert(s.service.Stop(), gc.IsNil)
+}
+
+func (s *SystemdSuite) TestRemoveMissing(c *gc.C) {
+	err := os.Remove(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.StopAndRemove(), gc.IsNil)
+}
+
+func (s *SystemdSuite) TestStopAndRemove(c *gc.C) {
+	s.RunningStatus(c)
+	s.MakeTool(c, "stop", "exit 99")
+	c.Assert(s.service.StopAndRemove(), gc.ErrorMatches, ".*exit status 99.*")
+	_, err := os.Stat(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, gc.IsNil)
+
+	s.MakeTool(c, "stop", "exit 0")
+	c.Assert(s.service.StopAndRemove(), gc.IsNil)
+	_, err = os.Stat(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *SystemdSuite) TestRemoveDisables(c *gc.C) {
+	s.MakeTool(c, "disable", "exit 99")
+	c.Assert(s.service.Remove(), gc.ErrorMatches, ".*exit status 99.*")
+	s.MakeTool(c, "disable", "exit 0")
+	c.Assert(s.service.Remove(), gc.IsNil)
+	_, err := os.Stat(filepath.Join(s.service.InitDir, "some-service.service"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *SystemdSuite) TestInstallErrors(c *gc.C) {
+	conf := &systemd.Conf{}
+	check := func(msg string) {
+		c.Assert(conf.Install(), gc.ErrorMatches, msg)
+		_, err := conf.InstallCommands()
+		c.Assert(err, gc.ErrorMatches, msg)
+	}
+	check("missing Name")
+	conf.Name = "some-service"
+	check("missing InitDir")
+	conf.InitDir = c.MkDir()
+	check("missing Desc")
+	conf.Desc = "this is a systemd service"
+	check("missing Cmd")
+	conf.Cmd = "do something"
+	conf.Limit = map[string]string{"nofile": "1 2 3"}
+	check(`invalid limit nofile "1 2 3"`)
+}
+
+const expectStart = `[Unit]
+Description=this is a systemd service
+After=syslog.target network.target
+
+[Service]
+`
+
+const expectEnd = `Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func (s *SystemdSuite) dummyConf(c *gc.C) *systemd.Conf {
+	return &systemd.Conf{
+		Service: systemd.Service{Name: "some-service", InitDir: c.MkDir()},
+		Desc:    "this is a systemd service",
+		Cmd:     "do something",
+	}
+}
+
+func (s *SystemdSuite) assertInstall(c *gc.C, conf *systemd.Conf, expectService string) {
+	expectContent := expectStart + expectService + expectEnd
+	expectPath := filepath.Join(conf.InitDir, "some-service.service")
+
+	cmds, err := conf.InstallCommands()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cmds, gc.DeepEquals, []string{
+		"cat >> " + expectPath + " << 'EOF'\n" + expectContent + "EOF\n",
+		"systemctl daemon-reload",
+		"systemctl enable some-service.service",
+		"systemctl start some-service.service",
+	})
+
+	s.StoppedStatus(c)
+	s.MakeTool(c, "start", "exit 99")
+	err = conf.Install()
+	c.Assert(err, gc.ErrorMatches, ".*exit status 99.*")
+	s.MakeTool(c, "start", "exit 0")
+	err = conf.Install()
+	c.Assert(err, gc.IsNil)
+	content, err := ioutil.ReadFile(expectPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Equals, expectContent)
+}
+
+func (s *SystemdSuite) TestInstallSimple(c *gc.C) {
+	conf := s.dummyConf(c)
+	s.assertInstall(c, conf, `ExecStart=/bin/bash -c "exec do something"
+`)
+}
+
+func (s *SystemdSuite) TestInstallOutput(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.Out = "/some/output/path"
+	s.assertInstall(c, conf, `ExecStart=/bin/bash -c "touch '/some/output/path'; chown syslog:syslog '/some/output/path'; chmod 0600 '/some/output/path'; exec do something >> '/some/output/path' 2>&1"
+`)
+}
+
+func (s *SystemdSuite) TestInstallEscapesCommand(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.Cmd = `echo "$HOME" 100%`
+	s.assertInstall(c, conf, `ExecStart=/bin/bash -c "exec echo \"$$HOME\" 100%%"
+`)
+}
+
+func (s *SystemdSuite) TestInstallEnv(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.Env = map[string]string{"FOO": "bar baz", "QUX": "ping pong"}
+	s.assertInstall(c, conf, `Environment="FOO=bar baz"
+Environment="QUX=ping pong"
+ExecStart=/bin/bash -c "exec do something"
+`)
+}
+
+func (s *SystemdSuite) TestInstallLimit(c *gc.C) {
+	conf := s.dummyConf(c)
+	conf.Limit = map[string]string{"nofile": "65000 65000", "nproc": "10000 20000"}
+	s.assertInstall(c, conf, `LimitNOFILE=65000
+LimitNPROC=10000:20000
+ExecStart=/bin/bash -c "exec do something"
+`)
+}
+
+func (s *SystemdSuite) TestInstallEnablesService(c *gc.C) {
+	s.StoppedStatus(c)
+	conf := s.dummyConf(c)
+	err := conf.Install()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands(c), gc.DeepEquals, []string{
+		"daemon-reload",
+		"enable some-service.service",
+		"is-active some-service.service",
+		"start some-service.service",
+	})
+}
@@ -72,6 +72,12 @@ type MachineTemplate struct {
 	// with the machine.
 	Placement string
 
+	// CloneFrom holds the id of an existing container whose instance
+	// should be snapshotted and cloned to provide the new machine's
+	// instance. It may only be set when adding a container to the
+	// machine hosting the existing container.
+	CloneFrom string
+
 	// principals holds the principal units that will
 	// associated with the machine.
 	principals []string
@@ -236,6 +242,9 @@ func (st *State) addMachineOps(template MachineTemplate) (*machineDoc, []txn.Op,
 	if err != nil {
 		return nil, nil, err
 	}
+	if template.CloneFrom != "" {
+		return nil, nil, fmt.Errorf("cannot clone a container to create a top level machine")
+	}
 	if template.InstanceId == "" {
 		if err := st.precheckInstance(template.Series, template.Constraints, template.Placement); err != nil {
 			return nil, nil, err
@@ -315,13 +324,23 @@ func (st *State) addMachineInsideMachineOps(template MachineTemplate, parentId s
 	if !parent.supportsContainerType(containerType) {
 		return nil, nil, fmt.Errorf("machine %s cannot host %s containers", parentId, containerType)
 	}
+	var ops []txn.Op
+	if template.CloneFrom != "" {
+		if err := st.checkCloneSource(template.CloneFrom, parentId, containerType); err != nil {
+			return nil, nil, err
+		}
+		ops = append(ops, txn.Op{
+			C:      st.machines.Name,
+			Id:     template.CloneFrom,
+			Assert: isAliveDoc,
+		})
+	}
 	newId, err := st.newContainerId(parentId, containerType)
 	if err != nil {
 		return nil, nil, err
 	}
 	mdoc := machineDocForTemplate(template, newId)
 	mdoc.ContainerType = string(containerType)
-	var ops []txn.Op
 	ops = append(ops, st.insertNewMachineOps(mdoc, template)...)
 	ops = append(ops,
 		// Update containers record for host machine.
@@ -332,6 +351,35 @@ func (st *State) addMachineInsideMachineOps(template MachineTemplate, parentId s
 	return mdoc, ops, nil
 }
 
+// checkCloneSource returns an error if the machine with id sourceId
+// cannot be cloned to create a new container of the given type inside
+// the machine with id parentId.
+func (st *State) checkCloneSource(sourceId, parentId string, containerType instance.ContainerType) error {
+	if containerType != instance.LXC {
+		return fmt.Errorf("cannot clone %s containers", containerType)
+	}
+	source, err := st.Machine(sourceId)
+	if err != nil {
+		return fmt.Errorf("cannot clone machine %s: %v", sourceId, err)
+	}
+	if source.Life() != Alive {
+		return fmt.Errorf("cannot clone machine %s: machine is not alive", sourceId)
+	}
+	if source.ContainerType() != containerType {
+		return fmt.Errorf("cannot clone machine %s: not a %s container", sourceId, containerType)
+	}
+	if sourceParentId, _ := source.ParentId(); sourceParentId != parentId {
+		return fmt.Errorf("cannot clone machine %s: not hosted on machine %s", sourceId, parentId)
+	}
+	if _, err := source.InstanceId(); err != nil {
+		if IsNotProvisionedError(err) {
+			return fmt.Errorf("cannot clone machine %s: machine is not provisioned", sourceId)
+		}
+		return err
+	}
+	return nil
+}
+
 // newContainerId returns a new id for a machine within the machine
 // with id parentId and the given container type.
 func (st *State) newContainerId(parentId string, containerType instance.ContainerType) (string, error) {
@@ -350,6 +398,9 @@ func (st *State) addMachineInsideNewMachineOps(template, parentTemplate MachineT
 	if template.InstanceId != "" || parentTemplate.InstanceId != "" {
 		return nil, nil, fmt.Errorf("cannot specify instance id for a new container")
 	}
+	if template.CloneFrom != "" || parentTemplate.CloneFrom != "" {
+		return nil, nil, fmt.Errorf("cannot clone a container into a new machine")
+	}
 	seq, err := st.sequence("machine")
 	if err != nil {
 		return nil, nil, err
@@ -407,6 +458,7 @@ func machineDocForTemplate(template MachineTemplate, id string) *machineDoc {
 		Addresses:  instanceAddressesToAddresses(template.Addresses),
 		NoVote:     template.NoVote,
 		Placement:  template.Placement,
+		CloneFrom:  template.CloneFrom,
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/juju/cert"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/bsoncodec"
 	"github.com/juju/juju/rpc/jsoncodec"
 	"github.com/juju/juju/state/api/params"
 )
@@ -113,6 +114,13 @@ type DialOpts struct {
 	// RetryDelay is the amount of time to wait between
 	// unsucssful connection attempts.
 	RetryDelay time.Duration
+
+	// PreferBSON specifies that the connection should use the
+	// more compact BSON codec if the API server supports it.
+	// API servers that predate codec negotiation reject the
+	// offered protocols, in which case the connection is
+	// retried with the JSON codec.
+	PreferBSON bool
 }
 
 // DefaultDialOpts returns a DialOpts representing the default
@@ -174,7 +182,7 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 	conn := result.(*websocket.Conn)
 	logger.Infof("connection established to %q", conn.RemoteAddr())
 
-	client := rpc.NewConn(jsoncodec.NewWebsocket(conn), nil)
+	client := rpc.NewConn(newCodec(conn), nil)
 	client.Start()
 	st := &State{
 		client:     client,
@@ -212,6 +220,10 @@ func dialWebsocket(addr, environUUID string, opts DialOpts, rootCAs *x509.CertPo
 	if err != nil {
 		return err
 	}
+	if opts.PreferBSON {
+		// The server picks the first protocol it supports.
+		cfg.Protocol = []string{bsoncodec.WebsocketProtocol, jsoncodec.WebsocketProtocol}
+	}
 	return try.Start(newWebsocketDialer(cfg, opts))
 }
 
@@ -235,6 +247,19 @@ func setUpWebsocket(addr, environUUID string, rootCAs *x509.CertPool) (*websocke
 	return cfg, nil
 }
 
+// newCodec returns an rpc codec for the given connection, using the
+// codec negotiated with the server when the connection was opened.
+func newCodec(conn *websocket.Conn) rpc.Codec {
+	// The websocket package replaces the offered protocols with the
+	// one the server selected, if any.
+	protocols := conn.Config().Protocol
+	if len(protocols) == 1 && protocols[0] == bsoncodec.WebsocketProtocol {
+		logger.Debugf("using BSON codec")
+		return bsoncodec.NewWebsocket(conn)
+	}
+	return jsoncodec.NewWebsocket(conn)
+}
+
 // newWebsocketDialer returns a function that
 // can be passed to utils/parallel.Try.Start.
 func newWebsocketDialer(cfg *websocket.Config, opts DialOpts) func(<-chan struct{}) (io.Closer, error) {
@@ -251,6 +276,12 @@ func newWebsocketDialer(cfg *websocket.Config, opts DialOpts) func(<-chan struct
 			}
 			logger.Infof("dialing %q", cfg.Location)
 			conn, err := websocket.DialConfig(cfg)
+			if isProtocolRejected(err) && len(cfg.Protocol) > 0 {
+				logger.Infof("API server at %q does not support codec negotiation; using JSON", cfg.Location)
+				jsonCfg := *cfg
+				jsonCfg.Protocol = nil
+				conn, err = websocket.DialConfig(&jsonCfg)
+			}
 			if err == nil {
 				return conn, nil
 			}
@@ -265,6 +296,17 @@ func newWebsocketDialer(cfg *websocket.Config, opts DialOpts) func(<-chan struct
 	}
 }
 
+// isProtocolRejected reports whether the given error from
+// websocket.DialConfig means that the server refused the
+// websocket handshake, as API servers that do not negotiate
+// codecs do when more than one protocol is offered.
+func isProtocolRejected(err error) bool {
+	if dialErr, ok := err.(*websocket.DialError); ok {
+		return dialErr.Err == websocket.ErrBadStatus
+	}
+	return false
+}
+
 func (s *State) heartbeatMonitor() {
 	for {
 		if err := s.Ping(); err != nil {
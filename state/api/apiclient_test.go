@@ -4,16 +4,20 @@
 package api_test
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
+	"net/http/httptest"
 	"strconv"
 
+	"code.google.com/p/go.net/websocket"
 	"github.com/juju/names"
 	"github.com/juju/utils/parallel"
 	gc "launchpad.net/gocheck"
 
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
@@ -154,6 +158,46 @@ func (s *apiclientSuite) TestOpenPassesEnvironTag(c *gc.C) {
 	st.Close()
 }
 
+func (s *apiclientSuite) TestOpenDefaultsToJSON(c *gc.C) {
+	st, err := api.Open(s.APIInfo(c), api.DialOpts{})
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	c.Assert(api.NegotiatedProtocols(st), gc.HasLen, 0)
+}
+
+func (s *apiclientSuite) TestOpenWithBSON(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	st, err := api.Open(s.APIInfo(c), api.DialOpts{PreferBSON: true})
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	c.Assert(api.NegotiatedProtocols(st), gc.DeepEquals, []string{"juju-rpc-bson"})
+
+	// Check that calls with large and irregular
+	// results work as they do with JSON.
+	status, err := st.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Machines[m.Id()].Series, gc.Equals, "quantal")
+
+	config, err := st.Client().EnvironmentGet()
+	c.Assert(err, gc.IsNil)
+	c.Assert(config["name"], gc.Equals, "dummyenv")
+
+	watcher, err := st.Client().WatchAll()
+	c.Assert(err, gc.IsNil)
+	defer watcher.Stop()
+	deltas, err := watcher.Next()
+	c.Assert(err, gc.IsNil)
+	found := false
+	for _, delta := range deltas {
+		if info, ok := delta.Entity.(*params.MachineInfo); ok && info.Id == m.Id() {
+			c.Assert(info.Series, gc.Equals, "quantal")
+			found = true
+		}
+	}
+	c.Assert(found, gc.Equals, true)
+}
+
 func (s *apiclientSuite) TestDialWebsocketStopped(c *gc.C) {
 	stopped := make(chan struct{})
 	f := api.NewWebsocketDialer(nil, api.DialOpts{})
@@ -163,6 +207,27 @@ func (s *apiclientSuite) TestDialWebsocketStopped(c *gc.C) {
 	c.Assert(result, gc.IsNil)
 }
 
+func (*websocketSuite) TestDialWebsocketFallsBackToJSON(c *gc.C) {
+	// API servers that predate codec negotiation have no
+	// handshake function, so the websocket package rejects
+	// connections that offer more than one protocol.
+	srv := httptest.NewTLSServer(websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			conn.Close()
+		},
+	})
+	defer srv.Close()
+	cfg, err := websocket.NewConfig("wss://"+srv.Listener.Addr().String()+"/", "http://localhost/")
+	c.Assert(err, gc.IsNil)
+	cfg.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	cfg.Protocol = []string{"juju-rpc-bson", "juju-rpc-json"}
+	f := api.NewWebsocketDialer(cfg, api.DialOpts{PreferBSON: true})
+	result, err := f(make(chan struct{}))
+	c.Assert(err, gc.IsNil)
+	defer result.Close()
+	c.Assert(result.(*websocket.Conn).Config().Protocol, gc.HasLen, 0)
+}
+
 func (*websocketSuite) TestSetUpWebsocketConfig(c *gc.C) {
 	conf, err := api.SetUpWebsocket("0.1.2.3:1234", "", nil)
 	c.Assert(err, gc.IsNil)
@@ -31,3 +31,18 @@ func (st *State) UpdateLatestRevisions() error {
 	}
 	return nil
 }
+
+// UpgradeCharms upgrades the services whose charm upgrade policy
+// allows it to the latest charm revisions recorded by
+// UpdateLatestRevisions.
+func (st *State) UpgradeCharms() error {
+	result := new(params.ErrorResult)
+	err := st.caller.Call("CharmRevisionUpdater", "", "UpgradeCharms", nil, result)
+	if err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -67,3 +67,20 @@ func (s *versionUpdaterSuite) TestUpdateRevisions(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(pending.String(), gc.Equals, "cs:quantal/mysql-23")
 }
+
+func (s *versionUpdaterSuite) TestUpgradeCharms(c *gc.C) {
+	s.SetupScenario(c)
+	svc, err := s.State.Service("mysql")
+	c.Assert(err, gc.IsNil)
+	err = svc.SetCharmUpgradePolicy(state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+	c.Assert(err, gc.IsNil)
+	err = s.updater.UpdateLatestRevisions()
+	c.Assert(err, gc.IsNil)
+
+	err = s.updater.UpgradeCharms()
+	c.Assert(err, gc.IsNil)
+	err = svc.Refresh()
+	c.Assert(err, gc.IsNil)
+	curl, _ := svc.CharmURL()
+	c.Assert(curl.String(), gc.Equals, "cs:quantal/mysql-23")
+}
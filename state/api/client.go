@@ -93,6 +93,28 @@ type ServiceStatus struct {
 	CanUpgradeTo  string
 	SubordinateTo []string
 	Units         map[string]UnitStatus
+
+	// Autoscale holds the service's autoscale policy and most
+	// recent scaling decisions, if the service is autoscaled.
+	Autoscale *AutoscaleStatus
+
+	// CharmUpgrade holds the service's charm upgrade policy and
+	// most recent charm upgrades, if the service has a policy.
+	CharmUpgrade *CharmUpgradeStatus
+}
+
+// CharmUpgradeStatus holds the charm upgrade policy of a service,
+// along with the most recent charm upgrades, latest first.
+type CharmUpgradeStatus struct {
+	Policy  params.CharmUpgradePolicy
+	History []params.CharmUpgradeRecord
+}
+
+// AutoscaleStatus holds the autoscale policy of a service,
+// along with the most recent scaling decisions, latest first.
+type AutoscaleStatus struct {
+	Policy  params.AutoscalePolicy
+	History []params.AutoscaleDecision
 }
 
 // UnitStatus holds status info about a unit.
@@ -120,6 +142,10 @@ type RelationStatus struct {
 	Interface string
 	Scope     charm.RelationScope
 	Endpoints []EndpointStatus
+
+	// SubordinatePlacement describes any restrictions on the principal
+	// units that are given subordinates by the relation.
+	SubordinatePlacement string
 }
 
 // EndpointStatus holds status info about a single endpoint
@@ -267,6 +293,21 @@ func (c *Client) AddRelation(endpoints ...string) (*params.AddRelationResults, e
 	return &addRelRes, err
 }
 
+// AddRelationWithPlacement adds a container-scoped relation between
+// the specified endpoints that gives subordinate units only to the
+// principal units that are named in units, if any, and whose machines
+// satisfy cons.
+func (c *Client) AddRelationWithPlacement(units []string, cons constraints.Value, endpoints ...string) (*params.AddRelationResults, error) {
+	var addRelRes params.AddRelationResults
+	params := params.AddRelation{
+		Endpoints:              endpoints,
+		SubordinateUnits:       units,
+		SubordinateConstraints: cons,
+	}
+	err := c.call("AddRelation", params, &addRelRes)
+	return &addRelRes, err
+}
+
 // DestroyRelation removes the relation between the specified endpoints.
 func (c *Client) DestroyRelation(endpoints ...string) error {
 	params := params.DestroyRelation{Endpoints: endpoints}
@@ -412,12 +453,43 @@ func (c *Client) AddServiceUnits(service string, numUnits int, machineSpec strin
 	return results.Units, err
 }
 
+// CloneServiceUnits adds numUnits units to a service, each in a new
+// container cloned from the container running sourceUnit.
+func (c *Client) CloneServiceUnits(service string, numUnits int, sourceUnit string) ([]string, error) {
+	args := params.CloneServiceUnits{
+		ServiceName: service,
+		NumUnits:    numUnits,
+		SourceUnit:  sourceUnit,
+	}
+	results := new(params.AddServiceUnitsResults)
+	err := c.call("CloneServiceUnits", args, results)
+	return results.Units, err
+}
+
 // DestroyServiceUnits decreases the number of units dedicated to a service.
 func (c *Client) DestroyServiceUnits(unitNames ...string) error {
 	params := params.DestroyServiceUnits{unitNames}
 	return c.call("DestroyServiceUnits", params, nil)
 }
 
+// MigrateUnit starts migrating a unit to the machine with the given id,
+// returning the name of the unit that will replace it.
+func (c *Client) MigrateUnit(unitName, machineSpec string) (string, error) {
+	args := params.MigrateUnit{
+		UnitName:      unitName,
+		ToMachineSpec: machineSpec,
+	}
+	var result params.MigrateUnitResult
+	err := c.call("MigrateUnit", args, &result)
+	return result.TargetUnit, err
+}
+
+// AbortUnitMigration abandons the migration a unit is taking part in.
+func (c *Client) AbortUnitMigration(unitName string) error {
+	args := params.AbortUnitMigration{UnitName: unitName}
+	return c.call("AbortUnitMigration", args, nil)
+}
+
 // ServiceDestroy destroys a given service.
 func (c *Client) ServiceDestroy(service string) error {
 	params := params.ServiceDestroy{
@@ -449,6 +521,42 @@ func (c *Client) SetServiceConstraints(service string, constraints constraints.V
 	return c.call("SetServiceConstraints", params, nil)
 }
 
+// SetAutoscale sets the policy by which the number of units of the
+// service is scaled according to the metrics reported by its units.
+// A nil policy stops the service from being autoscaled.
+func (c *Client) SetAutoscale(service string, policy *params.AutoscalePolicy) error {
+	params := params.SetAutoscale{
+		ServiceName: service,
+		Policy:      policy,
+	}
+	return c.call("SetAutoscale", params, nil)
+}
+
+// ServiceMetrics returns the batches of metrics reported by the units
+// of the service since the given time, oldest first.
+func (c *Client) ServiceMetrics(service string, since time.Time) ([]params.MetricBatch, error) {
+	args := params.ServiceMetrics{
+		ServiceName: service,
+		Since:       since,
+	}
+	var result params.ServiceMetricsResults
+	if err := c.call("ServiceMetrics", args, &result); err != nil {
+		return nil, err
+	}
+	return result.Batches, nil
+}
+
+// SetCharmUpgradePolicy sets the policy by which the service is
+// upgraded when new revisions of its charm appear in the charm store.
+// A nil policy clears the service's policy and its upgrade history.
+func (c *Client) SetCharmUpgradePolicy(service string, policy *params.CharmUpgradePolicy) error {
+	params := params.SetCharmUpgradePolicy{
+		ServiceName: service,
+		Policy:      policy,
+	}
+	return c.call("SetCharmUpgradePolicy", params, nil)
+}
+
 // SetEnvironmentConstraints specifies the constraints for the environment.
 func (c *Client) SetEnvironmentConstraints(constraints constraints.Value) error {
 	params := params.SetConstraints{
@@ -457,6 +565,27 @@ func (c *Client) SetEnvironmentConstraints(constraints constraints.Value) error
 	return c.call("SetEnvironmentConstraints", params, nil)
 }
 
+// ListCharms returns the charms stored in the environment, with the
+// services that use them.
+func (c *Client) ListCharms() ([]params.CharmSummary, error) {
+	var result params.ListCharmsResults
+	if err := c.call("ListCharms", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Charms, nil
+}
+
+// CharmsGC removes the charms that are not used by any service from
+// the environment and its storage, and returns their URLs. If dryRun
+// is true, the charms are reported but not removed.
+func (c *Client) CharmsGC(dryRun bool) ([]string, error) {
+	var result params.CharmsGCResults
+	if err := c.call("CharmsGC", params.CharmsGC{DryRun: dryRun}, &result); err != nil {
+		return nil, err
+	}
+	return result.Removed, nil
+}
+
 // CharmInfo holds information about a charm.
 type CharmInfo struct {
 	Revision int
@@ -690,6 +819,58 @@ func (c *Client) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, err
 	return charm.MustParseURL(jsonResponse.CharmURL), nil
 }
 
+// CharmFiles returns the paths of the files in the archive of the
+// given local charm, as stored in the environment.
+func (c *Client) CharmFiles(curl *charm.URL) ([]string, error) {
+	body, err := c.getCharmContent(curl, "")
+	if err != nil {
+		return nil, err
+	}
+	var jsonResponse params.CharmsResponse
+	if err := json.Unmarshal(body, &jsonResponse); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal charm files response: %v", err)
+	}
+	return jsonResponse.Files, nil
+}
+
+// CharmFile returns the contents of the file at path in the archive
+// of the given local charm, as stored in the environment.
+func (c *Client) CharmFile(curl *charm.URL, path string) ([]byte, error) {
+	return c.getCharmContent(curl, path)
+}
+
+// getCharmContent retrieves the list of files in the archive of the
+// given charm or, if path is not empty, the contents of that file.
+func (c *Client) getCharmContent(curl *charm.URL, path string) ([]byte, error) {
+	query := url.Values{"url": {curl.String()}}
+	if path != "" {
+		query.Set("file", path)
+	}
+	req, err := http.NewRequest("GET", c.st.serverRoot+"/charms?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create charm request: %v", err)
+	}
+	req.SetBasicAuth(c.st.tag, c.st.password)
+	// See the BUG note in AddLocalCharm.
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get charm %q: %v", curl, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read charm response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var jsonResponse params.CharmsResponse
+		if err := json.Unmarshal(body, &jsonResponse); err == nil && jsonResponse.Error != "" {
+			return nil, fmt.Errorf("cannot get charm %q: %v", curl, jsonResponse.Error)
+		}
+		return nil, fmt.Errorf("cannot get charm %q: %s", curl, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
 // AddCharm adds the given charm URL (which must include revision) to
 // the environment, if it does not exist yet. Local charms are not
 // supported, only charm store URLs. See also AddLocalCharm() in the
@@ -95,6 +95,37 @@ func (s *clientSuite) TestAddLocalCharm(c *gc.C) {
 	c.Assert(err, jc.Satisfies, params.IsCodeNotImplemented)
 }
 
+func (s *clientSuite) TestCharmFiles(c *gc.C) {
+	charmArchive := charmtesting.Charms.Bundle(c.MkDir(), "dummy")
+	curl := charm.MustParseURL(
+		fmt.Sprintf("local:quantal/%s-%d", charmArchive.Meta().Name, charmArchive.Revision()),
+	)
+	client := s.APIState.Client()
+	savedURL, err := client.AddLocalCharm(curl, charmArchive)
+	c.Assert(err, gc.IsNil)
+
+	files, err := client.CharmFiles(savedURL)
+	c.Assert(err, gc.IsNil)
+	c.Assert(files, jc.SameContents, charmArchiveFiles(c, charmArchive))
+
+	data, err := client.CharmFile(savedURL, "metadata.yaml")
+	c.Assert(err, gc.IsNil)
+	meta, err := charm.ReadMeta(bytes.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta, gc.DeepEquals, charmArchive.Meta())
+
+	_, err = client.CharmFile(savedURL, "no-such-file")
+	c.Assert(err, gc.ErrorMatches, `cannot get charm "local:quantal/dummy-\d+": 404 page not found`)
+	_, err = client.CharmFiles(charm.MustParseURL("local:quantal/missing-1"))
+	c.Assert(err, gc.ErrorMatches, `cannot get charm "local:quantal/missing-1": unable to retrieve and save the charm: .*`)
+}
+
+func charmArchiveFiles(c *gc.C, archive *charm.Bundle) []string {
+	manifest, err := archive.Manifest()
+	c.Assert(err, gc.IsNil)
+	return manifest.SortedValues()
+}
+
 func (s *clientSuite) TestClientEnvironmentUUID(c *gc.C) {
 	environ, err := s.State.Environment()
 	c.Assert(err, gc.IsNil)
@@ -26,3 +26,9 @@ func PatchEnvironTag(st *State, envTag string) func() {
 		st.environTag = originalTag
 	}
 }
+
+// NegotiatedProtocols returns the websocket subprotocols
+// of the connection after negotiation with the server.
+func NegotiatedProtocols(st *State) []string {
+	return st.conn.Config().Protocol
+}
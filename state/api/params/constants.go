@@ -39,6 +39,17 @@ const (
 	ResolvedNoHooks    ResolvedMode = "no-hooks"
 )
 
+// MigrationPhase describes how far the migration of a unit to a new
+// machine has progressed.
+type MigrationPhase string
+
+const (
+	MigrationProvisioning MigrationPhase = "provisioning"
+	MigrationExporting    MigrationPhase = "exporting"
+	MigrationImporting    MigrationPhase = "importing"
+	MigrationHandingOver  MigrationPhase = "handing-over"
+)
+
 // Status represents the status of an entity.
 // It could be a unit, machine or its agent.
 type Status string
@@ -67,6 +78,23 @@ const (
 	StatusDown Status = "down"
 )
 
+// Status data keys recording the state of automatic retries when
+// the provisioner fails to start an instance for a machine.
+const (
+	// StatusDataProvisioningAttempt holds the number of the
+	// last failed attempt.
+	StatusDataProvisioningAttempt = "provisioning-attempt"
+
+	// StatusDataProvisioningMaxAttempts holds the number of
+	// attempts that will be made before giving up.
+	StatusDataProvisioningMaxAttempts = "provisioning-max-attempts"
+
+	// StatusDataProvisioningNextRetry holds the time of the next
+	// attempt in RFC3339 format. It is absent once the provisioner
+	// has given up.
+	StatusDataProvisioningNextRetry = "provisioning-next-retry"
+)
+
 // Valid returns true if status has a known value.
 func (status Status) Valid() bool {
 	switch status {
@@ -123,6 +123,62 @@ type ResolvedModeResults struct {
 	Results []ResolvedModeResult
 }
 
+// UnitMigration describes the migration of a unit to a new machine,
+// as seen by one of the two units taking part in it. All fields are
+// empty when the unit is not migrating.
+type UnitMigration struct {
+	Source string
+	Target string
+	Phase  MigrationPhase
+
+	// RemoteAddress holds the private address of the other unit
+	// taking part in the migration, if it is known.
+	RemoteAddress string
+}
+
+// UnitMigrationResult holds a unit migration or an error.
+type UnitMigrationResult struct {
+	Error  *Error
+	Result UnitMigration
+}
+
+// UnitMigrationResults holds the bulk operation result of an API call
+// that returns unit migrations or errors.
+type UnitMigrationResults struct {
+	Results []UnitMigrationResult
+}
+
+// EntityMigrationPhase holds an entity tag and a migration phase.
+type EntityMigrationPhase struct {
+	Tag   string
+	Phase MigrationPhase
+}
+
+// EntitiesMigrationPhase holds the parameters for making a
+// SetMigrationPhase API call.
+type EntitiesMigrationPhase struct {
+	Entities []EntityMigrationPhase
+}
+
+// Metric holds a numeric measurement reported by a unit.
+type Metric struct {
+	Key   string
+	Value float64
+	Time  time.Time
+}
+
+// EntityMetrics holds an entity tag and the metrics it reported.
+type EntityMetrics struct {
+	Tag     string
+	Metrics []Metric
+}
+
+// EntitiesMetrics holds the parameters for making an AddMetrics
+// API call.
+type EntitiesMetrics struct {
+	Entities []EntityMetrics
+}
+
 // StringBoolResult holds the result of an API call that returns a
 // string and a boolean.
 type StringBoolResult struct {
@@ -650,6 +706,7 @@ type ProvisioningInfo struct {
 	Series      string
 	Placement   string
 	Networks    []string
+	CloneFrom   instance.Id
 }
 
 // ProvisioningInfoResult holds machine provisioning info or an error.
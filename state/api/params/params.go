@@ -11,6 +11,7 @@ import (
 
 	"github.com/juju/charm"
 	"github.com/juju/utils/proxy"
+	"labix.org/v2/mgo/bson"
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
@@ -73,6 +74,12 @@ type StatusData map[string]interface{}
 // The endpoints specified are unordered.
 type AddRelation struct {
 	Endpoints []string
+
+	// SubordinateUnits and SubordinateConstraints, if set, restrict
+	// the principal units that a container-scoped relation gives
+	// subordinate units to.
+	SubordinateUnits       []string
+	SubordinateConstraints constraints.Value
 }
 
 // AddRelationResults holds the results of a AddRelation call. The Endpoints
@@ -171,6 +178,102 @@ type ServiceUpdate struct {
 	Constraints     *constraints.Value
 }
 
+// AutoscalePolicy describes how the number of units of a service
+// is scaled according to the metrics reported by its units.
+type AutoscalePolicy struct {
+	MinUnits           int
+	MaxUnits           int
+	Metric             string
+	ScaleUpThreshold   float64
+	ScaleDownThreshold float64
+	Cooldown           time.Duration
+}
+
+// AutoscaleDecision records a change made by the autoscaler
+// to the number of units of a service.
+type AutoscaleDecision struct {
+	Time   time.Time
+	From   int
+	To     int
+	Reason string
+}
+
+// SetAutoscale holds the parameters for making the SetAutoscale call.
+// A nil Policy stops the service from being autoscaled.
+type SetAutoscale struct {
+	ServiceName string
+	Policy      *AutoscalePolicy
+}
+
+// ServiceMetrics holds the parameters for making the ServiceMetrics call.
+// Only metrics recorded at or after Since are returned.
+type ServiceMetrics struct {
+	ServiceName string
+	Since       time.Time
+}
+
+// MetricBatch holds a batch of metrics reported by a unit
+// in a single hook execution.
+type MetricBatch struct {
+	Unit     string
+	CharmURL string
+	Created  time.Time
+	Metrics  []Metric
+}
+
+// ServiceMetricsResults holds the results of the ServiceMetrics call.
+type ServiceMetricsResults struct {
+	Batches []MetricBatch
+}
+
+// CharmSummary describes a charm stored in the environment.
+type CharmSummary struct {
+	URL      string
+	Uploaded bool
+	Services []string
+}
+
+// ListCharmsResults holds the results of the ListCharms call.
+type ListCharmsResults struct {
+	Charms []CharmSummary
+}
+
+// CharmsGC holds the parameters for making the CharmsGC call.
+// If DryRun is true, the charms that would be removed are
+// reported but not removed.
+type CharmsGC struct {
+	DryRun bool
+}
+
+// CharmsGCResults holds the results of the CharmsGC call.
+type CharmsGCResults struct {
+	Removed []string
+}
+
+// CharmUpgradePolicy describes how a service is upgraded when new
+// revisions of its charm appear in the charm store.
+type CharmUpgradePolicy struct {
+	Mode string
+}
+
+// CharmUpgradeRecord records a new revision of a service's charm,
+// and what was done about it.
+type CharmUpgradeRecord struct {
+	Time     time.Time
+	From     string
+	To       string
+	Upgraded bool
+	Error    string
+}
+
+// SetCharmUpgradePolicy holds the parameters for making the
+// SetCharmUpgradePolicy call. A nil Policy clears the service's
+// policy and its charm upgrade history.
+type SetCharmUpgradePolicy struct {
+	ServiceName string
+	Policy      *CharmUpgradePolicy
+}
+
 // ServiceSetCharm sets the charm for a given service.
 type ServiceSetCharm struct {
 	ServiceName string
@@ -281,6 +384,29 @@ type AddServiceUnits struct {
 	ToMachineSpec string
 }
 
+// CloneServiceUnits holds parameters for the CloneServiceUnits call.
+type CloneServiceUnits struct {
+	ServiceName string
+	NumUnits    int
+	SourceUnit  string
+}
+
+// MigrateUnit holds parameters for the MigrateUnit call.
+type MigrateUnit struct {
+	UnitName      string
+	ToMachineSpec string
+}
+
+// MigrateUnitResult holds the result of the MigrateUnit call.
+type MigrateUnitResult struct {
+	TargetUnit string
+}
+
+// AbortUnitMigration holds parameters for the AbortUnitMigration call.
+type AbortUnitMigration struct {
+	UnitName string
+}
+
 // DestroyServiceUnits holds parameters for the DestroyUnits call.
 type DestroyServiceUnits struct {
 	UnitNames []string
@@ -423,24 +549,77 @@ func (d *Delta) UnmarshalJSON(data []byte) error {
 	} else if operation != "change" {
 		return fmt.Errorf("Unexpected operation %q", operation)
 	}
-	switch entityKind {
+	entity, err := newEntityInfo(entityKind)
+	if err != nil {
+		return err
+	}
+	d.Entity = entity
+	if err := json.Unmarshal(elements[2], &d.Entity); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetBSON implements bson.Getter. A Delta is represented
+// in the same way as it is in JSON.
+func (d Delta) GetBSON() (interface{}, error) {
+	c := "change"
+	if d.Removed {
+		c = "remove"
+	}
+	return []interface{}{d.Entity.EntityId().Kind, c, d.Entity}, nil
+}
+
+// SetBSON implements bson.Setter.
+func (d *Delta) SetBSON(raw bson.Raw) error {
+	var elements []bson.Raw
+	if err := raw.Unmarshal(&elements); err != nil {
+		return err
+	}
+	if len(elements) != 3 {
+		return fmt.Errorf(
+			"Expected 3 elements in top-level of BSON but got %d",
+			len(elements))
+	}
+	var entityKind, operation string
+	if err := elements[0].Unmarshal(&entityKind); err != nil {
+		return err
+	}
+	if err := elements[1].Unmarshal(&operation); err != nil {
+		return err
+	}
+	if operation == "remove" {
+		d.Removed = true
+	} else if operation != "change" {
+		return fmt.Errorf("Unexpected operation %q", operation)
+	}
+	entity, err := newEntityInfo(entityKind)
+	if err != nil {
+		return err
+	}
+	if err := elements[2].Unmarshal(entity); err != nil {
+		return err
+	}
+	d.Entity = entity
+	return nil
+}
+
+// newEntityInfo returns a new, empty EntityInfo
+// value of the given kind.
+func newEntityInfo(kind string) (EntityInfo, error) {
+	switch kind {
 	case "machine":
-		d.Entity = new(MachineInfo)
+		return new(MachineInfo), nil
 	case "service":
-		d.Entity = new(ServiceInfo)
+		return new(ServiceInfo), nil
 	case "unit":
-		d.Entity = new(UnitInfo)
+		return new(UnitInfo), nil
 	case "relation":
-		d.Entity = new(RelationInfo)
+		return new(RelationInfo), nil
 	case "annotation":
-		d.Entity = new(AnnotationInfo)
-	default:
-		return fmt.Errorf("Unexpected entity name %q", entityKind)
+		return new(AnnotationInfo), nil
 	}
-	if err := json.Unmarshal(elements[2], &d.Entity); err != nil {
-		return err
-	}
-	return nil
+	return nil, fmt.Errorf("Unexpected entity name %q", kind)
 }
 
 // EntityInfo is implemented by all entity Info types.
@@ -627,6 +806,11 @@ type ProvisioningScriptParams struct {
 	// commands. It is then the responsibility of the provisioner to
 	// ensure that all the packages required by Juju are available.
 	DisablePackageCommands bool
+
+	// InitSystem, if set, is the init system detected on the
+	// machine. Otherwise the init system is chosen by the
+	// machine's series.
+	InitSystem string
 }
 
 // ProvisioningScriptResult contains the result of the
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/juju/charm"
+	jc "github.com/juju/testing/checkers"
+	"labix.org/v2/mgo/bson"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/constraints"
@@ -165,3 +167,27 @@ func (s *MarshalSuite) TestDeltaMarshalJSONUnknownEntity(c *gc.C) {
 	err := json.Unmarshal([]byte(`["qwan","change",{}]`), new(params.Delta))
 	c.Check(err, gc.ErrorMatches, `Unexpected entity name "qwan"`)
 }
+
+func (s *MarshalSuite) TestDeltaBSONRoundTrip(c *gc.C) {
+	for i, t := range marshalTestCases {
+		c.Logf("test %d. %s", i, t.about)
+		// BSON documents must be at the top level, so
+		// embed the delta in a struct.
+		data, err := bson.Marshal(struct{ D params.Delta }{t.value})
+		c.Assert(err, gc.IsNil)
+		var unmarshalled struct{ D params.Delta }
+		err = bson.Unmarshal(data, &unmarshalled)
+		c.Assert(err, gc.IsNil)
+		// BSON does not distinguish between nil and empty
+		// slices, which jc.DeepEquals allows for.
+		c.Check(unmarshalled.D, jc.DeepEquals, t.value)
+	}
+}
+
+func (s *MarshalSuite) TestDeltaSetBSONUnknownEntity(c *gc.C) {
+	data, err := bson.Marshal(bson.M{"d": []interface{}{"qwan", "change", bson.M{}}})
+	c.Assert(err, gc.IsNil)
+	var v struct{ D params.Delta }
+	err = bson.Unmarshal(data, &v)
+	c.Check(err, gc.ErrorMatches, `Unexpected entity name "qwan"`)
+}
@@ -376,6 +376,21 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 	return result.OneError()
 }
 
+// AddMetrics records the metrics reported by the unit.
+func (u *Unit) AddMetrics(metrics []params.Metric) error {
+	var result params.ErrorResults
+	args := params.EntitiesMetrics{
+		Entities: []params.EntityMetrics{
+			{Tag: u.tag.String(), Metrics: metrics},
+		},
+	}
+	err := u.st.call("AddMetrics", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // ClearResolved removes any resolved setting on the unit.
 func (u *Unit) ClearResolved() error {
 	var result params.ErrorResults
@@ -389,6 +404,56 @@ func (u *Unit) ClearResolved() error {
 	return result.OneError()
 }
 
+// Migration returns the migration to a new machine that the unit is
+// taking part in. All fields of the result are empty if the unit is not
+// migrating.
+func (u *Unit) Migration() (params.UnitMigration, error) {
+	var results params.UnitMigrationResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("Migration", args, &results)
+	if err != nil {
+		return params.UnitMigration{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.UnitMigration{}, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.UnitMigration{}, result.Error
+	}
+	return result.Result, nil
+}
+
+// SetMigrationPhase advances the migration that the unit is taking
+// part in to the given phase.
+func (u *Unit) SetMigrationPhase(phase params.MigrationPhase) error {
+	var result params.ErrorResults
+	args := params.EntitiesMigrationPhase{
+		Entities: []params.EntityMigrationPhase{{Tag: u.tag.String(), Phase: phase}},
+	}
+	err := u.st.call("SetMigrationPhase", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// CompleteMigration hands over to the unit from the source unit of the
+// migration it is the target of.
+func (u *Unit) CompleteMigration() error {
+	var result params.ErrorResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.call("CompleteMigration", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // WatchConfigSettings returns a watcher for observing changes to the
 // unit's service configuration settings. The unit must have a charm URL
 // set before this method is called, and the returned watcher will be
@@ -182,6 +182,36 @@ func (s *unitSuite) TestResolve(c *gc.C) {
 	c.Assert(mode, gc.Equals, params.ResolvedNone)
 }
 
+func (s *unitSuite) TestMigration(c *gc.C) {
+	migration, err := s.apiUnit.Migration()
+	c.Assert(err, gc.IsNil)
+	c.Assert(migration, gc.Equals, params.UnitMigration{})
+
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	target, err := s.wordpressUnit.MigrateTo(machine)
+	c.Assert(err, gc.IsNil)
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+
+	migration, err = s.apiUnit.Migration()
+	c.Assert(err, gc.IsNil)
+	c.Assert(migration, gc.Equals, params.UnitMigration{
+		Source: "wordpress/0",
+		Target: "wordpress/1",
+		Phase:  params.MigrationExporting,
+	})
+
+	err = s.apiUnit.SetMigrationPhase(params.MigrationImporting)
+	c.Assert(err, gc.IsNil)
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.MigrationPhase(), gc.Equals, state.MigrationImporting)
+
+	err = s.apiUnit.CompleteMigration()
+	c.Assert(err, gc.ErrorMatches, `cannot complete migration to unit "wordpress/0": unit is not the target of a migration`)
+}
+
 func (s *unitSuite) TestIsPrincipal(c *gc.C) {
 	ok, err := s.apiUnit.IsPrincipal()
 	c.Assert(err, gc.IsNil)
@@ -130,7 +130,20 @@ func (a *srvAdmin) Login(c params.Creds) (params.LoginResult, error) {
 		return params.LoginResult{}, err
 	}
 
+	if a.root.srv.quotas != nil {
+		quota, err := a.root.srv.quotas.connect(entity.Tag().String())
+		if err != nil {
+			return params.LoginResult{}, err
+		}
+		if quota != nil {
+			newRoot.getResources().RegisterNamed("connectionQuota", quota)
+		}
+	}
+
 	a.root.rpcConn.ServeFinder(newRoot, serverError)
+	if metrics := a.root.srv.metrics; metrics != nil {
+		metrics.addResources(newRoot.getResources())
+	}
 	lastConnection := getAndUpdateLastConnectionForEntity(entity)
 	return params.LoginResult{
 		Servers:        hostPorts,
@@ -19,6 +19,7 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/bsoncodec"
 	"github.com/juju/juju/rpc/jsoncodec"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
@@ -41,6 +42,8 @@ type Server struct {
 	logDir    string
 	limiter   utils.Limiter
 	validator LoginValidator
+	metrics   *apiMetrics
+	quotas    *rateLimiter
 
 	mu          sync.Mutex // protects the fields that follow
 	environUUID string
@@ -58,6 +61,10 @@ type ServerConfig struct {
 	DataDir   string
 	LogDir    string
 	Validator LoginValidator
+
+	// RateLimits holds the per-entity request rate and
+	// connection limits. The zero value imposes no limits.
+	RateLimits RateLimitConfig
 }
 
 // NewServer serves the given state by accepting requests on the given
@@ -80,6 +87,8 @@ func NewServer(s *state.State, lis net.Listener, cfg ServerConfig) (*Server, err
 		logDir:    cfg.LogDir,
 		limiter:   utils.NewLimiter(loginRateLimit),
 		validator: cfg.Validator,
+		metrics:   newAPIMetrics(),
+		quotas:    newRateLimiter(cfg.RateLimits),
 	}
 	// TODO(rog) check that *srvRoot is a valid type for using
 	// as an RPC server.
@@ -113,8 +122,9 @@ func (srv *Server) Wait() error {
 }
 
 type requestNotifier struct {
-	id    int64
-	start time.Time
+	id      int64
+	start   time.Time
+	metrics *apiMetrics
 
 	mu   sync.Mutex
 	tag_ string
@@ -122,11 +132,12 @@ type requestNotifier struct {
 
 var globalCounter int64
 
-func newRequestNotifier() *requestNotifier {
+func newRequestNotifier(metrics *apiMetrics) *requestNotifier {
 	return &requestNotifier{
-		id:    atomic.AddInt64(&globalCounter, 1),
-		tag_:  "<unknown>",
-		start: time.Now(),
+		id:      atomic.AddInt64(&globalCounter, 1),
+		tag_:    "<unknown>",
+		start:   time.Now(),
+		metrics: metrics,
 	}
 }
 
@@ -147,22 +158,39 @@ func (n *requestNotifier) ServerRequest(hdr *rpc.Header, body interface{}) {
 	if hdr.Request.Type == "Pinger" && hdr.Request.Action == "Ping" {
 		return
 	}
+	// Incur request logging overhead only if we
+	// know we'll need it.
+	if logger.EffectiveLogLevel() > loggo.DEBUG {
+		return
+	}
 	// TODO(rog) 2013-10-11 remove secrets from some requests.
 	logger.Debugf("<- [%X] %s %s", n.id, n.tag(), jsoncodec.DumpRequest(hdr, body))
 }
 
 func (n *requestNotifier) ServerReply(req rpc.Request, hdr *rpc.Header, body interface{}, timeSpent time.Duration) {
+	if n.metrics != nil {
+		n.metrics.requestDone(req, hdr.Error != "", timeSpent)
+	}
 	if req.Type == "Pinger" && req.Action == "Ping" {
 		return
 	}
+	if logger.EffectiveLogLevel() > loggo.DEBUG {
+		return
+	}
 	logger.Debugf("-> [%X] %s %s %s %s[%q].%s", n.id, n.tag(), timeSpent, jsoncodec.DumpRequest(hdr, body), req.Type, req.Id, req.Action)
 }
 
 func (n *requestNotifier) join(req *http.Request) {
+	if n.metrics != nil {
+		n.metrics.connectionOpened()
+	}
 	logger.Infof("[%X] API connection from %s", n.id, req.RemoteAddr)
 }
 
 func (n *requestNotifier) leave() {
+	if n.metrics != nil {
+		n.metrics.connectionClosed()
+	}
 	logger.Infof("[%X] %s API connection terminated after %v", n.id, n.tag(), time.Since(n.start))
 }
 
@@ -218,6 +246,9 @@ func (srv *Server) run(lis net.Listener) {
 	handleAll(mux, "/environment/:envuuid/tools",
 		&toolsHandler{httpHandler{state: srv.state}},
 	)
+	handleAll(mux, "/environment/:envuuid/metrics",
+		&metricsHandler{httpHandler{state: srv.state}, srv.metrics},
+	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
 	// For backwards compatibility we register all the old paths
 	handleAll(mux, "/log",
@@ -236,16 +267,20 @@ func (srv *Server) run(lis net.Listener) {
 	handleAll(mux, "/backup",
 		&backupHandler{httpHandler{state: srv.state}},
 	)
+	handleAll(mux, "/metrics",
+		&metricsHandler{httpHandler{state: srv.state}, srv.metrics},
+	)
 	handleAll(mux, "/", http.HandlerFunc(srv.apiHandler))
 	// The error from http.Serve is not interesting.
 	http.Serve(lis, mux)
 }
 
 func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
-	reqNotifier := newRequestNotifier()
+	reqNotifier := newRequestNotifier(srv.metrics)
 	reqNotifier.join(req)
 	defer reqNotifier.leave()
 	wsServer := websocket.Server{
+		Handshake: selectProtocol,
 		Handler: func(conn *websocket.Conn) {
 			srv.wg.Add(1)
 			defer srv.wg.Done()
@@ -315,18 +350,49 @@ func (srv *Server) setEnvironUUID(uuid string) {
 	srv.environUUID = uuid
 }
 
-func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifier, envUUID string) error {
+// selectProtocol chooses the codec for a new websocket connection
+// from the subprotocols offered by the client, in order of the
+// client's preference. If none is acceptable, no subprotocol is
+// selected and the JSON codec is used.
+func selectProtocol(cfg *websocket.Config, req *http.Request) error {
+	for _, protocol := range cfg.Protocol {
+		switch protocol {
+		case jsoncodec.WebsocketProtocol, bsoncodec.WebsocketProtocol:
+			cfg.Protocol = []string{protocol}
+			return nil
+		}
+	}
+	cfg.Protocol = nil
+	return nil
+}
+
+// newCodec returns an rpc codec for the given connection,
+// as negotiated by selectProtocol.
+func newCodec(wsConn *websocket.Conn) rpc.Codec {
+	protocol := ""
+	if protocols := wsConn.Config().Protocol; len(protocols) == 1 {
+		protocol = protocols[0]
+	}
+	if protocol == bsoncodec.WebsocketProtocol {
+		codec := bsoncodec.NewWebsocket(wsConn)
+		if loggo.GetLogger("juju.rpc.bsoncodec").EffectiveLogLevel() <= loggo.TRACE {
+			codec.SetLogging(true)
+		}
+		return codec
+	}
 	codec := jsoncodec.NewWebsocket(wsConn)
 	if loggo.GetLogger("juju.rpc.jsoncodec").EffectiveLogLevel() <= loggo.TRACE {
 		codec.SetLogging(true)
 	}
-	var notifier rpc.RequestNotifier
-	if logger.EffectiveLogLevel() <= loggo.DEBUG {
-		// Incur request monitoring overhead only if we
-		// know we'll need it.
-		notifier = reqNotifier
-	}
-	conn := rpc.NewConn(codec, notifier)
+	return codec
+}
+
+func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifier, envUUID string) error {
+	codec := newCodec(wsConn)
+	// The request notifier is always installed so that
+	// metrics are collected; it only logs requests when
+	// debug logging is enabled.
+	conn := rpc.NewConn(codec, reqNotifier)
 	err := srv.validateEnvironUUID(envUUID)
 	if err != nil {
 		conn.Serve(&errRoot{err}, serverError)
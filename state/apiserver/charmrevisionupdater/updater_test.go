@@ -4,6 +4,8 @@
 package charmrevisionupdater_test
 
 import (
+	"time"
+
 	"github.com/juju/charm"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -11,6 +13,7 @@ import (
 
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/charmrevisionupdater"
 	"github.com/juju/juju/state/apiserver/charmrevisionupdater/testing"
 	"github.com/juju/juju/state/apiserver/common"
@@ -130,3 +133,125 @@ func (s *charmVersionSuite) TestEnvironmentUUIDUsed(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(s.Server.Metadata, gc.DeepEquals, []string{"environment_uuid=" + env.UUID()})
 }
+
+func (s *charmVersionSuite) setUpUpgrade(c *gc.C, policy state.CharmUpgradePolicy) *state.Service {
+	s.AddMachine(c, "0", state.JobManageEnviron)
+	s.SetupScenario(c)
+	svc, err := s.State.Service("mysql")
+	c.Assert(err, gc.IsNil)
+	err = svc.SetCharmUpgradePolicy(policy)
+	c.Assert(err, gc.IsNil)
+	result, err := s.charmrevisionupdater.UpdateLatestRevisions()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Error, gc.IsNil)
+	return svc
+}
+
+func (s *charmVersionSuite) upgradeCharms(c *gc.C, svc *state.Service) (string, []state.CharmUpgradeRecord) {
+	result, err := s.charmrevisionupdater.UpgradeCharms()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Error, gc.IsNil)
+	err = svc.Refresh()
+	c.Assert(err, gc.IsNil)
+	curl, _ := svc.CharmURL()
+	history, err := svc.CharmUpgradeHistory()
+	c.Assert(err, gc.IsNil)
+	return curl.String(), history
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsAuto(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+
+	curl, history := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-23")
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].From, gc.Equals, "cs:quantal/mysql-22")
+	c.Assert(history[0].To, gc.Equals, "cs:quantal/mysql-23")
+	c.Assert(history[0].Upgraded, jc.IsTrue)
+	c.Assert(history[0].Error, gc.Equals, "")
+	ch, err := s.State.Charm(charm.MustParseURL("cs:quantal/mysql-23"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(ch.IsUploaded(), jc.IsTrue)
+
+	// Nothing more happens until a new revision appears.
+	_, history = s.upgradeCharms(c, svc)
+	c.Assert(history, gc.HasLen, 1)
+
+	// Services without a policy are left alone.
+	varnish, err := s.State.Service("varnish")
+	c.Assert(err, gc.IsNil)
+	curl, _ = s.upgradeCharms(c, varnish)
+	c.Assert(curl, gc.Equals, "cs:quantal/varnish-5")
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsNotify(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeNotify})
+
+	curl, history := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-22")
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].To, gc.Equals, "cs:quantal/mysql-23")
+	c.Assert(history[0].Upgraded, jc.IsFalse)
+
+	// Each revision is only recorded once.
+	_, history = s.upgradeCharms(c, svc)
+	c.Assert(history, gc.HasLen, 1)
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsNever(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeNever})
+	curl, history := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-22")
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsWindow(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeWindow})
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": "0 2 * * * 1h",
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	t := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(charmrevisionupdater.Now, func() time.Time { return t })
+
+	curl, history := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-22")
+	c.Assert(history, gc.HasLen, 0)
+
+	t = time.Date(2014, 6, 2, 2, 30, 0, 0, time.UTC)
+	curl, history = s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-23")
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].Time.Equal(t), jc.IsTrue)
+	c.Assert(history[0].Upgraded, jc.IsTrue)
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsAutoIgnoresWindows(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": "0 2 * * * 1h",
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	t := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(charmrevisionupdater.Now, func() time.Time { return t })
+
+	curl, _ := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-23")
+}
+
+func (s *charmVersionSuite) TestUpgradeCharmsUnitInError(c *gc.C) {
+	svc := s.setUpUpgrade(c, state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+	unit, err := s.State.Unit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	err = unit.SetStatus(params.StatusError, "hook failed", nil)
+	c.Assert(err, gc.IsNil)
+
+	curl, history := s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-22")
+	c.Assert(history, gc.HasLen, 0)
+
+	err = unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	curl, _ = s.upgradeCharms(c, svc)
+	c.Assert(curl, gc.Equals, "cs:quantal/mysql-23")
+}
@@ -0,0 +1,146 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmrevisionupdater
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/client"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// UpgradeCharms acts on the latest charm revisions recorded by
+// UpdateLatestRevisions, according to the charm upgrade policy of
+// each service: services are upgraded when their policy allows it,
+// and new revisions are recorded in the history of the services that
+// asked to be notified. Services in window mode are only upgraded
+// within the environment's maintenance windows.
+func (api *CharmRevisionUpdaterAPI) UpgradeCharms() (params.ErrorResult, error) {
+	cfg, err := api.state.EnvironConfig()
+	if err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}, nil
+	}
+	services, err := api.state.AllServices()
+	if err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}, nil
+	}
+	t := now()
+	inWindow := cfg.MaintenancePlan().Delay(t) == 0
+	for _, service := range services {
+		if err := upgradeCharm(api.state, service, t, inWindow); err != nil {
+			logger.Errorf("cannot upgrade charm of service %q: %v", service.Name(), err)
+		}
+	}
+	return params.ErrorResult{}, nil
+}
+
+// upgradeCharm acts on the latest revision of the service's charm at
+// time t, according to the service's charm upgrade policy; inWindow
+// holds whether a maintenance window is open at t.
+func upgradeCharm(st *state.State, service *state.Service, t time.Time, inWindow bool) error {
+	policy, err := service.CharmUpgradePolicy()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if policy.Mode == state.CharmUpgradeNever {
+		return nil
+	}
+	curl, _ := service.CharmURL()
+	// Placeholders are recorded for the same series as the deployed
+	// charm, so services never change series here.
+	latest, err := st.LatestPlaceholderCharm(curl)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if latest.Revision() <= curl.Revision {
+		return nil
+	}
+	history, err := service.CharmUpgradeHistory()
+	if err != nil {
+		return err
+	}
+	var last *state.CharmUpgradeRecord
+	if len(history) > 0 && history[0].To == latest.String() {
+		last = &history[0]
+	}
+	record := state.CharmUpgradeRecord{
+		Time: t,
+		From: curl.String(),
+		To:   latest.String(),
+	}
+	upgrade := policy.Mode == state.CharmUpgradeAuto ||
+		policy.Mode == state.CharmUpgradeWindow && inWindow
+	if !upgrade {
+		if policy.Mode == state.CharmUpgradeNotify && last == nil {
+			logger.Infof("charm %q is available for service %q", latest, service.Name())
+			return service.RecordCharmUpgrade(record)
+		}
+		return nil
+	}
+	if unit, err := unitInError(service); err != nil {
+		return err
+	} else if unit != "" {
+		logger.Infof("not upgrading service %q to charm %q: unit %q is in error", service.Name(), latest, unit)
+		return nil
+	}
+	if err := upgradeService(st, service, latest.URL()); err != nil {
+		if last != nil && last.Error != "" {
+			// The failure has been recorded already.
+			return err
+		}
+		record.Error = err.Error()
+	} else {
+		logger.Infof("upgraded service %q to charm %q", service.Name(), latest)
+		record.Upgraded = true
+	}
+	return service.RecordCharmUpgrade(record)
+}
+
+// unitInError returns the name of a unit of the service that is in
+// an error state, or the empty string if there is none.
+func unitInError(service *state.Service) (string, error) {
+	units, err := service.AllUnits()
+	if err != nil {
+		return "", err
+	}
+	for _, unit := range units {
+		status, _, _, err := unit.Status()
+		if err != nil {
+			return "", err
+		}
+		if status == params.StatusError {
+			return unit.Name(), nil
+		}
+	}
+	return "", nil
+}
+
+// upgradeService adds the charm store charm with the given URL to
+// the environment, and upgrades the service to it.
+func upgradeService(st *state.State, service *state.Service, curl *charm.URL) error {
+	if err := client.AddStoreCharm(st, charm.Store, curl); err != nil {
+		return err
+	}
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return err
+	}
+	if err := service.SetCharm(ch, false); err != nil {
+		return fmt.Errorf("cannot set charm: %v", err)
+	}
+	return nil
+}
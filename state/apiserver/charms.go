@@ -373,7 +373,7 @@ func (h *charmsHandler) repackageAndUploadCharm(archive *charm.Bundle, curl *cha
 	}
 
 	// And finally, update state.
-	_, err = h.state.UpdateUploadedCharm(archive, curl, bundleURL, bundleSHA256)
+	_, err = h.state.UpdateUploadedCharm(archive, curl, bundleURL, name, bundleSHA256)
 	if err != nil {
 		return errors.Annotate(err, "cannot update uploaded charm in state")
 	}
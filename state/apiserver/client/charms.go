@@ -0,0 +1,146 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// ListCharms returns the charms stored in the environment, with the
+// services that use them.
+func (c *Client) ListCharms() (params.ListCharmsResults, error) {
+	charms, err := c.api.state.AllCharms()
+	if err != nil {
+		return params.ListCharmsResults{}, err
+	}
+	users, err := c.charmUsers()
+	if err != nil {
+		return params.ListCharmsResults{}, err
+	}
+	var result params.ListCharmsResults
+	for _, ch := range charms {
+		if ch.IsPlaceholder() {
+			continue
+		}
+		curl := ch.URL().String()
+		result.Charms = append(result.Charms, params.CharmSummary{
+			URL:      curl,
+			Uploaded: ch.IsUploaded(),
+			Services: users[curl],
+		})
+	}
+	return result, nil
+}
+
+// charmUsers returns the names of the services using each charm,
+// keyed by charm URL. Units that have not yet been upgraded to their
+// service's charm count as using the charm of their service.
+func (c *Client) charmUsers() (map[string][]string, error) {
+	services, err := c.api.state.AllServices()
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string][]string)
+	add := func(curl, service string) {
+		for _, name := range users[curl] {
+			if name == service {
+				return
+			}
+		}
+		users[curl] = append(users[curl], service)
+	}
+	for _, svc := range services {
+		curl, _ := svc.CharmURL()
+		add(curl.String(), svc.Name())
+		units, err := svc.AllUnits()
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range units {
+			if curl, ok := unit.CharmURL(); ok {
+				add(curl.String(), svc.Name())
+			}
+		}
+	}
+	for _, names := range users {
+		sort.Strings(names)
+	}
+	return users, nil
+}
+
+// CharmsGC removes the charms that are not used by any service or
+// unit from the environment, together with their archives in the
+// provider storage, and returns the URLs of the removed charms.
+func (c *Client) CharmsGC(args params.CharmsGC) (params.CharmsGCResults, error) {
+	var result params.CharmsGCResults
+	charms, err := c.api.state.AllCharms()
+	if err != nil {
+		return result, err
+	}
+	users, err := c.charmUsers()
+	if err != nil {
+		return result, err
+	}
+	var unused []*state.Charm
+	for _, ch := range charms {
+		if ch.IsPlaceholder() || !ch.IsUploaded() || len(users[ch.URL().String()]) > 0 {
+			continue
+		}
+		unused = append(unused, ch)
+	}
+	if len(unused) == 0 || args.DryRun {
+		for _, ch := range unused {
+			result.Removed = append(result.Removed, ch.URL().String())
+		}
+		return result, nil
+	}
+	stor, err := getStorage(c.api.state)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot access provider storage")
+	}
+	for _, ch := range unused {
+		// Remove the charm from state first, and only remove
+		// its archive once that has committed, so state never
+		// refers to a missing archive. Remove fails if the charm
+		// has started being used since it was listed.
+		if err := ch.Remove(); err != nil {
+			logger.Warningf("%v", err)
+			continue
+		}
+		result.Removed = append(result.Removed, ch.URL().String())
+		name := charmArchiveName(ch)
+		if name == "" {
+			logger.Warningf("archive for charm %q not known; leaving it in provider storage", ch.URL())
+			continue
+		}
+		if err := stor.Remove(name); err != nil {
+			return result, errors.Annotatef(err, "cannot remove archive for charm %q", ch.URL())
+		}
+	}
+	return result, nil
+}
+
+// getStorage returns the environment's provider storage.
+var getStorage = environs.GetStorage
+
+// charmArchiveName returns the name of the charm's archive in the
+// provider storage, or an empty string if it is not known. Local
+// charms uploaded before their storage path was recorded are always
+// stored under their quoted URL.
+func charmArchiveName(ch *state.Charm) string {
+	if name := ch.StoragePath(); name != "" {
+		return name
+	}
+	if ch.URL().Schema == "local" {
+		return charm.Quote(ch.URL().String())
+	}
+	return ""
+}
@@ -477,6 +477,27 @@ func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddService
 	return params.AddServiceUnitsResults{Units: unitNames}, nil
 }
 
+// CloneServiceUnits adds a given number of units to a service, each
+// running in a container cloned from the container of an existing unit.
+func (c *Client) CloneServiceUnits(args params.CloneServiceUnits) (params.AddServiceUnitsResults, error) {
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.AddServiceUnitsResults{}, err
+	}
+	if args.NumUnits < 1 {
+		return params.AddServiceUnitsResults{}, fmt.Errorf("must add at least one unit")
+	}
+	units, err := juju.CloneUnits(c.api.state, service, args.NumUnits, args.SourceUnit)
+	if err != nil {
+		return params.AddServiceUnitsResults{}, err
+	}
+	unitNames := make([]string, len(units))
+	for i, unit := range units {
+		unitNames[i] = unit.String()
+	}
+	return params.AddServiceUnitsResults{Units: unitNames}, nil
+}
+
 // DestroyServiceUnits removes a given set of service units.
 func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
 	var errs []string
@@ -500,6 +521,36 @@ func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
 	return destroyErr("units", args.UnitNames, errs)
 }
 
+// MigrateUnit starts migrating a unit to another machine, returning the
+// name of the unit that will replace it.
+func (c *Client) MigrateUnit(args params.MigrateUnit) (params.MigrateUnitResult, error) {
+	unit, err := c.api.state.Unit(args.UnitName)
+	if err != nil {
+		return params.MigrateUnitResult{}, err
+	}
+	if !names.IsValidMachine(args.ToMachineSpec) {
+		return params.MigrateUnitResult{}, fmt.Errorf("invalid machine id %q", args.ToMachineSpec)
+	}
+	machine, err := c.api.state.Machine(args.ToMachineSpec)
+	if err != nil {
+		return params.MigrateUnitResult{}, err
+	}
+	target, err := unit.MigrateTo(machine)
+	if err != nil {
+		return params.MigrateUnitResult{}, err
+	}
+	return params.MigrateUnitResult{TargetUnit: target.Name()}, nil
+}
+
+// AbortUnitMigration abandons the migration a unit is taking part in.
+func (c *Client) AbortUnitMigration(args params.AbortUnitMigration) error {
+	unit, err := c.api.state.Unit(args.UnitName)
+	if err != nil {
+		return err
+	}
+	return unit.AbortMigration()
+}
+
 // ServiceDestroy destroys a given service.
 func (c *Client) ServiceDestroy(args params.ServiceDestroy) error {
 	svc, err := c.api.state.Service(args.ServiceName)
@@ -537,6 +588,79 @@ func (c *Client) SetServiceConstraints(args params.SetConstraints) error {
 	return svc.SetConstraints(args.Constraints)
 }
 
+// SetAutoscale sets or clears the autoscale policy of a service.
+func (c *Client) SetAutoscale(args params.SetAutoscale) error {
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	if args.Policy == nil {
+		return svc.ClearAutoscalePolicy()
+	}
+	if !svc.IsPrincipal() {
+		return fmt.Errorf("cannot autoscale subordinate service %q", args.ServiceName)
+	}
+	return svc.SetAutoscalePolicy(state.AutoscalePolicy{
+		MinUnits:           args.Policy.MinUnits,
+		MaxUnits:           args.Policy.MaxUnits,
+		Metric:             args.Policy.Metric,
+		ScaleUpThreshold:   args.Policy.ScaleUpThreshold,
+		ScaleDownThreshold: args.Policy.ScaleDownThreshold,
+		Cooldown:           args.Policy.Cooldown,
+	})
+}
+
+// ServiceMetrics returns the batches of metrics reported by the units
+// of a service.
+func (c *Client) ServiceMetrics(args params.ServiceMetrics) (params.ServiceMetricsResults, error) {
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.ServiceMetricsResults{}, err
+	}
+	batches, err := svc.MetricBatches(args.Since)
+	if err != nil {
+		return params.ServiceMetricsResults{}, err
+	}
+	results := params.ServiceMetricsResults{
+		Batches: make([]params.MetricBatch, len(batches)),
+	}
+	for i, batch := range batches {
+		metrics := batch.Metrics()
+		result := params.MetricBatch{
+			Unit:     batch.Unit(),
+			CharmURL: batch.CharmURL(),
+			Created:  batch.Created(),
+			Metrics:  make([]params.Metric, len(metrics)),
+		}
+		for j, m := range metrics {
+			result.Metrics[j] = params.Metric{Key: m.Key, Value: m.Value, Time: m.Time}
+		}
+		results.Batches[i] = result
+	}
+	return results, nil
+}
+
+// SetCharmUpgradePolicy sets or clears the charm upgrade policy of a
+// service.
+func (c *Client) SetCharmUpgradePolicy(args params.SetCharmUpgradePolicy) error {
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	if args.Policy == nil {
+		return svc.ClearCharmUpgradePolicy()
+	}
+	policy := state.CharmUpgradePolicy{
+		Mode: state.CharmUpgradeMode(args.Policy.Mode),
+	}
+	if policy.Mode != state.CharmUpgradeNever {
+		if curl, _ := svc.CharmURL(); curl.Schema != "cs" {
+			return fmt.Errorf("cannot upgrade local charm of service %q automatically", args.ServiceName)
+		}
+	}
+	return svc.SetCharmUpgradePolicy(policy)
+}
+
 // SetEnvironmentConstraints sets the constraints for the environment.
 func (c *Client) SetEnvironmentConstraints(args params.SetConstraints) error {
 	return c.api.state.SetEnvironConstraints(args.Constraints)
@@ -548,7 +672,11 @@ func (c *Client) AddRelation(args params.AddRelation) (params.AddRelationResults
 	if err != nil {
 		return params.AddRelationResults{}, err
 	}
-	rel, err := c.api.state.AddRelation(inEps...)
+	placement := state.SubordinatePlacement{
+		Units:       args.SubordinateUnits,
+		Constraints: args.SubordinateConstraints,
+	}
+	rel, err := c.api.state.AddRelationWithPlacement(placement, inEps...)
 	if err != nil {
 		return params.AddRelationResults{}, err
 	}
@@ -694,6 +822,7 @@ func (c *Client) ProvisioningScript(args params.ProvisioningScriptParams) (param
 		return result, err
 	}
 	mcfg.DisablePackageCommands = args.DisablePackageCommands
+	mcfg.InitSystem = args.InitSystem
 	result.Script, err = manual.ProvisioningScript(mcfg)
 	return result, err
 }
@@ -928,9 +1057,15 @@ func (c *Client) AddCharm(args params.CharmURL) error {
 	if charmURL.Revision < 0 {
 		return fmt.Errorf("charm URL must include revision")
 	}
+	return AddStoreCharm(c.api.state, CharmStore, charmURL)
+}
 
+// AddStoreCharm downloads the charm with the given URL from the
+// charm store, uploads it to the provider storage and records it in
+// state, unless another caller has already done so.
+func AddStoreCharm(st *state.State, store charm.Repository, charmURL *charm.URL) error {
 	// First, check if a pending or a real charm exists in state.
-	stateCharm, err := c.api.state.PrepareStoreCharmUpload(charmURL)
+	stateCharm, err := st.PrepareStoreCharmUpload(charmURL)
 	if err == nil && stateCharm.IsUploaded() {
 		// Charm already in state (it was uploaded already).
 		return nil
@@ -939,12 +1074,12 @@ func (c *Client) AddCharm(args params.CharmURL) error {
 	}
 
 	// Get the charm and its information from the store.
-	envConfig, err := c.api.state.EnvironConfig()
+	envConfig, err := st.EnvironConfig()
 	if err != nil {
 		return err
 	}
-	store := config.SpecializeCharmRepo(CharmStore, envConfig)
-	downloadedCharm, err := store.Get(charmURL)
+	repo := config.SpecializeCharmRepo(store, envConfig)
+	downloadedCharm, err := repo.Get(charmURL)
 	if err != nil {
 		return errors.Annotatef(err, "cannot download charm %q", charmURL.String())
 	}
@@ -990,7 +1125,7 @@ func (c *Client) AddCharm(args params.CharmURL) error {
 	}
 
 	// Finally, update the charm data in state and mark it as no longer pending.
-	_, err = c.api.state.UpdateUploadedCharm(downloadedCharm, charmURL, bundleURL, bundleSHA256)
+	_, err = st.UpdateUploadedCharm(downloadedCharm, charmURL, bundleURL, archiveName, bundleSHA256)
 	if err == state.ErrCharmRevisionAlreadyModified ||
 		state.IsCharmAlreadyUploadedError(err) {
 		// This is not an error, it just signifies somebody else
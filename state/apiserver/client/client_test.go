@@ -238,6 +238,102 @@ func (s *clientSuite) TestClientAddServiceUnits(c *gc.C) {
 	c.Assert(assignedMachine, gc.Equals, "0")
 }
 
+func (s *clientSuite) TestClientCloneServiceUnits(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	_, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, "0", instance.LXC)
+	c.Assert(err, gc.IsNil)
+	err = container.SetProvisioned("inst-id", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	source, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = source.AssignToMachine(container)
+	c.Assert(err, gc.IsNil)
+
+	units, err := s.APIState.Client().CloneServiceUnits("dummy", 2, "dummy/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.DeepEquals, []string{"dummy/1", "dummy/2"})
+	for i, name := range units {
+		unit, err := s.State.Unit(name)
+		c.Assert(err, gc.IsNil)
+		machineId, err := unit.AssignedMachineId()
+		c.Assert(err, gc.IsNil)
+		c.Assert(machineId, gc.Equals, fmt.Sprintf("0/lxc/%d", i+1))
+		m, err := s.State.Machine(machineId)
+		c.Assert(err, gc.IsNil)
+		c.Assert(m.CloneFrom(), gc.Equals, "0/lxc/0")
+	}
+}
+
+func (s *clientSuite) TestClientCloneServiceUnitsNotInContainer(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	source, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = source.AssignToMachine(m)
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.APIState.Client().CloneServiceUnits("dummy", 1, "dummy/0")
+	c.Assert(err, gc.ErrorMatches, `cannot clone unit "dummy/0": machine 0 is not a container`)
+
+	_, err = s.APIState.Client().CloneServiceUnits("dummy", 1, "wordpress/0")
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/0" not found`)
+}
+
+func (s *clientSuite) TestClientMigrateUnit(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	source, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(source)
+	c.Assert(err, gc.IsNil)
+
+	target, err := s.APIState.Client().MigrateUnit("dummy/0", "1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(target, gc.Equals, "dummy/1")
+	err = unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	migratingTo, ok := unit.MigratingTo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(migratingTo, gc.Equals, "dummy/1")
+
+	err = s.APIState.Client().AbortUnitMigration("dummy/0")
+	c.Assert(err, gc.IsNil)
+	err = unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	_, ok = unit.MigratingTo()
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *clientSuite) TestClientMigrateUnitErrors(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(m)
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.APIState.Client().MigrateUnit("wordpress/0", "0")
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/0" not found`)
+	_, err = s.APIState.Client().MigrateUnit("dummy/0", "lxc:0")
+	c.Assert(err, gc.ErrorMatches, `invalid machine id "lxc:0"`)
+	_, err = s.APIState.Client().MigrateUnit("dummy/0", "42")
+	c.Assert(err, gc.ErrorMatches, `machine 42 not found`)
+	_, err = s.APIState.Client().MigrateUnit("dummy/0", "0")
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "dummy/0" to machine 0: unit is already assigned to machine 0`)
+	err = s.APIState.Client().AbortUnitMigration("dummy/0")
+	c.Assert(err, gc.ErrorMatches, `cannot abort migration of unit "dummy/0": unit is not migrating`)
+}
+
 var clientCharmInfoTests = []struct {
 	about string
 	url   string
@@ -1254,6 +1350,28 @@ func (s *clientSuite) TestSuccessfullyAddRelationSwapped(c *gc.C) {
 	s.assertAddRelation(c, endpoints)
 }
 
+func (s *clientSuite) TestAddRelationWithPlacement(c *gc.C) {
+	s.setUpScenario(c)
+	cons := constraints.MustParse("tags=monitored")
+	res, err := s.APIState.Client().AddRelationWithPlacement([]string{"mysql/0"}, cons, "logging", "mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(res.Endpoints["mysql"].Name, gc.Equals, "juju-info")
+	rel, err := s.State.KeyRelation("logging:info mysql:juju-info")
+	c.Assert(err, gc.IsNil)
+	placement, ok := rel.SubordinatePlacement()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(placement, gc.DeepEquals, state.SubordinatePlacement{
+		Units:       []string{"mysql/0"},
+		Constraints: cons,
+	})
+}
+
+func (s *clientSuite) TestAddRelationWithPlacementGlobalScope(c *gc.C) {
+	s.setUpScenario(c)
+	_, err := s.APIState.Client().AddRelationWithPlacement([]string{"mysql/0"}, constraints.Value{}, "wordpress", "mysql")
+	c.Assert(err, gc.ErrorMatches, `cannot add relation "wordpress:db mysql:server": subordinate placement requires a container-scoped relation`)
+}
+
 func (s *clientSuite) TestCallWithOnlyOneEndpoint(c *gc.C) {
 	s.setUpScenario(c)
 	endpoints := []string{"wordpress"}
@@ -1392,6 +1510,207 @@ func (s *clientSuite) TestClientWatchAll(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestClientSetAutoscale(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	policy := &params.AutoscalePolicy{
+		MinUnits:           1,
+		MaxUnits:           3,
+		Metric:             "load",
+		ScaleUpThreshold:   0.8,
+		ScaleDownThreshold: 0.2,
+		Cooldown:           time.Minute,
+	}
+	err := s.APIState.Client().SetAutoscale("dummy", policy)
+	c.Assert(err, gc.IsNil)
+	stored, err := service.AutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.Equals, state.AutoscalePolicy(*policy))
+
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Services["dummy"].Autoscale, gc.DeepEquals, &api.AutoscaleStatus{Policy: *policy})
+
+	err = s.APIState.Client().SetAutoscale("dummy", nil)
+	c.Assert(err, gc.IsNil)
+	_, err = service.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *clientSuite) TestClientSetAutoscaleErrors(c *gc.C) {
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	policy := &params.AutoscalePolicy{Metric: "load", MaxUnits: 3, ScaleUpThreshold: 1}
+	err := s.APIState.Client().SetAutoscale("logging", policy)
+	c.Assert(err, gc.ErrorMatches, `cannot autoscale subordinate service "logging"`)
+	err = s.APIState.Client().SetAutoscale("unknown", policy)
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
+
+func (s *clientSuite) TestClientServiceMetrics(c *gc.C) {
+	charm := s.AddTestingCharm(c, "dummy")
+	service := s.AddTestingService(c, "dummy", charm)
+	unit, err := service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.SetCharmURL(charm.URL())
+	c.Assert(err, gc.IsNil)
+	t0 := time.Now()
+	err = unit.AddMetrics([]state.Metric{{Key: "load", Value: 0.5, Time: t0}})
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.APIState.Client().ServiceMetrics("dummy", time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit, gc.Equals, "dummy/0")
+	c.Assert(batches[0].CharmURL, gc.Equals, charm.URL().String())
+	c.Assert(batches[0].Metrics, gc.HasLen, 1)
+	c.Assert(batches[0].Metrics[0].Key, gc.Equals, "load")
+	c.Assert(batches[0].Metrics[0].Value, gc.Equals, 0.5)
+	c.Assert(batches[0].Metrics[0].Time.Unix(), gc.Equals, t0.Unix())
+
+	batches, err = s.APIState.Client().ServiceMetrics("dummy", time.Now().Add(time.Hour))
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+
+	_, err = s.APIState.Client().ServiceMetrics("unknown", time.Time{})
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
+
+func (s *clientSuite) TestClientListCharms(c *gc.C) {
+	dummy := s.AddTestingCharm(c, "dummy")
+	s.AddTestingService(c, "dummy1", dummy)
+	s.AddTestingService(c, "dummy2", dummy)
+	wordpress := s.AddTestingCharm(c, "wordpress")
+	err := s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-1"))
+	c.Assert(err, gc.IsNil)
+
+	charms, err := s.APIState.Client().ListCharms()
+	c.Assert(err, gc.IsNil)
+	c.Assert(charms, gc.DeepEquals, []params.CharmSummary{{
+		URL:      dummy.URL().String(),
+		Uploaded: true,
+		Services: []string{"dummy1", "dummy2"},
+	}, {
+		URL:      wordpress.URL().String(),
+		Uploaded: true,
+	}})
+}
+
+func (s *clientSuite) TestClientCharmsGC(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	client := s.APIState.Client()
+
+	// Add an unused charm to state and storage.
+	curl, _ := addCharm(c, store, "wordpress")
+	err := client.AddCharm(curl)
+	c.Assert(err, gc.IsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, gc.IsNil)
+	storage, err := environs.GetStorage(s.State)
+	c.Assert(err, gc.IsNil)
+	s.assertUploaded(c, storage, sch.BundleURL(), sch.BundleSha256())
+
+	// And a charm in use.
+	dummy := s.AddTestingCharm(c, "dummy")
+	s.AddTestingService(c, "dummy", dummy)
+
+	removed, err := client.CharmsGC(true)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.DeepEquals, []string{curl.String()})
+	_, err = s.State.Charm(curl)
+	c.Assert(err, gc.IsNil)
+
+	removed, err = client.CharmsGC(false)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.DeepEquals, []string{curl.String()})
+	_, err = s.State.Charm(curl)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = storage.Get(getArchiveName(sch.BundleURL()))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Charm(dummy.URL())
+	c.Assert(err, gc.IsNil)
+
+	removed, err = client.CharmsGC(false)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.HasLen, 0)
+}
+
+// unstableURLStorage returns a different URL for a file each time
+// it is asked, as providers that sign their storage URLs do.
+type unstableURLStorage struct {
+	envstorage.Storage
+	count int
+}
+
+func (stor *unstableURLStorage) URL(name string) (string, error) {
+	u, err := stor.Storage.URL(name)
+	if err != nil {
+		return "", err
+	}
+	stor.count++
+	return fmt.Sprintf("%s?signature=%d", u, stor.count), nil
+}
+
+func (s *clientSuite) TestClientCharmsGCUnstableStorageURLs(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	apiClient := s.APIState.Client()
+	curl, _ := addCharm(c, store, "wordpress")
+	err := apiClient.AddCharm(curl)
+	c.Assert(err, gc.IsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, gc.IsNil)
+	name := getArchiveName(sch.BundleURL())
+	c.Assert(sch.StoragePath(), gc.Equals, name)
+	storage, err := environs.GetStorage(s.State)
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(client.GetStorage, func(st *state.State) (envstorage.Storage, error) {
+		return &unstableURLStorage{Storage: storage}, nil
+	})
+
+	removed, err := apiClient.CharmsGC(false)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.DeepEquals, []string{curl.String()})
+	_, err = storage.Get(name)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *clientSuite) TestClientSetCharmUpgradePolicy(c *gc.C) {
+	charmDir := charmtesting.Charms.Dir("dummy")
+	curl := charm.MustParseURL("cs:quantal/dummy-1")
+	bundleURL, err := url.Parse("http://bundles.testing.invalid/dummy-1")
+	c.Assert(err, gc.IsNil)
+	ch, err := s.State.AddCharm(charmDir, curl, bundleURL, "dummy-1-sha256")
+	c.Assert(err, gc.IsNil)
+	service := s.AddTestingService(c, "dummy", ch)
+
+	policy := &params.CharmUpgradePolicy{Mode: "window"}
+	err = s.APIState.Client().SetCharmUpgradePolicy("dummy", policy)
+	c.Assert(err, gc.IsNil)
+	stored, err := service.CharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.Equals, state.CharmUpgradePolicy{Mode: state.CharmUpgradeWindow})
+
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Services["dummy"].CharmUpgrade, gc.DeepEquals, &api.CharmUpgradeStatus{Policy: *policy})
+
+	err = s.APIState.Client().SetCharmUpgradePolicy("dummy", nil)
+	c.Assert(err, gc.IsNil)
+	_, err = service.CharmUpgradePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *clientSuite) TestClientSetCharmUpgradePolicyErrors(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	policy := &params.CharmUpgradePolicy{Mode: "auto"}
+	err := s.APIState.Client().SetCharmUpgradePolicy("wordpress", policy)
+	c.Assert(err, gc.ErrorMatches, `cannot upgrade local charm of service "wordpress" automatically`)
+	err = s.APIState.Client().SetCharmUpgradePolicy("wordpress", &params.CharmUpgradePolicy{Mode: "never"})
+	c.Assert(err, gc.IsNil)
+	err = s.APIState.Client().SetCharmUpgradePolicy("unknown", policy)
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
+
 func (s *clientSuite) TestClientSetServiceConstraints(c *gc.C) {
 	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 
@@ -1955,6 +2274,32 @@ func (s *clientSuite) TestProvisioningScriptDisablePackageCommands(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestProvisioningScriptInitSystem(c *gc.C) {
+	apiParams := params.AddMachineParams{
+		Jobs:       []params.MachineJob{params.JobHostUnits},
+		InstanceId: instance.Id("1234"),
+		Nonce:      "foo",
+		HardwareCharacteristics: instance.MustParseHardware("arch=amd64"),
+	}
+	machines, err := s.APIState.Client().AddMachines([]params.AddMachineParams{apiParams})
+	c.Assert(err, gc.IsNil)
+	c.Assert(len(machines), gc.Equals, 1)
+	machineId := machines[0].Machine
+	for _, initSystem := range []string{"upstart", "systemd"} {
+		script, err := s.APIState.Client().ProvisioningScript(params.ProvisioningScriptParams{
+			MachineId:  machineId,
+			Nonce:      apiParams.Nonce,
+			InitSystem: initSystem,
+		})
+		c.Assert(err, gc.IsNil)
+		var checker gc.Checker = jc.Contains
+		if initSystem == "upstart" {
+			checker = gc.Not(checker)
+		}
+		c.Assert(script, checker, "systemctl")
+	}
+}
+
 func (s *clientSuite) TestClientSpecializeStoreOnDeployServiceSetCharmAndAddCharm(c *gc.C) {
 	store, restore := makeMockCharmStore()
 	defer restore()
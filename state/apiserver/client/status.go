@@ -415,6 +415,9 @@ func (context *statusContext) processRelations() []api.RelationStatus {
 			Scope:     scope,
 			Endpoints: eps,
 		}
+		if placement, ok := relation.SubordinatePlacement(); ok {
+			relStatus.SubordinatePlacement = placement.String()
+		}
 		out = append(out, relStatus)
 	}
 	return out
@@ -524,9 +527,81 @@ func (context *statusContext) processService(service *state.Service) (status api
 	if service.IsPrincipal() {
 		status.Units = context.processUnits(context.units[service.Name()], serviceCharmURL.String())
 	}
+	status.Autoscale, err = processAutoscale(service)
+	if err != nil {
+		status.Err = err
+	}
+	status.CharmUpgrade, err = processCharmUpgrade(service)
+	if err != nil {
+		status.Err = err
+	}
 	return status
 }
 
+// processCharmUpgrade returns the charm upgrade status of the
+// service, or nil if the service has no charm upgrade policy.
+func processCharmUpgrade(service *state.Service) (*api.CharmUpgradeStatus, error) {
+	policy, err := service.CharmUpgradePolicy()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	history, err := service.CharmUpgradeHistory()
+	if err != nil {
+		return nil, err
+	}
+	status := &api.CharmUpgradeStatus{
+		Policy: params.CharmUpgradePolicy{
+			Mode: string(policy.Mode),
+		},
+	}
+	for _, record := range history {
+		status.History = append(status.History, params.CharmUpgradeRecord{
+			Time:     record.Time,
+			From:     record.From,
+			To:       record.To,
+			Upgraded: record.Upgraded,
+			Error:    record.Error,
+		})
+	}
+	return status, nil
+}
+
+// processAutoscale returns the autoscale status of the service,
+// or nil if the service is not autoscaled.
+func processAutoscale(service *state.Service) (*api.AutoscaleStatus, error) {
+	policy, err := service.AutoscalePolicy()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	history, err := service.AutoscaleHistory()
+	if err != nil {
+		return nil, err
+	}
+	status := &api.AutoscaleStatus{
+		Policy: params.AutoscalePolicy{
+			MinUnits:           policy.MinUnits,
+			MaxUnits:           policy.MaxUnits,
+			Metric:             policy.Metric,
+			ScaleUpThreshold:   policy.ScaleUpThreshold,
+			ScaleDownThreshold: policy.ScaleDownThreshold,
+			Cooldown:           policy.Cooldown,
+		},
+	}
+	for _, decision := range history {
+		status.History = append(status.History, params.AutoscaleDecision{
+			Time:   decision.Time,
+			From:   decision.From,
+			To:     decision.To,
+			Reason: decision.Reason,
+		})
+	}
+	return status, nil
+}
+
 func (context *statusContext) processUnits(units map[string]*state.Unit, serviceCharm string) map[string]api.UnitStatus {
 	unitsMap := make(map[string]api.UnitStatus)
 	for _, unit := range units {
@@ -669,8 +744,11 @@ func processAgent(entity stateAgent) (
 func filterStatusData(status params.StatusData) params.StatusData {
 	out := make(params.StatusData)
 	for name, value := range status {
-		// use a set here if we end up with a larger whitelist
-		if name == "relation-id" {
+		switch name {
+		case "relation-id",
+			params.StatusDataProvisioningAttempt,
+			params.StatusDataProvisioningMaxAttempts,
+			params.StatusDataProvisioningNextRetry:
 			out[name] = value
 		}
 	}
@@ -5,6 +5,7 @@ package common
 
 import (
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/watcher"
@@ -74,15 +75,21 @@ func (e *EnvironWatcher) EnvironConfig() (params.EnvironConfigResult, error) {
 		return result, err
 	}
 
-	config, err := e.st.EnvironConfig()
+	envConfig, err := e.st.EnvironConfig()
 	if err != nil {
 		return result, err
 	}
-	allAttrs := config.AllAttrs()
+	allAttrs := envConfig.AllAttrs()
 
 	// TODO(dimitern) If we have multiple environments in state, use a
 	// tag argument here and as a method argument.
 	if !canReadSecrets("") {
+		// The metadata credentials are optional, so they are
+		// left out entirely rather than masked.
+		for _, k := range config.MetadataSecretAttrs {
+			delete(allAttrs, k)
+		}
+
 		// Mask out any secrets in the environment configuration
 		// with values of the same type, so it'll pass validation.
 		//
@@ -90,11 +97,11 @@ func (e *EnvironWatcher) EnvironConfig() (params.EnvironConfigResult, error) {
 		// Delete the code below and mark the bug as fixed,
 		// once it's live tested on MAAS and 1.16 compatibility
 		// is dropped.
-		env, err := environs.New(config)
+		env, err := environs.New(envConfig)
 		if err != nil {
 			return result, err
 		}
-		secretAttrs, err := env.Provider().SecretAttrs(config)
+		secretAttrs, err := env.Provider().SecretAttrs(envConfig)
 		for k := range secretAttrs {
 			allAttrs[k] = "not available"
 		}
@@ -184,6 +184,37 @@ func (*environWatcherSuite) TestEnvironConfigReadSecretsFalse(c *gc.C) {
 	c.Check(map[string]interface{}(result.Config), jc.DeepEquals, testingEnvConfig.AllAttrs())
 }
 
+func (*environWatcherSuite) TestEnvironConfigReadSecretsFalseStripsMetadataCredentials(c *gc.C) {
+	getCanReadSecrets := func() (common.AuthFunc, error) {
+		return func(tag string) bool {
+			return false
+		}, nil
+	}
+
+	testingEnvConfig, err := testingEnvConfig(c).Apply(map[string]interface{}{
+		"tools-metadata-token":    "tools-token",
+		"image-metadata-username": "admin",
+		"image-metadata-password": "image-password",
+	})
+	c.Assert(err, gc.IsNil)
+	e := common.NewEnvironWatcher(
+		&fakeEnvironAccessor{envConfig: testingEnvConfig},
+		nil,
+		nil,
+		getCanReadSecrets,
+	)
+	result, err := e.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	c.Check(result.Config["image-metadata-username"], gc.Equals, "admin")
+	for _, key := range config.MetadataSecretAttrs {
+		_, ok := result.Config[key]
+		c.Check(ok, jc.IsFalse, gc.Commentf("%s", key))
+	}
+	// The remaining configuration is still valid.
+	_, err = config.New(config.NoDefaults, map[string]interface{}(result.Config))
+	c.Assert(err, gc.IsNil)
+}
+
 func testingEnvConfig(c *gc.C) *config.Config {
 	cfg, err := config.New(config.NoDefaults, dummy.SampleConfig())
 	c.Assert(err, gc.IsNil)
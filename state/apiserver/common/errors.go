@@ -72,6 +72,9 @@ var (
 	ErrStoppedWatcher = stderrors.New("watcher has been stopped")
 	ErrBadRequest     = stderrors.New("invalid request")
 	ErrTryAgain       = stderrors.New("try again")
+
+	ErrRateLimitExceeded  = stderrors.New("request rate limit exceeded")
+	ErrTooManyConnections = stderrors.New("too many concurrent connections")
 )
 
 var singletonErrorCodes = map[error]string{
@@ -86,6 +89,8 @@ var singletonErrorCodes = map[error]string{
 	ErrUnknownWatcher:            params.CodeNotFound,
 	ErrStoppedWatcher:            params.CodeStopped,
 	ErrTryAgain:                  params.CodeTryAgain,
+	ErrRateLimitExceeded:         params.CodeRateLimitExceeded,
+	ErrTooManyConnections:        params.CodeRateLimitExceeded,
 }
 
 func singletonCode(err error) (string, bool) {
@@ -99,6 +99,14 @@ var errorTransformTests = []struct {
 	err:        common.ErrTryAgain,
 	code:       params.CodeTryAgain,
 	helperFunc: params.IsCodeTryAgain,
+}, {
+	err:        common.ErrRateLimitExceeded,
+	code:       params.CodeRateLimitExceeded,
+	helperFunc: params.IsCodeRateLimitExceeded,
+}, {
+	err:        common.ErrTooManyConnections,
+	code:       params.CodeRateLimitExceeded,
+	helperFunc: params.IsCodeRateLimitExceeded,
 }, {
 	err:  stderrors.New("an error"),
 	code: "",
@@ -112,6 +112,20 @@ func (rs *Resources) Count() int {
 	return len(rs.resources)
 }
 
+// CountMatching returns the number of resources currently held
+// for which match returns true.
+func (rs *Resources) CountMatching(match func(Resource) bool) int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	count := 0
+	for _, r := range rs.resources {
+		if match(r) {
+			count++
+		}
+	}
+	return count
+}
+
 // StringResource is just a regular 'string' that matches the Resource
 // interface.
 type StringResource string
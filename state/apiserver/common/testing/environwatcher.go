@@ -8,6 +8,7 @@ import (
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/common"
@@ -60,6 +61,9 @@ func (s *EnvironWatcherTest) AssertEnvironConfig(c *gc.C, envWatcher Environment
 		for key := range secretAttrs {
 			configAttributes[key] = "not available"
 		}
+		for _, key := range config.MetadataSecretAttrs {
+			delete(configAttributes, key)
+		}
 	}
 
 	c.Assert(result.Config, jc.DeepEquals, params.EnvironConfig(configAttributes))
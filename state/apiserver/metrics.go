@@ -0,0 +1,269 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+// latencyBuckets holds the upper bounds, in seconds, of the
+// request latency histogram buckets.
+var latencyBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// methodKey identifies an API method for the purposes of metrics.
+type methodKey struct {
+	facade  string
+	version int
+	method  string
+}
+
+// unknownMethod is the key under which requests for methods that the
+// API server does not implement are recorded. Clients can send any
+// facade and method names they like, so recording those names would
+// let them grow the set of metrics without bound.
+var unknownMethod = methodKey{facade: "unknown", method: "unknown"}
+
+// requestKey returns the key under which the given request is
+// recorded.
+func requestKey(req rpc.Request) methodKey {
+	if !isKnownMethod(req) {
+		return unknownMethod
+	}
+	return methodKey{req.Type, req.Version, req.Action}
+}
+
+// isKnownMethod reports whether the given request names a method
+// implemented by the API server, either on the initial root or on
+// one of the registered facades.
+func isKnownMethod(req rpc.Request) bool {
+	if req.Version == 0 {
+		rootMethod, err := rpcreflect.TypeOf(reflect.TypeOf(&initialRoot{})).Method(req.Type)
+		if err == nil {
+			_, err := rootMethod.ObjType.Method(req.Action)
+			return err == nil
+		}
+	}
+	goType, err := common.Facades.GetType(req.Type, req.Version)
+	if err != nil {
+		return false
+	}
+	_, err = rpcreflect.ObjTypeOf(goType).Method(req.Action)
+	return err == nil
+}
+
+// methodStats holds the metrics collected for a single API method.
+type methodStats struct {
+	requests uint64
+	errors   uint64
+	// buckets holds the number of requests whose latency was at
+	// most the corresponding entry in latencyBuckets; the final
+	// entry counts all requests.
+	buckets []uint64
+	total   time.Duration
+}
+
+// apiMetrics collects metrics about the requests served by the
+// API server.
+type apiMetrics struct {
+	mu               sync.Mutex
+	methods          map[methodKey]*methodStats
+	connectionsTotal uint64
+	connections      int
+	resources        map[*common.Resources]bool
+}
+
+func newAPIMetrics() *apiMetrics {
+	return &apiMetrics{
+		methods:   make(map[methodKey]*methodStats),
+		resources: make(map[*common.Resources]bool),
+	}
+}
+
+// connectionOpened records a new API connection.
+func (m *apiMetrics) connectionOpened() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connectionsTotal++
+	m.connections++
+}
+
+// connectionClosed records the end of an API connection.
+func (m *apiMetrics) connectionClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connections--
+}
+
+// addResources records the resources of a logged in connection
+// so that its open watchers can be counted.
+func (m *apiMetrics) addResources(rs *common.Resources) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resources[rs] = true
+}
+
+// removeResources reverses addResources.
+func (m *apiMetrics) removeResources(rs *common.Resources) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.resources, rs)
+}
+
+// requestDone records a reply to the given request.
+func (m *apiMetrics) requestDone(req rpc.Request, failed bool, timeSpent time.Duration) {
+	key := requestKey(req)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.methods[key]
+	if stats == nil {
+		stats = &methodStats{
+			buckets: make([]uint64, len(latencyBuckets)+1),
+		}
+		m.methods[key] = stats
+	}
+	stats.requests++
+	if failed {
+		stats.errors++
+	}
+	stats.total += timeSpent
+	seconds := timeSpent.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+	stats.buckets[len(latencyBuckets)]++
+}
+
+// isWatcher reports whether the given resource is a watcher.
+// Other resources, such as pingers, may have the same methods as
+// watchers apart from Changes, so the watcher interfaces are
+// matched exactly.
+func isWatcher(r common.Resource) bool {
+	switch r.(type) {
+	case *multiwatcher.Watcher,
+		state.NotifyWatcher,
+		state.StringsWatcher,
+		state.RelationUnitsWatcher:
+		return true
+	}
+	return false
+}
+
+// writeTo writes the metrics to w in the Prometheus text
+// exposition format.
+func (m *apiMetrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	watchers := 0
+	for rs := range m.resources {
+		watchers += rs.CountMatching(isWatcher)
+	}
+	fmt.Fprintf(w, "# HELP juju_apiserver_connections_total Total number of API connections accepted.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_connections_total counter\n")
+	fmt.Fprintf(w, "juju_apiserver_connections_total %d\n", m.connectionsTotal)
+	fmt.Fprintf(w, "# HELP juju_apiserver_connections Number of active API connections.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_connections gauge\n")
+	fmt.Fprintf(w, "juju_apiserver_connections %d\n", m.connections)
+	fmt.Fprintf(w, "# HELP juju_apiserver_watchers Number of open watchers.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_watchers gauge\n")
+	fmt.Fprintf(w, "juju_apiserver_watchers %d\n", watchers)
+
+	keys := make([]methodKey, 0, len(m.methods))
+	for key := range m.methods {
+		keys = append(keys, key)
+	}
+	sort.Sort(methodKeys(keys))
+
+	fmt.Fprintf(w, "# HELP juju_apiserver_requests_total Total number of API requests served.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_requests_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(w, "juju_apiserver_requests_total{%s} %d\n", key.labels(), m.methods[key].requests)
+	}
+	fmt.Fprintf(w, "# HELP juju_apiserver_request_errors_total Total number of API requests that returned an error.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_request_errors_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(w, "juju_apiserver_request_errors_total{%s} %d\n", key.labels(), m.methods[key].errors)
+	}
+	fmt.Fprintf(w, "# HELP juju_apiserver_request_duration_seconds API request latency.\n")
+	fmt.Fprintf(w, "# TYPE juju_apiserver_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		stats := m.methods[key]
+		labels := key.labels()
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "juju_apiserver_request_duration_seconds_bucket{%s,le=%q} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), stats.buckets[i])
+		}
+		fmt.Fprintf(w, "juju_apiserver_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n",
+			labels, stats.buckets[len(latencyBuckets)])
+		fmt.Fprintf(w, "juju_apiserver_request_duration_seconds_sum{%s} %g\n", labels, stats.total.Seconds())
+		fmt.Fprintf(w, "juju_apiserver_request_duration_seconds_count{%s} %d\n", labels, stats.requests)
+	}
+}
+
+func (key methodKey) labels() string {
+	return fmt.Sprintf("facade=%q,version=\"%d\",method=%q", key.facade, key.version, key.method)
+}
+
+type methodKeys []methodKey
+
+func (k methodKeys) Len() int      { return len(k) }
+func (k methodKeys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k methodKeys) Less(i, j int) bool {
+	if k[i].facade != k[j].facade {
+		return k[i].facade < k[j].facade
+	}
+	if k[i].version != k[j].version {
+		return k[i].version < k[j].version
+	}
+	return k[i].method < k[j].method
+}
+
+// metricsHandler serves the API server metrics in the
+// Prometheus text exposition format.
+type metricsHandler struct {
+	httpHandler
+	metrics *apiMetrics
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.authenticate(r); err != nil {
+		h.authError(w, h)
+		return
+	}
+	if err := h.validateEnvironUUID(r); err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	switch r.Method {
+	case "GET":
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		h.metrics.writeTo(w)
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
+	}
+}
+
+// sendError sends a plain text error response.
+func (h *metricsHandler) sendError(w http.ResponseWriter, statusCode int, message string) error {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(statusCode)
+	_, err := io.WriteString(w, message+"\n")
+	return err
+}
@@ -0,0 +1,128 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/testing"
+)
+
+type metricsInternalSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&metricsInternalSuite{})
+
+type fakeWatcher struct {
+	fakePinger
+}
+
+func (fakeWatcher) Kill()                    {}
+func (fakeWatcher) Wait() error              { return nil }
+func (fakeWatcher) Changes() <-chan struct{} { return nil }
+
+// fakePinger has some of the methods of a watcher, but is not one.
+type fakePinger struct{}
+
+func (fakePinger) Stop() error { return nil }
+func (fakePinger) Err() error  { return errors.New("not implemented") }
+
+func (s *metricsInternalSuite) TestWriteTo(c *gc.C) {
+	m := newAPIMetrics()
+	m.connectionOpened()
+	m.connectionOpened()
+	m.connectionClosed()
+
+	resources := common.NewResources()
+	resources.RegisterNamed("dataDir", common.StringResource("/var/lib/juju"))
+	resources.Register(fakeWatcher{})
+	resources.Register(fakeWatcher{})
+	resources.Register(fakePinger{})
+	m.addResources(resources)
+
+	status := rpc.Request{Type: "Client", Version: 0, Action: "FullStatus"}
+	m.requestDone(status, false, 3*time.Millisecond)
+	m.requestDone(status, true, 2*time.Second)
+	m.requestDone(rpc.Request{Type: "Admin", Version: 0, Action: "Login"}, false, 20*time.Millisecond)
+
+	var buf bytes.Buffer
+	m.writeTo(&buf)
+	c.Assert(buf.String(), gc.Equals, `# HELP juju_apiserver_connections_total Total number of API connections accepted.
+# TYPE juju_apiserver_connections_total counter
+juju_apiserver_connections_total 2
+# HELP juju_apiserver_connections Number of active API connections.
+# TYPE juju_apiserver_connections gauge
+juju_apiserver_connections 1
+# HELP juju_apiserver_watchers Number of open watchers.
+# TYPE juju_apiserver_watchers gauge
+juju_apiserver_watchers 2
+# HELP juju_apiserver_requests_total Total number of API requests served.
+# TYPE juju_apiserver_requests_total counter
+juju_apiserver_requests_total{facade="Admin",version="0",method="Login"} 1
+juju_apiserver_requests_total{facade="Client",version="0",method="FullStatus"} 2
+# HELP juju_apiserver_request_errors_total Total number of API requests that returned an error.
+# TYPE juju_apiserver_request_errors_total counter
+juju_apiserver_request_errors_total{facade="Admin",version="0",method="Login"} 0
+juju_apiserver_request_errors_total{facade="Client",version="0",method="FullStatus"} 1
+# HELP juju_apiserver_request_duration_seconds API request latency.
+# TYPE juju_apiserver_request_duration_seconds histogram
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.005"} 0
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.01"} 0
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.025"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.05"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.1"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.25"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="0.5"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="1"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="2.5"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="5"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="10"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Admin",version="0",method="Login",le="+Inf"} 1
+juju_apiserver_request_duration_seconds_sum{facade="Admin",version="0",method="Login"} 0.02
+juju_apiserver_request_duration_seconds_count{facade="Admin",version="0",method="Login"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.005"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.01"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.025"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.05"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.1"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.25"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="0.5"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="1"} 1
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="2.5"} 2
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="5"} 2
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="10"} 2
+juju_apiserver_request_duration_seconds_bucket{facade="Client",version="0",method="FullStatus",le="+Inf"} 2
+juju_apiserver_request_duration_seconds_sum{facade="Client",version="0",method="FullStatus"} 2.003
+juju_apiserver_request_duration_seconds_count{facade="Client",version="0",method="FullStatus"} 2
+`)
+
+	m.removeResources(resources)
+	buf.Reset()
+	m.writeTo(&buf)
+	c.Assert(buf.String(), gc.Matches, "(?s).*\njuju_apiserver_watchers 0\n.*")
+}
+
+func (s *metricsInternalSuite) TestUnknownMethodsShareLabels(c *gc.C) {
+	m := newAPIMetrics()
+	m.requestDone(rpc.Request{Type: "NoSuchFacade", Version: 0, Action: "Foo"}, true, time.Millisecond)
+	m.requestDone(rpc.Request{Type: "Client", Version: 99, Action: "FullStatus"}, true, time.Millisecond)
+	m.requestDone(rpc.Request{Type: "Client", Version: 0, Action: "NoSuchMethod"}, true, time.Millisecond)
+	m.requestDone(rpc.Request{Type: "Admin", Version: 0, Action: "Bar"}, true, time.Millisecond)
+
+	var buf bytes.Buffer
+	m.writeTo(&buf)
+	c.Assert(buf.String(), gc.Matches, `(?s).*
+juju_apiserver_requests_total{facade="unknown",version="0",method="unknown"} 4
+# HELP juju_apiserver_request_errors_total .*`)
+	c.Assert(buf.String(), gc.Not(gc.Matches), `(?s).*(NoSuch|Bar|FullStatus).*`)
+}
@@ -0,0 +1,88 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"fmt"
+	"net/http"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type metricsSuite struct {
+	authHttpSuite
+}
+
+var _ = gc.Suite(&metricsSuite{})
+
+func (s *metricsSuite) metricsURL(c *gc.C) string {
+	uri := s.baseURL(c)
+	uri.Path += "/metrics"
+	return uri.String()
+}
+
+func (s *metricsSuite) TestRequiresAuth(c *gc.C) {
+	resp, err := s.sendRequest(c, "", "", "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, gc.IsNil)
+	body := assertResponse(c, resp, http.StatusUnauthorized, "text/plain")
+	c.Assert(string(body), gc.Equals, "unauthorized\n")
+}
+
+func (s *metricsSuite) TestAuthRequiresUser(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetProvisioned("foo", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	password, err := utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = machine.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+
+	resp, err := s.sendRequest(c, machine.Tag().String(), password, "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, gc.IsNil)
+	assertResponse(c, resp, http.StatusUnauthorized, "text/plain")
+}
+
+func (s *metricsSuite) TestRequiresGET(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.metricsURL(c), "", nil)
+	c.Assert(err, gc.IsNil)
+	body := assertResponse(c, resp, http.StatusMethodNotAllowed, "text/plain")
+	c.Assert(string(body), gc.Equals, `unsupported method: "POST"`+"\n")
+}
+
+func (s *metricsSuite) TestRejectsWrongEnvUUIDPath(c *gc.C) {
+	uri := s.baseURL(c)
+	uri.Path = "/environment/dead-beef-123456/metrics"
+	resp, err := s.authRequest(c, "GET", uri.String(), "", nil)
+	c.Assert(err, gc.IsNil)
+	body := assertResponse(c, resp, http.StatusNotFound, "text/plain")
+	c.Assert(string(body), gc.Equals, `unknown environment: "dead-beef-123456"`+"\n")
+}
+
+func (s *metricsSuite) TestMetrics(c *gc.C) {
+	_, err := s.APIState.Client().EnvironmentGet()
+	c.Assert(err, gc.IsNil)
+	w, err := s.APIState.Client().WatchAll()
+	c.Assert(err, gc.IsNil)
+	defer w.Stop()
+
+	environ, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	uri := s.baseURL(c)
+	uri.Path = fmt.Sprintf("/environment/%s/metrics", environ.UUID())
+	resp, err := s.authRequest(c, "GET", uri.String(), "", nil)
+	c.Assert(err, gc.IsNil)
+	body := string(assertResponse(c, resp, http.StatusOK, "text/plain; version=0.0.4"))
+
+	c.Check(body, jc.Contains, "# TYPE juju_apiserver_requests_total counter\n")
+	c.Check(body, gc.Matches, `(?s).*\njuju_apiserver_connections [1-9][0-9]*\n.*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_apiserver_watchers [1-9][0-9]*\n.*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_apiserver_requests_total\{facade="Client",version="0",method="EnvironmentGet"\} 1\n.*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_apiserver_request_errors_total\{facade="Client",version="0",method="EnvironmentGet"\} 0\n.*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_apiserver_request_duration_seconds_count\{facade="Client",version="0",method="EnvironmentGet"\} 1\n.*`)
+}
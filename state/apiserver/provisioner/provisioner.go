@@ -327,18 +327,28 @@ func (p *ProvisionerAPI) ProvisioningInfo(args params.Entities) (params.Provisio
 	for i, entity := range args.Entities {
 		machine, err := p.getMachine(canAccess, entity.Tag)
 		if err == nil {
-			result.Results[i].Result, err = getProvisioningInfo(machine)
+			result.Results[i].Result, err = getProvisioningInfo(p.st, machine)
 		}
 		result.Results[i].Error = common.ServerError(err)
 	}
 	return result, nil
 }
 
-func getProvisioningInfo(m *state.Machine) (*params.ProvisioningInfo, error) {
+func getProvisioningInfo(st *state.State, m *state.Machine) (*params.ProvisioningInfo, error) {
 	cons, err := m.Constraints()
 	if err != nil {
 		return nil, err
 	}
+	var cloneFrom instance.Id
+	if sourceId := m.CloneFrom(); sourceId != "" {
+		source, err := st.Machine(sourceId)
+		if err != nil {
+			return nil, err
+		}
+		if cloneFrom, err = source.InstanceId(); err != nil {
+			return nil, err
+		}
+	}
 	// TODO(dimitern) For now, since network names and
 	// provider ids are the same, we return what we got
 	// from state. In the future, when networks can be
@@ -354,6 +364,7 @@ func getProvisioningInfo(m *state.Machine) (*params.ProvisioningInfo, error) {
 		Series:      m.Series(),
 		Placement:   m.Placement(),
 		Networks:    networks,
+		CloneFrom:   cloneFrom,
 	}, nil
 }
 
@@ -768,6 +768,29 @@ func (s *withoutStateServerSuite) TestProvisioningInfo(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestProvisioningInfoClonedContainer(c *gc.C) {
+	host, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	source, err := s.State.AddMachineInsideMachine(template, host.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+	err = source.SetProvisioned("juju-machine-5-lxc-0", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	template.CloneFrom = source.Id()
+	clone, err := s.State.AddMachineInsideMachine(template, host.Id(), instance.LXC)
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{{Tag: clone.Tag().String()}}}
+	result, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[0].Result.CloneFrom, gc.Equals, instance.Id("juju-machine-5-lxc-0"))
+}
+
 func (s *withoutStateServerSuite) TestProvisioningInfoPermissions(c *gc.C) {
 	// Login as a machine agent for machine 0.
 	anAuthorizer := s.authorizer
@@ -0,0 +1,198 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/juju/names"
+	"github.com/juju/ratelimit"
+
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// EntityLimits holds the API request limits applied to
+// each entity of a given kind.
+type EntityLimits struct {
+	// RequestRate holds the sustained number of requests per
+	// second allowed for a single entity. Zero means unlimited.
+	RequestRate float64
+
+	// RequestBurst holds the number of requests an entity may make
+	// in a burst before being held to RequestRate. If zero, it
+	// defaults to RequestRate rounded up.
+	RequestBurst int64
+
+	// MaxConnections holds the maximum number of concurrent API
+	// connections allowed for a single entity. Zero means unlimited.
+	MaxConnections int
+}
+
+// RateLimitConfig holds the limits applied to API clients.
+// The zero value imposes no limits.
+type RateLimitConfig struct {
+	// Users holds the limits applied to each user.
+	Users EntityLimits
+
+	// Agents holds the limits applied to each machine and unit
+	// agent. Agents are exempt if it is left as the zero value.
+	Agents EntityLimits
+}
+
+// sweepInterval holds how often idle entities are looked for.
+const sweepInterval = time.Minute
+
+// entityLimiter holds the limiting state for a single entity.
+type entityLimiter struct {
+	connections int
+	bucket      *ratelimit.Bucket
+
+	// refillTime holds how long the bucket takes to refill
+	// completely, and full holds the time by which it will have
+	// done so since it was last taken from.
+	refillTime time.Duration
+	full       time.Time
+}
+
+// idle reports whether the entity's state is the same as that of an
+// entity that has never connected, so that it can be discarded.
+func (e *entityLimiter) idle(now time.Time) bool {
+	return e.connections == 0 && !now.Before(e.full)
+}
+
+// rateLimiter enforces a RateLimitConfig.
+type rateLimiter struct {
+	config RateLimitConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entities  map[string]*entityLimiter
+	lastSweep time.Time
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:   config,
+		now:      time.Now,
+		entities: make(map[string]*entityLimiter),
+	}
+}
+
+// limits returns the limits that apply to the entity with the given tag.
+func (l *rateLimiter) limits(tag string) EntityLimits {
+	if kind, err := names.TagKind(tag); err == nil && kind == names.UserTagKind {
+		return l.config.Users
+	}
+	return l.config.Agents
+}
+
+// entity returns the limiting state for the entity with the given
+// tag, creating it if necessary. The caller must hold l.mu.
+func (l *rateLimiter) entity(tag string, limits EntityLimits) *entityLimiter {
+	e := l.entities[tag]
+	if e == nil {
+		l.sweep()
+		e = &entityLimiter{}
+		if limits.RequestRate > 0 {
+			burst := limits.RequestBurst
+			if burst <= 0 {
+				burst = int64(math.Ceil(limits.RequestRate))
+			}
+			fillInterval := time.Duration(float64(time.Second) / limits.RequestRate)
+			e.bucket = ratelimit.NewBucket(fillInterval, burst)
+			e.refillTime = fillInterval * time.Duration(burst)
+		}
+		l.entities[tag] = e
+	}
+	return e
+}
+
+// sweep discards the state of idle entities, at most once every
+// sweepInterval. Entities are otherwise only discarded when their
+// last connection is released, and entities whose requests are
+// limited but whose connections are not are never released. The
+// caller must hold l.mu.
+func (l *rateLimiter) sweep() {
+	now := l.now()
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for tag, e := range l.entities {
+		if e.idle(now) {
+			delete(l.entities, tag)
+		}
+	}
+}
+
+// connect records a new connection by the entity with the given tag.
+// It returns a resource that releases the connection when stopped,
+// or common.ErrTooManyConnections if the entity is already at its
+// connection limit.
+func (l *rateLimiter) connect(tag string) (common.Resource, error) {
+	limits := l.limits(tag)
+	if limits.MaxConnections <= 0 {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entity(tag, limits)
+	if e.connections >= limits.MaxConnections {
+		logger.Debugf("%s has too many API connections", tag)
+		return nil, common.ErrTooManyConnections
+	}
+	e.connections++
+	return &connectionQuota{limiter: l, tag: tag}, nil
+}
+
+// release reverses a successful call to connect.
+func (l *rateLimiter) release(tag string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entities[tag]
+	if e == nil || e.connections == 0 {
+		return
+	}
+	e.connections--
+	if e.idle(l.now()) {
+		delete(l.entities, tag)
+	}
+}
+
+// allowRequest returns common.ErrRateLimitExceeded if the entity
+// with the given tag has exceeded its request rate, and nil
+// otherwise.
+func (l *rateLimiter) allowRequest(tag string) error {
+	limits := l.limits(tag)
+	if limits.RequestRate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entity(tag, limits)
+	e.full = l.now().Add(e.refillTime)
+	if e.bucket.TakeAvailable(1) == 0 {
+		logger.Debugf("%s exceeded its API request rate", tag)
+		return common.ErrRateLimitExceeded
+	}
+	return nil
+}
+
+// connectionQuota is a resource that holds one of an entity's
+// allowed connections until it is stopped.
+type connectionQuota struct {
+	limiter *rateLimiter
+	tag     string
+	once    sync.Once
+}
+
+// Stop implements common.Resource.
+func (q *connectionQuota) Stop() error {
+	q.once.Do(func() {
+		q.limiter.release(q.tag)
+	})
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/testing"
+)
+
+type rateLimitInternalSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&rateLimitInternalSuite{})
+
+func (s *rateLimitInternalSuite) TestNoLimits(c *gc.C) {
+	l := newRateLimiter(RateLimitConfig{})
+	for i := 0; i < 100; i++ {
+		c.Assert(l.allowRequest("user-bob"), gc.IsNil)
+		quota, err := l.connect("user-bob")
+		c.Assert(err, gc.IsNil)
+		c.Assert(quota, gc.IsNil)
+	}
+}
+
+func (s *rateLimitInternalSuite) TestRequestBurstDefaultsToRate(c *gc.C) {
+	l := newRateLimiter(RateLimitConfig{
+		Users: EntityLimits{RequestRate: 0.5},
+	})
+	c.Assert(l.allowRequest("user-bob"), gc.IsNil)
+	c.Assert(l.allowRequest("user-bob"), gc.Equals, common.ErrRateLimitExceeded)
+	// Other users have their own allowance.
+	c.Assert(l.allowRequest("user-alice"), gc.IsNil)
+}
+
+func (s *rateLimitInternalSuite) TestAgentsConfiguredSeparately(c *gc.C) {
+	l := newRateLimiter(RateLimitConfig{
+		Users:  EntityLimits{RequestRate: 0.001, RequestBurst: 1},
+		Agents: EntityLimits{RequestRate: 0.001, RequestBurst: 3},
+	})
+	for i := 0; i < 3; i++ {
+		c.Assert(l.allowRequest("machine-0"), gc.IsNil)
+	}
+	c.Assert(l.allowRequest("machine-0"), gc.Equals, common.ErrRateLimitExceeded)
+	c.Assert(l.allowRequest("unit-wordpress-0"), gc.IsNil)
+	c.Assert(l.allowRequest("user-bob"), gc.IsNil)
+	c.Assert(l.allowRequest("user-bob"), gc.Equals, common.ErrRateLimitExceeded)
+}
+
+func (s *rateLimitInternalSuite) TestMaxConnections(c *gc.C) {
+	l := newRateLimiter(RateLimitConfig{
+		Users: EntityLimits{MaxConnections: 2},
+	})
+	q1, err := l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	_, err = l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	_, err = l.connect("user-bob")
+	c.Assert(err, gc.Equals, common.ErrTooManyConnections)
+
+	// Agents are exempt.
+	quota, err := l.connect("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(quota, gc.IsNil)
+
+	// Stopping a quota more than once only releases it once.
+	c.Assert(q1.Stop(), gc.IsNil)
+	c.Assert(q1.Stop(), gc.IsNil)
+	_, err = l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	_, err = l.connect("user-bob")
+	c.Assert(err, gc.Equals, common.ErrTooManyConnections)
+}
+
+func (s *rateLimitInternalSuite) TestReleaseDiscardsIdleEntity(c *gc.C) {
+	now := time.Now()
+	l := newRateLimiter(RateLimitConfig{
+		Users: EntityLimits{RequestRate: 1, RequestBurst: 2, MaxConnections: 2},
+	})
+	l.now = func() time.Time { return now }
+	q1, err := l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	q2, err := l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	c.Assert(l.allowRequest("user-bob"), gc.IsNil)
+
+	c.Assert(q1.Stop(), gc.IsNil)
+	c.Assert(l.entities, gc.HasLen, 1)
+	// The bucket has not refilled yet, so its state is kept.
+	c.Assert(q2.Stop(), gc.IsNil)
+	c.Assert(l.entities, gc.HasLen, 1)
+
+	q3, err := l.connect("user-bob")
+	c.Assert(err, gc.IsNil)
+	now = now.Add(2 * time.Second)
+	c.Assert(q3.Stop(), gc.IsNil)
+	c.Assert(l.entities, gc.HasLen, 0)
+}
+
+func (s *rateLimitInternalSuite) TestSweepDiscardsIdleEntities(c *gc.C) {
+	now := time.Now()
+	l := newRateLimiter(RateLimitConfig{
+		Users: EntityLimits{RequestRate: 1},
+	})
+	l.now = func() time.Time { return now }
+	c.Assert(l.allowRequest("user-bob"), gc.IsNil)
+	c.Assert(l.allowRequest("user-alice"), gc.IsNil)
+	c.Assert(l.entities, gc.HasLen, 2)
+
+	// Entities are swept when new ones are added.
+	now = now.Add(sweepInterval)
+	c.Assert(l.allowRequest("user-carol"), gc.IsNil)
+	c.Assert(l.entities, gc.HasLen, 1)
+	c.Assert(l.entities["user-carol"], gc.NotNil)
+}
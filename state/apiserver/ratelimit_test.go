@@ -0,0 +1,126 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"net"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type rateLimitSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&rateLimitSuite{})
+
+func (s *rateLimitSuite) startServer(c *gc.C, limits apiserver.RateLimitConfig) *apiserver.Server {
+	listener, err := net.Listen("tcp", ":0")
+	c.Assert(err, gc.IsNil)
+	srv, err := apiserver.NewServer(s.State, listener, apiserver.ServerConfig{
+		Cert:       []byte(coretesting.ServerCert),
+		Key:        []byte(coretesting.ServerKey),
+		RateLimits: limits,
+	})
+	c.Assert(err, gc.IsNil)
+	return srv
+}
+
+func (s *rateLimitSuite) openAsUser(c *gc.C, srv *apiserver.Server) (*api.State, error) {
+	info := s.APIInfo(c)
+	info.Addrs = []string{srv.Addr()}
+	return api.Open(info, fastDialOpts)
+}
+
+func (s *rateLimitSuite) TestUserRequestRate(c *gc.C) {
+	srv := s.startServer(c, apiserver.RateLimitConfig{
+		Users: apiserver.EntityLimits{RequestRate: 0.001, RequestBurst: 2},
+	})
+	defer srv.Stop()
+
+	st, err := s.openAsUser(c, srv)
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = st.Client().EnvironmentGet()
+		c.Assert(err, gc.IsNil)
+	}
+	_, err = st.Client().EnvironmentGet()
+	c.Assert(err, gc.ErrorMatches, "request rate limit exceeded")
+	c.Assert(err, jc.Satisfies, params.IsCodeRateLimitExceeded)
+
+	// Pings are never limited.
+	err = st.Ping()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *rateLimitSuite) TestUserMaxConnections(c *gc.C) {
+	srv := s.startServer(c, apiserver.RateLimitConfig{
+		Users: apiserver.EntityLimits{MaxConnections: 1},
+	})
+	defer srv.Stop()
+
+	st, err := s.openAsUser(c, srv)
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.openAsUser(c, srv)
+	c.Assert(err, gc.ErrorMatches, "too many concurrent connections")
+	c.Assert(err, jc.Satisfies, params.IsCodeRateLimitExceeded)
+
+	// Closing the first connection releases its quota.
+	err = st.Close()
+	c.Assert(err, gc.IsNil)
+	var st2 *api.State
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		st2, err = s.openAsUser(c, srv)
+		if err == nil {
+			break
+		}
+	}
+	c.Assert(err, gc.IsNil)
+	st2.Close()
+}
+
+func (s *rateLimitSuite) TestAgentsExemptByDefault(c *gc.C) {
+	srv := s.startServer(c, apiserver.RateLimitConfig{
+		Users: apiserver.EntityLimits{RequestRate: 0.001, RequestBurst: 1, MaxConnections: 1},
+	})
+	defer srv.Stop()
+
+	stm, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = stm.SetProvisioned("foo", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	password, err := utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = stm.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+
+	for i := 0; i < 2; i++ {
+		st, err := api.Open(&api.Info{
+			Tag:      stm.Tag(),
+			Password: password,
+			Nonce:    "fake_nonce",
+			Addrs:    []string{srv.Addr()},
+			CACert:   coretesting.CACert,
+		}, fastDialOpts)
+		c.Assert(err, gc.IsNil)
+		defer st.Close()
+		for j := 0; j < 3; j++ {
+			_, err = st.Machiner().Machine(names.NewMachineTag(stm.Id()))
+			c.Assert(err, gc.IsNil)
+		}
+	}
+}
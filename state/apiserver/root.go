@@ -49,6 +49,8 @@ type srvRoot struct {
 	state       *state.State
 	rpcConn     *rpc.Conn
 	resources   *common.Resources
+	metrics     *apiMetrics
+	quotas      *rateLimiter
 	entity      taggedAuthenticator
 	objectMutex sync.RWMutex
 	objectCache map[objectKey]reflect.Value
@@ -64,6 +66,8 @@ func newSrvRoot(root *initialRoot, entity taggedAuthenticator) *srvRoot {
 		state:       root.srv.state,
 		rpcConn:     root.rpcConn,
 		resources:   common.NewResources(),
+		metrics:     root.srv.metrics,
+		quotas:      root.srv.quotas,
 		entity:      entity,
 		objectCache: make(map[objectKey]reflect.Value),
 	}
@@ -83,6 +87,9 @@ func (r *srvRoot) getRpcConn() *rpc.Conn {
 // cleaning up to ensure that all outstanding requests return.
 func (r *srvRoot) Kill() {
 	r.resources.StopAll()
+	if r.metrics != nil {
+		r.metrics.removeResources(r.resources)
+	}
 }
 
 // srvCaller is our implementation of the rpcreflect.MethodCaller interface.
@@ -128,6 +135,13 @@ func (r *srvRoot) FindMethod(rootName string, version int, methodName string) (r
 	if err != nil {
 		return nil, err
 	}
+	// Pings are never rate limited, so that a throttled
+	// client does not have its connection dropped.
+	if r.quotas != nil && rootName != "Pinger" {
+		if err := r.quotas.allowRequest(r.entity.Tag().String()); err != nil {
+			return nil, err
+		}
+	}
 
 	creator := func(id string) (reflect.Value, error) {
 		objKey := objectKey{name: rootName, version: version, objId: id}
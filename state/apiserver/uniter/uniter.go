@@ -414,6 +414,128 @@ func (u *UniterAPI) ClosePort(args params.EntitiesPorts) (params.ErrorResults, e
 	return result, nil
 }
 
+// AddMetrics records the metrics reported by each given unit.
+func (u *UniterAPI) AddMetrics(args params.EntitiesMetrics) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				metrics := make([]state.Metric, len(entity.Metrics))
+				for j, m := range entity.Metrics {
+					metrics[j] = state.Metric{Key: m.Key, Value: m.Value, Time: m.Time}
+				}
+				err = unit.AddMetrics(metrics)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// unitMigration returns the migration the given unit is taking part in.
+func (u *UniterAPI) unitMigration(unit *state.Unit) (params.UnitMigration, error) {
+	var result params.UnitMigration
+	var remote string
+	if target, ok := unit.MigratingTo(); ok {
+		result.Source, result.Target = unit.Name(), target
+		remote = target
+	} else if source, ok := unit.MigratingFrom(); ok {
+		result.Source, result.Target = source, unit.Name()
+		remote = source
+	} else {
+		return result, nil
+	}
+	result.Phase = params.MigrationPhase(unit.MigrationPhase())
+	remoteUnit, err := u.st.Unit(remote)
+	if errors.IsNotFound(err) {
+		return result, nil
+	} else if err != nil {
+		return params.UnitMigration{}, err
+	}
+	result.RemoteAddress, _ = remoteUnit.PrivateAddress()
+	return result, nil
+}
+
+// Migration returns the migration each given unit is taking part in.
+func (u *UniterAPI) Migration(args params.Entities) (params.UnitMigrationResults, error) {
+	result := params.UnitMigrationResults{
+		Results: make([]params.UnitMigrationResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UnitMigrationResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Result, err = u.unitMigration(unit)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetMigrationPhase advances the migration each given unit is taking
+// part in to the given phase.
+func (u *UniterAPI) SetMigrationPhase(args params.EntitiesMigrationPhase) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.SetMigrationPhase(state.MigrationPhase(entity.Phase))
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// CompleteMigration hands over to each given unit from the source unit
+// of the migration it is the target of.
+func (u *UniterAPI) CompleteMigration(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.CompleteMigration()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 func (u *UniterAPI) watchOneUnitConfigSettings(tag string) (string, error) {
 	unit, err := u.getUnit(tag)
 	if err != nil {
@@ -5,6 +5,7 @@ package uniter_test
 
 import (
 	stdtesting "testing"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
@@ -436,6 +437,80 @@ func (s *uniterSuite) TestClearResolved(c *gc.C) {
 	c.Assert(mode, gc.Equals, state.ResolvedNone)
 }
 
+func (s *uniterSuite) TestMigration(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.Migration(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.UnitMigrationResults{
+		Results: []params.UnitMigrationResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: params.UnitMigration{}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.machine1.SetAddresses(network.NewAddress("1.2.3.4", network.ScopeCloudLocal))
+	c.Assert(err, gc.IsNil)
+	_, err = s.wordpressUnit.MigrateTo(s.machine1)
+	c.Assert(err, gc.IsNil)
+	result, err = s.uniter.Migration(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results[1], gc.DeepEquals, params.UnitMigrationResult{
+		Result: params.UnitMigration{
+			Source:        "wordpress/0",
+			Target:        "wordpress/1",
+			Phase:         params.MigrationProvisioning,
+			RemoteAddress: "1.2.3.4",
+		},
+	})
+}
+
+func (s *uniterSuite) TestSetMigrationPhase(c *gc.C) {
+	target, err := s.wordpressUnit.MigrateTo(s.machine1)
+	c.Assert(err, gc.IsNil)
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+
+	args := params.EntitiesMigrationPhase{Entities: []params.EntityMigrationPhase{
+		{Tag: "unit-mysql-0", Phase: params.MigrationImporting},
+		{Tag: "unit-wordpress-0", Phase: params.MigrationImporting},
+		{Tag: "unit-wordpress-1", Phase: params.MigrationHandingOver},
+	}}
+	result, err := s.uniter.SetMigrationPhase(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.MigrationPhase(), gc.Equals, state.MigrationImporting)
+}
+
+func (s *uniterSuite) TestCompleteMigration(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.CompleteMigration(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{&params.Error{Message: `cannot complete migration to unit "wordpress/0": unit is not the target of a migration`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
 func (s *uniterSuite) TestGetPrincipal(c *gc.C) {
 	// Add a subordinate to wordpressUnit.
 	_, _, subordinate := s.addRelatedService(c, "wordpress", "logging", s.wordpressUnit)
@@ -717,6 +792,33 @@ func (s *uniterSuite) TestClosePort(c *gc.C) {
 	c.Assert(openedPorts, gc.HasLen, 0)
 }
 
+func (s *uniterSuite) TestAddMetrics(c *gc.C) {
+	now := time.Now()
+	metrics := []params.Metric{{Key: "load", Value: 0.5, Time: now}}
+	args := params.EntitiesMetrics{Entities: []params.EntityMetrics{
+		{Tag: "unit-mysql-0", Metrics: metrics},
+		{Tag: "unit-wordpress-0", Metrics: metrics},
+		{Tag: "unit-wordpress-0", Metrics: []params.Metric{{Key: "Bad.Key", Value: 1, Time: now}}},
+		{Tag: "unit-foo-42", Metrics: metrics},
+	}}
+	result, err := s.uniter.AddMetrics(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{&params.Error{Message: `cannot add metrics for unit "wordpress/0": invalid metric key "Bad.Key"`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	stored, err := s.wordpressUnit.Metrics()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.HasLen, 1)
+	c.Assert(stored[0].Key, gc.Equals, "load")
+	c.Assert(stored[0].Value, gc.Equals, 0.5)
+}
+
 func (s *uniterSuite) TestWatchConfigSettings(c *gc.C) {
 	err := s.wordpressUnit.SetCharmURL(s.wpCharm.URL())
 	c.Assert(err, gc.IsNil)
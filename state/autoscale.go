@@ -0,0 +1,261 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// AutoscalePolicy describes how the number of units of a service
+// is scaled according to the metrics reported by its units.
+type AutoscalePolicy struct {
+	// MinUnits and MaxUnits bound the number of alive units
+	// of the service.
+	MinUnits int
+	MaxUnits int
+
+	// Metric is the key of the metric that drives the scaling.
+	Metric string
+
+	// ScaleUpThreshold and ScaleDownThreshold hold the values of
+	// the metric, averaged over the service's units, above which
+	// a unit is added and below which a unit is removed.
+	ScaleUpThreshold   float64
+	ScaleDownThreshold float64
+
+	// Cooldown is the minimum time between scaling decisions
+	// that are driven by the metric.
+	Cooldown time.Duration
+}
+
+// Validate returns an error if the policy is not valid.
+func (p AutoscalePolicy) Validate() error {
+	switch {
+	case p.MinUnits < 0:
+		return errors.New("minimum number of units cannot be negative")
+	case p.MaxUnits < 1:
+		return errors.New("maximum number of units must be at least 1")
+	case p.MinUnits > p.MaxUnits:
+		return fmt.Errorf("minimum number of units %d exceeds maximum %d", p.MinUnits, p.MaxUnits)
+	case !IsValidMetricKey(p.Metric):
+		return fmt.Errorf("invalid metric key %q", p.Metric)
+	case p.ScaleDownThreshold >= p.ScaleUpThreshold:
+		return errors.New("scale down threshold must be less than scale up threshold")
+	case p.Cooldown < 0:
+		return errors.New("cooldown cannot be negative")
+	}
+	return nil
+}
+
+// AutoscaleDecision records a change made by the autoscaler
+// to the number of units of a service.
+type AutoscaleDecision struct {
+	Time   time.Time
+	From   int
+	To     int
+	Reason string
+}
+
+// autoscaleHistoryLimit holds the number of the most recent
+// decisions that are kept for each service.
+const autoscaleHistoryLimit = 20
+
+// autoscaleDoc holds a service's autoscale policy, along with the
+// most recent decisions made under the policy, latest first.
+type autoscaleDoc struct {
+	ServiceName        string `bson:"_id"`
+	MinUnits           int
+	MaxUnits           int
+	Metric             string
+	ScaleUpThreshold   float64
+	ScaleDownThreshold float64
+	Cooldown           time.Duration
+	History            []AutoscaleDecision
+	TxnRevno           int64 `bson:"txn-revno"`
+}
+
+func (doc *autoscaleDoc) policy() AutoscalePolicy {
+	return AutoscalePolicy{
+		MinUnits:           doc.MinUnits,
+		MaxUnits:           doc.MaxUnits,
+		Metric:             doc.Metric,
+		ScaleUpThreshold:   doc.ScaleUpThreshold,
+		ScaleDownThreshold: doc.ScaleDownThreshold,
+		Cooldown:           doc.Cooldown,
+	}
+}
+
+// SetAutoscalePolicy sets the policy by which the number of units
+// of the service is scaled. The policy's maximum number of units must
+// not be less than the service's minimum number of units. Subordinate
+// services cannot be autoscaled, as their units follow those of the
+// principal services. Any history of decisions made under a previous
+// policy is kept.
+func (s *Service) SetAutoscalePolicy(policy AutoscalePolicy) (err error) {
+	defer errors.Maskf(&err, "cannot set autoscale policy for service %q", s)
+	if s.doc.Subordinate {
+		return errors.New("subordinate services cannot be autoscaled")
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	service := &Service{st: s.st, doc: s.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := service.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		if service.doc.Life != Alive {
+			return nil, errors.New("service is no longer alive")
+		}
+		if service.doc.MinUnits > policy.MaxUnits {
+			return nil, fmt.Errorf("service minimum of %d units exceeds maximum %d", service.doc.MinUnits, policy.MaxUnits)
+		}
+		ops := []txn.Op{{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Assert: append(isAliveDoc, bson.DocElem{"minunits", bson.D{{"$lte", policy.MaxUnits}}}),
+		}}
+		var doc autoscaleDoc
+		err := s.st.autoscale.FindId(s.doc.Name).One(&doc)
+		if err == mgo.ErrNotFound {
+			return append(ops, txn.Op{
+				C:      s.st.autoscale.Name,
+				Id:     s.doc.Name,
+				Assert: txn.DocMissing,
+				Insert: &autoscaleDoc{
+					ServiceName:        s.doc.Name,
+					MinUnits:           policy.MinUnits,
+					MaxUnits:           policy.MaxUnits,
+					Metric:             policy.Metric,
+					ScaleUpThreshold:   policy.ScaleUpThreshold,
+					ScaleDownThreshold: policy.ScaleDownThreshold,
+					Cooldown:           policy.Cooldown,
+				},
+			}), nil
+		} else if err != nil {
+			return nil, err
+		}
+		if doc.policy() == policy {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return append(ops, txn.Op{
+			C:      s.st.autoscale.Name,
+			Id:     s.doc.Name,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"minunits", policy.MinUnits},
+				{"maxunits", policy.MaxUnits},
+				{"metric", policy.Metric},
+				{"scaleupthreshold", policy.ScaleUpThreshold},
+				{"scaledownthreshold", policy.ScaleDownThreshold},
+				{"cooldown", policy.Cooldown},
+			}}},
+		}), nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// ClearAutoscalePolicy stops the service from being autoscaled,
+// and discards the history of autoscaling decisions.
+func (s *Service) ClearAutoscalePolicy() error {
+	ops := []txn.Op{removeAutoscaleOp(s.st, s.doc.Name)}
+	if err := s.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot clear autoscale policy for service %q: %v", s, err)
+	}
+	return nil
+}
+
+// AutoscalePolicy returns the policy by which the number of units
+// of the service is scaled. A NotFound error is returned if the
+// service is not autoscaled.
+func (s *Service) AutoscalePolicy() (AutoscalePolicy, error) {
+	doc, err := s.autoscaleDoc()
+	if err != nil {
+		return AutoscalePolicy{}, err
+	}
+	return doc.policy(), nil
+}
+
+// AutoscaleHistory returns the most recent decisions made by the
+// autoscaler for the service, latest first.
+func (s *Service) AutoscaleHistory() ([]AutoscaleDecision, error) {
+	doc, err := s.autoscaleDoc()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return doc.History, nil
+}
+
+func (s *Service) autoscaleDoc() (*autoscaleDoc, error) {
+	var doc autoscaleDoc
+	err := s.st.autoscale.FindId(s.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("autoscale policy for service %q", s)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get autoscale policy for service %q: %v", s, err)
+	}
+	return &doc, nil
+}
+
+// RecordAutoscaleDecision adds the decision to the history of the
+// service's autoscaling decisions, discarding the oldest decisions
+// if necessary.
+func (s *Service) RecordAutoscaleDecision(decision AutoscaleDecision) (err error) {
+	defer errors.Maskf(&err, "cannot record autoscale decision for service %q", s)
+	decision.Time = decision.Time.UTC()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := s.autoscaleDoc()
+		if err != nil {
+			return nil, err
+		}
+		history := append([]AutoscaleDecision{decision}, doc.History...)
+		if len(history) > autoscaleHistoryLimit {
+			history = history[:autoscaleHistoryLimit]
+		}
+		return []txn.Op{{
+			C:      s.st.autoscale.Name,
+			Id:     s.doc.Name,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$set", bson.D{{"history", history}}}},
+		}}, nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// AutoscaledServices returns the names of all services that
+// have an autoscale policy.
+func (st *State) AutoscaledServices() ([]string, error) {
+	var docs []struct {
+		ServiceName string `bson:"_id"`
+	}
+	if err := st.autoscale.Find(nil).Select(bson.D{{"_id", 1}}).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get autoscaled services: %v", err)
+	}
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.ServiceName
+	}
+	return names, nil
+}
+
+// removeAutoscaleOp returns the operation required to remove
+// the service's autoscale policy and history.
+func removeAutoscaleOp(st *State, serviceName string) txn.Op {
+	return txn.Op{
+		C:      st.autoscale.Name,
+		Id:     serviceName,
+		Remove: true,
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type AutoscaleSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&AutoscaleSuite{})
+
+func (s *AutoscaleSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+var validPolicy = state.AutoscalePolicy{
+	MinUnits:           1,
+	MaxUnits:           4,
+	Metric:             "load",
+	ScaleUpThreshold:   0.8,
+	ScaleDownThreshold: 0.2,
+	Cooldown:           5 * time.Minute,
+}
+
+func (s *AutoscaleSuite) TestSetAutoscalePolicy(c *gc.C) {
+	_, err := s.service.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	services, err := s.State.AutoscaledServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(services, gc.HasLen, 0)
+
+	err = s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.IsNil)
+	policy, err := s.service.AutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, validPolicy)
+	services, err = s.State.AutoscaledServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(services, gc.DeepEquals, []string{"wordpress"})
+
+	changed := validPolicy
+	changed.MaxUnits = 10
+	err = s.service.SetAutoscalePolicy(changed)
+	c.Assert(err, gc.IsNil)
+	policy, err = s.service.AutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, changed)
+
+	err = s.service.ClearAutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.service.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Clearing the policy again is not an error.
+	err = s.service.ClearAutoscalePolicy()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *AutoscaleSuite) TestSetAutoscalePolicyInvalid(c *gc.C) {
+	for i, t := range []struct {
+		change func(*state.AutoscalePolicy)
+		err    string
+	}{{
+		change: func(p *state.AutoscalePolicy) { p.MinUnits = -1 },
+		err:    "minimum number of units cannot be negative",
+	}, {
+		change: func(p *state.AutoscalePolicy) { p.MaxUnits = 0 },
+		err:    "maximum number of units must be at least 1",
+	}, {
+		change: func(p *state.AutoscalePolicy) { p.MinUnits = 5 },
+		err:    "minimum number of units 5 exceeds maximum 4",
+	}, {
+		change: func(p *state.AutoscalePolicy) { p.Metric = "cpu.load" },
+		err:    `invalid metric key "cpu.load"`,
+	}, {
+		change: func(p *state.AutoscalePolicy) { p.ScaleDownThreshold = 0.8 },
+		err:    "scale down threshold must be less than scale up threshold",
+	}, {
+		change: func(p *state.AutoscalePolicy) { p.Cooldown = -time.Second },
+		err:    "cooldown cannot be negative",
+	}} {
+		c.Logf("test %d", i)
+		policy := validPolicy
+		t.change(&policy)
+		err := s.service.SetAutoscalePolicy(policy)
+		c.Check(err, gc.ErrorMatches, `cannot set autoscale policy for service "wordpress": `+t.err)
+	}
+}
+
+func (s *AutoscaleSuite) TestSetAutoscalePolicyDyingService(c *gc.C) {
+	_, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.service.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.ErrorMatches, `cannot set autoscale policy for service "wordpress": service is no longer alive`)
+}
+
+func (s *AutoscaleSuite) TestSetAutoscalePolicySubordinate(c *gc.C) {
+	logging := s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	err := logging.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.ErrorMatches, `cannot set autoscale policy for service "logging": subordinate services cannot be autoscaled`)
+	_, err = logging.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *AutoscaleSuite) TestSetAutoscalePolicyBelowMinUnits(c *gc.C) {
+	err := s.service.SetMinUnits(5)
+	c.Assert(err, gc.IsNil)
+	err = s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.ErrorMatches, `cannot set autoscale policy for service "wordpress": service minimum of 5 units exceeds maximum 4`)
+	_, err = s.service.AutoscalePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *AutoscaleSuite) TestSetMinUnitsAboveAutoscaleMaximum(c *gc.C) {
+	err := s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.IsNil)
+	err = s.service.SetMinUnits(5)
+	c.Assert(err, gc.ErrorMatches, `cannot set minimum units for service "wordpress": minimum of 5 units exceeds autoscale maximum 4`)
+	c.Assert(s.service.MinUnits(), gc.Equals, 0)
+	err = s.service.SetMinUnits(4)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.service.MinUnits(), gc.Equals, 4)
+}
+
+func (s *AutoscaleSuite) TestRecordAutoscaleDecision(c *gc.C) {
+	decision := state.AutoscaleDecision{Time: time.Now(), From: 1, To: 2, Reason: "busy"}
+	err := s.service.RecordAutoscaleDecision(decision)
+	c.Assert(err, gc.ErrorMatches, `cannot record autoscale decision for service "wordpress": autoscale policy for service "wordpress" not found`)
+
+	err = s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.IsNil)
+	for i := 0; i < 25; i++ {
+		decision.Reason = fmt.Sprintf("decision %d", i)
+		err := s.service.RecordAutoscaleDecision(decision)
+		c.Assert(err, gc.IsNil)
+	}
+	history, err := s.service.AutoscaleHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 20)
+	c.Assert(history[0].Reason, gc.Equals, "decision 24")
+	c.Assert(history[19].Reason, gc.Equals, "decision 5")
+
+	// Changing the policy keeps the history.
+	changed := validPolicy
+	changed.MaxUnits = 10
+	err = s.service.SetAutoscalePolicy(changed)
+	c.Assert(err, gc.IsNil)
+	history, err = s.service.AutoscaleHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 20)
+}
+
+func (s *AutoscaleSuite) TestRemoveServiceRemovesPolicy(c *gc.C) {
+	err := s.service.SetAutoscalePolicy(validPolicy)
+	c.Assert(err, gc.IsNil)
+	err = s.service.Destroy()
+	c.Assert(err, gc.IsNil)
+	services, err := s.State.AutoscaledServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(services, gc.HasLen, 0)
+}
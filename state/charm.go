@@ -4,9 +4,15 @@
 package state
 
 import (
+	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/juju/charm"
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
 )
 
 // charmDoc represents the internal state of a charm in MongoDB.
@@ -19,8 +25,24 @@ type charmDoc struct {
 	BundleSha256  string
 	PendingUpload bool
 	Placeholder   bool
+
+	// StoragePath holds the name of the charm bundle in the
+	// provider storage, if it is known.
+	StoragePath string
+
+	// PendingRemoval is set while Remove checks whether the
+	// charm is in use; no service or unit may start using the
+	// charm while it is set, until RemovalExpires. A mark left
+	// behind by a Remove that never completed is then cleared
+	// by the next user of the charm.
+	PendingRemoval bool
+	RemovalExpires time.Time
 }
 
+// charmRemovalTimeout holds the time for which a charm that is being
+// removed cannot be used.
+var charmRemovalTimeout = time.Minute
+
 // Charm represents the state of a charm in the environment.
 type Charm struct {
 	st  *State
@@ -73,6 +95,12 @@ func (c *Charm) BundleSha256() string {
 	return c.doc.BundleSha256
 }
 
+// StoragePath returns the name of the charm bundle in the provider
+// storage, or an empty string if it is not known.
+func (c *Charm) StoragePath() string {
+	return c.doc.StoragePath
+}
+
 // IsUploaded returns whether the charm has been uploaded to the
 // provider storage.
 func (c *Charm) IsUploaded() bool {
@@ -84,3 +112,136 @@ func (c *Charm) IsUploaded() bool {
 func (c *Charm) IsPlaceholder() bool {
 	return c.doc.Placeholder
 }
+
+// IsPendingUpload returns whether the charm record has been created
+// but its bundle has not yet been uploaded to the provider storage.
+func (c *Charm) IsPendingUpload() bool {
+	return c.doc.PendingUpload
+}
+
+// Remove removes the charm from state. Charms that are still used by
+// a service or unit, placeholders and charms pending upload cannot be
+// removed. The charm bundle is not removed from the provider storage;
+// that is the responsibility of the caller, once Remove has succeeded.
+func (c *Charm) Remove() (err error) {
+	defer errors.Maskf(&err, "cannot remove charm %q", c)
+	if c.doc.Placeholder || c.doc.PendingUpload {
+		return fmt.Errorf("charm is not uploaded")
+	}
+	// Mark the charm first, so that nothing can start using it
+	// between checking that it is unused and removing it.
+	expires := time.Now().Add(charmRemovalTimeout)
+	ops := []txn.Op{{
+		C:  c.st.charms.Name,
+		Id: c.doc.URL,
+		Assert: bson.D{
+			{"placeholder", bson.D{{"$ne", true}}},
+			{"pendingupload", bson.D{{"$ne", true}}},
+		},
+		Update: bson.D{{"$set", bson.D{
+			{"pendingremoval", true},
+			{"removalexpires", expires},
+		}}},
+	}}
+	if err := c.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("charm")
+	} else if err != nil {
+		return err
+	}
+	c.doc.PendingRemoval = true
+	c.doc.RemovalExpires = expires
+	inUse, err := c.inUse()
+	if err != nil || inUse {
+		if err := c.cancelRemoval(); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	} else if inUse {
+		return fmt.Errorf("charm is in use")
+	}
+	// The mark is cleared if the charm is used after it expired,
+	// in which case the charm is no longer removed.
+	ops = []txn.Op{{
+		C:      c.st.charms.Name,
+		Id:     c.doc.URL,
+		Assert: bson.D{{"pendingremoval", true}},
+		Remove: true,
+	}}
+	if err := c.st.runTransaction(ops); err == txn.ErrAborted {
+		if count, err := c.st.charms.FindId(c.doc.URL).Count(); err != nil {
+			return err
+		} else if count == 0 {
+			return errors.NotFoundf("charm")
+		}
+		return fmt.Errorf("charm is in use")
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// inUse returns whether any service or unit refers to the charm.
+func (c *Charm) inUse() (bool, error) {
+	sel := bson.D{{"charmurl", c.doc.URL}}
+	for _, coll := range []*mgo.Collection{c.st.services, c.st.units} {
+		count, err := coll.Find(sel).Count()
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// cancelRemoval clears the mark set by Remove, so that the charm can
+// be used again.
+func (c *Charm) cancelRemoval() error {
+	ops := []txn.Op{{
+		C:      c.st.charms.Name,
+		Id:     c.doc.URL,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"pendingremoval", false}}}},
+	}}
+	if err := c.st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+		return err
+	}
+	c.doc.PendingRemoval = false
+	return nil
+}
+
+// charmUsable returns a selector matching charms that are not being
+// removed, or whose removal mark has expired.
+func charmUsable() bson.D {
+	return bson.D{{"$or", []bson.D{
+		{{"pendingremoval", bson.D{{"$ne", true}}}},
+		{{"removalexpires", bson.D{{"$lt", time.Now()}}}},
+	}}}
+}
+
+// useCharmOp returns an operation asserting that the charm with the
+// given URL exists and is not being removed, and clearing any expired
+// removal mark. It must be part of any transaction that makes a
+// service or unit refer to the charm.
+func useCharmOp(st *State, curl *charm.URL) txn.Op {
+	return txn.Op{
+		C:      st.charms.Name,
+		Id:     curl,
+		Assert: charmUsable(),
+		Update: bson.D{{"$set", bson.D{{"pendingremoval", false}}}},
+	}
+}
+
+// isCharmUsable returns whether the charm with the given URL exists
+// and is not being removed.
+func isCharmUsable(st *State, curl *charm.URL) (bool, error) {
+	sel := append(bson.D{{"_id", curl}}, charmUsable()...)
+	count, err := st.charms.Find(sel).Count()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
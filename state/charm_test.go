@@ -6,6 +6,7 @@ package state_test
 import (
 	"bytes"
 	"net/url"
+	"time"
 
 	"github.com/juju/charm"
 	charmtesting "github.com/juju/charm/testing"
@@ -74,6 +75,101 @@ func (s *CharmSuite) TestCharmNotFound(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *CharmSuite) TestAllCharms(c *gc.C) {
+	ch10 := s.AddConfigCharm(c, "dummy", "options: {}", 10)
+	ch2 := s.AddConfigCharm(c, "dummy", "options: {}", 2)
+	charms, err := s.State.AllCharms()
+	c.Assert(err, gc.IsNil)
+	var urls []string
+	for _, ch := range charms {
+		urls = append(urls, ch.URL().String())
+	}
+	c.Assert(urls, gc.DeepEquals, []string{
+		s.curl.String(), ch2.URL().String(), ch10.URL().String(),
+	})
+}
+
+func (s *CharmSuite) TestRemove(c *gc.C) {
+	ch, err := s.State.Charm(s.curl)
+	c.Assert(err, gc.IsNil)
+	err = ch.Remove()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Charm(s.curl)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = ch.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm ".*": charm not found`)
+}
+
+func (s *CharmSuite) TestRemoveInUse(c *gc.C) {
+	ch, err := s.State.Charm(s.curl)
+	c.Assert(err, gc.IsNil)
+	svc := s.AddTestingService(c, "dummy", ch)
+	err = ch.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm ".*": charm is in use`)
+
+	err = svc.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = ch.Remove()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *CharmSuite) TestRemoveInUseBeforeMarking(c *gc.C) {
+	ch, err := s.State.Charm(s.curl)
+	c.Assert(err, gc.IsNil)
+	defer state.SetBeforeHooks(c, s.State, func() {
+		s.AddTestingService(c, "dummy", ch)
+	}).Check()
+	err = ch.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm ".*": charm is in use`)
+
+	// The charm can still be used.
+	s.AddTestingService(c, "dummy2", ch)
+}
+
+func (s *CharmSuite) TestRemoveRejectsNewUsers(c *gc.C) {
+	ch, err := s.State.Charm(s.curl)
+	c.Assert(err, gc.IsNil)
+	svc := s.AddTestingService(c, "dummy", s.AddConfigCharm(c, "dummy", "options: {}", 2))
+	defer state.SetAfterHooks(c, s.State, func() {
+		_, err := s.State.AddService("dummy2", "user-admin", ch, nil)
+		c.Assert(err, gc.ErrorMatches, `cannot add service "dummy2": charm ".*" is no longer available`)
+		err = svc.SetCharm(ch, false)
+		c.Assert(err, gc.ErrorMatches, `charm ".*" is no longer available`)
+	}).Check()
+	err = ch.Remove()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Charm(s.curl)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *CharmSuite) TestRemovalMarkExpires(c *gc.C) {
+	ch, err := s.State.Charm(s.curl)
+	c.Assert(err, gc.IsNil)
+	state.MarkCharmPendingRemoval(c, ch, time.Now().Add(time.Hour))
+	_, err = s.State.AddService("dummy", "user-admin", ch, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add service "dummy": charm ".*" is no longer available`)
+
+	// A mark left behind by a removal that never completed
+	// stops blocking the charm once it expires.
+	state.MarkCharmPendingRemoval(c, ch, time.Now().Add(-time.Second))
+	s.AddTestingService(c, "dummy", ch)
+	err = ch.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm ".*": charm is in use`)
+}
+
+func (s *CharmSuite) TestRemovePending(c *gc.C) {
+	curl, err := s.State.PrepareLocalCharmUpload(charm.MustParseURL("local:quantal/dummy-5"))
+	c.Assert(err, gc.IsNil)
+	charms, err := s.State.AllCharms()
+	c.Assert(err, gc.IsNil)
+	c.Assert(charms, gc.HasLen, 2)
+	c.Assert(charms[1].URL(), gc.DeepEquals, curl)
+	c.Assert(charms[1].IsPendingUpload(), jc.IsTrue)
+	err = charms[1].Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm ".*": charm is not uploaded`)
+}
+
 type CharmTestHelperSuite struct {
 	ConnSuite
 }
@@ -0,0 +1,176 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// CharmUpgradeMode determines what happens when a new revision of a
+// service's charm appears in the charm store.
+type CharmUpgradeMode string
+
+const (
+	// CharmUpgradeNever leaves the service alone. This is the mode
+	// of services without a charm upgrade policy.
+	CharmUpgradeNever CharmUpgradeMode = "never"
+
+	// CharmUpgradeNotify records new revisions in the service's
+	// charm upgrade history, without upgrading the service.
+	CharmUpgradeNotify CharmUpgradeMode = "notify"
+
+	// CharmUpgradeAuto upgrades the service as soon as a new
+	// revision for the same series appears.
+	CharmUpgradeAuto CharmUpgradeMode = "auto"
+
+	// CharmUpgradeWindow upgrades the service to new revisions for
+	// the same series, but only within the environment's maintenance
+	// windows.
+	CharmUpgradeWindow CharmUpgradeMode = "window"
+)
+
+// CharmUpgradePolicy describes how a service is upgraded when new
+// revisions of its charm become available.
+type CharmUpgradePolicy struct {
+	Mode CharmUpgradeMode
+}
+
+// Validate returns an error if the policy is not valid.
+func (p CharmUpgradePolicy) Validate() error {
+	switch p.Mode {
+	case CharmUpgradeNever, CharmUpgradeNotify, CharmUpgradeAuto, CharmUpgradeWindow:
+		return nil
+	}
+	return fmt.Errorf("unknown charm upgrade mode %q", p.Mode)
+}
+
+// CharmUpgradeRecord records a new revision of a service's charm, and
+// what was done about it.
+type CharmUpgradeRecord struct {
+	Time time.Time
+	From string
+	To   string
+
+	// Upgraded holds whether the service was upgraded to To.
+	Upgraded bool
+
+	// Error holds the reason the upgrade failed, if it did.
+	Error string
+}
+
+// charmUpgradeHistoryLimit holds the number of the most recent
+// records that are kept for each service.
+const charmUpgradeHistoryLimit = 20
+
+// charmUpgradeDoc holds a service's charm upgrade policy, along with
+// the most recent records of the upgrades made under the policy,
+// latest first.
+type charmUpgradeDoc struct {
+	ServiceName string `bson:"_id"`
+	Mode        CharmUpgradeMode
+	History     []CharmUpgradeRecord
+}
+
+func (doc *charmUpgradeDoc) policy() CharmUpgradePolicy {
+	return CharmUpgradePolicy{Mode: doc.Mode}
+}
+
+// charmUpgradePolicies returns access to the charm upgrade policies
+// of the environment's services.
+func (st *State) charmUpgradePolicies() *servicePolicies {
+	return &servicePolicies{
+		st:           st,
+		coll:         st.charmUpgrades,
+		what:         "charm upgrade policy",
+		historyLimit: charmUpgradeHistoryLimit,
+	}
+}
+
+// SetCharmUpgradePolicy sets the policy by which the service is
+// upgraded when new revisions of its charm appear. Any history of
+// upgrades made under a previous policy is kept.
+func (s *Service) SetCharmUpgradePolicy(policy CharmUpgradePolicy) (err error) {
+	defer errors.Maskf(&err, "cannot set charm upgrade policy for service %q", s)
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if alive, err := isAlive(s.st.services, s.doc.Name); err != nil {
+				return nil, err
+			} else if !alive {
+				return nil, errors.New("service is no longer alive")
+			}
+		}
+		ops, err := s.st.charmUpgradePolicies().setOps(s.doc.Name, &charmUpgradeDoc{
+			ServiceName: s.doc.Name,
+			Mode:        policy.Mode,
+		}, bson.D{
+			{"mode", policy.Mode},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(ops, txn.Op{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Assert: isAliveDoc,
+		}), nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// ClearCharmUpgradePolicy stops the service from being upgraded
+// automatically, and discards the history of its charm upgrades.
+func (s *Service) ClearCharmUpgradePolicy() error {
+	ops := []txn.Op{s.st.charmUpgradePolicies().removeOp(s.doc.Name)}
+	if err := s.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot clear charm upgrade policy for service %q: %v", s, err)
+	}
+	return nil
+}
+
+// CharmUpgradePolicy returns the policy by which the service is
+// upgraded when new revisions of its charm appear. A NotFound error
+// is returned if the service has no policy.
+func (s *Service) CharmUpgradePolicy() (CharmUpgradePolicy, error) {
+	doc, err := s.charmUpgradeDoc()
+	if err != nil {
+		return CharmUpgradePolicy{}, err
+	}
+	return doc.policy(), nil
+}
+
+// CharmUpgradeHistory returns the most recent charm upgrade records
+// of the service, latest first.
+func (s *Service) CharmUpgradeHistory() ([]CharmUpgradeRecord, error) {
+	doc, err := s.charmUpgradeDoc()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return doc.History, nil
+}
+
+func (s *Service) charmUpgradeDoc() (*charmUpgradeDoc, error) {
+	var doc charmUpgradeDoc
+	if err := s.st.charmUpgradePolicies().get(s.doc.Name, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// RecordCharmUpgrade adds the record to the history of the service's
+// charm upgrades, discarding the oldest records if necessary.
+func (s *Service) RecordCharmUpgrade(record CharmUpgradeRecord) (err error) {
+	defer errors.Maskf(&err, "cannot record charm upgrade for service %q", s)
+	record.Time = record.Time.UTC()
+	return s.st.charmUpgradePolicies().record(s.doc.Name, record)
+}
@@ -0,0 +1,102 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type CharmUpgradeSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&CharmUpgradeSuite{})
+
+func (s *CharmUpgradeSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+var windowPolicy = state.CharmUpgradePolicy{Mode: state.CharmUpgradeWindow}
+
+func (s *CharmUpgradeSuite) TestSetCharmUpgradePolicy(c *gc.C) {
+	_, err := s.service.CharmUpgradePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.service.SetCharmUpgradePolicy(windowPolicy)
+	c.Assert(err, gc.IsNil)
+	policy, err := s.service.CharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, windowPolicy)
+
+	auto := state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto}
+	err = s.service.SetCharmUpgradePolicy(auto)
+	c.Assert(err, gc.IsNil)
+	policy, err = s.service.CharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, auto)
+
+	err = s.service.ClearCharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.service.CharmUpgradePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Clearing the policy again is not an error.
+	err = s.service.ClearCharmUpgradePolicy()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *CharmUpgradeSuite) TestSetCharmUpgradePolicyInvalid(c *gc.C) {
+	err := s.service.SetCharmUpgradePolicy(state.CharmUpgradePolicy{Mode: "sometimes"})
+	c.Assert(err, gc.ErrorMatches, `cannot set charm upgrade policy for service "wordpress": unknown charm upgrade mode "sometimes"`)
+}
+
+func (s *CharmUpgradeSuite) TestRecordCharmUpgrade(c *gc.C) {
+	record := state.CharmUpgradeRecord{
+		Time:     time.Now(),
+		From:     "cs:quantal/wordpress-1",
+		Upgraded: true,
+	}
+	err := s.service.RecordCharmUpgrade(record)
+	c.Assert(err, gc.ErrorMatches, `cannot record charm upgrade for service "wordpress": charm upgrade policy for service "wordpress" not found`)
+
+	err = s.service.SetCharmUpgradePolicy(windowPolicy)
+	c.Assert(err, gc.IsNil)
+	for i := 0; i < 25; i++ {
+		record.To = fmt.Sprintf("cs:quantal/wordpress-%d", i+2)
+		err := s.service.RecordCharmUpgrade(record)
+		c.Assert(err, gc.IsNil)
+	}
+	history, err := s.service.CharmUpgradeHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 20)
+	c.Assert(history[0].To, gc.Equals, "cs:quantal/wordpress-26")
+	c.Assert(history[19].To, gc.Equals, "cs:quantal/wordpress-7")
+
+	// Changing the policy keeps the history.
+	err = s.service.SetCharmUpgradePolicy(state.CharmUpgradePolicy{Mode: state.CharmUpgradeNotify})
+	c.Assert(err, gc.IsNil)
+	history, err = s.service.CharmUpgradeHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 20)
+}
+
+func (s *CharmUpgradeSuite) TestRemoveServiceRemovesPolicy(c *gc.C) {
+	err := s.service.SetCharmUpgradePolicy(windowPolicy)
+	c.Assert(err, gc.IsNil)
+	err = s.service.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.service.CharmUpgradePolicy()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
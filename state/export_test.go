@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/charm"
 	charmtesting "github.com/juju/charm/testing"
@@ -232,3 +233,21 @@ var (
 	GetOrCreatePorts = getOrCreatePorts
 	GetPorts         = getPorts
 )
+
+var MetricBatchPruneSize = &metricBatchPruneSize
+
+// MarkCharmPendingRemoval marks the charm as Remove does before
+// checking whether it is in use, as if Remove never completed.
+func MarkCharmPendingRemoval(c *gc.C, ch *Charm, expires time.Time) {
+	ops := []txn.Op{{
+		C:      ch.st.charms.Name,
+		Id:     ch.doc.URL,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"pendingremoval", true},
+			{"removalexpires", expires},
+		}}},
+	}}
+	err := ch.st.runTransaction(ops)
+	c.Assert(err, gc.IsNil)
+}
@@ -120,6 +120,9 @@ type machineDoc struct {
 	// Placement is the placement directive that should be used when provisioning
 	// an instance for the machine.
 	Placement string `bson:",omitempty"`
+	// CloneFrom is the id of the container whose instance should be
+	// cloned to provide an instance for the machine.
+	CloneFrom string `bson:",omitempty"`
 	// Deprecated. InstanceId, now lives on instanceData.
 	// This attribute is retained so that data from existing machines can be read.
 	// SCHEMACHANGE
@@ -1150,6 +1153,13 @@ func (m *Machine) Placement() string {
 	return m.doc.Placement
 }
 
+// CloneFrom returns the id of the container whose instance should be
+// cloned to provide an instance for the machine, or the empty string if
+// a fresh instance should be provisioned.
+func (m *Machine) CloneFrom() string {
+	return m.doc.CloneFrom
+}
+
 // Constraints returns the exact constraints that should apply when provisioning
 // an instance for the machine.
 func (m *Machine) Constraints() (constraints.Value, error) {
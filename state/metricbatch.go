@@ -0,0 +1,158 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// MetricBatch holds a batch of metrics reported by a unit
+// in a single hook execution.
+type MetricBatch struct {
+	st  *State
+	doc metricBatchDoc
+}
+
+// metricBatchDoc represents a batch of metrics in MongoDB.
+type metricBatchDoc struct {
+	Id       string `bson:"_id"`
+	Unit     string
+	Service  string
+	CharmURL string
+	Created  time.Time
+	Metrics  []metricDoc
+}
+
+type metricDoc struct {
+	Key   string
+	Value float64
+	Time  time.Time
+}
+
+func newMetricBatch(st *State, doc *metricBatchDoc) *MetricBatch {
+	return &MetricBatch{st: st, doc: *doc}
+}
+
+// Id returns the id of the metric batch.
+func (m *MetricBatch) Id() string {
+	return m.doc.Id
+}
+
+// Unit returns the name of the unit that reported the metrics.
+func (m *MetricBatch) Unit() string {
+	return m.doc.Unit
+}
+
+// Service returns the name of the service of the unit
+// that reported the metrics.
+func (m *MetricBatch) Service() string {
+	return m.doc.Service
+}
+
+// CharmURL returns the URL of the charm the unit was running
+// when it reported the metrics, or the empty string if the
+// unit had no charm set.
+func (m *MetricBatch) CharmURL() string {
+	return m.doc.CharmURL
+}
+
+// Created returns the time the metrics were recorded.
+func (m *MetricBatch) Created() time.Time {
+	return m.doc.Created
+}
+
+// Metrics returns the metrics in the batch.
+func (m *MetricBatch) Metrics() []Metric {
+	metrics := make([]Metric, len(m.doc.Metrics))
+	for i, doc := range m.doc.Metrics {
+		metrics[i] = Metric{doc.Key, doc.Value, doc.Time}
+	}
+	return metrics
+}
+
+// addMetricBatchOp returns the operation required to record the
+// given metrics as a new batch reported by the unit.
+func addMetricBatchOp(u *Unit, metrics []Metric, created time.Time) txn.Op {
+	doc := &metricBatchDoc{
+		Id:      bson.NewObjectId().Hex(),
+		Unit:    u.doc.Name,
+		Service: u.doc.Service,
+		Created: created.UTC(),
+		Metrics: make([]metricDoc, len(metrics)),
+	}
+	if u.doc.CharmURL != nil {
+		doc.CharmURL = u.doc.CharmURL.String()
+	}
+	for i, m := range metrics {
+		doc.Metrics[i] = metricDoc{m.Key, m.Value, m.Time.UTC()}
+	}
+	return txn.Op{
+		C:      u.st.metrics.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}
+}
+
+// MetricBatches returns the batches of metrics reported by the units
+// of the service since the given time, oldest first.
+func (s *Service) MetricBatches(since time.Time) ([]*MetricBatch, error) {
+	var docs []metricBatchDoc
+	query := bson.D{
+		{"service", s.doc.Name},
+		{"created", bson.D{{"$gte", since.UTC()}}},
+	}
+	err := s.st.metrics.Find(query).Sort("created").All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get metric batches for service %q: %v", s, err)
+	}
+	batches := make([]*MetricBatch, len(docs))
+	for i := range docs {
+		batches[i] = newMetricBatch(s.st, &docs[i])
+	}
+	return batches, nil
+}
+
+// metricBatchPruneSize holds the maximum number of metric batches
+// removed by a single transaction when pruning.
+var metricBatchPruneSize = 1000
+
+// PruneMetricBatches removes the batches of metrics that were
+// recorded before the given time, and returns how many were removed.
+// The batches are removed in transactions of bounded size.
+func (st *State) PruneMetricBatches(before time.Time) (int, error) {
+	query := bson.D{{"created", bson.D{{"$lt", before.UTC()}}}}
+	removed := 0
+	for {
+		var docs []struct {
+			Id string `bson:"_id"`
+		}
+		err := st.metrics.Find(query).Select(bson.D{{"_id", 1}}).Limit(metricBatchPruneSize).All(&docs)
+		if err != nil {
+			return removed, fmt.Errorf("cannot find old metric batches: %v", err)
+		}
+		if len(docs) == 0 {
+			return removed, nil
+		}
+		ops := make([]txn.Op, len(docs))
+		for i, doc := range docs {
+			ops[i] = txn.Op{
+				C:      st.metrics.Name,
+				Id:     doc.Id,
+				Remove: true,
+			}
+		}
+		if err := st.runTransaction(ops); err != nil {
+			return removed, fmt.Errorf("cannot remove old metric batches: %v", err)
+		}
+		removed += len(docs)
+		if len(docs) < metricBatchPruneSize {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type MetricBatchSuite struct {
+	ConnSuite
+	charm   *state.Charm
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&MetricBatchSuite{})
+
+func (s *MetricBatchSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.charm = s.AddTestingCharm(c, "wordpress")
+	s.service = s.AddTestingService(c, "wordpress", s.charm)
+	var err error
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetCharmURL(s.charm.URL())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *MetricBatchSuite) TestAddMetricsRecordsBatch(c *gc.C) {
+	t0 := time.Now().Add(-time.Minute)
+	err := s.unit.AddMetrics([]state.Metric{
+		{Key: "load", Value: 0.5, Time: t0},
+		{Key: "users", Value: 12, Time: t0},
+	})
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 0.9, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.service.MetricBatches(time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 2)
+	batch := batches[0]
+	c.Assert(batch.Unit(), gc.Equals, "wordpress/0")
+	c.Assert(batch.Service(), gc.Equals, "wordpress")
+	c.Assert(batch.CharmURL(), gc.Equals, s.charm.URL().String())
+	metrics := batch.Metrics()
+	c.Assert(metrics, gc.HasLen, 2)
+	c.Assert(metrics[0].Key, gc.Equals, "load")
+	c.Assert(metrics[0].Value, gc.Equals, 0.5)
+	c.Assert(metrics[0].Time.Unix(), gc.Equals, t0.Unix())
+	c.Assert(metrics[1].Key, gc.Equals, "users")
+	c.Assert(batches[1].Metrics(), gc.HasLen, 1)
+	c.Assert(batches[1].Created().Before(batch.Created()), gc.Equals, false)
+
+	batches, err = s.service.MetricBatches(time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+}
+
+func (s *MetricBatchSuite) TestMetricBatchesOutliveUnit(c *gc.C) {
+	err := s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.service.MetricBatches(time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit(), gc.Equals, "wordpress/0")
+}
+
+func (s *MetricBatchSuite) TestPruneMetricBatches(c *gc.C) {
+	err := s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+
+	removed, err := s.State.PruneMetricBatches(time.Now().Add(-time.Hour))
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.Equals, 0)
+
+	removed, err = s.State.PruneMetricBatches(time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.Equals, 1)
+	batches, err := s.service.MetricBatches(time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+
+	// The latest values are kept for the autoscaler.
+	metrics, err := s.unit.Metrics()
+	c.Assert(err, gc.IsNil)
+	c.Assert(metrics, gc.HasLen, 1)
+}
+
+func (s *MetricBatchSuite) TestPruneMetricBatchesInChunks(c *gc.C) {
+	s.PatchValue(state.MetricBatchPruneSize, 2)
+	for i := 0; i < 5; i++ {
+		err := s.unit.AddMetrics([]state.Metric{{Key: "load", Value: float64(i), Time: time.Now()}})
+		c.Assert(err, gc.IsNil)
+	}
+	removed, err := s.State.PruneMetricBatches(time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed, gc.Equals, 5)
+	batches, err := s.service.MetricBatches(time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+}
@@ -4,6 +4,8 @@
 package state
 
 import (
+	"fmt"
+
 	"github.com/juju/errors"
 	jujutxn "github.com/juju/txn"
 	"labix.org/v2/mgo/bson"
@@ -28,6 +30,8 @@ type minUnitsDoc struct {
 }
 
 // SetMinUnits changes the number of minimum units required by the service.
+// If the service is autoscaled, the minimum cannot exceed the maximum number
+// of units allowed by its autoscale policy.
 func (s *Service) SetMinUnits(minUnits int) (err error) {
 	defer errors.Maskf(&err, "cannot set minimum units for service %q", s)
 	defer func() {
@@ -57,7 +61,25 @@ func (s *Service) SetMinUnits(minUnits int) (err error) {
 		if minUnits == service.doc.MinUnits {
 			return nil, jujutxn.ErrNoOperations
 		}
-		return setMinUnitsOps(service, minUnits), nil
+		ops := setMinUnitsOps(service, minUnits)
+		autoscale, err := service.autoscaleDoc()
+		if errors.IsNotFound(err) {
+			return append(ops, txn.Op{
+				C:      s.st.autoscale.Name,
+				Id:     service.doc.Name,
+				Assert: txn.DocMissing,
+			}), nil
+		} else if err != nil {
+			return nil, err
+		}
+		if minUnits > autoscale.MaxUnits {
+			return nil, fmt.Errorf("minimum of %d units exceeds autoscale maximum %d", minUnits, autoscale.MaxUnits)
+		}
+		return append(ops, txn.Op{
+			C:      s.st.autoscale.Name,
+			Id:     service.doc.Name,
+			Assert: bson.D{{"maxunits", bson.D{{"$gte", minUnits}}}},
+		}), nil
 	}
 	return s.st.run(buildTxn)
 }
@@ -134,6 +134,9 @@ var indexes = []struct {
 	{"networkinterfaces", []string{"macaddress", "networkname"}, true},
 	{"networkinterfaces", []string{"networkname"}, false},
 	{"networkinterfaces", []string{"machineid"}, false},
+	{"unitmetrics", []string{"service"}, false},
+	{"metrics", []string{"service", "created"}, false},
+	{"metrics", []string{"created"}, false},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -207,6 +210,10 @@ func newState(session *mgo.Session, mongoInfo *authentication.MongoInfo, policy
 		networks:          db.C("networks"),
 		networkInterfaces: db.C("networkinterfaces"),
 		minUnits:          db.C("minunits"),
+		autoscale:         db.C("autoscale"),
+		charmUpgrades:     db.C("charmupgrades"),
+		unitMetrics:       db.C("unitmetrics"),
+		metrics:           db.C("metrics"),
 		settings:          db.C("settings"),
 		settingsrefs:      db.C("settingsrefs"),
 		constraints:       db.C("constraints"),
@@ -42,6 +42,10 @@ type relationDoc struct {
 	Endpoints []Endpoint
 	Life      Life
 	UnitCount int
+	// Placement restricts the principal units given subordinates
+	// by a container-scoped relation; it is nil if there are no
+	// restrictions.
+	Placement *subordinatePlacementDoc `bson:",omitempty"`
 }
 
 // Relation represents a relation between one or two service endpoints.
@@ -238,6 +242,16 @@ func (r *Relation) Endpoint(serviceName string) (Endpoint, error) {
 	return Endpoint{}, fmt.Errorf("service %q is not a member of %q", serviceName, r)
 }
 
+// SubordinatePlacement returns the restrictions on the principal units
+// that are given subordinate units by the relation, and whether there
+// are any.
+func (r *Relation) SubordinatePlacement() (SubordinatePlacement, bool) {
+	if r.doc.Placement == nil {
+		return SubordinatePlacement{}, false
+	}
+	return r.doc.Placement.value(), true
+}
+
 // Endpoints returns the endpoints for the relation.
 func (r *Relation) Endpoints() []Endpoint {
 	return r.doc.Endpoints
@@ -205,6 +205,14 @@ func (ru *RelationUnit) subordinateOps() ([]txn.Op, string, error) {
 	selSubordinate := bson.D{{"service", serviceName}, {"principal", unitName}}
 	var lDoc lifeDoc
 	if err := ru.st.units.Find(selSubordinate).One(&lDoc); err == mgo.ErrNotFound {
+		if placement, ok := ru.relation.SubordinatePlacement(); ok {
+			if allowed, err := placement.allowsPrincipal(ru.unit); err != nil {
+				return nil, "", err
+			} else if !allowed {
+				// The unit enters scope, but without a subordinate.
+				return nil, "", nil
+			}
+		}
 		service, err := ru.st.Service(serviceName)
 		if err != nil {
 			return nil, "", err
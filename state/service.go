@@ -228,6 +228,8 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 	}}
 	ops = append(ops, removeRequestedNetworksOp(s.st, s.globalKey()))
 	ops = append(ops, removeConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, removeAutoscaleOp(s.st, s.doc.Name))
+	ops = append(ops, s.st.charmUpgradePolicies().removeOp(s.doc.Name))
 	return append(ops, annotationRemoveOp(s.st, s.globalKey()))
 }
 
@@ -424,6 +426,8 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 			Assert: append(isAliveDoc, differentCharm...),
 			Update: bson.D{{"$set", bson.D{{"charmurl", ch.URL()}, {"forcecharm", force}}}},
 		},
+		// Make sure the charm is not removed underneath us.
+		useCharmOp(s.st, ch.URL()),
 	}
 	// Add any extra peer relations that need creation.
 	newPeers := s.extraPeerRelations(ch.Meta())
@@ -478,6 +482,11 @@ func (s *Service) SetCharm(ch *Charm, force bool) (err error) {
 			} else if !alive {
 				return nil, fmt.Errorf("service %q is not alive", s.doc.Name)
 			}
+			if usable, err := isCharmUsable(s.st, ch.URL()); err != nil {
+				return nil, err
+			} else if !usable {
+				return nil, fmt.Errorf("charm %q is no longer available", ch.URL())
+			}
 		}
 		// Make sure the service doesn't have this charm already.
 		sel := bson.D{{"_id", s.doc.Name}, {"charmurl", ch.URL()}}
@@ -662,6 +671,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 		removeConstraintsOp(s.st, u.globalKey()),
 		removeStatusOp(s.st, u.globalKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
+		removeUnitMetricsOp(s.st, u.doc.Name),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
 	if u.doc.CharmURL != nil {
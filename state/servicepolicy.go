@@ -0,0 +1,127 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// servicePolicies gives access to a collection of documents, keyed by
+// service name, each holding a policy for a service along with the
+// most recent records of what was done under the policy, latest first,
+// in a "history" field.
+type servicePolicies struct {
+	st   *State
+	coll *mgo.Collection
+
+	// what describes the policies in error messages.
+	what string
+
+	// historyLimit holds the number of the most recent records
+	// that are kept for each service.
+	historyLimit int
+}
+
+// get reads the policy document of the named service into doc.
+// A NotFound error is returned if the service has no policy.
+func (p *servicePolicies) get(serviceName string, doc interface{}) error {
+	err := p.coll.FindId(serviceName).One(doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("%s for service %q", p.what, serviceName)
+	} else if err != nil {
+		return fmt.Errorf("cannot get %s for service %q: %v", p.what, serviceName, err)
+	}
+	return nil
+}
+
+// setOps returns the operations required to give the named service a
+// policy with the given fields. If the service has no policy yet, doc
+// is inserted; it must hold the same fields and an empty history. If
+// the service's policy already has the given fields, the returned
+// error is jujutxn.ErrNoOperations.
+func (p *servicePolicies) setOps(serviceName string, doc interface{}, fields bson.D) ([]txn.Op, error) {
+	if count, err := p.coll.FindId(serviceName).Count(); err != nil {
+		return nil, err
+	} else if count == 0 {
+		return []txn.Op{{
+			C:      p.coll.Name,
+			Id:     serviceName,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	unchanged := append(bson.D{{"_id", serviceName}}, fields...)
+	if count, err := p.coll.Find(unchanged).Count(); err != nil {
+		return nil, err
+	} else if count > 0 {
+		return nil, jujutxn.ErrNoOperations
+	}
+	return []txn.Op{{
+		C:      p.coll.Name,
+		Id:     serviceName,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", fields}},
+	}}, nil
+}
+
+// record adds the record to the history of the named service's
+// policy, discarding the oldest records if necessary.
+func (p *servicePolicies) record(serviceName string, record interface{}) error {
+	data, err := bson.Marshal(record)
+	if err != nil {
+		return err
+	}
+	raw := bson.Raw{Kind: 0x03, Data: data}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		var doc struct {
+			History  []bson.Raw
+			TxnRevno int64 `bson:"txn-revno"`
+		}
+		if err := p.get(serviceName, &doc); err != nil {
+			return nil, err
+		}
+		history := append([]bson.Raw{raw}, doc.History...)
+		if len(history) > p.historyLimit {
+			history = history[:p.historyLimit]
+		}
+		return []txn.Op{{
+			C:      p.coll.Name,
+			Id:     serviceName,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$set", bson.D{{"history", history}}}},
+		}}, nil
+	}
+	return p.st.run(buildTxn)
+}
+
+// serviceNames returns the names of all services that have a policy.
+func (p *servicePolicies) serviceNames() ([]string, error) {
+	var docs []struct {
+		ServiceName string `bson:"_id"`
+	}
+	if err := p.coll.Find(nil).Select(bson.D{{"_id", 1}}).All(&docs); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.ServiceName
+	}
+	return names, nil
+}
+
+// removeOp returns the operation required to remove the named
+// service's policy and history.
+func (p *servicePolicies) removeOp(serviceName string) txn.Op {
+	return txn.Op{
+		C:      p.coll.Name,
+		Id:     serviceName,
+		Remove: true,
+	}
+}
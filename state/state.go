@@ -62,6 +62,10 @@ type State struct {
 	networks          *mgo.Collection
 	networkInterfaces *mgo.Collection
 	minUnits          *mgo.Collection
+	autoscale         *mgo.Collection
+	charmUpgrades     *mgo.Collection
+	unitMetrics       *mgo.Collection
+	metrics           *mgo.Collection
 	settings          *mgo.Collection
 	settingsrefs      *mgo.Collection
 	constraints       *mgo.Collection
@@ -525,7 +529,7 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, b
 	} else if err != nil {
 		return nil, err
 	}
-	return st.updateCharmDoc(ch, curl, bundleURL, bundleSha256, stillPlaceholder)
+	return st.updateCharmDoc(ch, curl, bundleURL, "", bundleSha256, stillPlaceholder)
 }
 
 // Charm returns the charm with the given URL. Charms pending upload
@@ -550,6 +554,33 @@ func (st *State) Charm(curl *charm.URL) (*Charm, error) {
 	return newCharm(st, cdoc)
 }
 
+// AllCharms returns all the charms in the environment, ordered by URL,
+// including placeholders and charms pending upload.
+func (st *State) AllCharms() ([]*Charm, error) {
+	var docs []charmDoc
+	if err := st.charms.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get all charms: %v", err)
+	}
+	sort.Sort(charmDocsByURL(docs))
+	charms := make([]*Charm, len(docs))
+	for i := range docs {
+		charms[i] = &Charm{st: st, doc: docs[i]}
+	}
+	return charms, nil
+}
+
+type charmDocsByURL []charmDoc
+
+func (d charmDocsByURL) Len() int      { return len(d) }
+func (d charmDocsByURL) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d charmDocsByURL) Less(i, j int) bool {
+	ui, uj := d[i].URL.WithRevision(-1).String(), d[j].URL.WithRevision(-1).String()
+	if ui != uj {
+		return ui < uj
+	}
+	return d[i].URL.Revision < d[j].URL.Revision
+}
+
 // LatestPlaceholderCharm returns the latest charm described by the
 // given URL but which is not yet deployed.
 func (st *State) LatestPlaceholderCharm(curl *charm.URL) (*Charm, error) {
@@ -815,7 +846,9 @@ var ErrCharmRevisionAlreadyModified = fmt.Errorf("charm revision already modifie
 
 // UpdateUploadedCharm marks the given charm URL as uploaded and
 // updates the rest of its data, returning it as *state.Charm.
-func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, bundleSha256 string) (*Charm, error) {
+// The storagePath holds the name of the bundle in the provider
+// storage, from which bundleURL was obtained.
+func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, storagePath, bundleSha256 string) (*Charm, error) {
 	doc := &charmDoc{}
 	err := st.charms.FindId(curl).One(&doc)
 	if err == mgo.ErrNotFound {
@@ -828,7 +861,7 @@ func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, bundleURL
 		return nil, &ErrCharmAlreadyUploaded{curl}
 	}
 
-	return st.updateCharmDoc(ch, curl, bundleURL, bundleSha256, stillPending)
+	return st.updateCharmDoc(ch, curl, bundleURL, storagePath, bundleSha256, stillPending)
 }
 
 // updateCharmDoc updates the charm with specified URL with the given
@@ -836,13 +869,14 @@ func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, bundleURL
 // charm is no longer a placeholder or pending (depending on preReq),
 // it returns ErrCharmRevisionAlreadyModified.
 func (st *State) updateCharmDoc(
-	ch charm.Charm, curl *charm.URL, bundleURL *url.URL, bundleSha256 string, preReq interface{}) (*Charm, error) {
+	ch charm.Charm, curl *charm.URL, bundleURL *url.URL, storagePath, bundleSha256 string, preReq interface{}) (*Charm, error) {
 
 	updateFields := bson.D{{"$set", bson.D{
 		{"meta", ch.Meta()},
 		{"config", ch.Config()},
 		{"actions", ch.Actions()},
 		{"bundleurl", bundleURL},
+		{"storagepath", storagePath},
 		{"bundlesha256", bundleSha256},
 		{"pendingupload", false},
 		{"placeholder", false},
@@ -959,7 +993,9 @@ func (st *State) AddService(name, ownerTag string, ch *Charm, networks []string)
 			Id:     name,
 			Assert: txn.DocMissing,
 			Insert: svcDoc,
-		}}
+		},
+		useCharmOp(st, ch.URL()),
+	}
 	// Collect peer relation addition operations.
 	peerOps, err := st.addPeerRelationsOps(name, peers)
 	if err != nil {
@@ -981,6 +1017,12 @@ func (st *State) AddService(name, ownerTag string, ch *Charm, networks []string)
 			return nil, fmt.Errorf("unknown user %q", ownerId)
 		}
 
+		if usable, err := isCharmUsable(st, ch.URL()); err != nil {
+			return nil, err
+		} else if !usable {
+			return nil, fmt.Errorf("charm %q is no longer available", ch.URL())
+		}
+
 		return nil, fmt.Errorf("service already exists")
 	} else if err != nil {
 		return nil, err
@@ -1215,6 +1257,15 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 
 // AddRelation creates a new relation with the given endpoints.
 func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
+	return st.AddRelationWithPlacement(SubordinatePlacement{}, eps...)
+}
+
+// AddRelationWithPlacement creates a new relation with the given
+// endpoints, which gives subordinate units only to the principal
+// units allowed by placement. A non-empty placement is only valid
+// for a container-scoped relation between a principal service and
+// a subordinate service.
+func (st *State) AddRelationWithPlacement(placement SubordinatePlacement, eps ...Endpoint) (r *Relation, err error) {
 	key := relationKey(eps)
 	defer errors.Maskf(&err, "cannot add relation %q", key)
 	// Enforce basic endpoint sanity. The epCount restrictions may be relaxed
@@ -1236,6 +1287,9 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 	} else {
 		matchSeries = false
 	}
+	if !placement.IsEmpty() && !matchSeries {
+		return nil, fmt.Errorf("subordinate placement requires a container-scoped relation")
+	}
 	// We only get a unique relation id once, to save on roundtrips. If it's
 	// -1, we haven't got it yet (we don't get it at this stage, because we
 	// still don't know whether it's sane to even attempt creation).
@@ -1253,6 +1307,7 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 		// Collect per-service operations, checking sanity as we go.
 		var ops []txn.Op
 		series := map[string]bool{}
+		principalService := ""
 		for _, ep := range eps {
 			svc, err := st.Service(ep.ServiceName)
 			if errors.IsNotFound(err) {
@@ -1270,6 +1325,9 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 			if !ep.ImplementedBy(ch) {
 				return nil, fmt.Errorf("%q does not implement %q", ep.ServiceName, ep)
 			}
+			if !ch.Meta().Subordinate {
+				principalService = ep.ServiceName
+			}
 			ops = append(ops, txn.Op{
 				C:      st.services.Name,
 				Id:     ep.ServiceName,
@@ -1280,6 +1338,13 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 		if matchSeries && len(series) != 1 {
 			return nil, fmt.Errorf("principal and subordinate services' series must match")
 		}
+		var placementDoc *subordinatePlacementDoc
+		if !placement.IsEmpty() {
+			if err := placement.validate(principalService); err != nil {
+				return nil, fmt.Errorf("invalid subordinate placement: %v", err)
+			}
+			placementDoc = newSubordinatePlacementDoc(placement)
+		}
 		// Create a new unique id if that has not already been done, and add
 		// an operation to create the relation document.
 		if id == -1 {
@@ -1293,6 +1358,7 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 			Id:        id,
 			Endpoints: eps,
 			Life:      Alive,
+			Placement: placementDoc,
 		}
 		ops = append(ops, txn.Op{
 			C:      st.relations.Name,
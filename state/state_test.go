@@ -337,18 +337,18 @@ func (s *StateSuite) TestUpdateUploadedCharm(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 
 	// Test with already uploaded and a missing charms.
-	sch, err := s.State.UpdateUploadedCharm(ch, curl, bundleURL, bundleSHA256)
+	sch, err := s.State.UpdateUploadedCharm(ch, curl, bundleURL, "dummy-1", bundleSHA256)
 	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("charm %q already uploaded", curl))
 	c.Assert(sch, gc.IsNil)
 	missingCurl := charm.MustParseURL("local:quantal/missing-1")
-	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, bundleURL, "missing")
+	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, bundleURL, "missing-1", "missing")
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 	c.Assert(sch, gc.IsNil)
 
 	// Test with with an uploaded local charm.
 	_, err = s.State.PrepareLocalCharmUpload(missingCurl)
 	c.Assert(err, gc.IsNil)
-	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, bundleURL, "missing")
+	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, bundleURL, "missing-1", "missing")
 	c.Assert(err, gc.IsNil)
 	c.Assert(sch.URL(), gc.DeepEquals, missingCurl)
 	c.Assert(sch.Revision(), gc.Equals, missingCurl.Revision)
@@ -357,6 +357,7 @@ func (s *StateSuite) TestUpdateUploadedCharm(c *gc.C) {
 	c.Assert(sch.Meta(), gc.DeepEquals, ch.Meta())
 	c.Assert(sch.Config(), gc.DeepEquals, ch.Config())
 	c.Assert(sch.BundleURL(), gc.DeepEquals, bundleURL)
+	c.Assert(sch.StoragePath(), gc.Equals, "missing-1")
 	c.Assert(sch.BundleSha256(), gc.Equals, "missing")
 }
 
@@ -704,6 +705,90 @@ func (s *StateSuite) TestAddContainerToExistingMachine(c *gc.C) {
 	s.assertMachineContainers(c, m1, []string{"1/lxc/0", "1/lxc/1"})
 }
 
+func (s *StateSuite) TestAddContainerClonedFromContainer(c *gc.C) {
+	oneJob := []state.MachineJob{state.JobHostUnits}
+	_, err := s.State.AddMachine("quantal", oneJob...)
+	c.Assert(err, gc.IsNil)
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   oneJob,
+	}
+	source, err := s.State.AddMachineInsideMachine(template, "0", instance.LXC)
+	c.Assert(err, gc.IsNil)
+
+	template.CloneFrom = source.Id()
+	_, err = s.State.AddMachineInsideMachine(template, "0", instance.LXC)
+	c.Assert(err, gc.ErrorMatches, "cannot add a new machine: cannot clone machine 0/lxc/0: machine is not provisioned")
+
+	err = source.SetProvisioned("inst-id", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	m, err := s.State.AddMachineInsideMachine(template, "0", instance.LXC)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0/lxc/1")
+	c.Assert(m.CloneFrom(), gc.Equals, "0/lxc/0")
+
+	m, err = s.State.Machine("0/lxc/1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.CloneFrom(), gc.Equals, "0/lxc/0")
+}
+
+func (s *StateSuite) TestAddContainerClonedFromInvalidSource(c *gc.C) {
+	oneJob := []state.MachineJob{state.JobHostUnits}
+	_, err := s.State.AddMachine("quantal", oneJob...)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddMachine("quantal", oneJob...)
+	c.Assert(err, gc.IsNil)
+	source, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   oneJob,
+	}, "0", instance.LXC)
+	c.Assert(err, gc.IsNil)
+	err = source.SetProvisioned("inst-id", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+
+	for i, test := range []struct {
+		cloneFrom     string
+		parentId      string
+		containerType instance.ContainerType
+		err           string
+	}{{
+		cloneFrom:     "0/lxc/0",
+		parentId:      "1",
+		containerType: instance.LXC,
+		err:           "cannot clone machine 0/lxc/0: not hosted on machine 1",
+	}, {
+		cloneFrom:     "0/lxc/0",
+		parentId:      "0",
+		containerType: instance.KVM,
+		err:           "cannot clone kvm containers",
+	}, {
+		cloneFrom:     "1",
+		parentId:      "0",
+		containerType: instance.LXC,
+		err:           "cannot clone machine 1: not a lxc container",
+	}, {
+		cloneFrom:     "0/lxc/5",
+		parentId:      "0",
+		containerType: instance.LXC,
+		err:           `cannot clone machine 0/lxc/5: machine 0/lxc/5 not found`,
+	}} {
+		c.Logf("test %d: clone %s into %s", i, test.cloneFrom, test.parentId)
+		_, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+			Series:    "quantal",
+			Jobs:      oneJob,
+			CloneFrom: test.cloneFrom,
+		}, test.parentId, test.containerType)
+		c.Check(err, gc.ErrorMatches, "cannot add a new machine: "+test.err)
+	}
+
+	_, err = s.State.AddOneMachine(state.MachineTemplate{
+		Series:    "quantal",
+		Jobs:      oneJob,
+		CloneFrom: "0/lxc/0",
+	})
+	c.Assert(err, gc.ErrorMatches, ".*cannot clone a container to create a top level machine")
+}
+
 func (s *StateSuite) TestAddContainerToMachineWithKnownSupportedContainers(c *gc.C) {
 	oneJob := []state.MachineJob{state.JobHostUnits}
 	host, err := s.State.AddMachine("quantal", oneJob...)
@@ -0,0 +1,161 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+)
+
+// SubordinatePlacement restricts the principal units of a
+// container-scoped relation that are given a subordinate unit.
+// A principal unit is given a subordinate only if it satisfies
+// every restriction that is set.
+type SubordinatePlacement struct {
+	// Units, if not empty, holds the names of the only
+	// principal units that may be given a subordinate.
+	Units []string
+
+	// Constraints holds constraints that the hardware of a
+	// principal unit's machine must satisfy, including any
+	// tags that the machine must have.
+	Constraints constraints.Value
+}
+
+// IsEmpty returns whether the placement places no restrictions
+// on the principal units that are given a subordinate.
+func (p SubordinatePlacement) IsEmpty() bool {
+	return len(p.Units) == 0 && constraints.IsEmpty(&p.Constraints)
+}
+
+// String returns the placement in a form suitable for display.
+func (p SubordinatePlacement) String() string {
+	var strs []string
+	if len(p.Units) > 0 {
+		strs = append(strs, "units="+strings.Join(p.Units, ","))
+	}
+	if cons := p.Constraints.String(); cons != "" {
+		strs = append(strs, cons)
+	}
+	return strings.Join(strs, " ")
+}
+
+// validate checks that the placement can be applied to a relation
+// in which principalService is the principal service.
+func (p SubordinatePlacement) validate(principalService string) error {
+	for _, name := range p.Units {
+		if !names.IsValidUnit(name) {
+			return fmt.Errorf("invalid unit name %q", name)
+		}
+		if service := names.UnitService(name); service != principalService {
+			return fmt.Errorf("unit %q is not a unit of principal service %q", name, principalService)
+		}
+	}
+	cons := p.Constraints
+	switch {
+	case cons.InstanceType != nil:
+		return fmt.Errorf("cannot place subordinates by instance type")
+	case cons.Networks != nil:
+		return fmt.Errorf("cannot place subordinates by networks")
+	case cons.Container != nil:
+		return fmt.Errorf("cannot place subordinates by container type")
+	}
+	return nil
+}
+
+// subordinatePlacementDoc is the mongodb representation
+// of a SubordinatePlacement.
+type subordinatePlacementDoc struct {
+	Units       []string `bson:",omitempty"`
+	Constraints constraintsDoc
+}
+
+func newSubordinatePlacementDoc(p SubordinatePlacement) *subordinatePlacementDoc {
+	return &subordinatePlacementDoc{
+		Units:       p.Units,
+		Constraints: newConstraintsDoc(p.Constraints),
+	}
+}
+
+func (doc *subordinatePlacementDoc) value() SubordinatePlacement {
+	return SubordinatePlacement{
+		Units:       doc.Units,
+		Constraints: doc.Constraints.value(),
+	}
+}
+
+// allowsPrincipal returns whether the placement allows the given
+// principal unit to be given a subordinate.
+func (p SubordinatePlacement) allowsPrincipal(unit *Unit) (bool, error) {
+	if len(p.Units) > 0 && !stringSliceContains(p.Units, unit.Name()) {
+		return false, nil
+	}
+	if constraints.IsEmpty(&p.Constraints) {
+		return true, nil
+	}
+	machineId, err := unit.AssignedMachineId()
+	if IsNotAssigned(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	machine, err := unit.st.Machine(machineId)
+	if err != nil {
+		return false, err
+	}
+	hc, err := machine.HardwareCharacteristics()
+	if errors.IsNotFound(err) {
+		// The machine is not provisioned, so nothing
+		// is known about its hardware.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return hardwareSatisfies(*hc, p.Constraints), nil
+}
+
+// hardwareSatisfies returns whether hardware with the given
+// characteristics satisfies the given constraints. Hardware that
+// is not known is taken not to satisfy any constraint on it.
+func hardwareSatisfies(hc instance.HardwareCharacteristics, cons constraints.Value) bool {
+	atLeast := func(have, want *uint64) bool {
+		return want == nil || (have != nil && *have >= *want)
+	}
+	if cons.Arch != nil && *cons.Arch != "" && (hc.Arch == nil || *hc.Arch != *cons.Arch) {
+		return false
+	}
+	if !atLeast(hc.CpuCores, cons.CpuCores) ||
+		!atLeast(hc.CpuPower, cons.CpuPower) ||
+		!atLeast(hc.Mem, cons.Mem) ||
+		!atLeast(hc.RootDisk, cons.RootDisk) {
+		return false
+	}
+	if cons.Tags != nil {
+		var tags []string
+		if hc.Tags != nil {
+			tags = *hc.Tags
+		}
+		for _, tag := range *cons.Tags {
+			if !stringSliceContains(tags, tag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func stringSliceContains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
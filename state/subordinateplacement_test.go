@@ -0,0 +1,149 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type SubordinatePlacementSuite struct {
+	ConnSuite
+	mysql   *state.Service
+	logging *state.Service
+}
+
+var _ = gc.Suite(&SubordinatePlacementSuite{})
+
+func (s *SubordinatePlacementSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.logging = s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+}
+
+func (s *SubordinatePlacementSuite) addRelation(c *gc.C, placement state.SubordinatePlacement) *state.Relation {
+	eps, err := s.State.InferEndpoints([]string{"mysql", "logging"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelationWithPlacement(placement, eps...)
+	c.Assert(err, gc.IsNil)
+	return rel
+}
+
+// addPrincipal adds a unit of mysql on a new machine provisioned
+// with the given hardware, and enters it into the relation's scope.
+func (s *SubordinatePlacementSuite) addPrincipal(c *gc.C, rel *state.Relation, hardware string) *state.Unit {
+	unit, err := s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	hc := instance.MustParseHardware(hardware)
+	err = machine.SetProvisioned(instance.Id("i-"+machine.Id()), "fake_nonce", &hc)
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	return unit
+}
+
+func (s *SubordinatePlacementSuite) assertSubordinates(c *gc.C, unit *state.Unit, expect ...string) {
+	err := unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(unit.SubordinateNames(), jc.SameContents, expect)
+}
+
+func (s *SubordinatePlacementSuite) TestNoPlacement(c *gc.C) {
+	rel := s.addRelation(c, state.SubordinatePlacement{})
+	_, ok := rel.SubordinatePlacement()
+	c.Assert(ok, jc.IsFalse)
+	unit := s.addPrincipal(c, rel, "mem=1G")
+	s.assertSubordinates(c, unit, "logging/0")
+}
+
+func (s *SubordinatePlacementSuite) TestUnits(c *gc.C) {
+	placement := state.SubordinatePlacement{Units: []string{"mysql/1"}}
+	rel := s.addRelation(c, placement)
+	stored, ok := rel.SubordinatePlacement()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(stored.Units, gc.DeepEquals, []string{"mysql/1"})
+	c.Assert(stored.String(), gc.Equals, "units=mysql/1")
+
+	unit0 := s.addPrincipal(c, rel, "mem=1G")
+	unit1 := s.addPrincipal(c, rel, "mem=1G")
+	s.assertSubordinates(c, unit0)
+	s.assertSubordinates(c, unit1, "logging/0")
+}
+
+func (s *SubordinatePlacementSuite) TestConstraints(c *gc.C) {
+	placement := state.SubordinatePlacement{
+		Constraints: constraints.MustParse("mem=4G tags=monitored"),
+	}
+	rel := s.addRelation(c, placement)
+	err := rel.Refresh()
+	c.Assert(err, gc.IsNil)
+	stored, ok := rel.SubordinatePlacement()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(stored.String(), gc.Equals, "mem=4096M tags=monitored")
+
+	small := s.addPrincipal(c, rel, "mem=2G tags=monitored")
+	untagged := s.addPrincipal(c, rel, "mem=8G")
+	matching := s.addPrincipal(c, rel, "mem=8G tags=web,monitored")
+	s.assertSubordinates(c, small)
+	s.assertSubordinates(c, untagged)
+	s.assertSubordinates(c, matching, "logging/0")
+}
+
+func (s *SubordinatePlacementSuite) TestConstraintsUnassignedPrincipal(c *gc.C) {
+	placement := state.SubordinatePlacement{
+		Constraints: constraints.MustParse("tags=monitored"),
+	}
+	rel := s.addRelation(c, placement)
+	unit, err := s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	s.assertSubordinates(c, unit)
+}
+
+func (s *SubordinatePlacementSuite) TestAddRelationWithPlacementErrors(c *gc.C) {
+	eps, err := s.State.InferEndpoints([]string{"mysql", "logging"})
+	c.Assert(err, gc.IsNil)
+	for i, t := range []struct {
+		placement state.SubordinatePlacement
+		err       string
+	}{{
+		placement: state.SubordinatePlacement{Units: []string{"mysql"}},
+		err:       `invalid subordinate placement: invalid unit name "mysql"`,
+	}, {
+		placement: state.SubordinatePlacement{Units: []string{"logging/0"}},
+		err:       `invalid subordinate placement: unit "logging/0" is not a unit of principal service "mysql"`,
+	}, {
+		placement: state.SubordinatePlacement{Constraints: constraints.MustParse("instance-type=m1.small")},
+		err:       "invalid subordinate placement: cannot place subordinates by instance type",
+	}, {
+		placement: state.SubordinatePlacement{Constraints: constraints.MustParse("container=lxc")},
+		err:       "invalid subordinate placement: cannot place subordinates by container type",
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.State.AddRelationWithPlacement(t.placement, eps...)
+		c.Check(err, gc.ErrorMatches, `cannot add relation "logging:info mysql:juju-info": `+t.err)
+	}
+}
+
+func (s *SubordinatePlacementSuite) TestAddRelationWithPlacementGlobalScope(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	eps, err := s.State.InferEndpoints([]string{"mysql", "wordpress"})
+	c.Assert(err, gc.IsNil)
+	placement := state.SubordinatePlacement{Units: []string{"mysql/0"}}
+	_, err = s.State.AddRelationWithPlacement(placement, eps...)
+	c.Assert(err, gc.ErrorMatches, `cannot add relation "wordpress:db mysql:server": subordinate placement requires a container-scoped relation`)
+}
@@ -79,6 +79,13 @@ type unitDoc struct {
 	TxnRevno     int64 `bson:"txn-revno"`
 	PasswordHash string
 
+	// MigratingTo and MigratingFrom name the other unit taking part
+	// in a migration of this unit; MigrationPhase records how far
+	// the migration has progressed.
+	MigratingTo    string         `bson:",omitempty"`
+	MigratingFrom  string         `bson:",omitempty"`
+	MigrationPhase MigrationPhase `bson:",omitempty"`
+
 	// No longer used - to be removed.
 	PublicAddress  string
 	PrivateAddress string
@@ -923,9 +930,9 @@ func (u *Unit) SetCharmURL(curl *charm.URL) (err error) {
 			// Already set
 			return nil, jujutxn.ErrNoOperations
 		}
-		if count, err := u.st.charms.FindId(curl).Count(); err != nil {
+		if usable, err := isCharmUsable(u.st, curl); err != nil {
 			return nil, err
-		} else if count < 1 {
+		} else if !usable {
 			return nil, fmt.Errorf("unknown charm url %q", curl)
 		}
 
@@ -939,6 +946,7 @@ func (u *Unit) SetCharmURL(curl *charm.URL) (err error) {
 		differentCharm := bson.D{{"charmurl", bson.D{{"$ne", curl}}}}
 		ops := []txn.Op{
 			incOp,
+			useCharmOp(u.st, curl),
 			{
 				C:      u.st.units.Name,
 				Id:     u.doc.Name,
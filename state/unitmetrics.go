@@ -0,0 +1,157 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// Metric is a numeric measurement reported by a unit.
+type Metric struct {
+	Key   string
+	Value float64
+
+	// Time holds the time the unit took the measurement. The
+	// most recent values returned by Unit.Metrics and
+	// Service.UnitMetrics instead hold the time the state server
+	// received them, so they can be compared with its own clock.
+	Time time.Time
+}
+
+var validMetricKey = regexp.MustCompile("^[a-z][a-z0-9]*(?:[_-][a-z0-9]+)*$")
+
+// IsValidMetricKey returns whether key is a valid metric key.
+func IsValidMetricKey(key string) bool {
+	return validMetricKey.MatchString(key)
+}
+
+// unitMetricsDoc holds the most recent value of each metric
+// reported by a unit.
+type unitMetricsDoc struct {
+	UnitName string `bson:"_id"`
+	Service  string
+	Metrics  map[string]metricValueDoc
+}
+
+type metricValueDoc struct {
+	Value float64
+	Time  time.Time
+}
+
+// AddMetrics records the given metrics as a new batch reported by the
+// unit, and as the most recent values reported by the unit. Metrics
+// with keys that the unit has not reported keep their previous values.
+// The most recent values are stamped with the current time rather than
+// the time reported by the unit, whose clock may differ from ours.
+func (u *Unit) AddMetrics(metrics []Metric) (err error) {
+	defer errors.Maskf(&err, "cannot add metrics for unit %q", u)
+	if len(metrics) == 0 {
+		return nil
+	}
+	created := time.Now().UTC()
+	values := make(map[string]metricValueDoc)
+	for _, m := range metrics {
+		if !IsValidMetricKey(m.Key) {
+			return fmt.Errorf("invalid metric key %q", m.Key)
+		}
+		values[m.Key] = metricValueDoc{m.Value, created}
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+				return nil, err
+			} else if !notDead {
+				return nil, errors.New("unit is dead")
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		}, addMetricBatchOp(u, metrics, created)}
+		count, err := u.st.unitMetrics.FindId(u.doc.Name).Count()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return append(ops, txn.Op{
+				C:      u.st.unitMetrics.Name,
+				Id:     u.doc.Name,
+				Assert: txn.DocMissing,
+				Insert: &unitMetricsDoc{
+					UnitName: u.doc.Name,
+					Service:  u.doc.Service,
+					Metrics:  values,
+				},
+			}), nil
+		}
+		var set bson.D
+		for key, value := range values {
+			set = append(set, bson.DocElem{"metrics." + key, value})
+		}
+		return append(ops, txn.Op{
+			C:      u.st.unitMetrics.Name,
+			Id:     u.doc.Name,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", set}},
+		}), nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// Metrics returns the most recent value of each metric reported
+// by the unit.
+func (u *Unit) Metrics() ([]Metric, error) {
+	var doc unitMetricsDoc
+	err := u.st.unitMetrics.FindId(u.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get metrics for unit %q: %v", u, err)
+	}
+	return doc.metrics(), nil
+}
+
+func (doc *unitMetricsDoc) metrics() []Metric {
+	metrics := make([]Metric, 0, len(doc.Metrics))
+	for key, value := range doc.Metrics {
+		metrics = append(metrics, Metric{key, value.Value, value.Time})
+	}
+	return metrics
+}
+
+// UnitMetrics returns the most recent value of the metric with the
+// given key reported by each unit of the service, keyed by unit name.
+// Units that have not reported the metric are omitted.
+func (s *Service) UnitMetrics(key string) (map[string]Metric, error) {
+	var docs []unitMetricsDoc
+	err := s.st.unitMetrics.Find(bson.D{{"service", s.doc.Name}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get unit metrics for service %q: %v", s, err)
+	}
+	metrics := make(map[string]Metric)
+	for _, doc := range docs {
+		if value, ok := doc.Metrics[key]; ok {
+			metrics[doc.UnitName] = Metric{key, value.Value, value.Time}
+		}
+	}
+	return metrics, nil
+}
+
+// removeUnitMetricsOp returns the operation required to remove the
+// metrics reported by the named unit.
+func removeUnitMetricsOp(st *State, unitName string) txn.Op {
+	return txn.Op{
+		C:      st.unitMetrics.Name,
+		Id:     unitName,
+		Remove: true,
+	}
+}
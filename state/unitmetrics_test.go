@@ -0,0 +1,94 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type UnitMetricsSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&UnitMetricsSuite{})
+
+func (s *UnitMetricsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	var err error
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *UnitMetricsSuite) TestAddMetrics(c *gc.C) {
+	metrics, err := s.unit.Metrics()
+	c.Assert(err, gc.IsNil)
+	c.Assert(metrics, gc.HasLen, 0)
+
+	t0 := time.Now().Add(-time.Minute)
+	err = s.unit.AddMetrics([]state.Metric{
+		{Key: "load", Value: 0.5, Time: t0},
+		{Key: "users", Value: 12, Time: t0},
+	})
+	c.Assert(err, gc.IsNil)
+	t1 := time.Now()
+	err = s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 0.9, Time: t1}})
+	c.Assert(err, gc.IsNil)
+
+	metrics, err = s.unit.Metrics()
+	c.Assert(err, gc.IsNil)
+	values := make(map[string]float64)
+	for _, m := range metrics {
+		values[m.Key] = m.Value
+	}
+	c.Assert(values, gc.DeepEquals, map[string]float64{"load": 0.9, "users": 12})
+
+	unit1, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit1.AddMetrics([]state.Metric{{Key: "users", Value: 3, Time: t1}})
+	c.Assert(err, gc.IsNil)
+	byUnit, err := s.service.UnitMetrics("load")
+	c.Assert(err, gc.IsNil)
+	c.Assert(byUnit, gc.HasLen, 1)
+	c.Assert(byUnit["wordpress/0"].Value, gc.Equals, 0.9)
+	// The time the metric was received is recorded, not the
+	// time reported by the unit.
+	received := byUnit["wordpress/0"].Time
+	c.Assert(received.Before(t1.Add(-time.Second)), jc.IsFalse)
+	c.Assert(received.After(time.Now()), jc.IsFalse)
+	byUnit, err = s.service.UnitMetrics("users")
+	c.Assert(err, gc.IsNil)
+	c.Assert(byUnit, gc.HasLen, 2)
+}
+
+func (s *UnitMetricsSuite) TestAddMetricsInvalidKey(c *gc.C) {
+	err := s.unit.AddMetrics([]state.Metric{{Key: "$load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.ErrorMatches, `cannot add metrics for unit "wordpress/0": invalid metric key "\$load"`)
+}
+
+func (s *UnitMetricsSuite) TestAddMetricsDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.ErrorMatches, `cannot add metrics for unit "wordpress/0": unit is dead`)
+}
+
+func (s *UnitMetricsSuite) TestRemoveUnitRemovesMetrics(c *gc.C) {
+	err := s.unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+	byUnit, err := s.service.UnitMetrics("load")
+	c.Assert(err, gc.IsNil)
+	c.Assert(byUnit, gc.HasLen, 0)
+}
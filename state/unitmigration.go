@@ -0,0 +1,456 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// MigrationPhase describes how far the migration of a unit to a new
+// machine has progressed.
+type MigrationPhase string
+
+const (
+	// MigrationProvisioning indicates that the target unit of a
+	// migration is being deployed, and has not yet started.
+	MigrationProvisioning MigrationPhase = "provisioning"
+
+	// MigrationExporting indicates that the target unit has started,
+	// and that the source unit should run its migrate-out hook.
+	MigrationExporting MigrationPhase = "exporting"
+
+	// MigrationImporting indicates that the source unit has exported
+	// its data, and that the target unit should run its migrate-in hook.
+	MigrationImporting MigrationPhase = "importing"
+
+	// MigrationHandingOver indicates that the target unit has imported
+	// the source unit's data, and will take over from the source unit
+	// once it has joined all of the source unit's relations.
+	MigrationHandingOver MigrationPhase = "handing-over"
+)
+
+// migrationPhases holds the phases of a migration, in order.
+var migrationPhases = []MigrationPhase{
+	MigrationProvisioning,
+	MigrationExporting,
+	MigrationImporting,
+	MigrationHandingOver,
+}
+
+// migrationSourcePhases holds the phases that only the source unit of a
+// migration may advance to; every other phase is advanced to by the target.
+var migrationSourcePhases = map[MigrationPhase]bool{
+	MigrationImporting: true,
+}
+
+// migrationPhaseIndex returns the position of the phase in
+// migrationPhases, or -1 if the phase is not known.
+func migrationPhaseIndex(phase MigrationPhase) int {
+	for i, p := range migrationPhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+var errNotMigrating = stderrors.New("unit is not migrating")
+
+// notMigratingDoc asserts that a unit is not taking part in a migration.
+var notMigratingDoc = bson.D{
+	{"migratingto", bson.D{{"$exists", false}}},
+	{"migratingfrom", bson.D{{"$exists", false}}},
+}
+
+// clearMigration removes all record of a migration from a unit document.
+var clearMigration = bson.D{{"$unset", bson.D{
+	{"migratingto", nil},
+	{"migratingfrom", nil},
+	{"migrationphase", nil},
+}}}
+
+// MigratingTo returns the name of the unit that u is being migrated to,
+// and whether u is the source of a migration.
+func (u *Unit) MigratingTo() (string, bool) {
+	return u.doc.MigratingTo, u.doc.MigratingTo != ""
+}
+
+// MigratingFrom returns the name of the unit that is being migrated to u,
+// and whether u is the target of a migration.
+func (u *Unit) MigratingFrom() (string, bool) {
+	return u.doc.MigratingFrom, u.doc.MigratingFrom != ""
+}
+
+// MigrationPhase returns the phase of the migration that u is taking part
+// in. It returns an empty phase if u is not migrating.
+func (u *Unit) MigrationPhase() MigrationPhase {
+	return u.doc.MigrationPhase
+}
+
+// migrationUnits returns the names of the source and target units of the
+// migration that u is taking part in.
+func (u *Unit) migrationUnits() (source, target string, err error) {
+	switch {
+	case u.doc.MigratingTo != "":
+		return u.doc.Name, u.doc.MigratingTo, nil
+	case u.doc.MigratingFrom != "":
+		return u.doc.MigratingFrom, u.doc.Name, nil
+	}
+	return "", "", errNotMigrating
+}
+
+// migrationOps returns operations that assert that the source and target
+// units are migrating in the given phase, and apply the supplied update
+// to both unit documents.
+func migrationOps(st *State, source, target string, phase MigrationPhase, update bson.D) []txn.Op {
+	return []txn.Op{{
+		C:      st.units.Name,
+		Id:     source,
+		Assert: bson.D{{"migratingto", target}, {"migrationphase", phase}},
+		Update: update,
+	}, {
+		C:      st.units.Name,
+		Id:     target,
+		Assert: bson.D{{"migratingfrom", source}, {"migrationphase", phase}},
+		Update: update,
+	}}
+}
+
+// MigrateTo starts the migration of the unit to the given machine. A new
+// unit of the same service is added and assigned to the machine; once it
+// has started, the units run the migrate-out and migrate-in hooks in turn
+// so the charm can transfer the unit's data, after which the new unit
+// takes over the unit's relations and opened ports and the unit is
+// destroyed. The new unit is returned.
+func (u *Unit) MigrateTo(m *Machine) (target *Unit, err error) {
+	defer errors.Maskf(&err, "cannot migrate unit %q to machine %s", u, m)
+	if !u.IsPrincipal() {
+		return nil, fmt.Errorf("unit is a subordinate")
+	}
+	svc, err := u.Service()
+	if err != nil {
+		return nil, err
+	}
+	unit := &Unit{st: u.st, doc: u.doc}
+	var name string
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := unit.Refresh(); err != nil {
+				return nil, err
+			}
+			if err := svc.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case unit.doc.Life != Alive:
+			return nil, unitNotAliveErr
+		case svc.doc.Life != Alive:
+			return nil, fmt.Errorf("service is not alive")
+		case unit.doc.MigratingTo != "":
+			return nil, fmt.Errorf("unit is already migrating to %q", unit.doc.MigratingTo)
+		case unit.doc.MigratingFrom != "":
+			return nil, fmt.Errorf("unit is being migrated from %q", unit.doc.MigratingFrom)
+		case unit.doc.MachineId == "":
+			return nil, fmt.Errorf("unit is not assigned to a machine")
+		case unit.doc.MachineId == m.Id():
+			return nil, fmt.Errorf("unit is already assigned to machine %s", m)
+		}
+		var ops []txn.Op
+		var err error
+		name, ops, err = svc.addUnitOps("", nil)
+		if err != nil {
+			return nil, err
+		}
+		udoc := ops[0].Insert.(*unitDoc)
+		udoc.MigratingFrom = unit.doc.Name
+		udoc.MigrationPhase = MigrationProvisioning
+		return append(ops, txn.Op{
+			C:      u.st.units.Name,
+			Id:     unit.doc.Name,
+			Assert: append(isAliveDoc, notMigratingDoc...),
+			Update: bson.D{{"$set", bson.D{
+				{"migratingto", name},
+				{"migrationphase", MigrationProvisioning},
+			}}},
+		}), nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return nil, err
+	}
+	u.doc = unit.doc
+	u.doc.MigratingTo = name
+	u.doc.MigrationPhase = MigrationProvisioning
+	if target, err = u.st.Unit(name); err != nil {
+		return nil, err
+	}
+	if err := target.AssignToMachine(m); err != nil {
+		if abortErr := target.AbortMigration(); abortErr != nil {
+			unitLogger.Errorf("cannot abort migration to unit %q: %v", target, abortErr)
+		} else {
+			u.doc.MigratingTo = ""
+			u.doc.MigrationPhase = ""
+		}
+		return nil, err
+	}
+	return target, nil
+}
+
+// SetMigrationPhase advances the migration that the unit is taking part
+// in to the given phase. Phases must be advanced in order, and only by
+// the unit that owns them: the source unit advances the migration to
+// MigrationImporting once it has exported its data, and the target unit
+// advances it to every other phase. Setting a phase the migration has
+// already reached has no effect.
+func (u *Unit) SetMigrationPhase(phase MigrationPhase) (err error) {
+	defer errors.Maskf(&err, "cannot set migration phase of unit %q", u)
+	unit := &Unit{st: u.st, doc: u.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := unit.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		source, target, err := unit.migrationUnits()
+		if err != nil {
+			return nil, err
+		}
+		current := unit.doc.MigrationPhase
+		index := migrationPhaseIndex(phase)
+		isSource := unit.doc.Name == source
+		switch currentIndex := migrationPhaseIndex(current); {
+		case index == -1:
+			return nil, fmt.Errorf("unknown migration phase %q", phase)
+		case isSource != migrationSourcePhases[phase]:
+			owner := target
+			if migrationSourcePhases[phase] {
+				owner = source
+			}
+			return nil, fmt.Errorf("only unit %q can set migration phase %q", owner, phase)
+		case index <= currentIndex:
+			return nil, jujutxn.ErrNoOperations
+		case index != currentIndex+1:
+			return nil, fmt.Errorf("cannot change migration phase from %q to %q", current, phase)
+		}
+		update := bson.D{{"$set", bson.D{{"migrationphase", phase}}}}
+		return migrationOps(u.st, source, target, current, update), nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return err
+	}
+	u.doc = unit.doc
+	if migrationPhaseIndex(phase) > migrationPhaseIndex(u.doc.MigrationPhase) {
+		u.doc.MigrationPhase = phase
+	}
+	return nil
+}
+
+// CompleteMigration hands over from the source unit of the migration to
+// u, its target. The target must have joined every live relation that the
+// source is in scope of; the ports opened by the source are opened by
+// the target, and the source is destroyed.
+func (u *Unit) CompleteMigration() (err error) {
+	defer errors.Maskf(&err, "cannot complete migration to unit %q", u)
+	target := &Unit{st: u.st, doc: u.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := target.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		sourceName, ok := target.MigratingFrom()
+		if !ok {
+			return nil, fmt.Errorf("unit is not the target of a migration")
+		}
+		if target.doc.MigrationPhase != MigrationHandingOver {
+			return nil, fmt.Errorf("migration is %s, not %s", target.doc.MigrationPhase, MigrationHandingOver)
+		}
+		source, err := u.st.Unit(sourceName)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRelationsHandedOver(source, target); err != nil {
+			return nil, err
+		}
+		portsOps, portsUpdate, err := handOverPortsOps(u.st, source, target)
+		if err != nil {
+			return nil, err
+		}
+		sourceAssert := bson.D{{"migratingto", target.doc.Name}, {"migrationphase", MigrationHandingOver}}
+		sourceUpdate := clearMigration
+		var destroyOps []txn.Op
+		if source.doc.Life == Alive {
+			// The source has been running its agent, so it is set to
+			// Dying and left for its agent to clean up.
+			sourceAssert = append(sourceAssert, isAliveDoc...)
+			sourceUpdate = append(bson.D{{"$set", bson.D{{"life", Dying}}}}, clearMigration...)
+			destroyOps = []txn.Op{
+				u.st.newCleanupOp(cleanupDyingUnit, source.doc.Name),
+				minUnitsTriggerOp(u.st, source.doc.Service),
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     source.doc.Name,
+			Assert: sourceAssert,
+			Update: sourceUpdate,
+		}, {
+			C:      u.st.units.Name,
+			Id:     target.doc.Name,
+			Assert: bson.D{{"migratingfrom", source.doc.Name}, {"migrationphase", MigrationHandingOver}},
+			Update: append(portsUpdate, clearMigration...),
+		}}
+		ops = append(ops, portsOps...)
+		return append(ops, destroyOps...), nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return err
+	}
+	u.doc = target.doc
+	u.doc.MigratingFrom = ""
+	u.doc.MigrationPhase = ""
+	return nil
+}
+
+// checkRelationsHandedOver returns an error if the target unit has not yet
+// entered scope of every live relation the source unit is in scope of.
+func checkRelationsHandedOver(source, target *Unit) error {
+	relations, err := source.RelationsInScope()
+	if err != nil {
+		return err
+	}
+	for _, rel := range relations {
+		if rel.Life() != Alive {
+			continue
+		}
+		ru, err := rel.Unit(target)
+		if err != nil {
+			return err
+		}
+		if inScope, err := ru.InScope(); err != nil {
+			return err
+		} else if !inScope {
+			return fmt.Errorf("unit %q has not joined relation %q", target, rel)
+		}
+	}
+	return nil
+}
+
+// handOverPortsOps returns operations that open every port opened by the
+// source unit on the target unit's machine, together with the update that
+// records those ports on the target unit document.
+func handOverPortsOps(st *State, source, target *Unit) ([]txn.Op, bson.D, error) {
+	opened := make(map[network.Port]bool)
+	for _, port := range target.OpenedPorts() {
+		opened[port] = true
+	}
+	var ranges []PortRange
+	var unitPorts []network.Port
+	for _, port := range source.OpenedPorts() {
+		if opened[port] {
+			continue
+		}
+		portRange, err := NewPortRange(target.doc.Name, port.Number, port.Number, port.Protocol)
+		if err != nil {
+			return nil, nil, err
+		}
+		ranges = append(ranges, portRange)
+		unitPorts = append(unitPorts, port)
+	}
+	if len(ranges) == 0 {
+		return nil, nil, nil
+	}
+	machineId, err := target.AssignedMachineId()
+	if err != nil {
+		return nil, nil, err
+	}
+	machinePorts, err := getOrCreatePorts(st, machineId)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, portRange := range ranges {
+		if !machinePorts.canOpenPorts(portRange) {
+			return nil, nil, fmt.Errorf("cannot open ports %v on machine %v due to conflict", portRange, machineId)
+		}
+	}
+	update := bson.D{{"$addToSet", bson.D{{"ports", bson.D{{"$each", unitPorts}}}}}}
+	if machinePorts.new {
+		return addPortsDocOps(st, machineId, ranges...), update, nil
+	}
+	ops := []txn.Op{{
+		C:      st.machines.Name,
+		Id:     machineId,
+		Assert: notDeadDoc,
+	}, {
+		C:      st.openedPorts.Name,
+		Id:     machinePorts.Id(),
+		Assert: bson.D{{"txn-revno", machinePorts.doc.TxnRevno}},
+		Update: bson.D{{"$addToSet", bson.D{{"ports", bson.D{{"$each", ranges}}}}}},
+	}}
+	return ops, update, nil
+}
+
+// AbortMigration abandons the migration that the unit is taking part in.
+// The target unit is destroyed, and the source unit carries on as if the
+// migration had never started.
+func (u *Unit) AbortMigration() (err error) {
+	defer errors.Maskf(&err, "cannot abort migration of unit %q", u)
+	unit := &Unit{st: u.st, doc: u.doc}
+	var target *Unit
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := unit.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		sourceName, targetName, err := unit.migrationUnits()
+		if err != nil {
+			return nil, err
+		}
+		// Either unit may already have been removed, in which case
+		// only the other needs to be updated.
+		var ops []txn.Op
+		if _, err := u.st.Unit(sourceName); err == nil {
+			ops = append(ops, txn.Op{
+				C:      u.st.units.Name,
+				Id:     sourceName,
+				Assert: bson.D{{"migratingto", targetName}},
+				Update: clearMigration,
+			})
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if target, err = u.st.Unit(targetName); err == nil {
+			ops = append(ops, txn.Op{
+				C:      u.st.units.Name,
+				Id:     targetName,
+				Assert: bson.D{{"migratingfrom", sourceName}},
+				Update: clearMigration,
+			})
+		} else if errors.IsNotFound(err) {
+			target = nil
+		} else {
+			return nil, err
+		}
+		return ops, nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return err
+	}
+	u.doc.MigratingTo = ""
+	u.doc.MigratingFrom = ""
+	u.doc.MigrationPhase = ""
+	if target != nil {
+		return target.Destroy()
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type UnitMigrationSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+	machine *state.Machine
+}
+
+var _ = gc.Suite(&UnitMigrationSuite{})
+
+func (s *UnitMigrationSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	var err error
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	source, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AssignToMachine(source)
+	c.Assert(err, gc.IsNil)
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *UnitMigrationSuite) assertMigrating(c *gc.C, source, target *state.Unit, phase state.MigrationPhase) {
+	for _, u := range []*state.Unit{source, target} {
+		err := u.Refresh()
+		c.Assert(err, gc.IsNil)
+		c.Assert(u.MigrationPhase(), gc.Equals, phase)
+	}
+	name, ok := source.MigratingTo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(name, gc.Equals, target.Name())
+	name, ok = target.MigratingFrom()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(name, gc.Equals, source.Name())
+}
+
+func (s *UnitMigrationSuite) assertNotMigrating(c *gc.C, u *state.Unit) {
+	err := u.Refresh()
+	c.Assert(err, gc.IsNil)
+	_, ok := u.MigratingTo()
+	c.Assert(ok, jc.IsFalse)
+	_, ok = u.MigratingFrom()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(u.MigrationPhase(), gc.Equals, state.MigrationPhase(""))
+}
+
+func (s *UnitMigrationSuite) TestMigrateTo(c *gc.C) {
+	target, err := s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.IsNil)
+	c.Assert(target.Name(), gc.Equals, "wordpress/1")
+	machineId, err := target.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machineId, gc.Equals, s.machine.Id())
+	s.assertMigrating(c, s.unit, target, state.MigrationProvisioning)
+}
+
+func (s *UnitMigrationSuite) TestMigrateToErrors(c *gc.C) {
+	_, err := s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.IsNil)
+	_, err = s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "wordpress/0" to machine 1: unit is already migrating to "wordpress/1"`)
+
+	unit, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	_, err = unit.MigrateTo(s.machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "wordpress/2" to machine 1: unit is not assigned to a machine`)
+	err = unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+	_, err = unit.MigrateTo(s.machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "wordpress/2" to machine 1: unit is already assigned to machine 1`)
+
+	err = unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	err = unit.Destroy()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = unit.MigrateTo(machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "wordpress/2" to machine 2: unit is not alive`)
+}
+
+func (s *UnitMigrationSuite) TestMigrateSubordinate(c *gc.C) {
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "logging"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(s.unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	subordinate, err := s.State.Unit("logging/0")
+	c.Assert(err, gc.IsNil)
+	_, err = subordinate.MigrateTo(s.machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "logging/0" to machine 1: unit is a subordinate`)
+}
+
+func (s *UnitMigrationSuite) TestMigrateToAbortsWhenAssignmentFails(c *gc.C) {
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = s.unit.MigrateTo(machine)
+	c.Assert(err, gc.ErrorMatches, `cannot migrate unit "wordpress/0" to machine 2: cannot assign unit "wordpress/1" to machine 2: series does not match`)
+	s.assertNotMigrating(c, s.unit)
+	_, err = s.State.Unit("wordpress/1")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UnitMigrationSuite) TestSetMigrationPhase(c *gc.C) {
+	err := s.unit.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/0": unit is not migrating`)
+
+	target, err := s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetMigrationPhase(state.MigrationImporting)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/0": cannot change migration phase from "provisioning" to "importing"`)
+
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+	s.assertMigrating(c, s.unit, target, state.MigrationExporting)
+	err = s.unit.SetMigrationPhase(state.MigrationImporting)
+	c.Assert(err, gc.IsNil)
+	s.assertMigrating(c, s.unit, target, state.MigrationImporting)
+
+	// Setting a phase that has already been reached has no effect.
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetMigrationPhase(state.MigrationImporting)
+	c.Assert(err, gc.IsNil)
+	s.assertMigrating(c, s.unit, target, state.MigrationImporting)
+
+	err = target.SetMigrationPhase("flying")
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/1": unknown migration phase "flying"`)
+}
+
+func (s *UnitMigrationSuite) TestSetMigrationPhaseOwner(c *gc.C) {
+	target, err := s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/0": only unit "wordpress/1" can set migration phase "exporting"`)
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+
+	err = target.SetMigrationPhase(state.MigrationImporting)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/1": only unit "wordpress/0" can set migration phase "importing"`)
+	err = s.unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetMigrationPhase(state.MigrationImporting)
+	c.Assert(err, gc.IsNil)
+
+	err = s.unit.SetMigrationPhase(state.MigrationHandingOver)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase of unit "wordpress/0": only unit "wordpress/1" can set migration phase "handing-over"`)
+	err = target.Refresh()
+	c.Assert(err, gc.IsNil)
+	s.assertMigrating(c, s.unit, target, state.MigrationImporting)
+}
+
+func (s *UnitMigrationSuite) startMigration(c *gc.C, phase state.MigrationPhase) *state.Unit {
+	target, err := s.unit.MigrateTo(s.machine)
+	c.Assert(err, gc.IsNil)
+	for _, p := range []state.MigrationPhase{
+		state.MigrationExporting, state.MigrationImporting, state.MigrationHandingOver,
+	} {
+		if target.MigrationPhase() == phase {
+			break
+		}
+		owner := target
+		if p == state.MigrationImporting {
+			owner = s.unit
+		}
+		err := owner.Refresh()
+		c.Assert(err, gc.IsNil)
+		err = owner.SetMigrationPhase(p)
+		c.Assert(err, gc.IsNil)
+		err = target.Refresh()
+		c.Assert(err, gc.IsNil)
+	}
+	return target
+}
+
+func (s *UnitMigrationSuite) TestCompleteMigration(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(s.unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.OpenPort("tcp", 80)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+
+	target := s.startMigration(c, state.MigrationImporting)
+	err = target.CompleteMigration()
+	c.Assert(err, gc.ErrorMatches, `cannot complete migration to unit "wordpress/1": migration is importing, not handing-over`)
+
+	err = target.SetMigrationPhase(state.MigrationHandingOver)
+	c.Assert(err, gc.IsNil)
+	err = target.CompleteMigration()
+	c.Assert(err, gc.ErrorMatches, `cannot complete migration to unit "wordpress/1": unit "wordpress/1" has not joined relation "wordpress:db mysql:server"`)
+
+	ru, err = rel.Unit(target)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	err = target.CompleteMigration()
+	c.Assert(err, gc.IsNil)
+
+	s.assertNotMigrating(c, s.unit)
+	s.assertNotMigrating(c, target)
+	c.Assert(s.unit.Life(), gc.Equals, state.Dying)
+	c.Assert(target.Life(), gc.Equals, state.Alive)
+	c.Assert(target.OpenedPorts(), gc.DeepEquals, []network.Port{{Protocol: "tcp", Number: 80}})
+}
+
+func (s *UnitMigrationSuite) TestCompleteMigrationNotMigrating(c *gc.C) {
+	err := s.unit.CompleteMigration()
+	c.Assert(err, gc.ErrorMatches, `cannot complete migration to unit "wordpress/0": unit is not the target of a migration`)
+}
+
+func (s *UnitMigrationSuite) TestAbortMigration(c *gc.C) {
+	target := s.startMigration(c, state.MigrationExporting)
+	err := s.unit.AbortMigration()
+	c.Assert(err, gc.IsNil)
+	s.assertNotMigrating(c, s.unit)
+	c.Assert(s.unit.Life(), gc.Equals, state.Alive)
+	// The target never started, so it is removed directly.
+	err = target.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.unit.AbortMigration()
+	c.Assert(err, gc.ErrorMatches, `cannot abort migration of unit "wordpress/0": unit is not migrating`)
+}
+
+func (s *UnitMigrationSuite) TestAbortMigrationFromTarget(c *gc.C) {
+	target := s.startMigration(c, state.MigrationImporting)
+	err := target.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	err = target.AbortMigration()
+	c.Assert(err, gc.IsNil)
+	s.assertNotMigrating(c, s.unit)
+	s.assertNotMigrating(c, target)
+	c.Assert(target.Life(), gc.Equals, state.Dying)
+}
+
+func (s *UnitMigrationSuite) TestAbortMigrationTargetRemoved(c *gc.C) {
+	target := s.startMigration(c, state.MigrationProvisioning)
+	err := target.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AbortMigration()
+	c.Assert(err, gc.IsNil)
+	s.assertNotMigrating(c, s.unit)
+}
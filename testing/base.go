@@ -36,6 +36,7 @@ func (s *JujuOSEnvSuite) SetUpTest(c *gc.C) {
 		osenv.JujuHomeEnvKey,
 		osenv.JujuEnvEnvKey,
 		osenv.JujuLoggingConfigEnvKey,
+		osenv.JujuKeyringPassphraseEnvKey,
 	} {
 		s.oldEnvironment[name] = os.Getenv(name)
 		os.Setenv(name, "")
@@ -0,0 +1,78 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package vcs offers a common interface to branches of the version
+// control systems that charms may be published from.
+package vcs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/juju/bzr"
+	"github.com/juju/juju/git"
+)
+
+// Branch represents a branch in a version control system.
+type Branch interface {
+	// Kind returns the name of the version control system,
+	// such as "bzr" or "git".
+	Kind() string
+
+	// Location returns the location of the branch.
+	Location() string
+
+	// CheckClean returns an error if the branch has uncommitted changes.
+	CheckClean() error
+
+	// RevisionId returns the revision id for the tip of the branch.
+	RevisionId() (string, error)
+
+	// PushLocation returns the default push location for the branch.
+	PushLocation() (string, error)
+
+	// Push pushes any new revisions in the branch to location,
+	// and remembers it as the default push location.
+	Push(location string) error
+}
+
+// Open returns the branch at location, which may be any directory
+// inside a Bazaar branch or a Git working tree.
+func Open(location string) (Branch, error) {
+	if b := bzr.New(location); exists(b.Join(".bzr")) {
+		return bzrBranch{b}, nil
+	}
+	if b := git.New(location); exists(b.Join(".git")) {
+		return gitBranch{b}, nil
+	}
+	return nil, fmt.Errorf("not a bzr or git branch: %s", location)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+type bzrBranch struct {
+	*bzr.Branch
+}
+
+func (bzrBranch) Kind() string {
+	return "bzr"
+}
+
+func (b bzrBranch) Push(location string) error {
+	return b.Branch.Push(&bzr.PushAttr{Location: location, Remember: true})
+}
+
+type gitBranch struct {
+	*git.Branch
+}
+
+func (gitBranch) Kind() string {
+	return "git"
+}
+
+func (b gitBranch) Push(location string) error {
+	return b.Branch.Push(&git.PushAttr{Location: location, Remember: true})
+}
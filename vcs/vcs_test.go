@@ -0,0 +1,52 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package vcs_test
+
+import (
+	"os"
+	stdtesting "testing"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/bzr"
+	"github.com/juju/juju/git"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/vcs"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+var _ = gc.Suite(&VCSSuite{})
+
+type VCSSuite struct {
+	testing.BaseSuite
+}
+
+func (s *VCSSuite) TestOpenBzr(c *gc.C) {
+	b := bzr.New(c.MkDir())
+	c.Assert(b.Init(), gc.IsNil)
+	branch, err := vcs.Open(b.Location())
+	c.Assert(err, gc.IsNil)
+	c.Assert(branch.Kind(), gc.Equals, "bzr")
+	c.Assert(branch.Location(), gc.Equals, b.Location())
+}
+
+func (s *VCSSuite) TestOpenGit(c *gc.C) {
+	b := git.New(c.MkDir())
+	c.Assert(b.Init(), gc.IsNil)
+	err := os.Mkdir(b.Join("dir"), 0755)
+	c.Assert(err, gc.IsNil)
+	branch, err := vcs.Open(b.Join("dir"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(branch.Kind(), gc.Equals, "git")
+	c.Assert(branch.Location(), gc.Equals, git.New(b.Join("dir")).Location())
+}
+
+func (s *VCSSuite) TestOpenNoBranch(c *gc.C) {
+	dir := c.MkDir()
+	_, err := vcs.Open(dir)
+	c.Assert(err, gc.ErrorMatches, "not a bzr or git branch: "+dir)
+}
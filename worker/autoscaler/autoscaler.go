@@ -0,0 +1,219 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package autoscaler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.autoscaler")
+
+// interval holds how often the autoscaled services are evaluated.
+var interval = time.Minute
+
+// metricMaxAge holds the age beyond which a unit's reported
+// metric value is no longer taken into account.
+var metricMaxAge = 5 * time.Minute
+
+var _ worker.Worker = (*Autoscaler)(nil)
+
+// Autoscaler periodically adds and removes units of the services
+// that have an autoscale policy, according to the metrics reported
+// by their units.
+type Autoscaler struct {
+	st   *state.State
+	tomb tomb.Tomb
+}
+
+// NewAutoscaler returns a worker that autoscales services.
+func NewAutoscaler(st *state.State) *Autoscaler {
+	a := &Autoscaler{st: st}
+	go func() {
+		defer a.tomb.Done()
+		a.tomb.Kill(a.loop())
+	}()
+	return a
+}
+
+func (a *Autoscaler) String() string {
+	return "autoscaler"
+}
+
+// Stop stops the worker.
+func (a *Autoscaler) Stop() error {
+	a.tomb.Kill(nil)
+	return a.tomb.Wait()
+}
+
+// Kill is defined on the worker.Worker interface.
+func (a *Autoscaler) Kill() {
+	a.tomb.Kill(nil)
+}
+
+// Wait is defined on the worker.Worker interface.
+func (a *Autoscaler) Wait() error {
+	return a.tomb.Wait()
+}
+
+func (a *Autoscaler) loop() error {
+	for {
+		a.autoscaleAll()
+		select {
+		case <-a.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (a *Autoscaler) autoscaleAll() {
+	serviceNames, err := a.st.AutoscaledServices()
+	if err != nil {
+		logger.Errorf("%v", err)
+		return
+	}
+	now := time.Now()
+	for _, name := range serviceNames {
+		if err := a.autoscale(name, now); err != nil {
+			logger.Errorf("cannot autoscale service %q: %v", name, err)
+		}
+	}
+}
+
+// autoscale evaluates the named service's autoscale policy,
+// and adds or removes units of the service as necessary.
+func (a *Autoscaler) autoscale(serviceName string, now time.Time) error {
+	service, err := a.st.Service(serviceName)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if service.Life() != state.Alive {
+		return nil
+	}
+	policy, err := service.AutoscalePolicy()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// The service's own minimum number of units is never undercut,
+	// even when it is above the minimum allowed by the policy.
+	if min := service.MinUnits(); min > policy.MinUnits {
+		policy.MinUnits = min
+	}
+	history, err := service.AutoscaleHistory()
+	if err != nil {
+		return err
+	}
+	var lastScaled time.Time
+	if len(history) > 0 {
+		lastScaled = history[0].Time
+	}
+	allUnits, err := service.AllUnits()
+	if err != nil {
+		return err
+	}
+	var units []*state.Unit
+	for _, unit := range allUnits {
+		if unit.Life() == state.Alive {
+			units = append(units, unit)
+		}
+	}
+	metrics, err := service.UnitMetrics(policy.Metric)
+	if err != nil {
+		return err
+	}
+	var values []float64
+	for _, unit := range units {
+		if metric, ok := metrics[unit.Name()]; ok && now.Sub(metric.Time) <= metricMaxAge {
+			values = append(values, metric.Value)
+		}
+	}
+	target, reason := decide(policy, len(units), values, lastScaled, now)
+	if target == len(units) {
+		return nil
+	}
+	logger.Infof("scaling service %q from %d to %d units: %s", serviceName, len(units), target, reason)
+	if target > len(units) {
+		if _, err := juju.AddUnits(a.st, service, target-len(units), ""); err != nil {
+			return err
+		}
+	} else {
+		sortUnitsNewestFirst(units)
+		for _, unit := range units[:len(units)-target] {
+			if err := unit.Destroy(); err != nil {
+				return err
+			}
+		}
+	}
+	return service.RecordAutoscaleDecision(state.AutoscaleDecision{
+		Time:   now,
+		From:   len(units),
+		To:     target,
+		Reason: reason,
+	})
+}
+
+// decide returns the number of units a service with the given
+// policy and number of alive units should have, and the reason
+// for any change. values holds the recent values of the policy's
+// metric reported by the units, and lastScaled holds the time of
+// the previous scaling decision.
+func decide(policy state.AutoscalePolicy, count int, values []float64, lastScaled, now time.Time) (int, string) {
+	switch {
+	case count < policy.MinUnits:
+		return policy.MinUnits, fmt.Sprintf("%d units is below the minimum of %d", count, policy.MinUnits)
+	case count > policy.MaxUnits:
+		return policy.MaxUnits, fmt.Sprintf("%d units is above the maximum of %d", count, policy.MaxUnits)
+	case len(values) == 0:
+		return count, ""
+	case now.Sub(lastScaled) < policy.Cooldown:
+		return count, ""
+	}
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	average := total / float64(len(values))
+	switch {
+	case average > policy.ScaleUpThreshold && count < policy.MaxUnits:
+		return count + 1, fmt.Sprintf("average %s of %.4g is above %.4g", policy.Metric, average, policy.ScaleUpThreshold)
+	case average < policy.ScaleDownThreshold && count > policy.MinUnits:
+		return count - 1, fmt.Sprintf("average %s of %.4g is below %.4g", policy.Metric, average, policy.ScaleDownThreshold)
+	}
+	return count, ""
+}
+
+// sortUnitsNewestFirst sorts the units of a service so that the
+// most recently added units come first.
+func sortUnitsNewestFirst(units []*state.Unit) {
+	sort.Sort(sort.Reverse(byUnitNumber(units)))
+}
+
+type byUnitNumber []*state.Unit
+
+func (u byUnitNumber) Len() int      { return len(u) }
+func (u byUnitNumber) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u byUnitNumber) Less(i, j int) bool {
+	return unitNumber(u[i].Name()) < unitNumber(u[j].Name())
+}
+
+func unitNumber(unitName string) int {
+	n, _ := strconv.Atoi(unitName[strings.LastIndex(unitName, "/")+1:])
+	return n
+}
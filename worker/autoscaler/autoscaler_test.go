@@ -0,0 +1,238 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package autoscaler_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/autoscaler"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type autoscalerSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&autoscalerSuite{})
+
+var testPolicy = state.AutoscalePolicy{
+	MinUnits:           1,
+	MaxUnits:           3,
+	Metric:             "load",
+	ScaleUpThreshold:   0.8,
+	ScaleDownThreshold: 0.2,
+	Cooldown:           5 * time.Minute,
+}
+
+var now = time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC)
+
+var decideTests = []struct {
+	about      string
+	count      int
+	values     []float64
+	lastScaled time.Time
+	target     int
+	reason     string
+}{{
+	about:  "below minimum",
+	count:  0,
+	target: 1,
+	reason: "0 units is below the minimum of 1",
+}, {
+	about:  "above maximum",
+	count:  5,
+	values: []float64{0.9},
+	target: 3,
+	reason: "5 units is above the maximum of 3",
+}, {
+	about:  "no metrics",
+	count:  2,
+	target: 2,
+}, {
+	about:  "scale up",
+	count:  2,
+	values: []float64{0.7, 1.1},
+	target: 3,
+	reason: "average load of 0.9 is above 0.8",
+}, {
+	about:  "scale up at maximum",
+	count:  3,
+	values: []float64{1},
+	target: 3,
+}, {
+	about:  "scale down",
+	count:  2,
+	values: []float64{0.1, 0.2},
+	target: 1,
+	reason: "average load of 0.15 is below 0.2",
+}, {
+	about:  "scale down at minimum",
+	count:  1,
+	values: []float64{0},
+	target: 1,
+}, {
+	about:  "within thresholds",
+	count:  2,
+	values: []float64{0.5},
+	target: 2,
+}, {
+	about:      "cooling down",
+	count:      2,
+	values:     []float64{1},
+	lastScaled: now.Add(-time.Minute),
+	target:     2,
+}, {
+	about:      "cooled down",
+	count:      2,
+	values:     []float64{1},
+	lastScaled: now.Add(-10 * time.Minute),
+	target:     3,
+	reason:     "average load of 1 is above 0.8",
+}, {
+	about:      "bounds ignore cooldown",
+	count:      0,
+	lastScaled: now.Add(-time.Minute),
+	target:     1,
+	reason:     "0 units is below the minimum of 1",
+}}
+
+func (s *autoscalerSuite) TestDecide(c *gc.C) {
+	for i, t := range decideTests {
+		c.Logf("test %d: %s", i, t.about)
+		target, reason := autoscaler.Decide(testPolicy, t.count, t.values, t.lastScaled, now)
+		c.Check(target, gc.Equals, t.target)
+		c.Check(reason, gc.Equals, t.reason)
+	}
+}
+
+// addMetric reports a value of the load metric for the unit.
+// The state server stamps the value with the time it is added.
+func (s *autoscalerSuite) addMetric(c *gc.C, unit *state.Unit, value float64) {
+	err := unit.AddMetrics([]state.Metric{{Key: "load", Value: value, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *autoscalerSuite) aliveUnitNames(c *gc.C, service *state.Service) []string {
+	units, err := service.AllUnits()
+	c.Assert(err, gc.IsNil)
+	var names []string
+	for _, unit := range units {
+		if unit.Life() == state.Alive {
+			names = append(names, unit.Name())
+		}
+	}
+	return names
+}
+
+func (s *autoscalerSuite) TestAutoscaleUpAndDown(c *gc.C) {
+	// The evaluation times below are ahead of the times the
+	// metrics are received, so don't let them go stale.
+	s.PatchValue(autoscaler.MetricMaxAge, time.Hour)
+	start := time.Now().Truncate(time.Second)
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := wordpress.SetAutoscalePolicy(testPolicy)
+	c.Assert(err, gc.IsNil)
+
+	// The service is first scaled up to its minimum.
+	err = autoscaler.AutoscaleService(s.State, "wordpress", start)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.DeepEquals, []string{"wordpress/0"})
+
+	// High load adds a unit, once the cooldown has passed.
+	unit0, err := s.State.Unit("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	s.addMetric(c, unit0, 0.95)
+	err = autoscaler.AutoscaleService(s.State, "wordpress", start.Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.HasLen, 1)
+	later := start.Add(6 * time.Minute)
+	s.addMetric(c, unit0, 0.95)
+	err = autoscaler.AutoscaleService(s.State, "wordpress", later)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.DeepEquals, []string{"wordpress/0", "wordpress/1"})
+
+	// Low load removes the newest unit.
+	unit1, err := s.State.Unit("wordpress/1")
+	c.Assert(err, gc.IsNil)
+	later = later.Add(6 * time.Minute)
+	s.addMetric(c, unit0, 0.1)
+	s.addMetric(c, unit1, 0.1)
+	err = autoscaler.AutoscaleService(s.State, "wordpress", later)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.DeepEquals, []string{"wordpress/0"})
+
+	history, err := wordpress.AutoscaleHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 3)
+	c.Assert(history[0].Time.Equal(later), jc.IsTrue)
+	c.Assert(history[0].From, gc.Equals, 2)
+	c.Assert(history[0].To, gc.Equals, 1)
+	c.Assert(history[0].Reason, gc.Equals, "average load of 0.1 is below 0.2")
+	c.Assert(history[1].Reason, gc.Equals, "average load of 0.95 is above 0.8")
+	c.Assert(history[2].Reason, gc.Equals, "0 units is below the minimum of 1")
+}
+
+func (s *autoscalerSuite) TestAutoscaleRespectsServiceMinUnits(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := wordpress.SetAutoscalePolicy(testPolicy)
+	c.Assert(err, gc.IsNil)
+	err = wordpress.SetMinUnits(2)
+	c.Assert(err, gc.IsNil)
+	for i := 0; i < 2; i++ {
+		unit, err := wordpress.AddUnit()
+		c.Assert(err, gc.IsNil)
+		s.addMetric(c, unit, 0.1)
+	}
+	// Low load does not take the service below its own minimum,
+	// although the policy allows fewer units.
+	err = autoscaler.AutoscaleService(s.State, "wordpress", time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.DeepEquals, []string{"wordpress/0", "wordpress/1"})
+	history, err := wordpress.AutoscaleHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *autoscalerSuite) TestAutoscaleIgnoresStaleMetrics(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = wordpress.SetAutoscalePolicy(testPolicy)
+	c.Assert(err, gc.IsNil)
+	s.addMetric(c, unit, 0.95)
+	// The metric was received an hour before the service is evaluated.
+	err = autoscaler.AutoscaleService(s.State, "wordpress", time.Now().Add(time.Hour))
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.aliveUnitNames(c, wordpress), gc.DeepEquals, []string{"wordpress/0"})
+	history, err := wordpress.AutoscaleHistory()
+	c.Assert(err, gc.IsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *autoscalerSuite) TestWorkerAutoscales(c *gc.C) {
+	s.PatchValue(autoscaler.Interval, 10*time.Millisecond)
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := wordpress.SetAutoscalePolicy(testPolicy)
+	c.Assert(err, gc.IsNil)
+
+	a := autoscaler.NewAutoscaler(s.State)
+	defer func() { c.Assert(worker.Stop(a), gc.IsNil) }()
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		if len(s.aliveUnitNames(c, wordpress)) == 1 {
+			return
+		}
+	}
+	c.Fatalf("service was not scaled to its minimum")
+}
@@ -0,0 +1,22 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package autoscaler
+
+import (
+	"time"
+
+	"github.com/juju/juju/state"
+)
+
+var (
+	Interval     = &interval
+	MetricMaxAge = &metricMaxAge
+	Decide       = decide
+)
+
+// AutoscaleService evaluates the named service's autoscale policy
+// at the given time, without starting a worker.
+func AutoscaleService(st *state.State, serviceName string, now time.Time) error {
+	return (&Autoscaler{st: st}).autoscale(serviceName, now)
+}
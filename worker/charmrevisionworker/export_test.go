@@ -3,4 +3,7 @@
 
 package charmrevisionworker
 
-var Interval = &interval
+var (
+	Interval        = &interval
+	UpgradeInterval = &upgradeInterval
+)
@@ -19,18 +19,26 @@ var logger = loggo.GetLogger("juju.worker.charmrevisionworker")
 // interval sets how often the resuming is called.
 var interval = 24 * time.Hour
 
+// upgradeInterval sets how often services are considered for
+// automatic charm upgrades, which must happen often enough for
+// upgrade windows to be honoured.
+var upgradeInterval = 10 * time.Minute
+
 var _ worker.Worker = (*RevisionUpdateWorker)(nil)
 
 // RevisionUpdateWorker is responsible for a periodical retrieval of charm versions
 // from the charm store, and recording the revision status for deployed charms.
+// It also upgrades the services whose charm upgrade policy allows it.
+// Both only happen within the environment's maintenance windows.
 type RevisionUpdateWorker struct {
-	st   *charmrevisionupdater.State
-	tomb tomb.Tomb
+	st       *charmrevisionupdater.State
+	schedule *worker.MaintenanceScheduler
+	tomb     tomb.Tomb
 }
 
 // NewRevisionUpdateWorker periodically retrieves charm versions from the charm store.
-func NewRevisionUpdateWorker(st *charmrevisionupdater.State) *RevisionUpdateWorker {
-	ruw := &RevisionUpdateWorker{st: st}
+func NewRevisionUpdateWorker(st *charmrevisionupdater.State, schedule *worker.MaintenanceScheduler) *RevisionUpdateWorker {
+	ruw := &RevisionUpdateWorker{st: st, schedule: schedule}
 	go func() {
 		defer ruw.tomb.Done()
 		ruw.tomb.Kill(ruw.loop())
@@ -59,13 +67,26 @@ func (ruw *RevisionUpdateWorker) Wait() error {
 }
 
 func (ruw *RevisionUpdateWorker) loop() error {
-	ruw.updateVersions()
+	updateTimer := time.After(0)
+	upgradeTimer := time.After(upgradeInterval)
 	for {
 		select {
 		case <-ruw.tomb.Dying():
 			return tomb.ErrDying
-		case <-time.After(interval):
+		case <-updateTimer:
+			if delay := ruw.schedule.Delay(); delay > 0 {
+				logger.Debugf("waiting %v for a maintenance window", delay)
+				updateTimer = time.After(delay)
+				continue
+			}
 			ruw.updateVersions()
+			ruw.upgradeCharms()
+			updateTimer = time.After(interval)
+		case <-upgradeTimer:
+			if ruw.schedule.Delay() == 0 {
+				ruw.upgradeCharms()
+			}
+			upgradeTimer = time.After(upgradeInterval)
 		}
 	}
 }
@@ -75,3 +96,9 @@ func (ruw *RevisionUpdateWorker) updateVersions() {
 		logger.Errorf("cannot process charms: %v", err)
 	}
 }
+
+func (ruw *RevisionUpdateWorker) upgradeCharms() {
+	if err := ruw.st.UpgradeCharms(); err != nil {
+		logger.Errorf("cannot upgrade charms: %v", err)
+	}
+}
@@ -4,10 +4,12 @@
 package charmrevisionworker_test
 
 import (
+	"fmt"
 	stdtesting "testing"
 	"time"
 
 	"github.com/juju/charm"
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
@@ -17,6 +19,7 @@ import (
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/apiserver/charmrevisionupdater/testing"
 	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/charmrevisionworker"
 )
 
@@ -36,6 +39,7 @@ var _ = gc.Suite(&RevisionUpdateSuite{})
 
 func (s *RevisionUpdateSuite) SetUpSuite(c *gc.C) {
 	c.Assert(*charmrevisionworker.Interval, gc.Equals, 24*time.Hour)
+	c.Assert(*charmrevisionworker.UpgradeInterval, gc.Equals, 10*time.Minute)
 	s.JujuConnSuite.SetUpSuite(c)
 	s.CharmSuite.SetUpSuite(c, &s.JujuConnSuite)
 }
@@ -69,7 +73,8 @@ func (s *RevisionUpdateSuite) runUpdater(c *gc.C, updateInterval time.Duration)
 	revisionUpdaterState := s.st.CharmRevisionUpdater()
 	c.Assert(revisionUpdaterState, gc.NotNil)
 
-	s.versionUpdater = charmrevisionworker.NewRevisionUpdateWorker(revisionUpdaterState)
+	schedule := worker.NewMaintenanceScheduler(s.st.Environment())
+	s.versionUpdater = charmrevisionworker.NewRevisionUpdateWorker(revisionUpdaterState, schedule)
 	s.AddCleanup(func(c *gc.C) { s.versionUpdater.Stop() })
 }
 
@@ -110,3 +115,37 @@ func (s *RevisionUpdateSuite) TestVersionUpdateRunsPeriodically(c *gc.C) {
 	// Check the results of the latest changes.
 	c.Assert(s.checkCharmRevision(c, 24), jc.IsTrue)
 }
+
+func (s *RevisionUpdateSuite) TestVersionUpdateWaitsForMaintenanceWindow(c *gc.C) {
+	s.SetupScenario(c)
+	// Allow maintenance only in a daily window that opens in two hours.
+	opens := time.Now().UTC().Add(2 * time.Hour)
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": fmt.Sprintf("%d %d * * * 1h", opens.Minute(), opens.Hour()),
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	s.runUpdater(c, 5*time.Millisecond)
+	time.Sleep(coretesting.ShortWait)
+	_, err = s.State.LatestPlaceholderCharm(charm.MustParseURL("cs:quantal/mysql"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RevisionUpdateSuite) TestUpgradesCharms(c *gc.C) {
+	s.SetupScenario(c)
+	svc, err := s.State.Service("mysql")
+	c.Assert(err, gc.IsNil)
+	err = svc.SetCharmUpgradePolicy(state.CharmUpgradePolicy{Mode: state.CharmUpgradeAuto})
+	c.Assert(err, gc.IsNil)
+
+	// The service is upgraded as soon as the new revision is found.
+	s.runUpdater(c, time.Hour)
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		err := svc.Refresh()
+		c.Assert(err, gc.IsNil)
+		if curl, _ := svc.CharmURL(); curl.Revision == 23 {
+			return
+		}
+	}
+	c.Fatalf("service not upgraded")
+}
@@ -4,7 +4,10 @@
 package cleaner
 
 import (
+	"time"
+
 	"github.com/juju/loggo"
+	"launchpad.net/tomb"
 
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/watcher"
@@ -13,15 +16,38 @@ import (
 
 var logger = loggo.GetLogger("juju.worker.cleaner")
 
+// metricsRetention holds how long unit metric batches are kept.
+var metricsRetention = 7 * 24 * time.Hour
+
+// pruneInterval holds how often old unit metric batches are removed.
+var pruneInterval = time.Hour
+
 // Cleaner is responsible for cleaning up the state.
 type Cleaner struct {
-	st *state.State
+	st       *state.State
+	schedule *worker.MaintenanceScheduler
 }
 
 // NewCleaner returns a worker.Worker that runs state.Cleanup()
-// if the CleanupWatcher signals documents marked for deletion.
+// if the CleanupWatcher signals documents marked for deletion,
+// and that periodically removes unit metric batches older than
+// the retention period. Cleanups complete removals requested by
+// the user, so they run straight away; metric batches are only
+// removed within the environment's maintenance windows.
 func NewCleaner(st *state.State) worker.Worker {
-	return worker.NewNotifyWorker(&Cleaner{st: st})
+	c := &Cleaner{
+		st:       st,
+		schedule: worker.NewMaintenanceScheduler(st),
+	}
+	w := &cleanerWorker{
+		cleaner: c,
+		notify:  worker.NewNotifyWorker(c),
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
 }
 
 func (c *Cleaner) SetUp() (watcher.NotifyWatcher, error) {
@@ -41,3 +67,56 @@ func (c *Cleaner) TearDown() error {
 	// Nothing to cleanup, only state is the watcher
 	return nil
 }
+
+// pruneMetrics removes the unit metric batches recorded
+// before the retention period.
+func (c *Cleaner) pruneMetrics(now time.Time) {
+	removed, err := c.st.PruneMetricBatches(now.Add(-metricsRetention))
+	if err != nil {
+		logger.Errorf("cannot prune metrics: %v", err)
+	} else if removed > 0 {
+		logger.Infof("pruned %d metric batches", removed)
+	}
+}
+
+// cleanerWorker runs the Cleaner's notify worker alongside the
+// periodic pruning of metrics.
+type cleanerWorker struct {
+	tomb    tomb.Tomb
+	cleaner *Cleaner
+	notify  worker.Worker
+}
+
+// Kill is defined on the worker.Worker interface.
+func (w *cleanerWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is defined on the worker.Worker interface.
+func (w *cleanerWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+func (w *cleanerWorker) loop() error {
+	done := make(chan error, 1)
+	go func() {
+		done <- w.notify.Wait()
+	}()
+	pruneTimer := time.After(0)
+	for {
+		select {
+		case <-w.tomb.Dying():
+			w.notify.Kill()
+			return <-done
+		case err := <-done:
+			return err
+		case <-pruneTimer:
+			if delay := w.cleaner.schedule.Delay(); delay > 0 {
+				pruneTimer = time.After(delay)
+				continue
+			}
+			w.cleaner.pruneMetrics(time.Now())
+			pruneTimer = time.After(pruneInterval)
+		}
+	}
+}
@@ -4,12 +4,14 @@
 package cleaner_test
 
 import (
+	"fmt"
 	stdtesting "testing"
 	"time"
 
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/cleaner"
@@ -71,3 +73,50 @@ func (s *CleanerSuite) TestCleaner(c *gc.C) {
 		break
 	}
 }
+
+func (s *CleanerSuite) TestCleanerPrunesMetrics(c *gc.C) {
+	// A negative retention period makes all metrics old enough
+	// to be pruned.
+	s.PatchValue(cleaner.MetricsRetention, -time.Hour)
+
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+
+	cr := cleaner.NewCleaner(s.State)
+	defer func() { c.Assert(worker.Stop(cr), gc.IsNil) }()
+
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		batches, err := svc.MetricBatches(time.Time{})
+		c.Assert(err, gc.IsNil)
+		if len(batches) == 0 {
+			return
+		}
+	}
+	c.Fatalf("timed out waiting for metrics to be pruned")
+}
+
+func (s *CleanerSuite) TestCleanerPrunesMetricsInMaintenanceWindow(c *gc.C) {
+	s.PatchValue(cleaner.MetricsRetention, -time.Hour)
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AddMetrics([]state.Metric{{Key: "load", Value: 1, Time: time.Now()}})
+	c.Assert(err, gc.IsNil)
+	// Allow maintenance only in a daily window that opens in two hours.
+	opens := time.Now().UTC().Add(2 * time.Hour)
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": fmt.Sprintf("%d %d * * * 1h", opens.Minute(), opens.Hour()),
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	cr := cleaner.NewCleaner(s.State)
+	defer func() { c.Assert(worker.Stop(cr), gc.IsNil) }()
+
+	time.Sleep(coretesting.ShortWait)
+	batches, err := svc.MetricBatches(time.Time{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+}
@@ -17,10 +17,11 @@ func (*fakeAPI) ConnectionInfo() (params.DeployerConnectionValues, error) {
 	}, nil
 }
 
-func NewTestSimpleContext(agentConfig agent.Config, initDir, logDir string) *SimpleContext {
+func NewTestSimpleContext(agentConfig agent.Config, initSystem, initDir, logDir string) *SimpleContext {
 	return &SimpleContext{
 		api:         &fakeAPI{},
 		agentConfig: agentConfig,
+		initSystem:  initSystem,
 		initDir:     initDir,
 	}
 }
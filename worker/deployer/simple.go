@@ -16,22 +16,23 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/api/params"
-	"github.com/juju/juju/upstart"
 	"github.com/juju/juju/version"
 )
 
-// InitDir is the default upstart init directory.
+// InitDir, if not empty, overrides the directory in which the local
+// init system keeps its service configurations.
 // This is a var so it can be overridden by tests.
-var InitDir = "/etc/init"
+var InitDir = ""
 
 // APICalls defines the interface to the API that the simple context needs.
 type APICalls interface {
 	ConnectionInfo() (params.DeployerConnectionValues, error)
 }
 
-// SimpleContext is a Context that manages unit deployments via upstart
-// jobs on the local system.
+// SimpleContext is a Context that manages unit deployments via services
+// of the local init system.
 type SimpleContext struct {
 
 	// api is used to get the current state server addresses at the time the
@@ -42,22 +43,32 @@ type SimpleContext struct {
 	// running the deployer.
 	agentConfig agent.Config
 
-	// initDir specifies the directory used by upstart on the local system.
-	// It is typically set to "/etc/init".
+	// initSystem is the init system used on the local system.
+	initSystem string
+
+	// initDir specifies the directory used by the init system on the
+	// local system. It is typically set to "/etc/init" for upstart,
+	// and "/etc/systemd/system" for systemd.
 	initDir string
 }
 
 var _ Context = (*SimpleContext)(nil)
 
 // NewSimpleContext returns a new SimpleContext, acting on behalf of
-// the specified deployer, that deploys unit agents as upstart jobs in
-// "/etc/init". Paths to which agents and tools are installed are
+// the specified deployer, that deploys unit agents as services of the
+// local init system. Paths to which agents and tools are installed are
 // relative to dataDir.
 func NewSimpleContext(agentConfig agent.Config, api APICalls) *SimpleContext {
+	initSystem := service.DetectInitSystem()
+	initDir := InitDir
+	if initDir == "" {
+		initDir = service.InitDir(initSystem)
+	}
 	return &SimpleContext{
 		api:         api,
 		agentConfig: agentConfig,
-		initDir:     InitDir,
+		initSystem:  initSystem,
+		initDir:     initDir,
 	}
 }
 
@@ -67,7 +78,10 @@ func (ctx *SimpleContext) AgentConfig() agent.Config {
 
 func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err error) {
 	// Check sanity.
-	svc := ctx.upstartService(unitName)
+	svc, err := ctx.service(unitName, service.Conf{})
+	if err != nil {
+		return err
+	}
 	if svc.Installed() {
 		return fmt.Errorf("unit %q is already deployed", unitName)
 	}
@@ -115,7 +129,7 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 	}
 	defer removeOnErr(&err, conf.Dir())
 
-	// Install an upstart job that runs the unit agent.
+	// Install a service that runs the unit agent.
 	logPath := path.Join(logDir, tag.String()+".log")
 	cmd := strings.Join([]string{
 		path.Join(toolsDir, "jujud"), "unit",
@@ -127,37 +141,42 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 	// As much as I'd like to remove JujuContainerType now, it is still
 	// needed as MAAS still needs it at this stage, and we can't fix
 	// everything at once.
-	uconf := &upstart.Conf{
-		Service: *svc,
-		Desc:    "juju unit agent for " + unitName,
-		Cmd:     cmd,
-		Out:     logPath,
+	svc, err = ctx.service(unitName, service.Conf{
+		Desc: "juju unit agent for " + unitName,
+		Cmd:  cmd,
+		Out:  logPath,
 		Env: map[string]string{
 			osenv.JujuContainerTypeEnvKey: containerType,
 		},
+	})
+	if err != nil {
+		return err
 	}
-	return uconf.Install()
+	return svc.Install()
 }
 
-// findUpstartJob tries to find an upstart job matching the
-// given unit name in one of these formats:
+// findJob tries to find a service matching the given unit name
+// in one of these formats, where the suffix depends on the init
+// system:
 //   jujud-<deployer-tag>:<unit-tag>.conf (for compatibility)
 //   jujud-<unit-tag>.conf (default)
-func (ctx *SimpleContext) findUpstartJob(unitName string) *upstart.Service {
-	unitsAndJobs, err := ctx.deployedUnitsUpstartJobs()
+func (ctx *SimpleContext) findJob(unitName string) service.Service {
+	unitsAndJobs, err := ctx.deployedUnitsJobs()
 	if err != nil {
 		return nil
 	}
 	if job, ok := unitsAndJobs[unitName]; ok {
-		svc := upstart.NewService(job)
-		svc.InitDir = ctx.initDir
+		svc, err := service.New(ctx.initSystem, job, service.Conf{InitDir: ctx.initDir})
+		if err != nil {
+			return nil
+		}
 		return svc
 	}
 	return nil
 }
 
 func (ctx *SimpleContext) RecallUnit(unitName string) error {
-	svc := ctx.findUpstartJob(unitName)
+	svc := ctx.findJob(unitName)
 	if svc == nil || !svc.Installed() {
 		return fmt.Errorf("unit %q is not deployed", unitName)
 	}
@@ -175,15 +194,19 @@ func (ctx *SimpleContext) RecallUnit(unitName string) error {
 	return os.Remove(toolsDir)
 }
 
-var deployedRe = regexp.MustCompile("^(jujud-.*unit-([a-z0-9-]+)-([0-9]+))\\.conf$")
+var deployedRe = regexp.MustCompile("^(jujud-.*unit-([a-z0-9-]+)-([0-9]+))\\.[a-z]+$")
 
-func (ctx *SimpleContext) deployedUnitsUpstartJobs() (map[string]string, error) {
+func (ctx *SimpleContext) deployedUnitsJobs() (map[string]string, error) {
 	fis, err := ioutil.ReadDir(ctx.initDir)
 	if err != nil {
 		return nil, err
 	}
+	suffix := service.ConfSuffix(ctx.initSystem)
 	installed := make(map[string]string)
 	for _, fi := range fis {
+		if !strings.HasSuffix(fi.Name(), suffix) {
+			continue
+		}
 		if groups := deployedRe.FindStringSubmatch(fi.Name()); len(groups) == 4 {
 			unitName := groups[2] + "/" + groups[3]
 			if !names.IsValidUnit(unitName) {
@@ -196,7 +219,7 @@ func (ctx *SimpleContext) deployedUnitsUpstartJobs() (map[string]string, error)
 }
 
 func (ctx *SimpleContext) DeployedUnits() ([]string, error) {
-	unitsAndJobs, err := ctx.deployedUnitsUpstartJobs()
+	unitsAndJobs, err := ctx.deployedUnitsJobs()
 	if err != nil {
 		return nil, err
 	}
@@ -207,14 +230,12 @@ func (ctx *SimpleContext) DeployedUnits() ([]string, error) {
 	return installed, nil
 }
 
-// upstartService returns an upstart.Service corresponding to the specified
-// unit.
-func (ctx *SimpleContext) upstartService(unitName string) *upstart.Service {
+// service returns the service, as defined by conf, that runs the
+// agent of the specified unit.
+func (ctx *SimpleContext) service(unitName string, conf service.Conf) (service.Service, error) {
 	tag := names.NewUnitTag(unitName).String()
-	svcName := "jujud-" + tag
-	svc := upstart.NewService(svcName)
-	svc.InitDir = ctx.initDir
-	return svc
+	conf.InitDir = ctx.initDir
+	return service.New(ctx.initSystem, "jujud-"+tag, conf)
 }
 
 func removeOnErr(err *error, path string) {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/service"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
@@ -63,6 +64,36 @@ func (s *SimpleContextSuite) TestDeployRecall(c *gc.C) {
 	s.checkUnitRemoved(c, "foo/123")
 }
 
+func (s *SimpleContextSuite) TestDeployRecallSystemd(c *gc.C) {
+	s.initSystem = service.InitSystemSystemd
+	s.makeBin(c, "systemctl", `
+case "$1" in
+is-active) [ -e $(dirname $0)/started ] && echo active || echo inactive;;
+start) touch $(dirname $0)/started;;
+stop) rm -f $(dirname $0)/started;;
+esac`)
+	mgr := s.getContext(c)
+	err := mgr.DeployUnit("foo/123", "some-password")
+	c.Assert(err, gc.IsNil)
+	units, err := mgr.DeployedUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.DeepEquals, []string{"foo/123"})
+
+	tag := names.NewUnitTag("foo/123")
+	confPath, _, toolsDir := s.paths(tag)
+	c.Assert(confPath, gc.Matches, `.*/jujud-unit-foo-123\.service`)
+	data, err := ioutil.ReadFile(confPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Matches, `(?s).*ExecStart=.*exec `+regexp.QuoteMeta(filepath.Join(toolsDir, "jujud"))+` unit .* --unit-name foo/123 .*`)
+
+	err = mgr.RecallUnit("foo/123")
+	c.Assert(err, gc.IsNil)
+	units, err = mgr.DeployedUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.HasLen, 0)
+	s.checkUnitRemoved(c, "foo/123")
+}
+
 func (s *SimpleContextSuite) TestOldDeployedUnitsCanBeRecalled(c *gc.C) {
 	// After r1347 deployer tag is no longer part of the upstart conf filenames,
 	// now only the units' tags are used. This change is with the assumption only
@@ -131,17 +162,19 @@ func (s *SimpleContextSuite) TestOldDeployedUnitsCanBeRecalled(c *gc.C) {
 }
 
 type SimpleToolsFixture struct {
-	dataDir  string
-	logDir   string
-	initDir  string
-	origPath string
-	binDir   string
+	dataDir    string
+	logDir     string
+	initSystem string
+	initDir    string
+	origPath   string
+	binDir     string
 }
 
 var fakeJujud = "#!/bin/bash --norc\n# fake-jujud\nexit 0\n"
 
 func (fix *SimpleToolsFixture) SetUp(c *gc.C, dataDir string) {
 	fix.dataDir = dataDir
+	fix.initSystem = service.InitSystemUpstart
 	fix.initDir = c.MkDir()
 	fix.logDir = c.MkDir()
 	toolsDir := tools.SharedToolsDir(fix.dataDir, version.Current)
@@ -184,16 +217,16 @@ func (fix *SimpleToolsFixture) assertUpstartCount(c *gc.C, count int) {
 
 func (fix *SimpleToolsFixture) getContext(c *gc.C) *deployer.SimpleContext {
 	config := agentConfig(names.NewMachineTag("99"), fix.dataDir, fix.logDir)
-	return deployer.NewTestSimpleContext(config, fix.initDir, fix.logDir)
+	return deployer.NewTestSimpleContext(config, fix.initSystem, fix.initDir, fix.logDir)
 }
 
 func (fix *SimpleToolsFixture) getContextForMachine(c *gc.C, machineTag names.Tag) *deployer.SimpleContext {
 	config := agentConfig(machineTag, fix.dataDir, fix.logDir)
-	return deployer.NewTestSimpleContext(config, fix.initDir, fix.logDir)
+	return deployer.NewTestSimpleContext(config, fix.initSystem, fix.initDir, fix.logDir)
 }
 
 func (fix *SimpleToolsFixture) paths(tag names.Tag) (confPath, agentDir, toolsDir string) {
-	confName := fmt.Sprintf("jujud-%s.conf", tag)
+	confName := fmt.Sprintf("jujud-%s%s", tag, service.ConfSuffix(fix.initSystem))
 	confPath = filepath.Join(fix.initDir, confName)
 	agentDir = agent.Dir(fix.dataDir, tag)
 	toolsDir = tools.ToolsDir(fix.dataDir, tag.String())
@@ -26,3 +26,8 @@ func SetMustErr(f func(watcher.Errer) error) {
 func MustErr() func(watcher.Errer) error {
 	return mustErr
 }
+
+var (
+	MaintenanceNow      = &maintenanceNow
+	MaxMaintenanceDelay = &maxMaintenanceDelay
+)
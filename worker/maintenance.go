@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker
+
+import (
+	"time"
+
+	"github.com/juju/juju/environs/config"
+)
+
+// maxMaintenanceDelay bounds the delays returned by a
+// MaintenanceScheduler, so that workers waiting for a maintenance
+// window notice when the windows change, and in particular when an
+// ad-hoc window is opened.
+var maxMaintenanceDelay = 5 * time.Minute
+
+// maintenanceNow returns the current time; it is replaced in tests.
+var maintenanceNow = time.Now
+
+// MaintenanceWindow is a period of time within which automated
+// maintenance operations may run.
+type MaintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+
+	// Schedule holds the specification of the recurring window
+	// that opens at Start. It is empty for ad-hoc windows.
+	Schedule string
+}
+
+// MaintenanceWindows returns, in order, the first n maintenance
+// windows described by the environment configuration that are open
+// at or after t. Times are given in the environment's maintenance
+// timezone. An ad-hoc window is open from t until the time the
+// configuration gives.
+func MaintenanceWindows(cfg *config.Config, t time.Time, n int) []MaintenanceWindow {
+	var windows []MaintenanceWindow
+	for _, p := range cfg.MaintenancePlan().Upcoming(t, n) {
+		windows = append(windows, MaintenanceWindow{
+			Start:    p.Start,
+			End:      p.End,
+			Schedule: p.Window,
+		})
+	}
+	return windows
+}
+
+// MaintenanceDelay returns how long after t automated maintenance
+// operations must wait for a maintenance window to open, according
+// to the environment configuration. It returns zero if a window is
+// open at t, or if no windows are configured at all.
+func MaintenanceDelay(cfg *config.Config, t time.Time) time.Duration {
+	return cfg.MaintenancePlan().Delay(t)
+}
+
+// MaintenanceScheduler is consulted by workers that run automated
+// maintenance operations, such as charm upgrades and tools upgrades,
+// to find out whether they may run them now.
+type MaintenanceScheduler struct {
+	st EnvironConfigGetter
+}
+
+// NewMaintenanceScheduler returns a MaintenanceScheduler that reads
+// the maintenance windows from the environment configuration.
+func NewMaintenanceScheduler(st EnvironConfigGetter) *MaintenanceScheduler {
+	return &MaintenanceScheduler{st}
+}
+
+// Delay returns how long the caller must wait before running a
+// maintenance operation, which is zero if it may run it now. Long
+// delays are shortened, so the caller should call Delay again once
+// it has waited. If the windows cannot be read, the error is logged
+// and the operation may run.
+func (s *MaintenanceScheduler) Delay() time.Duration {
+	cfg, err := s.st.EnvironConfig()
+	if err != nil {
+		logger.Errorf("cannot read maintenance windows: %v", err)
+		return 0
+	}
+	delay := MaintenanceDelay(cfg, maintenanceNow())
+	if delay > maxMaintenanceDelay {
+		delay = maxMaintenanceDelay
+	}
+	return delay
+}
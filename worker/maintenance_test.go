@@ -0,0 +1,131 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker_test
+
+import (
+	"errors"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+)
+
+type maintenanceSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&maintenanceSuite{})
+
+// Sunday 29th June 2014.
+var sunday = time.Date(2014, 6, 29, 12, 30, 0, 0, time.UTC)
+
+func (s *maintenanceSuite) TestMaintenanceWindows(c *gc.C) {
+	cfg := coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"maintenance-windows": "0 12 * * * 1h; 0 2 * * 6 4h",
+	})
+	windows := worker.MaintenanceWindows(cfg, sunday, 4)
+	c.Assert(windows, gc.DeepEquals, []worker.MaintenanceWindow{{
+		Start:    time.Date(2014, 6, 29, 12, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 6, 29, 13, 0, 0, 0, time.UTC),
+		Schedule: "0 12 * * * 1h",
+	}, {
+		Start:    time.Date(2014, 6, 30, 12, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 6, 30, 13, 0, 0, 0, time.UTC),
+		Schedule: "0 12 * * * 1h",
+	}, {
+		Start:    time.Date(2014, 7, 1, 12, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 7, 1, 13, 0, 0, 0, time.UTC),
+		Schedule: "0 12 * * * 1h",
+	}, {
+		Start:    time.Date(2014, 7, 2, 12, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 7, 2, 13, 0, 0, 0, time.UTC),
+		Schedule: "0 12 * * * 1h",
+	}})
+
+	windows = worker.MaintenanceWindows(cfg, sunday.Add(time.Hour), 1)
+	c.Assert(windows, gc.DeepEquals, []worker.MaintenanceWindow{{
+		Start:    time.Date(2014, 6, 30, 12, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 6, 30, 13, 0, 0, 0, time.UTC),
+		Schedule: "0 12 * * * 1h",
+	}})
+}
+
+func (s *maintenanceSuite) TestMaintenanceWindowsAdHoc(c *gc.C) {
+	cfg := coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"maintenance-windows":    "0 2 * * 6 4h",
+		"maintenance-open-until": "2014-06-29T14:00:00Z",
+	})
+	windows := worker.MaintenanceWindows(cfg, sunday, 2)
+	c.Assert(windows, gc.DeepEquals, []worker.MaintenanceWindow{{
+		Start: sunday,
+		End:   time.Date(2014, 6, 29, 14, 0, 0, 0, time.UTC),
+	}, {
+		Start:    time.Date(2014, 7, 5, 2, 0, 0, 0, time.UTC),
+		End:      time.Date(2014, 7, 5, 6, 0, 0, 0, time.UTC),
+		Schedule: "0 2 * * 6 4h",
+	}})
+
+	// The ad-hoc window is ignored once it has closed.
+	windows = worker.MaintenanceWindows(cfg, sunday.Add(2*time.Hour), 1)
+	c.Assert(windows, gc.HasLen, 1)
+	c.Assert(windows[0].Schedule, gc.Equals, "0 2 * * 6 4h")
+}
+
+func (s *maintenanceSuite) TestMaintenanceWindowsTimezone(c *gc.C) {
+	// Brisbane is ten hours ahead of UTC all year round.
+	cfg := coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"maintenance-windows":  "0 2 * * * 1h",
+		"maintenance-timezone": "Australia/Brisbane",
+	})
+	windows := worker.MaintenanceWindows(cfg, sunday, 1)
+	c.Assert(windows, gc.HasLen, 1)
+	c.Assert(windows[0].Start.UTC(), gc.DeepEquals, time.Date(2014, 6, 29, 16, 0, 0, 0, time.UTC))
+}
+
+func (s *maintenanceSuite) TestMaintenanceDelay(c *gc.C) {
+	cfg := coretesting.EnvironConfig(c)
+	c.Assert(worker.MaintenanceDelay(cfg, sunday), gc.Equals, time.Duration(0))
+
+	cfg = coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"maintenance-windows": "0 12 * * * 1h; 0 14 * * * 1h",
+	})
+	c.Assert(worker.MaintenanceDelay(cfg, sunday), gc.Equals, time.Duration(0))
+	c.Assert(worker.MaintenanceDelay(cfg, sunday.Add(time.Hour)), gc.Equals, 30*time.Minute)
+	c.Assert(worker.MaintenanceDelay(cfg, sunday.Add(3*time.Hour)), gc.Equals, 20*time.Hour+30*time.Minute)
+
+	cfg, err := cfg.Apply(map[string]interface{}{
+		"maintenance-open-until": "2014-06-29T16:00:00Z",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(worker.MaintenanceDelay(cfg, sunday.Add(3*time.Hour)), gc.Equals, time.Duration(0))
+}
+
+type fakeEnvironConfigGetter struct {
+	cfg *config.Config
+	err error
+}
+
+func (g *fakeEnvironConfigGetter) EnvironConfig() (*config.Config, error) {
+	return g.cfg, g.err
+}
+
+func (s *maintenanceSuite) TestMaintenanceSchedulerDelay(c *gc.C) {
+	s.PatchValue(worker.MaintenanceNow, func() time.Time { return sunday })
+	getter := &fakeEnvironConfigGetter{
+		cfg: coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+			"maintenance-windows": "45 12 * * * 1h",
+		}),
+	}
+	scheduler := worker.NewMaintenanceScheduler(getter)
+	c.Assert(scheduler.Delay(), gc.Equals, 5*time.Minute)
+
+	s.PatchValue(worker.MaxMaintenanceDelay, time.Hour)
+	c.Assert(scheduler.Delay(), gc.Equals, 15*time.Minute)
+
+	getter.err = errors.New("boom")
+	c.Assert(scheduler.Delay(), gc.Equals, time.Duration(0))
+}
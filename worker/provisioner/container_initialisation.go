@@ -15,6 +15,7 @@ import (
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
+	"github.com/juju/juju/container/nspawn"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -188,6 +189,13 @@ func (cs *ContainerSetup) getContainerArtifacts(containerType instance.Container
 			logger.Errorf("failed to create new kvm broker")
 			return nil, nil, err
 		}
+	case instance.NSPAWN:
+		initialiser = nspawn.NewContainerInitialiser()
+		broker, err = NewNspawnBroker(cs.provisioner, tools, cs.config, managerConfig)
+		if err != nil {
+			logger.Errorf("failed to create new nspawn broker")
+			return nil, nil, err
+		}
 	default:
 		return nil, nil, fmt.Errorf("unknown container type: %v", containerType)
 	}
@@ -60,6 +60,9 @@ func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (insta
 	if args.MachineConfig.HasNetworks() {
 		return nil, nil, nil, fmt.Errorf("starting kvm containers with networks is not supported yet.")
 	}
+	if args.CloneFrom != "" {
+		return nil, nil, nil, fmt.Errorf("cloning kvm containers is not supported")
+	}
 	// TODO: refactor common code out of the container brokers.
 	machineId := args.MachineConfig.MachineId
 	kvmLogger.Infof("starting kvm container for machineId: %s", machineId)
@@ -77,6 +80,7 @@ func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (insta
 	series := args.Tools.OneSeries()
 	args.MachineConfig.MachineContainerType = instance.KVM
 	args.MachineConfig.Tools = args.Tools[0]
+	args.MachineConfig.Constraints = args.Constraints
 
 	config, err := broker.api.ContainerConfig()
 	if err != nil {
@@ -74,6 +74,7 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (insta
 	series := args.Tools.OneSeries()
 	args.MachineConfig.MachineContainerType = instance.LXC
 	args.MachineConfig.Tools = args.Tools[0]
+	args.MachineConfig.Constraints = args.Constraints
 
 	config, err := broker.api.ContainerConfig()
 	if err != nil {
@@ -93,7 +94,18 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (insta
 		return nil, nil, nil, err
 	}
 
-	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
+	var inst instance.Instance
+	var hardware *instance.HardwareCharacteristics
+	if args.CloneFrom != "" {
+		cloner, ok := broker.manager.(container.Cloner)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("cloning lxc containers is not supported")
+		}
+		lxcLogger.Infof("cloning lxc container %s for machineId: %s", args.CloneFrom, machineId)
+		inst, hardware, err = cloner.CloneContainer(args.CloneFrom, args.MachineConfig, series, network)
+	} else {
+		inst, hardware, err = broker.manager.CreateContainer(args.MachineConfig, series, network)
+	}
 	if err != nil {
 		lxcLogger.Errorf("failed to start container: %v", err)
 		return nil, nil, nil, err
@@ -89,19 +89,25 @@ func (s *lxcBrokerSuite) SetUpTest(c *gc.C) {
 }
 
 func (s *lxcBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	lxc, _ := s.startInstanceWithConstraints(c, machineId, constraints.Value{})
+	return lxc
+}
+
+func (s *lxcBrokerSuite) startInstanceWithConstraints(
+	c *gc.C, machineId string, cons constraints.Value,
+) (instance.Instance, *instance.HardwareCharacteristics) {
 	machineNonce := "fake-nonce"
 	stateInfo := jujutesting.FakeStateInfo(machineId)
 	apiInfo := jujutesting.FakeAPIInfo(machineId)
 	machineConfig := environs.NewMachineConfig(machineId, machineNonce, nil, stateInfo, apiInfo)
-	cons := constraints.Value{}
 	possibleTools := s.broker.(coretools.HasTools).Tools("precise")
-	lxc, _, _, err := s.broker.StartInstance(environs.StartInstanceParams{
+	lxc, hardware, _, err := s.broker.StartInstance(environs.StartInstanceParams{
 		Constraints:   cons,
 		Tools:         possibleTools,
 		MachineConfig: machineConfig,
 	})
 	c.Assert(err, gc.IsNil)
-	return lxc
+	return lxc, hardware
 }
 
 func (s *lxcBrokerSuite) TestStartInstance(c *gc.C) {
@@ -117,6 +123,22 @@ func (s *lxcBrokerSuite) TestStartInstance(c *gc.C) {
 	c.Assert(string(lxcConfContents), jc.Contains, "lxc.network.link = lxcbr0")
 }
 
+func (s *lxcBrokerSuite) TestStartInstanceCloneFailureNotRetryable(c *gc.C) {
+	machineId := "1/lxc/1"
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
+	possibleTools := s.broker.(coretools.HasTools).Tools("precise")
+	_, _, _, err := s.broker.StartInstance(environs.StartInstanceParams{
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+		CloneFrom:     "juju-machine-1-lxc-0",
+	})
+	c.Assert(err, gc.ErrorMatches, `container "juju-machine-1-lxc-0" not found`)
+	// The provisioner would only fail in the same way again.
+	c.Assert(environs.IsRetryable(err), jc.IsFalse)
+}
+
 func (s *lxcBrokerSuite) TestStartInstanceWithBridgeEnviron(c *gc.C) {
 	s.agentConfig.SetValue(agent.LxcBridge, "br0")
 	machineId := "1/lxc/0"
@@ -131,6 +153,17 @@ func (s *lxcBrokerSuite) TestStartInstanceWithBridgeEnviron(c *gc.C) {
 	c.Assert(string(lxcConfContents), jc.Contains, "lxc.network.link = br0")
 }
 
+func (s *lxcBrokerSuite) TestStartInstanceWithConstraints(c *gc.C) {
+	cons := constraints.MustParse("mem=1G cpu-cores=2")
+	lxc, hardware := s.startInstanceWithConstraints(c, "1/lxc/0", cons)
+	c.Assert(*hardware.Mem, gc.Equals, uint64(1024))
+	c.Assert(*hardware.CpuCores, gc.Equals, uint64(2))
+	config, err := ioutil.ReadFile(filepath.Join(s.LxcDir, string(lxc.Id()), "config"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(config), jc.Contains, "lxc.cgroup.memory.limit_in_bytes = 1024M\n")
+	c.Assert(string(config), jc.Contains, "lxc.cgroup.cpu.cfs_quota_us = 200000\n")
+}
+
 func (s *lxcBrokerSuite) TestStopInstance(c *gc.C) {
 	lxc0 := s.startInstance(c, "1/lxc/0")
 	lxc1 := s.startInstance(c, "1/lxc/1")
@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/nspawn"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/tools"
+)
+
+var nspawnLogger = loggo.GetLogger("juju.provisioner.nspawn")
+
+var _ environs.InstanceBroker = (*nspawnBroker)(nil)
+var _ tools.HasTools = (*nspawnBroker)(nil)
+
+func NewNspawnBroker(
+	api APICalls,
+	tools *tools.Tools,
+	agentConfig agent.Config,
+	managerConfig container.ManagerConfig,
+) (environs.InstanceBroker, error) {
+	manager, err := nspawn.NewContainerManager(managerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &nspawnBroker{
+		manager:     manager,
+		api:         api,
+		tools:       tools,
+		agentConfig: agentConfig,
+	}, nil
+}
+
+type nspawnBroker struct {
+	manager     container.Manager
+	api         APICalls
+	tools       *tools.Tools
+	agentConfig agent.Config
+}
+
+func (broker *nspawnBroker) Tools(series string) tools.List {
+	seriesTools := *broker.tools
+	seriesTools.Version.Series = series
+	return tools.List{&seriesTools}
+}
+
+// StartInstance is specified in the Broker interface.
+func (broker *nspawnBroker) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	if args.MachineConfig.HasNetworks() {
+		return nil, nil, nil, fmt.Errorf("starting nspawn containers with networks is not supported yet.")
+	}
+	if args.CloneFrom != "" {
+		return nil, nil, nil, fmt.Errorf("cloning nspawn containers is not supported")
+	}
+	machineId := args.MachineConfig.MachineId
+	nspawnLogger.Infof("starting nspawn container for machineId: %s", machineId)
+
+	// As with kvm, the lxc bridge setting doubles as the bridge for
+	// nspawn containers until the bridge is part of the container config.
+	bridgeDevice := broker.agentConfig.Value(agent.LxcBridge)
+	if bridgeDevice == "" {
+		bridgeDevice = nspawn.DefaultNspawnBridge
+	}
+	network := container.BridgeNetworkConfig(bridgeDevice)
+
+	// TODO: series doesn't necessarily need to be the same as the host.
+	series := args.Tools.OneSeries()
+	args.MachineConfig.MachineContainerType = instance.NSPAWN
+	args.MachineConfig.Tools = args.Tools[0]
+
+	config, err := broker.api.ContainerConfig()
+	if err != nil {
+		nspawnLogger.Errorf("failed to get container config: %v", err)
+		return nil, nil, nil, err
+	}
+	if err := environs.PopulateMachineConfig(
+		args.MachineConfig,
+		config.ProviderType,
+		config.AuthorizedKeys,
+		config.SSLHostnameVerification,
+		config.Proxy,
+		config.AptProxy,
+		config.PreferIPv6,
+	); err != nil {
+		nspawnLogger.Errorf("failed to populate machine config: %v", err)
+		return nil, nil, nil, err
+	}
+
+	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
+	if err != nil {
+		nspawnLogger.Errorf("failed to start container: %v", err)
+		return nil, nil, nil, err
+	}
+	nspawnLogger.Infof("started nspawn container for machineId: %s, %s, %s", machineId, inst.Id(), hardware.String())
+	return inst, hardware, nil, nil
+}
+
+// StopInstances shuts down the given instances.
+func (broker *nspawnBroker) StopInstances(ids ...instance.Id) error {
+	for _, id := range ids {
+		nspawnLogger.Infof("stopping nspawn container for instance: %s", id)
+		if err := broker.manager.DestroyContainer(id); err != nil {
+			nspawnLogger.Errorf("container did not stop: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AllInstances only returns running containers.
+func (broker *nspawnBroker) AllInstances() (result []instance.Instance, err error) {
+	return broker.manager.ListContainers()
+}
@@ -0,0 +1,101 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"path/filepath"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	nspawntesting "github.com/juju/juju/container/nspawn/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	instancetest "github.com/juju/juju/instance/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type nspawnBrokerSuite struct {
+	nspawntesting.TestSuite
+	broker      environs.InstanceBroker
+	agentConfig agent.Config
+}
+
+var _ = gc.Suite(&nspawnBrokerSuite{})
+
+func (s *nspawnBrokerSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	tools := &coretools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	var err error
+	s.agentConfig, err = agent.NewAgentConfig(
+		agent.AgentConfigParams{
+			DataDir:           "/not/used/here",
+			Tag:               names.NewUnitTag("ubuntu/1"),
+			UpgradedToVersion: version.Current.Number,
+			Password:          "dummy-secret",
+			Nonce:             "nonce",
+			APIAddresses:      []string{"10.0.0.1:1234"},
+			CACert:            coretesting.CACert,
+		})
+	c.Assert(err, gc.IsNil)
+	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
+	s.broker, err = provisioner.NewNspawnBroker(&fakeAPI{}, tools, s.agentConfig, managerConfig)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *nspawnBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	machineNonce := "fake-nonce"
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, machineNonce, nil, stateInfo, apiInfo)
+	possibleTools := s.broker.(coretools.HasTools).Tools("trusty")
+	inst, hardware, _, err := s.broker.StartInstance(environs.StartInstanceParams{
+		Constraints:   constraints.Value{},
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(hardware.Arch, gc.NotNil)
+	c.Assert(machineConfig.MachineContainerType, gc.Equals, instance.NSPAWN)
+	return inst
+}
+
+func (s *nspawnBrokerSuite) TestStartInstance(c *gc.C) {
+	inst := s.startInstance(c, "1/nspawn/0")
+	c.Assert(inst.Id(), gc.Equals, instance.Id("juju-machine-1-nspawn-0"))
+	c.Assert(filepath.Join(s.ContainerDir, string(inst.Id()), "cloud-init"), jc.IsNonEmptyFile)
+	s.assertInstances(c, inst)
+}
+
+func (s *nspawnBrokerSuite) TestStopInstance(c *gc.C) {
+	nspawn0 := s.startInstance(c, "1/nspawn/0")
+	nspawn1 := s.startInstance(c, "1/nspawn/1")
+
+	err := s.broker.StopInstances(nspawn0.Id())
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c, nspawn1)
+	c.Assert(filepath.Join(s.ContainerDir, string(nspawn0.Id())), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, string(nspawn0.Id())), jc.IsDirectory)
+
+	err = s.broker.StopInstances(nspawn1.Id())
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c)
+}
+
+func (s *nspawnBrokerSuite) assertInstances(c *gc.C, inst ...instance.Instance) {
+	results, err := s.broker.AllInstances()
+	c.Assert(err, gc.IsNil)
+	instancetest.MatchInstances(c, results, inst...)
+}
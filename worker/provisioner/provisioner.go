@@ -121,9 +121,19 @@ func (p *provisioner) getStartTask(safeMode bool) (ProvisionerTask, error) {
 	if !ok {
 		errors.Errorf("expacted names.MachineTag, got %T", tag)
 	}
+	environConfig, err := p.st.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	retryStrategy := RetryStrategy{
+		MaxAttempts: environConfig.ProvisionerRetryCount(),
+		Delay:       DefaultRetryDelay,
+		MaxDelay:    DefaultMaxRetryDelay,
+	}
 	task := NewProvisionerTask(
 		machineTag, safeMode, p.st,
-		machineWatcher, retryWatcher, p.broker, auth)
+		machineWatcher, retryWatcher, p.broker, auth,
+		environConfig.ProvisionerParallelism(), retryStrategy)
 	return task, nil
 }
 
@@ -5,6 +5,7 @@ package provisioner
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -56,7 +57,12 @@ func NewProvisionerTask(
 	retryWatcher apiwatcher.NotifyWatcher,
 	broker environs.InstanceBroker,
 	auth authentication.AuthenticationProvider,
+	parallelism int,
+	retryStrategy RetryStrategy,
 ) ProvisionerTask {
+	if parallelism < 1 {
+		parallelism = 1
+	}
 	task := &provisionerTask{
 		machineTag:     machineTag,
 		machineGetter:  machineGetter,
@@ -67,6 +73,9 @@ func NewProvisionerTask(
 		safeMode:       safeMode,
 		safeModeChan:   make(chan bool, 1),
 		machines:       make(map[string]*apiprovisioner.Machine),
+		parallelism:    parallelism,
+		retries:        newMachineRetries(retryStrategy),
+		retryMachines:  make(chan string),
 	}
 	go func() {
 		defer task.tomb.Done()
@@ -91,6 +100,23 @@ type provisionerTask struct {
 	instances map[instance.Id]instance.Instance
 	// machine id -> machine
 	machines map[string]*apiprovisioner.Machine
+
+	// parallelism holds the maximum number of instances
+	// started at the same time.
+	parallelism int
+
+	// prepareMu serialises the preparation of machines being
+	// started in parallel: the authentication provider, the
+	// provisioning info API calls and the tools lookup are not
+	// safe for concurrent use. Only the broker's StartInstance
+	// calls, which dominate the time taken, run concurrently.
+	prepareMu sync.Mutex
+
+	// retries records failed attempts to start instances,
+	// and retryMachines receives the ids of machines
+	// whose start should be retried.
+	retries       *machineRetries
+	retryMachines chan string
 }
 
 // Kill implements worker.Worker.Kill.
@@ -119,6 +145,7 @@ func (task *provisionerTask) Err() error {
 func (task *provisionerTask) loop() error {
 	logger.Infof("Starting up provisioner task %s", task.machineTag)
 	defer watcher.Stop(task.machineWatcher, &task.tomb)
+	defer task.retries.stop()
 
 	// Don't allow the safe mode to change until we have
 	// read at least one set of changes, which will populate
@@ -166,6 +193,10 @@ func (task *provisionerTask) loop() error {
 			if err := task.processMachinesWithTransientErrors(); err != nil {
 				return errors.Annotate(err, "failed to process machines with transient errors")
 			}
+		case id := <-task.retryMachines:
+			if err := task.retryMachine(id); err != nil {
+				return errors.Annotatef(err, "failed to retry provisioning machine %v", id)
+			}
 		}
 	}
 }
@@ -195,12 +226,55 @@ func (task *provisionerTask) processMachinesWithTransientErrors() error {
 			logger.Errorf("cannot reset status of machine %q: %v", status.Id, err)
 			continue
 		}
-		task.machines[machine.Tag().String()] = machine
+		task.machines[machine.Id()] = machine
+		// The operator asked for the machine to be retried,
+		// so give it a full set of attempts again.
+		task.retries.forget(machine.Id())
 		pending = append(pending, machine)
 	}
 	return task.startMachines(pending)
 }
 
+// retryMachine retries starting an instance for the machine
+// with the given id after an earlier attempt failed.
+func (task *provisionerTask) retryMachine(id string) error {
+	machine, ok := task.machines[id]
+	if !ok {
+		task.retries.forget(id)
+		return nil
+	}
+	if err := machine.Refresh(); params.IsCodeNotFoundOrCodeUnauthorized(err) {
+		task.retries.forget(id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if machine.Life() != params.Alive {
+		task.retries.forget(id)
+		return nil
+	}
+	if _, err := machine.InstanceId(); err == nil {
+		// The machine has been provisioned since.
+		task.retries.forget(id)
+		return nil
+	} else if !params.IsCodeNotProvisioned(err) {
+		return err
+	}
+	status, _, err := machine.Status()
+	if err != nil {
+		return err
+	}
+	if status != params.StatusError {
+		// Something else is already dealing with the machine.
+		return nil
+	}
+	logger.Infof("retrying provisioning of machine %q", machine)
+	if err := machine.SetStatus(params.StatusPending, "", nil); err != nil {
+		return err
+	}
+	return task.startMachines([]*apiprovisioner.Machine{machine})
+}
+
 func (task *provisionerTask) processMachines(ids []string) error {
 	logger.Tracef("processMachines(%v)", ids)
 	// Populate the tasks maps of current instances and machines.
@@ -248,6 +322,7 @@ func (task *provisionerTask) processMachines(ids []string) error {
 			logger.Errorf("failed to remove dead machine %q", machine)
 		}
 		delete(task.machines, machine.Id())
+		task.retries.forget(machine.Id())
 	}
 
 	// Start an instance for the pending ones
@@ -406,13 +481,27 @@ func (task *provisionerTask) stopInstances(instances []instance.Instance) error
 	return nil
 }
 
+// startMachines starts instances for the given machines, starting
+// at most task.parallelism instances at the same time. It returns
+// the first error encountered, after all the starts have finished.
 func (task *provisionerTask) startMachines(machines []*apiprovisioner.Machine) error {
+	var wg sync.WaitGroup
+	limiter := make(chan struct{}, task.parallelism)
+	errs := make(chan error, len(machines))
 	for _, m := range machines {
-		if err := task.startMachine(m); err != nil {
-			return errors.Annotatef(err, "cannot start machine %v", m)
-		}
+		limiter <- struct{}{}
+		wg.Add(1)
+		go func(m *apiprovisioner.Machine) {
+			defer wg.Done()
+			defer func() { <-limiter }()
+			if err := task.startMachine(m); err != nil {
+				errs <- errors.Annotatef(err, "cannot start machine %v", m)
+			}
+		}(m)
 	}
-	return nil
+	wg.Wait()
+	close(errs)
+	return <-errs
 }
 
 func (task *provisionerTask) setErrorStatus(message string, machine *apiprovisioner.Machine, err error) error {
@@ -424,6 +513,52 @@ func (task *provisionerTask) setErrorStatus(message string, machine *apiprovisio
 	return nil
 }
 
+// startInstanceFailed records a failed attempt to start an instance
+// for the given machine. If the error is retryable and the machine
+// has attempts remaining, a retry is scheduled; the attempt count and
+// the time of the next retry are recorded in the machine's status data.
+func (task *provisionerTask) startInstanceFailed(machine *apiprovisioner.Machine, err error) error {
+	id := machine.Id()
+	var data params.StatusData
+	var delay time.Duration
+	retry := false
+	if environs.IsRetryable(err) {
+		var attempt int
+		attempt, delay, retry = task.retries.failed(id)
+		data = params.StatusData{
+			params.StatusDataProvisioningAttempt:     attempt,
+			params.StatusDataProvisioningMaxAttempts: task.retries.strategy.MaxAttempts,
+		}
+		if retry {
+			retryAt := time.Now().Add(delay).UTC().Format(time.RFC3339)
+			logger.Warningf("cannot start instance for machine %q (attempt %d), retrying at %s: %v", machine, attempt, retryAt, err)
+			data[params.StatusDataProvisioningNextRetry] = retryAt
+		} else {
+			logger.Errorf("cannot start instance for machine %q after %d attempts: %v", machine, attempt, err)
+		}
+	} else {
+		// Retrying would only fail in the same way.
+		task.retries.forget(id)
+		logger.Errorf("cannot start instance for machine %q: %v", machine, err)
+	}
+	info := fmt.Sprintf("cannot start instance for machine %q: %v", machine, err)
+	if err1 := machine.SetStatus(params.StatusError, info, data); err1 != nil {
+		// Something is wrong with this machine, better report it back.
+		return errors.Annotatef(err1, "cannot set error status for machine %q", machine)
+	}
+	if retry {
+		// Only schedule the retry once the status is set,
+		// as retryMachine only retries machines in error.
+		task.retries.schedule(id, delay, func() {
+			select {
+			case task.retryMachines <- id:
+			case <-task.tomb.Dying():
+			}
+		})
+	}
+	return nil
+}
+
 func (task *provisionerTask) prepareNetworkAndInterfaces(networkInfo []network.Info) (
 	networks []params.Network, ifaces []params.NetworkInterface) {
 	if len(networkInfo) == 0 {
@@ -453,11 +588,14 @@ func (task *provisionerTask) prepareNetworkAndInterfaces(networkInfo []network.I
 }
 
 func (task *provisionerTask) startMachine(machine *apiprovisioner.Machine) error {
+	task.prepareMu.Lock()
 	provisioningInfo, err := task.provisioningInfo(machine)
 	if err != nil {
+		task.prepareMu.Unlock()
 		return err
 	}
 	possibleTools, err := task.possibleTools(provisioningInfo.Series, provisioningInfo.Constraints)
+	task.prepareMu.Unlock()
 	if err != nil {
 		return task.setErrorStatus("cannot find tools for machine %q: %v", machine, err)
 	}
@@ -467,13 +605,15 @@ func (task *provisionerTask) startMachine(machine *apiprovisioner.Machine) error
 		MachineConfig:     provisioningInfo.MachineConfig,
 		Placement:         provisioningInfo.Placement,
 		DistributionGroup: machine.DistributionGroup,
+		CloneFrom:         provisioningInfo.CloneFrom,
 	})
 	if err != nil {
-		// Set the state to error, so the machine will be skipped next
-		// time until the error is resolved, but don't return an
+		// Set the state to error, so the machine will be skipped until
+		// it is retried or the error is resolved, but don't return an
 		// error; just keep going with the other machines.
-		return task.setErrorStatus("cannot start instance for machine %q: %v", machine, err)
+		return task.startInstanceFailed(machine, err)
 	}
+	task.retries.forget(machine.Id())
 	nonce := provisioningInfo.MachineConfig.MachineNonce
 	networks, ifaces := task.prepareNetworkAndInterfaces(networkInfo)
 
@@ -511,6 +651,7 @@ type provisioningInfo struct {
 	Constraints   constraints.Value
 	Series        string
 	Placement     string
+	CloneFrom     instance.Id
 	MachineConfig *cloudinit.MachineConfig
 }
 
@@ -550,6 +691,7 @@ func (task *provisionerTask) provisioningInfo(machine *apiprovisioner.Machine) (
 		Constraints:   pInfo.Constraints,
 		Series:        pInfo.Series,
 		Placement:     pInfo.Placement,
+		CloneFrom:     pInfo.CloneFrom,
 		MachineConfig: machineConfig,
 	}, nil
 }
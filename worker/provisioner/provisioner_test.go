@@ -6,6 +6,7 @@ package provisioner_test
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -916,6 +917,18 @@ func (s *ProvisionerSuite) TestProvisioningSafeModeChange(c *gc.C) {
 func (s *ProvisionerSuite) newProvisionerTask(
 	c *gc.C, safeMode bool, broker environs.InstanceBroker, machineGetter provisioner.MachineGetter,
 ) provisioner.ProvisionerTask {
+	retryStrategy := provisioner.RetryStrategy{
+		MaxAttempts: config.DefaultProvisionerRetryCount,
+		Delay:       provisioner.DefaultRetryDelay,
+		MaxDelay:    provisioner.DefaultMaxRetryDelay,
+	}
+	return s.newProvisionerTaskWithOptions(c, safeMode, broker, machineGetter, config.DefaultProvisionerParallelism, retryStrategy)
+}
+
+func (s *ProvisionerSuite) newProvisionerTaskWithOptions(
+	c *gc.C, safeMode bool, broker environs.InstanceBroker, machineGetter provisioner.MachineGetter,
+	parallelism int, retryStrategy provisioner.RetryStrategy,
+) provisioner.ProvisionerTask {
 
 	machineWatcher, err := s.provisioner.WatchEnvironMachines()
 	c.Assert(err, gc.IsNil)
@@ -925,7 +938,8 @@ func (s *ProvisionerSuite) newProvisionerTask(
 	c.Assert(err, gc.IsNil)
 	return provisioner.NewProvisionerTask(
 		names.NewMachineTag("0"), safeMode, machineGetter,
-		machineWatcher, retryWatcher, broker, auth)
+		machineWatcher, retryWatcher, broker, auth,
+		parallelism, retryStrategy)
 }
 
 func (s *ProvisionerSuite) TestTurningOffSafeModeReapsUnknownInstances(c *gc.C) {
@@ -995,8 +1009,245 @@ func (s *ProvisionerSuite) TestProvisionerRetriesTransientErrors(c *gc.C) {
 	c.Assert(err, jc.Satisfies, state.IsNotProvisionedError)
 }
 
+func (s *ProvisionerSuite) TestProvisionerRetriesStartInstanceAutomatically(c *gc.C) {
+	m0, err := s.addMachine()
+	c.Assert(err, gc.IsNil)
+	m1, err := s.addMachine()
+	c.Assert(err, gc.IsNil)
+	broker := &failingBroker{
+		Environ: s.Environ,
+		// m0 starts on its third attempt; m1 never starts.
+		failures: map[string]int{m0.Id(): 2, m1.Id(): 100},
+		attempts: make(map[string]int),
+	}
+	retryStrategy := provisioner.RetryStrategy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+	}
+	task := s.newProvisionerTaskWithOptions(c, false, broker, s.provisioner, 2, retryStrategy)
+	defer stop(c, task)
+
+	s.checkStartInstance(c, m0)
+
+	// m1 gives up after three attempts, recording
+	// the attempts in its status.
+	s.waitMachine(c, m1, func() bool {
+		err := m1.Refresh()
+		c.Assert(err, gc.IsNil)
+		status, _, data, err := m1.Status()
+		c.Assert(err, gc.IsNil)
+		return status == params.StatusError && fmt.Sprint(data[params.StatusDataProvisioningAttempt]) == "3"
+	})
+	status, info, data, err := m1.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusError)
+	c.Assert(info, gc.Equals, fmt.Sprintf("cannot start instance for machine %q: start instance failed", m1.Id()))
+	c.Assert(fmt.Sprint(data[params.StatusDataProvisioningMaxAttempts]), gc.Equals, "3")
+	c.Assert(data[params.StatusDataProvisioningNextRetry], gc.IsNil)
+	s.checkNoOperations(c)
+	c.Assert(broker.attemptCount(m0.Id()), gc.Equals, 3)
+	c.Assert(broker.attemptCount(m1.Id()), gc.Equals, 3)
+}
+
+func (s *ProvisionerSuite) TestProvisionerDoesNotRetryPermanentErrors(c *gc.C) {
+	m0, err := s.addMachine()
+	c.Assert(err, gc.IsNil)
+	broker := &failingBroker{
+		Environ:   s.Environ,
+		failures:  map[string]int{m0.Id(): 100},
+		attempts:  make(map[string]int),
+		permanent: true,
+	}
+	retryStrategy := provisioner.RetryStrategy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+	}
+	task := s.newProvisionerTaskWithOptions(c, false, broker, s.provisioner, 2, retryStrategy)
+	defer stop(c, task)
+
+	s.waitMachine(c, m0, func() bool {
+		err := m0.Refresh()
+		c.Assert(err, gc.IsNil)
+		status, _, _, err := m0.Status()
+		c.Assert(err, gc.IsNil)
+		return status == params.StatusError
+	})
+	// Give the provisioner time to retry, were it going to.
+	time.Sleep(coretesting.ShortWait)
+	status, info, data, err := m0.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusError)
+	c.Assert(info, gc.Equals, fmt.Sprintf("cannot start instance for machine %q: start instance failed", m0.Id()))
+	c.Assert(data[params.StatusDataProvisioningAttempt], gc.IsNil)
+	c.Assert(data[params.StatusDataProvisioningNextRetry], gc.IsNil)
+	c.Assert(broker.attemptCount(m0.Id()), gc.Equals, 1)
+}
+
+func (s *ProvisionerSuite) TestProvisionerStartsInstancesInParallel(c *gc.C) {
+	var machines []*state.Machine
+	for i := 0; i < 3; i++ {
+		m, err := s.addMachine()
+		c.Assert(err, gc.IsNil)
+		machines = append(machines, m)
+	}
+	broker := &blockingBroker{
+		Environ: s.Environ,
+		started: make(chan string),
+		release: make(chan struct{}),
+	}
+	retryStrategy := provisioner.RetryStrategy{MaxAttempts: 1}
+	task := s.newProvisionerTaskWithOptions(c, false, broker, s.provisioner, 2, retryStrategy)
+	defer stop(c, task)
+
+	// Two instances are started at once, but not a third.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-broker.started:
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for instance %d to start", i)
+		}
+	}
+	select {
+	case id := <-broker.started:
+		c.Fatalf("machine %s started while two others were starting", id)
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	close(broker.release)
+	select {
+	case <-broker.started:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for last instance to start")
+	}
+	for _, m := range machines {
+		s.waitHardwareCharacteristics(c, m, func() bool {
+			_, err := m.InstanceId()
+			return err == nil
+		})
+	}
+}
+
+func (s *ProvisionerSuite) TestProvisionerSerialisesAuthentication(c *gc.C) {
+	var machines []*state.Machine
+	for i := 0; i < 3; i++ {
+		m, err := s.addMachine()
+		c.Assert(err, gc.IsNil)
+		machines = append(machines, m)
+	}
+	auth, err := authentication.NewAPIAuthenticator(s.provisioner)
+	c.Assert(err, gc.IsNil)
+	checkingAuth := &serialAuth{AuthenticationProvider: auth}
+	machineWatcher, err := s.provisioner.WatchEnvironMachines()
+	c.Assert(err, gc.IsNil)
+	retryWatcher, err := s.provisioner.WatchMachineErrorRetry()
+	c.Assert(err, gc.IsNil)
+	task := provisioner.NewProvisionerTask(
+		names.NewMachineTag("0"), false, s.provisioner,
+		machineWatcher, retryWatcher, s.Environ, checkingAuth,
+		3, provisioner.RetryStrategy{MaxAttempts: 1})
+	defer stop(c, task)
+
+	for _, m := range machines {
+		s.waitHardwareCharacteristics(c, m, func() bool {
+			_, err := m.InstanceId()
+			return err == nil
+		})
+	}
+	c.Assert(checkingAuth.overlapped(), jc.IsFalse)
+}
+
+// serialAuth records whether SetupAuthentication
+// was ever called concurrently.
+type serialAuth struct {
+	authentication.AuthenticationProvider
+	mu      sync.Mutex
+	active  int
+	overlap bool
+}
+
+func (a *serialAuth) SetupAuthentication(machine authentication.TaggedPasswordChanger) (*authentication.MongoInfo, *api.Info, error) {
+	a.mu.Lock()
+	a.active++
+	if a.active > 1 {
+		a.overlap = true
+	}
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.active--
+		a.mu.Unlock()
+	}()
+	// Give any concurrent caller a chance to overlap.
+	time.Sleep(coretesting.ShortWait)
+	return a.AuthenticationProvider.SetupAuthentication(machine)
+}
+
+func (a *serialAuth) overlapped() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.overlap
+}
+
+// failingBroker fails to start instances for each machine the
+// given number of times. The errors are retryable unless
+// permanent is set.
+type failingBroker struct {
+	environs.Environ
+	mu        sync.Mutex
+	failures  map[string]int
+	attempts  map[string]int
+	permanent bool
+}
+
+func (b *failingBroker) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	id := args.MachineConfig.MachineId
+	b.mu.Lock()
+	b.attempts[id]++
+	fail := b.attempts[id] <= b.failures[id]
+	b.mu.Unlock()
+	if fail {
+		err := fmt.Errorf("start instance failed")
+		if !b.permanent {
+			err = environs.NewRetryableError(err)
+		}
+		return nil, nil, nil, err
+	}
+	return b.Environ.StartInstance(args)
+}
+
+func (b *failingBroker) attemptCount(id string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attempts[id]
+}
+
+func (b *failingBroker) GetToolsSources() ([]simplestreams.DataSource, error) {
+	return b.Environ.(tools.SupportsCustomSources).GetToolsSources()
+}
+
+// blockingBroker reports each started instance and
+// waits to be released before starting it.
+type blockingBroker struct {
+	environs.Environ
+	started chan string
+	release chan struct{}
+}
+
+func (b *blockingBroker) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	b.started <- args.MachineConfig.MachineId
+	<-b.release
+	return b.Environ.StartInstance(args)
+}
+
+func (b *blockingBroker) GetToolsSources() ([]simplestreams.DataSource, error) {
+	return b.Environ.(tools.SupportsCustomSources).GetToolsSources()
+}
+
 type mockBroker struct {
 	environs.Environ
+	mu         sync.Mutex
 	retryCount map[string]int
 }
 
@@ -1005,12 +1256,15 @@ func (b *mockBroker) StartInstance(args environs.StartInstanceParams) (instance.
 	// Machines 3 is provisioned after some attempts have been made.
 	// Machine 4 is never provisioned.
 	id := args.MachineConfig.MachineId
+	b.mu.Lock()
 	retries := b.retryCount[id]
 	if (id != "3" && id != "4") || retries > 2 {
+		b.mu.Unlock()
 		return b.Environ.StartInstance(args)
 	} else {
 		b.retryCount[id] = retries + 1
 	}
+	b.mu.Unlock()
 	return nil, nil, nil, fmt.Errorf("error: some error")
 }
 
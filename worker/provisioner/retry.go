@@ -0,0 +1,115 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryStrategy defines how the provisioner retries failed
+// attempts to start an instance for a machine.
+type RetryStrategy struct {
+	// MaxAttempts holds the number of times an instance is
+	// started for a machine before giving up.
+	MaxAttempts int
+
+	// Delay holds the time to wait before the first retry.
+	// The delay doubles for each subsequent retry.
+	Delay time.Duration
+
+	// MaxDelay holds the longest time to wait between retries.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryDelay and DefaultMaxRetryDelay hold the delays used
+// when retrying failed attempts to start instances.
+var (
+	DefaultRetryDelay    = 10 * time.Second
+	DefaultMaxRetryDelay = 5 * time.Minute
+)
+
+// delay returns the time to wait before retrying after
+// the given failed attempt.
+func (s RetryStrategy) delay(attempt int) time.Duration {
+	d := s.Delay
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= s.MaxDelay {
+			return s.MaxDelay
+		}
+	}
+	return d
+}
+
+// machineRetries tracks the failed attempts to start instances for
+// machines, and schedules retries. It is safe to call its methods
+// concurrently.
+type machineRetries struct {
+	mu       sync.Mutex
+	strategy RetryStrategy
+	attempts map[string]int
+	timers   map[string]*time.Timer
+}
+
+func newMachineRetries(strategy RetryStrategy) *machineRetries {
+	return &machineRetries{
+		strategy: strategy,
+		attempts: make(map[string]int),
+		timers:   make(map[string]*time.Timer),
+	}
+}
+
+// failed records a failed attempt to start an instance for the
+// machine with the given id, cancelling any pending retry. It returns
+// the number of the failed attempt and, if the machine has attempts
+// remaining, the time to wait before retrying.
+func (r *machineRetries) failed(id string) (attempt int, delay time.Duration, retry bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopTimer(id)
+	r.attempts[id]++
+	attempt = r.attempts[id]
+	if attempt >= r.strategy.MaxAttempts {
+		delete(r.attempts, id)
+		return attempt, 0, false
+	}
+	return attempt, r.strategy.delay(attempt), true
+}
+
+// schedule arranges for retry to be called after the given delay,
+// unless the retry is cancelled first.
+func (r *machineRetries) schedule(id string, delay time.Duration, retry func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopTimer(id)
+	r.timers[id] = time.AfterFunc(delay, retry)
+}
+
+// forget discards any failed attempts recorded for the machine
+// with the given id and cancels any pending retry.
+func (r *machineRetries) forget(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopTimer(id)
+	delete(r.attempts, id)
+}
+
+// stop cancels all pending retries.
+func (r *machineRetries) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id := range r.timers {
+		r.stopTimer(id)
+	}
+}
+
+// stopTimer cancels any pending retry for the machine with the
+// given id. The caller must hold r.mu.
+func (r *machineRetries) stopTimer(id string) {
+	if t := r.timers[id]; t != nil {
+		t.Stop()
+		delete(r.timers, id)
+	}
+}
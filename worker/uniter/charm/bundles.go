@@ -10,11 +10,14 @@ import (
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
-	"github.com/juju/utils"
 
 	"github.com/juju/juju/downloader"
 )
 
+// downloadRetries holds the number of times a failed charm
+// download is retried before giving up.
+var downloadRetries = 3
+
 // BundlesDir is responsible for storing and retrieving charm bundles
 // identified by state charms.
 type BundlesDir struct {
@@ -41,8 +44,9 @@ func (d *BundlesDir) Read(info BundleInfo, abort <-chan struct{}) (Bundle, error
 	return charm.ReadBundle(path)
 }
 
-// download fetches the supplied charm and checks that it has the correct sha256
-// hash, then copies it into the directory. If a value is received on abort, the
+// download fetches the supplied charm, resuming and retrying the download
+// if it is interrupted, and checks that it has the correct sha256 hash, then
+// copies it into the directory. If a value is received on abort, the
 // download will be stopped.
 func (d *BundlesDir) download(info BundleInfo, abort <-chan struct{}) (err error) {
 	archiveURL, disableSSLHostnameVerification, err := info.ArchiveURL()
@@ -59,7 +63,17 @@ func (d *BundlesDir) download(info BundleInfo, abort <-chan struct{}) (err error
 	if disableSSLHostnameVerification {
 		logger.Infof("SSL hostname verification disabled")
 	}
-	dl := downloader.New(aurl, dir, disableSSLHostnameVerification)
+	archiveSha256, err := info.ArchiveSha256()
+	if err != nil {
+		return err
+	}
+	dl := downloader.NewWithOptions(downloader.Options{
+		URL:                  aurl,
+		Dir:                  dir,
+		HostnameVerification: disableSSLHostnameVerification,
+		ExpectedSHA256:       archiveSha256,
+		MaxRetries:           downloadRetries,
+	})
 	defer dl.Stop()
 	for {
 		select {
@@ -70,21 +84,7 @@ func (d *BundlesDir) download(info BundleInfo, abort <-chan struct{}) (err error
 			if st.Err != nil {
 				return st.Err
 			}
-			logger.Infof("download complete")
 			defer st.File.Close()
-			actualSha256, _, err := utils.ReadSHA256(st.File)
-			if err != nil {
-				return err
-			}
-			archiveSha256, err := info.ArchiveSha256()
-			if err != nil {
-				return err
-			}
-			if actualSha256 != archiveSha256 {
-				return fmt.Errorf(
-					"expected sha256 %q, got %q", archiveSha256, actualSha256,
-				)
-			}
 			logger.Infof("download verified")
 			if err := os.MkdirAll(d.path, 0755); err != nil {
 				return err
@@ -106,7 +106,7 @@ func (s *BundlesDirSuite) TestGet(c *gc.C) {
 	gitjujutesting.Server.Response(200, nil, []byte("roflcopter"))
 	_, err = d.Read(apiCharm, nil)
 	prefix := fmt.Sprintf(`failed to download charm "cs:quantal/dummy-1" from %q: `, sch.BundleURL())
-	c.Assert(err, gc.ErrorMatches, prefix+fmt.Sprintf(`expected sha256 %q, got ".*"`, sch.BundleSha256()))
+	c.Assert(err, gc.ErrorMatches, prefix+fmt.Sprintf(`cannot download ".*": expected sha256 %q, got ".*"`, sch.BundleSha256()))
 
 	// Try to get a charm whose bundle doesn't exist.
 	gitjujutesting.Server.Response(404, nil, nil)
@@ -90,6 +90,14 @@ type HookContext struct {
 
 	// proxySettings are the current proxy settings that the uniter knows about
 	proxySettings proxy.Settings
+
+	// migration describes the migration the unit is taking part in when
+	// running a migrate-out or migrate-in hook; it is nil otherwise.
+	migration *params.UnitMigration
+
+	// metrics holds the metrics recorded by add-metric, to be
+	// reported when the context is finalized.
+	metrics []params.Metric
 }
 
 func NewHookContext(
@@ -151,6 +159,15 @@ func (ctx *HookContext) ClosePort(protocol string, port int) error {
 	return ctx.unit.ClosePort(protocol, port)
 }
 
+func (ctx *HookContext) AddMetric(key string, value float64) error {
+	ctx.metrics = append(ctx.metrics, params.Metric{
+		Key:   key,
+		Value: value,
+		Time:  time.Now(),
+	})
+	return nil
+}
+
 func (ctx *HookContext) OwnerTag() string {
 	return ctx.serviceOwner
 }
@@ -217,6 +234,7 @@ func (ctx *HookContext) hookVars(charmDir, toolsDir, socketPath string) []string
 		name, _ := ctx.RemoteUnitName()
 		vars = append(vars, "JUJU_REMOTE_UNIT="+name)
 	}
+	vars = append(vars, ctx.migrationVars()...)
 	vars = append(vars, ctx.proxySettings.AsEnvironmentValues()...)
 	return vars
 }
@@ -238,6 +256,16 @@ func (ctx *HookContext) finalizeContext(process string, err error) error {
 		}
 		rctx.ClearCache()
 	}
+	if writeChanges && len(ctx.metrics) > 0 {
+		if e := ctx.unit.AddMetrics(ctx.metrics); e != nil {
+			e = fmt.Errorf("could not add metrics from %q: %v", process, e)
+			logger.Errorf("%v", e)
+			if err == nil {
+				err = e
+			}
+		}
+	}
+	ctx.metrics = nil
 	return err
 }
 
@@ -788,6 +788,36 @@ func (s *RunCommandSuite) TestRunCommandsHasEnvironSet(c *gc.C) {
 	}
 }
 
+func (s *RunCommandSuite) TestRunCommandsHasMigrationEnvironSet(c *gc.C) {
+	context := s.getHookContext(c)
+	uniter.SetHookContextMigration(context, params.UnitMigration{
+		Source:        "u/0",
+		Target:        "u/1",
+		Phase:         params.MigrationExporting,
+		RemoteAddress: "10.0.0.2",
+	})
+	charmDir := c.MkDir()
+	result, err := context.RunCommands("env | sort", charmDir, "/path/to/tools", "/path/to/socket")
+	c.Assert(err, gc.IsNil)
+	stdout := string(result.Stdout)
+	c.Check(stdout, jc.Contains, "JUJU_MIGRATION_SOURCE=u/0\n")
+	c.Check(stdout, jc.Contains, "JUJU_MIGRATION_TARGET=u/1\n")
+	c.Check(stdout, jc.Contains, "JUJU_MIGRATION_REMOTE_ADDRESS=10.0.0.2\n")
+}
+
+func (s *RunCommandSuite) TestRunCommandsReportsMetrics(c *gc.C) {
+	context := s.getHookContext(c)
+	err := context.AddMetric("load", 0.5)
+	c.Assert(err, gc.IsNil)
+	_, err = context.RunCommands("true", c.MkDir(), "/path/to/tools", "/path/to/socket")
+	c.Assert(err, gc.IsNil)
+	metrics, err := s.unit.Metrics()
+	c.Assert(err, gc.IsNil)
+	c.Assert(metrics, gc.HasLen, 1)
+	c.Assert(metrics[0].Key, gc.Equals, "load")
+	c.Assert(metrics[0].Value, gc.Equals, 0.5)
+}
+
 func (s *RunCommandSuite) TestRunCommandsStdOutAndErrAndRC(c *gc.C) {
 	context := s.getHookContext(c)
 	charmDir := c.MkDir()
@@ -4,7 +4,11 @@
 package uniter
 
 import (
+	"time"
+
 	"github.com/juju/utils/proxy"
+
+	"github.com/juju/juju/state/api/params"
 )
 
 func SetUniterObserver(u *Uniter, observer UniterExecutionObserver) {
@@ -16,3 +20,13 @@ func (u *Uniter) GetProxyValues() proxy.Settings {
 	defer u.proxyMutex.Unlock()
 	return u.proxy
 }
+
+func SetHookContextMigration(ctx *HookContext, migration params.UnitMigration) {
+	ctx.migration = &migration
+}
+
+func SetCollectMetricsInterval(interval time.Duration) (restore func()) {
+	old := collectMetricsInterval
+	collectMetricsInterval = interval
+	return func() { collectMetricsInterval = old }
+}
@@ -46,6 +46,8 @@ type filter struct {
 	outResolvedOn  chan params.ResolvedMode
 	outRelations   chan []int
 	outRelationsOn chan []int
+	outMigration   chan params.UnitMigration
+	outMigrationOn chan params.UnitMigration
 
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
@@ -85,6 +87,7 @@ type filter struct {
 	unit             *uniter.Unit
 	life             params.Life
 	resolved         params.ResolvedMode
+	migration        params.UnitMigration
 	service          *uniter.Service
 	upgradeFrom      serviceCharm
 	upgradeAvailable serviceCharm
@@ -110,6 +113,8 @@ func newFilter(st *uniter.State, unitTag string) (*filter, error) {
 		outResolvedOn:     make(chan params.ResolvedMode),
 		outRelations:      make(chan []int),
 		outRelationsOn:    make(chan []int),
+		outMigration:      make(chan params.UnitMigration),
+		outMigrationOn:    make(chan params.UnitMigration),
 		wantForcedUpgrade: make(chan bool),
 		wantResolved:      make(chan struct{}),
 		discardConfig:     make(chan struct{}),
@@ -178,6 +183,13 @@ func (f *filter) RelationsEvents() <-chan []int {
 	return f.outRelationsOn
 }
 
+// MigrationEvents returns a channel that will receive the unit's migration
+// whenever the unit starts taking part in a migration, or the migration's
+// phase changes.
+func (f *filter) MigrationEvents() <-chan params.UnitMigration {
+	return f.outMigrationOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -429,6 +441,9 @@ func (f *filter) loop(unitTag string) (err error) {
 		case f.outAction <- f.nextAction:
 			f.nextAction = f.getNextAction()
 			filterLogger.Debugf("sent action event")
+		case f.outMigration <- f.migration:
+			filterLogger.Debugf("sent migration event")
+			f.outMigration = nil
 		case f.outRelations <- f.relations:
 			filterLogger.Debugf("sent relations event")
 			f.outRelations = nil
@@ -531,6 +546,31 @@ func (f *filter) unitChanged() error {
 			f.outResolved = f.outResolvedOn
 		}
 	}
+	return f.migrationChanged()
+}
+
+// migrationChanged responds to changes in the migration the unit is taking
+// part in.
+func (f *filter) migrationChanged() error {
+	migration, err := f.unit.Migration()
+	if params.IsCodeNotImplemented(err) {
+		// The state server is too old to migrate units.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// The remote address is only needed by the migration hooks, which
+	// look it up afresh when they run; changes to it are not events.
+	migration.RemoteAddress = ""
+	if migration != f.migration {
+		f.migration = migration
+		if f.migration.Phase != "" {
+			filterLogger.Debugf("preparing new migration event")
+			f.outMigration = f.outMigrationOn
+		} else {
+			f.outMigration = nil
+		}
+	}
 	return nil
 }
 
@@ -214,6 +214,49 @@ func (s *FilterSuite) TestResolvedEvents(c *gc.C) {
 	assertChange(params.ResolvedNoHooks)
 }
 
+func (s *FilterSuite) TestMigrationEvents(c *gc.C) {
+	f, err := newFilter(s.uniter, s.unit.Tag().String())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+
+	migrationAsserter := coretesting.ContentAsserterC{
+		C:       c,
+		Precond: func() { s.BackingState.StartSync() },
+		Chan:    f.MigrationEvents(),
+	}
+	migrationAsserter.AssertNoReceive()
+
+	// Start migrating the unit; new event received.
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	target, err := s.unit.MigrateTo(machine)
+	c.Assert(err, gc.IsNil)
+	assertChange := func(phase params.MigrationPhase) {
+		migration := migrationAsserter.AssertOneReceive().(params.UnitMigration)
+		c.Assert(migration, gc.Equals, params.UnitMigration{
+			Source: "wordpress/0",
+			Target: "wordpress/1",
+			Phase:  phase,
+		})
+	}
+	assertChange(params.MigrationProvisioning)
+
+	// Change the unit in an irrelevant way; no events.
+	err = s.unit.SetResolved(state.ResolvedRetryHooks)
+	c.Assert(err, gc.IsNil)
+	migrationAsserter.AssertNoReceive()
+
+	// Advance the migration; new event received.
+	err = target.SetMigrationPhase(state.MigrationExporting)
+	c.Assert(err, gc.IsNil)
+	assertChange(params.MigrationExporting)
+
+	// Abort the migration; no events.
+	err = s.unit.AbortMigration()
+	c.Assert(err, gc.IsNil)
+	migrationAsserter.AssertNoReceive()
+}
+
 func (s *FilterSuite) TestCharmUpgradeEvents(c *gc.C) {
 	oldCharm := s.AddTestingCharm(c, "upgrade1")
 	svc := s.AddTestingService(c, "upgradetest", oldCharm)
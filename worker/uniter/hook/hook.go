@@ -11,6 +11,21 @@ import (
 	"github.com/juju/names"
 )
 
+const (
+	// MigrateOut is run on the source unit of a migration, once the
+	// target unit has started, so the charm can export the unit's data.
+	MigrateOut hooks.Kind = "migrate-out"
+
+	// MigrateIn is run on the target unit of a migration, once the
+	// source unit has run its migrate-out hook, so the charm can import
+	// the source unit's data.
+	MigrateIn hooks.Kind = "migrate-in"
+
+	// CollectMetrics is run periodically, so the charm can report
+	// metrics for the unit with add-metric.
+	CollectMetrics hooks.Kind = "collect-metrics"
+)
+
 // Info holds details required to execute a hook. Not all fields are
 // relevant to all Kind values.
 type Info struct {
@@ -43,6 +58,8 @@ func (hi Info) Validate() error {
 		fallthrough
 	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken:
 		return nil
+	case MigrateOut, MigrateIn, CollectMetrics:
+		return nil
 	case hooks.ActionRequested:
 		if !names.IsValidAction(hi.ActionId) {
 			return fmt.Errorf("action id %q cannot be parsed as an action tag", hi.ActionId)
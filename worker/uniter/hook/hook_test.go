@@ -51,6 +51,8 @@ var validateTests = []struct {
 	{hook.Info{Kind: hooks.RelationChanged, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationDeparted, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationBroken}, ""},
+	{hook.Info{Kind: hook.MigrateOut}, ""},
+	{hook.Info{Kind: hook.MigrateIn}, ""},
 }
 
 func (s *InfoSuite) TestValidate(c *gc.C) {
@@ -0,0 +1,66 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/cmd"
+)
+
+// AddMetricCommand implements the add-metric command.
+type AddMetricCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Metrics map[string]float64
+}
+
+func NewAddMetricCommand(ctx Context) cmd.Command {
+	return &AddMetricCommand{ctx: ctx}
+}
+
+func (c *AddMetricCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add-metric",
+		Args:    "key=value [key=value ...]",
+		Purpose: "record numeric measurements of the unit",
+		Doc: `
+The metrics are recorded, with the current time, when the hook completes
+successfully. Charms usually report metrics from the collect-metrics hook,
+which is run periodically. Recorded metrics are shown by juju metrics, and
+may be used to autoscale the unit's service.
+`,
+	}
+}
+
+func (c *AddMetricCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no metrics specified")
+	}
+	c.Metrics = make(map[string]float64)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf(`expected "key=value", got %q`, arg)
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for metric %q: %q is not a number", parts[0], parts[1])
+		}
+		c.Metrics[parts[0]] = value
+	}
+	return nil
+}
+
+func (c *AddMetricCommand) Run(_ *cmd.Context) error {
+	for key, value := range c.Metrics {
+		if err := c.ctx.AddMetric(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
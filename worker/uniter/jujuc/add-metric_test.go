@@ -0,0 +1,68 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type AddMetricSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&AddMetricSuite{})
+
+func (s *AddMetricSuite) TestHelp(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "add-metric")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"--help"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), gc.Matches, `(?s)usage: add-metric key=value \[key=value ...\]
+purpose: record numeric measurements of the unit
+.*`)
+}
+
+func (s *AddMetricSuite) TestAddMetric(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "add-metric")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"load=0.75", "users=12"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(hctx.metrics, gc.DeepEquals, map[string]float64{"load": 0.75, "users": 12})
+}
+
+var addMetricErrorTests = []struct {
+	args []string
+	err  string
+}{{
+	args: nil,
+	err:  "no metrics specified",
+}, {
+	args: []string{"load"},
+	err:  `expected "key=value", got "load"`,
+}, {
+	args: []string{"=1"},
+	err:  `expected "key=value", got "=1"`,
+}, {
+	args: []string{"load=high"},
+	err:  `invalid value for metric "load": "high" is not a number`,
+}}
+
+func (s *AddMetricSuite) TestAddMetricErrors(c *gc.C) {
+	for i, t := range addMetricErrorTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "add-metric")
+		c.Assert(err, gc.IsNil)
+		err = testing.InitCommand(com, t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
@@ -56,6 +56,10 @@ type Context interface {
 
 	// OwnerTag returns the owner of the service the executing units belongs to
 	OwnerTag() string
+
+	// AddMetric records a numeric measurement of the executing unit,
+	// to be reported when the hook completes successfully.
+	AddMetric(key string, value float64) error
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
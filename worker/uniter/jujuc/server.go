@@ -24,6 +24,7 @@ var logger = loggo.GetLogger("worker.uniter.jujuc")
 
 // newCommands maps Command names to initializers.
 var newCommands = map[string]func(Context) cmd.Command{
+	"add-metric" + cmdSuffix:    NewAddMetricCommand,
 	"close-port" + cmdSuffix:    NewClosePortCommand,
 	"config-get" + cmdSuffix:    NewConfigGetCommand,
 	"juju-log" + cmdSuffix:      NewJujuLogCommand,
@@ -191,6 +191,7 @@ var newCommandTests = []struct {
 	name string
 	err  string
 }{
+	{"add-metric", ""},
 	{"close-port", ""},
 	{"config-get", ""},
 	{"juju-log", ""},
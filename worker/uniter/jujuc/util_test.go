@@ -79,10 +79,11 @@ func setSettings(c *gc.C, ru *state.RelationUnit, settings map[string]interface{
 }
 
 type Context struct {
-	ports  set.Strings
-	relid  int
-	remote string
-	rels   map[int]*ContextRelation
+	ports   set.Strings
+	relid   int
+	remote  string
+	rels    map[int]*ContextRelation
+	metrics map[string]float64
 }
 
 func (c *Context) UnitName() string {
@@ -142,6 +143,14 @@ func (c *Context) OwnerTag() string {
 	return "test-owner"
 }
 
+func (c *Context) AddMetric(key string, value float64) error {
+	if c.metrics == nil {
+		c.metrics = make(map[string]float64)
+	}
+	c.metrics[key] = value
+	return nil
+}
+
 type ContextRelation struct {
 	id    int
 	name  string
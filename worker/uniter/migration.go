@@ -0,0 +1,77 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// migrationHook responds to a change in the migration the unit is taking
+// part in, and returns the hook that should be run as a result, if any.
+func (u *Uniter) migrationHook(migration params.UnitMigration) *hook.Info {
+	u.migration = migration
+	if migration.Source == u.unit.Name() {
+		if migration.Phase == params.MigrationExporting {
+			return &hook.Info{Kind: hook.MigrateOut}
+		}
+		return nil
+	}
+	switch migration.Phase {
+	case params.MigrationProvisioning:
+		// The unit has started, so the source unit can export its data.
+		logger.Infof("ready to import data from unit %q", migration.Source)
+		if err := u.unit.SetMigrationPhase(params.MigrationExporting); err != nil {
+			logger.Warningf("cannot start migration from unit %q: %v", migration.Source, err)
+		}
+	case params.MigrationImporting:
+		return &hook.Info{Kind: hook.MigrateIn}
+	case params.MigrationHandingOver:
+		u.completeMigration()
+	}
+	return nil
+}
+
+// completeMigration attempts to take over from the source unit of the
+// migration the unit is the target of. This fails until the unit has
+// joined all of the source unit's relations, so failures are logged and
+// the handover is retried whenever the unit's relations change.
+func (u *Uniter) completeMigration() {
+	if u.migration.Target != u.unit.Name() || u.migration.Phase != params.MigrationHandingOver {
+		return
+	}
+	if err := u.unit.CompleteMigration(); err != nil {
+		logger.Infof("cannot take over from unit %q yet: %v", u.migration.Source, err)
+		return
+	}
+	logger.Infof("took over from unit %q", u.migration.Source)
+	u.migration = params.UnitMigration{}
+}
+
+// commitMigrationHook records that a migration hook has run, advancing the
+// migration to its next phase.
+func (u *Uniter) commitMigrationHook(hi hook.Info) {
+	phase := params.MigrationImporting
+	if hi.Kind == hook.MigrateIn {
+		phase = params.MigrationHandingOver
+	}
+	// The migration may have been aborted while the hook was running,
+	// which is no reason to stop the unit.
+	if err := u.unit.SetMigrationPhase(phase); err != nil {
+		logger.Warningf("cannot advance migration after %q hook: %v", hi.Kind, err)
+	}
+}
+
+// migrationVars returns the environment variables that describe the
+// migration to the migrate-out and migrate-in hooks.
+func (ctx *HookContext) migrationVars() []string {
+	if ctx.migration == nil {
+		return nil
+	}
+	return []string{
+		"JUJU_MIGRATION_SOURCE=" + ctx.migration.Source,
+		"JUJU_MIGRATION_TARGET=" + ctx.migration.Target,
+		"JUJU_MIGRATION_REMOTE_ADDRESS=" + ctx.migration.RemoteAddress,
+	}
+}
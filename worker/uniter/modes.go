@@ -6,6 +6,7 @@ package uniter
 import (
 	stderrors "errors"
 	"fmt"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/charm/hooks"
@@ -239,6 +240,7 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 // modeAbideAliveLoop handles all state changes for ModeAbide when the unit
 // is in an Alive state.
 func modeAbideAliveLoop(u *Uniter) (Mode, error) {
+	collectMetrics := time.After(collectMetricsInterval)
 	for {
 		hi := hook.Info{}
 		select {
@@ -259,12 +261,35 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 			for _, r := range added {
 				r.StartHooks()
 			}
+			// Joining a relation may be all that stands between
+			// the unit and the end of a migration.
+			u.completeMigration()
 			continue
+		case migration := <-u.f.MigrationEvents():
+			migrationHook := u.migrationHook(migration)
+			if migrationHook == nil {
+				continue
+			}
+			hi = *migrationHook
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
+		case <-collectMetrics:
+			collectMetrics = time.After(collectMetricsInterval)
+			if !u.hasHook(hook.CollectMetrics) {
+				continue
+			}
+			hi = hook.Info{Kind: hook.CollectMetrics}
 		}
 		if err := u.runHook(hi); err == errHookFailed {
-			return ModeHookError, nil
+			if hi.Kind != hook.CollectMetrics {
+				return ModeHookError, nil
+			}
+			// A failure to collect metrics is no reason to stop
+			// the unit; the hook runs again at the next interval.
+			logger.Warningf("%q hook failed; running it again in %v", hi.Kind, collectMetricsInterval)
+			if err := u.writeState(Continue, Pending, &hi, nil); err != nil {
+				return nil, err
+			}
 		} else if err != nil {
 			return nil, err
 		}
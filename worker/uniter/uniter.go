@@ -39,6 +39,10 @@ import (
 
 var logger = loggo.GetLogger("juju.worker.uniter")
 
+// collectMetricsInterval holds how often the collect-metrics hook
+// is run, for charms that define it.
+var collectMetricsInterval = 5 * time.Minute
+
 const (
 	// These work fine for linux, but should we need to work with windows
 	// workloads in the future, we'll need to move these into a file that is
@@ -85,6 +89,9 @@ type Uniter struct {
 	proxyMutex sync.Mutex
 
 	ranConfigChanged bool
+	// migration holds the last known state of the migration the unit is
+	// taking part in, if any.
+	migration params.UnitMigration
 	// The execution observer is only used in tests at this stage. Should this
 	// need to be extended, perhaps a list of observers would be needed.
 	observer UniterExecutionObserver
@@ -487,6 +494,13 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	if err != nil {
 		return err
 	}
+	if hi.Kind == hook.MigrateOut || hi.Kind == hook.MigrateIn {
+		migration, err := u.unit.Migration()
+		if err != nil {
+			return err
+		}
+		hctx.migration = &migration
+	}
 	srv, socketPath, err := u.startJujucServer(hctx)
 	if err != nil {
 		return err
@@ -529,6 +543,13 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	return u.commitHook(hi)
 }
 
+// hasHook returns whether the deployed charm defines a hook
+// of the given kind.
+func (u *Uniter) hasHook(kind hooks.Kind) bool {
+	_, err := os.Stat(filepath.Join(u.charmPath, "hooks", string(kind)))
+	return err == nil
+}
+
 // commitHook ensures that state is consistent with the supplied hook, and
 // that the fact of the hook's completion is persisted.
 func (u *Uniter) commitHook(hi hook.Info) error {
@@ -544,6 +565,9 @@ func (u *Uniter) commitHook(hi hook.Info) error {
 	if hi.Kind == hooks.ConfigChanged {
 		u.ranConfigChanged = true
 	}
+	if hi.Kind == hook.MigrateOut || hi.Kind == hook.MigrateIn {
+		u.commitMigrationHook(hi)
+	}
 	if err := u.writeState(Continue, Pending, &hi, nil); err != nil {
 		return err
 	}
@@ -1346,6 +1346,46 @@ func (s *UniterSuite) TestUniterSubordinates(c *gc.C) {
 	s.runUniterTests(c, subordinatesTests)
 }
 
+var collectMetricsTests = []uniterTest{
+	ut(
+		"collect-metrics hook reports metrics",
+		createCharm{
+			customize: func(c *gc.C, ctx *context, path string) {
+				hook := "#!/bin/bash --norc\nadd-metric load=1.5\n"
+				err := ioutil.WriteFile(filepath.Join(path, "hooks", "collect-metrics"), []byte(hook), 0755)
+				c.Assert(err, gc.IsNil)
+			},
+		},
+		serveCharm{},
+		createUniter{},
+		waitUnit{status: params.StatusStarted},
+		waitMetric{"load", 1.5},
+	), ut(
+		"collect-metrics hook failure does not stop the unit",
+		createCharm{
+			customize: func(c *gc.C, ctx *context, path string) {
+				// The hook fails the first time it runs.
+				hook := "#!/bin/bash --norc\n" +
+					"if [ ! -f collected ]; then touch collected; exit 1; fi\n" +
+					"add-metric load=2\n"
+				err := ioutil.WriteFile(filepath.Join(path, "hooks", "collect-metrics"), []byte(hook), 0755)
+				c.Assert(err, gc.IsNil)
+			},
+		},
+		serveCharm{},
+		createUniter{},
+		waitUnit{status: params.StatusStarted},
+		waitMetric{"load", 2},
+		waitUnit{status: params.StatusStarted},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterCollectMetrics(c *gc.C) {
+	defer uniter.SetCollectMetricsInterval(coretesting.ShortWait)()
+	s.runUniterTests(c, collectMetricsTests)
+}
+
 func (s *UniterSuite) runUniterTests(c *gc.C, uniterTests []uniterTest) {
 	for i, t := range uniterTests {
 		c.Logf("\ntest %d: %s\n", i, t.summary)
@@ -2225,6 +2265,29 @@ func (s chmod) step(c *gc.C, ctx *context) {
 	c.Assert(err, gc.IsNil)
 }
 
+type waitMetric struct {
+	key   string
+	value float64
+}
+
+func (s waitMetric) step(c *gc.C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		select {
+		case <-timeout:
+			c.Fatalf("timed out waiting for metric %q", s.key)
+		case <-time.After(coretesting.ShortWait):
+			metrics, err := ctx.unit.Metrics()
+			c.Assert(err, gc.IsNil)
+			for _, m := range metrics {
+				if m.Key == s.key && m.Value == s.value {
+					return
+				}
+			}
+		}
+	}
+}
+
 type custom struct {
 	f func(*gc.C, *context)
 }
@@ -11,6 +11,7 @@ import (
 
 var (
 	RetryAfter           = &retryAfter
+	DownloadRetries      = &downloadRetries
 	AllowedTargetVersion = allowedTargetVersion
 )
 
@@ -5,7 +5,7 @@ package upgrader
 
 import (
 	"fmt"
-	"net/http"
+	"os"
 	"time"
 
 	"github.com/juju/loggo"
@@ -15,10 +15,12 @@ import (
 
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/downloader"
 	"github.com/juju/juju/state/api/upgrader"
 	"github.com/juju/juju/state/watcher"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
 )
 
 // retryAfter returns a channel that receives a value
@@ -27,15 +29,21 @@ var retryAfter = func() <-chan time.Time {
 	return time.After(5 * time.Second)
 }
 
+// downloadRetries holds the number of times an interrupted tools
+// download is resumed before the upgrader gives up and waits for
+// retryAfter.
+var downloadRetries = 3
+
 var logger = loggo.GetLogger("juju.worker.upgrader")
 
 // Upgrader represents a worker that watches the state for upgrade
 // requests.
 type Upgrader struct {
-	tomb    tomb.Tomb
-	st      *upgrader.State
-	dataDir string
-	tag     names.Tag
+	tomb     tomb.Tomb
+	st       *upgrader.State
+	schedule *worker.MaintenanceScheduler
+	dataDir  string
+	tag      names.Tag
 }
 
 // NewUpgrader returns a new upgrader worker. It watches changes to the
@@ -43,12 +51,16 @@ type Upgrader struct {
 // download the tools for any new version into the given data directory.  If
 // an upgrade is needed, the worker will exit with an UpgradeReadyError
 // holding details of the requested upgrade. The tools will have been
-// downloaded and unpacked.
-func NewUpgrader(st *upgrader.State, agentConfig agent.Config) *Upgrader {
+// downloaded and unpacked. If schedule is not nil, upgrades are only
+// started within its maintenance windows. Only state servers are given
+// a schedule: other agents are not offered a new version until the
+// state servers are running it, so they follow straight away.
+func NewUpgrader(st *upgrader.State, agentConfig agent.Config, schedule *worker.MaintenanceScheduler) *Upgrader {
 	u := &Upgrader{
-		st:      st,
-		dataDir: agentConfig.DataDir(),
-		tag:     agentConfig.Tag(),
+		st:       st,
+		schedule: schedule,
+		dataDir:  agentConfig.DataDir(),
+		tag:      agentConfig.Tag(),
 	}
 	go func() {
 		defer u.tomb.Done()
@@ -137,6 +149,13 @@ func (u *Upgrader) loop() error {
 				wantVersion, version.Current)
 			continue
 		}
+		if u.schedule != nil {
+			if delay := u.schedule.Delay(); delay > 0 {
+				logger.Infof("upgrade to %v waiting %v for a maintenance window", wantVersion, delay)
+				retry = time.After(delay)
+				continue
+			}
+		}
 		logger.Infof("upgrade requested from %v to %v", currentTools.Version, wantVersion)
 		// TODO(dimitern) 2013-10-03 bug #1234715
 		// Add a testing HTTPS storage to verify the
@@ -171,16 +190,21 @@ func (u *Upgrader) ensureTools(agentTools *coretools.Tools, hostnameVerification
 		return nil
 	}
 	logger.Infof("fetching tools from %q", agentTools.URL)
-	client := utils.GetHTTPClient(hostnameVerification)
-	resp, err := client.Get(agentTools.URL)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad HTTP response: %v", resp.Status)
+	dl := downloader.NewWithOptions(downloader.Options{
+		URL:                  agentTools.URL,
+		HostnameVerification: hostnameVerification,
+		ExpectedSize:         agentTools.Size,
+		ExpectedSHA256:       agentTools.SHA256,
+		MaxRetries:           downloadRetries,
+	})
+	defer dl.Stop()
+	status := <-dl.Done()
+	if status.Err != nil {
+		return status.Err
 	}
-	err = agenttools.UnpackTools(u.dataDir, agentTools, resp.Body)
+	defer os.Remove(status.File.Name())
+	defer status.File.Close()
+	err := agenttools.UnpackTools(u.dataDir, agentTools, status.File)
 	if err != nil {
 		return fmt.Errorf("cannot unpack tools: %v", err)
 	}
@@ -29,6 +29,7 @@ import (
 	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/upgrader"
 )
 
@@ -81,8 +82,13 @@ func agentConfig(tag names.Tag, datadir string) agent.Config {
 }
 
 func (s *UpgraderSuite) makeUpgrader() *upgrader.Upgrader {
+	schedule := worker.NewMaintenanceScheduler(s.state.Environment())
+	return s.makeUpgraderWithSchedule(schedule)
+}
+
+func (s *UpgraderSuite) makeUpgraderWithSchedule(schedule *worker.MaintenanceScheduler) *upgrader.Upgrader {
 	config := agentConfig(s.machine.Tag(), s.DataDir())
-	return upgrader.NewUpgrader(s.state.Upgrader(), config)
+	return upgrader.NewUpgrader(s.state.Upgrader(), config, schedule)
 }
 
 func (s *UpgraderSuite) TestUpgraderSetsTools(c *gc.C) {
@@ -151,6 +157,55 @@ func (s *UpgraderSuite) TestUpgraderUpgradesImmediately(c *gc.C) {
 	envtesting.CheckTools(c, foundTools, newTools)
 }
 
+func (s *UpgraderSuite) TestUpgraderWaitsForMaintenanceWindow(c *gc.C) {
+	stor := s.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	// Allow maintenance only in a daily window that opens in two hours.
+	opens := time.Now().UTC().Add(2 * time.Hour)
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": fmt.Sprintf("%d %d * * * 1h", opens.Minute(), opens.Hour()),
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	u := s.makeUpgrader()
+	time.Sleep(coretesting.ShortWait)
+	err = u.Stop()
+	c.Assert(err, gc.IsNil)
+	_, err = agenttools.ReadTools(s.DataDir(), newTools.Version)
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *UpgraderSuite) TestUpgraderWithoutScheduleIgnoresMaintenanceWindow(c *gc.C) {
+	stor := s.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, gc.IsNil)
+	opens := time.Now().UTC().Add(2 * time.Hour)
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-windows": fmt.Sprintf("%d %d * * * 1h", opens.Minute(), opens.Hour()),
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	dummy.SetStorageDelay(coretesting.ShortWait)
+
+	u := s.makeUpgraderWithSchedule(nil)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldTools.Version,
+		NewTools:  newTools.Version,
+		DataDir:   s.DataDir(),
+	})
+}
+
 func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 	stor := s.Environ.Storage()
 	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
@@ -160,6 +215,8 @@ func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 	err := statetesting.SetAgentVersion(s.State, newTools.Version.Number)
 	c.Assert(err, gc.IsNil)
 
+	// Leave the retrying to the upgrader rather than the downloader.
+	s.PatchValue(upgrader.DownloadRetries, 0)
 	retryc := make(chan time.Time)
 	*upgrader.RetryAfter = func() <-chan time.Time {
 		c.Logf("replacement retry after")
@@ -241,6 +298,22 @@ func (s *UpgraderSuite) TestEnsureToolsChecksBeforeDownloading(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *UpgraderSuite) TestEnsureToolsVerifiesDownload(c *gc.C) {
+	stor := s.Environ.Storage()
+	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))
+	s.PatchValue(&version.Current, oldTools.Version)
+	newTools := envtesting.AssertUploadFakeToolsVersions(
+		c, stor, version.MustParseBinary("5.4.5-precise-amd64"))[0]
+	u := s.makeUpgrader()
+	defer u.Stop()
+
+	newTools.SHA256 = "abcd"
+	err := upgrader.EnsureTools(u, newTools, utils.VerifySSLHostnames)
+	c.Assert(err, gc.ErrorMatches, `cannot download ".*": expected sha256 "abcd", got ".*"`)
+	_, err = agenttools.ReadTools(s.DataDir(), newTools.Version)
+	c.Assert(err, gc.NotNil)
+}
+
 func (s *UpgraderSuite) TestUpgraderRefusesToDowngradeMinorVersions(c *gc.C) {
 	stor := s.Environ.Storage()
 	origTools := envtesting.PrimeTools(c, stor, s.DataDir(), version.MustParseBinary("5.4.3-precise-amd64"))